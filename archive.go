@@ -0,0 +1,126 @@
+package sqlAssister
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// ArchiveRows moves the records of fromTable matching whereClause, the body of a WHERE clause such as WhereFromStruct renders,
+// to toTable in a single statement, returning the number of records moved. No record can be deleted without being archived, or
+// archived without being deleted, even with concurrent writes, as the records inserted are exactly those the DELETE returned:
+//
+//	WITH "del" AS (DELETE FROM fromTable WHERE whereClause RETURNING columns) INSERT INTO toTable (columns) SELECT columns FROM "del"
+//
+// The two tables must have the same columns, compared by name from their TableMeta before anything is executed: the call fails
+// naming the columns only one of them has, after reading the metadata again in case it was cached before a migration. The columns
+// are listed by name, so they may be declared in another order. Only Postgres runs a DELETE inside WITH, other dialects fail with
+// ErrUnsupportedDialect. whereClause can't be empty, pass "true" to archive every record
+/*
+
+Example:
+
+	moved, err := Assister.ArchiveRows(ctx, "orders", "orders_archive", `"closed_at" < $1`, cutoff)
+	if err != nil {
+		return err
+	}
+*/
+func (ac Assister) ArchiveRows(ctx context.Context, fromTable, toTable, whereClause string, args ...any) (int64, error) {
+	if ac.dialect != Postgres {
+		return 0, unsupportedDialectf("archiving records in a single statement is not supported on %s, copy them with "+
+			"INSERT ... SELECT & delete them with the same WHERE in a WithSerializable transaction instead", ac.dialect)
+	}
+	err := utils.ValidateIdentifier(fromTable)
+	if err != nil {
+		return 0, err
+	}
+	err = utils.ValidateIdentifier(toTable)
+	if err != nil {
+		return 0, err
+	}
+	if strings.TrimSpace(whereClause) == "" {
+		return 0, errors.New("archiving records requires a WHERE clause, pass \"true\" to archive every record")
+	}
+
+	columns, err := ac.archiveColumns(ctx, fromTable, toTable)
+	if err != nil {
+		return 0, err
+	}
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		// Quoted as the metadata names them, regardless of the quoting mode
+		quoted[i] = utils.QuoteIdentifier(ac.dialect, column)
+	}
+	columnList := strings.Join(quoted, ", ")
+
+	query := "WITH " + ac.QuoteIdentifier("del") + " AS (DELETE FROM " + ac.QuoteIdentifier(fromTable) + " WHERE " + whereClause +
+		"\nRETURNING " + columnList + ") INSERT INTO " + ac.QuoteIdentifier(toTable) + " (" + columnList + ") SELECT " + columnList +
+		" FROM " + ac.QuoteIdentifier("del")
+	err = utils.QueryChecker(query, ac.queryChecks()...)
+	if err != nil {
+		return 0, err
+	}
+
+	results, err := ac.conn().ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return results.RowsAffected()
+}
+
+// archiveColumns returns the columns of fromTable in the order they are declared, failing when toTable's aren't the same.
+// A mismatch may come from metadata cached before a migration, it is read again before failing
+func (ac Assister) archiveColumns(ctx context.Context, fromTable, toTable string) ([]string, error) {
+	var from, to TableMeta
+	var fromOnly, toOnly []string
+	for attempt := 0; attempt < 2; attempt++ {
+		if attempt > 0 {
+			ac.InvalidateMetadata(fromTable, toTable)
+		}
+
+		var err error
+		from, err = ac.TableMeta(ctx, fromTable)
+		if err != nil {
+			return nil, err
+		}
+		to, err = ac.TableMeta(ctx, toTable)
+		if err != nil {
+			return nil, err
+		}
+
+		fromOnly, toOnly = columnDifference(from, to), columnDifference(to, from)
+		if len(fromOnly) == 0 && len(toOnly) == 0 {
+			columns := make([]string, len(from.Columns))
+			for i, column := range from.Columns {
+				columns[i] = column.Name
+			}
+			return columns, nil
+		}
+	}
+
+	var differences []string
+	if len(fromOnly) > 0 {
+		differences = append(differences, "only "+fromTable+" has "+strings.Join(fromOnly, ", "))
+	}
+	if len(toOnly) > 0 {
+		differences = append(differences, "only "+toTable+" has "+strings.Join(toOnly, ", "))
+	}
+
+	return nil, fmt.Errorf("cannot archive %s into %s, their columns differ: %s", fromTable, toTable, strings.Join(differences, " & "))
+}
+
+// columnDifference returns the columns of a that b doesn't have, in the order a declares them
+func columnDifference(a, b TableMeta) []string {
+	var columns []string
+	for _, column := range a.Columns {
+		if _, ok := b.Column(column.Name); !ok {
+			columns = append(columns, column.Name)
+		}
+	}
+
+	return columns
+}
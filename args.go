@@ -0,0 +1,89 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// normalizeArgs prepares args for binding on the dialect: registered converters are applied (see utils.RegisterArgConverter),
+// Durations are bound as the dialect measures time (see Duration) & typed nils such as a nil *string are replaced by
+// an untyped nil so every driver binds them as NULL
+func normalizeArgs(dialect Dialect, args []any) ([]any, error) {
+	if len(args) == 0 {
+		return args, nil
+	}
+
+	// A new slice so the caller's args are never modified
+	normalized := make([]any, len(args))
+	for i, arg := range args {
+		converted, err := utils.ConvertArg(arg)
+		if err != nil {
+			return nil, err
+		}
+		switch d := converted.(type) {
+		case Duration:
+			converted = durationArg(dialect, d)
+		case *Duration:
+			if d != nil {
+				converted = durationArg(dialect, *d)
+			}
+		}
+		if isTypedNil(converted) {
+			converted = nil
+		}
+		normalized[i] = converted
+	}
+
+	return normalized, nil
+}
+
+// isTypedNil reports whether v is a nil pointer held in a non nil interface
+func isTypedNil(v any) bool {
+	if v == nil {
+		return false
+	}
+
+	value := reflect.ValueOf(v)
+	return value.Kind() == reflect.Pointer && value.IsNil()
+}
+
+// argQuerier normalizes the args of every statement executed on q, see normalizeArgs
+type argQuerier struct {
+	q       querier
+	dialect Dialect
+}
+
+func (q argQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	args, err := normalizeArgs(q.dialect, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.q.ExecContext(ctx, query, args...)
+}
+
+func (q argQuerier) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return q.q.PrepareContext(ctx, query)
+}
+
+func (q argQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	args, err := normalizeArgs(q.dialect, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.q.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext can't return an error, when a converter fails the args are bound as given & the driver reports the problem on Scan
+func (q argQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	normalized, err := normalizeArgs(q.dialect, args)
+	if err == nil {
+		args = normalized
+	}
+
+	return q.q.QueryRowContext(ctx, query, args...)
+}
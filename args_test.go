@@ -0,0 +1,68 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+func TestTypedNilArgsBindAsNull(t *testing.T) {
+	db := openTestDB(t, bookTable)
+	ac := New(db, WithDialect(SQLite))
+
+	var nilInt *int
+	var nilString *string
+	var nilValuer *sql.NullInt64
+	inserts := []struct {
+		name     string
+		authorID any
+	}{
+		{"nil *int", nilInt},
+		{"nil *string", nilString},
+		{"nil valuer pointer", nilValuer},
+	}
+
+	for i, insert := range inserts {
+		err := ac.UpdateSingleRow(`INSERT INTO "books" ("id", "name", "author_id") VALUES (?, ?, ?)`, i+1, insert.name, insert.authorID)
+		if err != nil {
+			t.Fatalf("%s: %v", insert.name, err)
+		}
+	}
+
+	books, err := Select[testBook](context.Background(), ac, `SELECT * FROM "books" ORDER BY "id"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(books) != len(inserts) {
+		t.Fatalf("expected %d records, got %d", len(inserts), len(books))
+	}
+	for _, book := range books {
+		if book.AuthorID != nil {
+			t.Errorf("%s: expected NULL, got %d", book.Name, *book.AuthorID)
+		}
+	}
+
+	var nulls int
+	err = db.QueryRow(`SELECT COUNT(*) FROM "books" WHERE "author_id" IS NULL`).Scan(&nulls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nulls != len(inserts) {
+		t.Errorf("expected %d NULL author_ids, got %d", len(inserts), nulls)
+	}
+}
+
+func TestNormalizeArgsLeavesCallerArgs(t *testing.T) {
+	var nilInt *int
+	args := []any{nilInt, 1}
+	normalized, err := normalizeArgs(SQLite, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if normalized[0] != nil {
+		t.Errorf("expected the typed nil replaced by nil, got %#v", normalized[0])
+	}
+	if args[0] == nil {
+		t.Error("expected the caller's args to be left as they were")
+	}
+}
@@ -0,0 +1,256 @@
+package sqlAssister
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+var (
+	nullTimeType = reflect.TypeOf(sql.NullTime{})
+	nullBoolType = reflect.TypeOf(sql.NullBool{})
+)
+
+// decoding holds how the values rows.Scan can't read from text are decoded, from the Assister's options
+type decoding struct {
+	timeLayouts []string
+	truthy      []string
+	falsy       []string
+	// emptyNull reads NULL into a string as "", set for the fields it applies to, see WithEmptyAsNull
+	emptyNull bool
+	// cipher & encryptedText decrypt the fields tagged encrypted, encrypted is set for them, see WithFieldCipher
+	cipher        Cipher
+	encryptedText bool
+	encrypted     bool
+	// recovery recovers the panics of the destinations scanned into, see WithRecoverPanics
+	recovery panicRecovery
+}
+
+func (ac Assister) decoding() decoding {
+	return decoding{timeLayouts: ac.timeLayouts, truthy: ac.truthy, falsy: ac.falsy, cipher: ac.fieldCipher, encryptedText: ac.encryptedText,
+		recovery: ac.panicRecovery()}
+}
+
+// assignDest stores a value read from the driver into a scan destination, mirroring what rows.Scan does
+// for values that have already been read, e.g. to check a group of columns for NULL before assigning them
+func assignDest(dest any, src any) error {
+	if scanner, ok := dest.(sql.Scanner); ok {
+		return scanner.Scan(src)
+	}
+
+	return assignValue(reflect.ValueOf(dest).Elem(), src, decoding{})
+}
+
+// assignValue converts src, one of the driver value types (nil, int64, float64, bool, []byte, string or time.Time), into target.
+// Unlike rows.Scan it reads times & MySQL BIT(1) booleans from []byte, as drivers using a text protocol return them, see decodeDest.
+// Times & booleans read as text are decoded according to d
+func assignValue(target reflect.Value, src any, d decoding) error {
+	if target.Type() == nullTimeType {
+		if src == nil {
+			target.Set(reflect.Zero(nullTimeType))
+			return nil
+		}
+		var t time.Time
+		err := assignValue(reflect.ValueOf(&t).Elem(), src, d)
+		if err != nil {
+			return err
+		}
+		target.Set(reflect.ValueOf(sql.NullTime{Time: t, Valid: true}))
+		return nil
+	}
+	if target.Type() == nullBoolType {
+		if src == nil {
+			target.Set(reflect.Zero(nullBoolType))
+			return nil
+		}
+		var b bool
+		err := assignValue(reflect.ValueOf(&b).Elem(), src, d)
+		if err != nil {
+			return err
+		}
+		target.Set(reflect.ValueOf(sql.NullBool{Bool: b, Valid: true}))
+		return nil
+	}
+	if target.CanAddr() {
+		if scanner, ok := target.Addr().Interface().(sql.Scanner); ok {
+			return scanner.Scan(src)
+		}
+	}
+
+	if src == nil {
+		switch target.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+			target.Set(reflect.Zero(target.Type()))
+			return nil
+		case reflect.String:
+			if d.emptyNull {
+				target.SetString("")
+				return nil
+			}
+		}
+		return fmt.Errorf("converting NULL to %s is unsupported", target.Type())
+	}
+
+	switch target.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(target.Type().Elem())
+		err := assignValue(elem.Elem(), src, d)
+		if err != nil {
+			return err
+		}
+		target.Set(elem)
+		return nil
+	case reflect.Interface:
+		if b, ok := src.([]byte); ok {
+			src = append([]byte(nil), b...)
+		}
+		target.Set(reflect.ValueOf(src))
+		return nil
+	}
+
+	srcValue := reflect.ValueOf(src)
+	if b, ok := src.([]byte); ok && target.Kind() == reflect.Slice && target.Type().Elem().Kind() == reflect.Uint8 {
+		target.SetBytes(append([]byte(nil), b...))
+		return nil
+	}
+	if srcValue.Type().AssignableTo(target.Type()) {
+		target.Set(srcValue)
+		return nil
+	}
+
+	text, isText := asText(src)
+	if target.Type() == timeType {
+		if !isText {
+			return fmt.Errorf("unsupported conversion of %T into %s", src, target.Type())
+		}
+		t, err := utils.ParseTime(text, d.timeLayouts...)
+		if err != nil {
+			return err
+		}
+		target.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch target.Kind() {
+	case reflect.String:
+		switch v := src.(type) {
+		case time.Time:
+			target.SetString(v.Format(time.RFC3339Nano))
+		case int64, float64, bool:
+			target.SetString(fmt.Sprint(v))
+		default:
+			if !isText {
+				break
+			}
+			target.SetString(text)
+		}
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		var err error
+		switch v := src.(type) {
+		case int64:
+			n = v
+		default:
+			if !isText {
+				break
+			}
+			n, err = strconv.ParseInt(text, 10, 64)
+		}
+		if err == nil && (isText || srcValue.Kind() == reflect.Int64) {
+			if target.OverflowInt(n) {
+				return fmt.Errorf("value %d overflows %s", n, target.Type())
+			}
+			target.SetInt(n)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("converting %q to %s: %w", text, target.Type(), err)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		var err error
+		switch v := src.(type) {
+		case int64:
+			if v < 0 {
+				return fmt.Errorf("value %d overflows %s", v, target.Type())
+			}
+			n = uint64(v)
+		default:
+			if !isText {
+				break
+			}
+			n, err = strconv.ParseUint(text, 10, 64)
+		}
+		if err == nil && (isText || srcValue.Kind() == reflect.Int64) {
+			if target.OverflowUint(n) {
+				return fmt.Errorf("value %d overflows %s", n, target.Type())
+			}
+			target.SetUint(n)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("converting %q to %s: %w", text, target.Type(), err)
+		}
+	case reflect.Float32, reflect.Float64:
+		switch v := src.(type) {
+		case float64:
+			target.SetFloat(v)
+			return nil
+		case int64:
+			target.SetFloat(float64(v))
+			return nil
+		}
+		if isText {
+			f, err := strconv.ParseFloat(text, target.Type().Bits())
+			if err != nil {
+				return fmt.Errorf("converting %q to %s: %w", text, target.Type(), err)
+			}
+			target.SetFloat(f)
+			return nil
+		}
+	case reflect.Bool:
+		switch v := src.(type) {
+		case int64:
+			if v == 0 || v == 1 {
+				target.SetBool(v == 1)
+				return nil
+			}
+		case []byte:
+			// MySQL returns a BIT(1) column as a single raw byte
+			if len(v) == 1 && v[0] <= 1 {
+				target.SetBool(v[0] == 1)
+				return nil
+			}
+		}
+		if isText {
+			b, err := d.parseBool(text)
+			if err != nil {
+				return err
+			}
+			target.SetBool(b)
+			return nil
+		}
+		if v, ok := src.(int64); ok {
+			return fmt.Errorf("ambiguous boolean %d: only 0 & 1 are booleans", v)
+		}
+	}
+
+	return fmt.Errorf("unsupported conversion of %T into %s", src, target.Type())
+}
+
+// asText returns src as a string when it is textual
+func asText(src any) (string, bool) {
+	switch v := src.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	}
+
+	return "", false
+}
@@ -0,0 +1,151 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// mysqlRecord covers the column types MySQL's text protocol returns as []byte
+type mysqlRecord struct {
+	ID      int64        `db:"id"`
+	Price   float64      `db:"price"`
+	Amount  string       `db:"amount"`
+	Active  bool         `db:"active"`
+	Flag    bool         `db:"flag"`
+	Created time.Time    `db:"created_at"`
+	Day     time.Time    `db:"day"`
+	Updated *time.Time   `db:"updated_at"`
+	Deleted sql.NullTime `db:"deleted_at"`
+	Stock   *int64       `db:"stock"`
+	Ratio   *float64     `db:"ratio"`
+	Shipped *bool        `db:"shipped"`
+}
+
+var mysqlColumns = []string{"id", "price", "amount", "active", "flag", "created_at", "day", "updated_at", "deleted_at", "stock", "ratio", "shipped"}
+
+// mysqlTextRow returns a record as MySQL's text protocol does: every value that isn't NULL as the []byte of its text,
+// BIT columns as their raw bytes
+func mysqlTextRow(values ...string) []driver.Value {
+	row := make([]driver.Value, len(values))
+	for i, value := range values {
+		if value != "NULL" {
+			row[i] = []byte(value)
+		}
+	}
+
+	return row
+}
+
+// openMySQLFake returns an Assister on a sqlmock answering the query with rows given in the text protocol
+func openMySQLFake(t *testing.T, columns []string, rows ...[]driver.Value) *Assister {
+	t.Helper()
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		err := mock.ExpectationsWereMet()
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	mockRows := sqlmock.NewRows(columns)
+	for _, row := range rows {
+		mockRows.AddRow(row...)
+	}
+	mock.ExpectQuery("SELECT * FROM `records`").WillReturnRows(mockRows)
+
+	return New(db, WithDialect(MySQL))
+}
+
+func TestScanMySQLTextProtocol(t *testing.T) {
+	ac := openMySQLFake(t, mysqlColumns,
+		mysqlTextRow("1", "9.99", "12.50", "1", "\x01", "2024-03-01 12:30:45", "2024-03-01", "2024-03-02 08:00:00.123456", "2024-03-03 09:00:00", "7", "0.25", "0"),
+		mysqlTextRow("2", "-1.5e3", "0.00", "0", "\x00", "2024-03-01 12:30:45.5", "1999-12-31", "NULL", "NULL", "NULL", "NULL", "NULL"),
+	)
+
+	records, err := Select[mysqlRecord](context.Background(), ac, "SELECT * FROM `records`")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+
+	first := records[0]
+	if first.ID != 1 || first.Price != 9.99 || first.Amount != "12.50" || !first.Active || !first.Flag {
+		t.Errorf("expected the numbers & booleans decoded, got %+v", first)
+	}
+	if !first.Created.Equal(time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC)) || !first.Day.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the DATETIME & DATE decoded, got %v & %v", first.Created, first.Day)
+	}
+	if first.Updated == nil || !first.Updated.Equal(time.Date(2024, 3, 2, 8, 0, 0, 123456000, time.UTC)) {
+		t.Errorf("expected the *time.Time decoded with its fraction, got %v", first.Updated)
+	}
+	if !first.Deleted.Valid || !first.Deleted.Time.Equal(time.Date(2024, 3, 3, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the sql.NullTime decoded, got %+v", first.Deleted)
+	}
+	if first.Stock == nil || *first.Stock != 7 || first.Ratio == nil || *first.Ratio != 0.25 || first.Shipped == nil || *first.Shipped {
+		t.Errorf("expected the pointers set, got %v %v %v", first.Stock, first.Ratio, first.Shipped)
+	}
+
+	second := records[1]
+	if second.Price != -1500 || second.Active || second.Flag || second.Created.Nanosecond() != 500000000 {
+		t.Errorf("expected the second record decoded, got %+v", second)
+	}
+	if second.Updated != nil || second.Deleted.Valid || second.Stock != nil || second.Ratio != nil || second.Shipped != nil {
+		t.Errorf("expected the NULLs left unset, got %+v", second)
+	}
+}
+
+func TestGetMySQLTextProtocol(t *testing.T) {
+	ac := openMySQLFake(t, []string{"created_at"}, mysqlTextRow("2024-03-01 12:30:45"))
+	created, err := Get[time.Time](context.Background(), ac, "SELECT * FROM `records`")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created.Equal(time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC)) {
+		t.Errorf("expected the DATETIME decoded, got %v", created)
+	}
+
+	ac = openMySQLFake(t, []string{"flag"}, mysqlTextRow("\x01"))
+	flag, err := Get[bool](context.Background(), ac, "SELECT * FROM `records`")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !flag {
+		t.Error("expected the BIT(1) decoded as true")
+	}
+}
+
+func TestScanMySQLTextProtocolFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		column string
+		value  string
+		want   string
+	}{
+		{"unknown time layout", "created_at", "01/03/2024", "matches none of the layouts"},
+		{"not a boolean", "active", "yes please", "active"},
+		{"wide BIT", "flag", "\x02", "flag"},
+		{"not a number", "id", "twelve", "id"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ac := openMySQLFake(t, []string{test.column}, mysqlTextRow(test.value))
+			_, err := Select[mysqlRecord](context.Background(), ac, "SELECT * FROM `records`")
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("expected an error mentioning %q, got %v", test.want, err)
+			}
+		})
+	}
+}
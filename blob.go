@@ -0,0 +1,340 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// defaultBlobChunkSize is how many bytes of a blob are read or written per statement unless WithBlobChunkSize says otherwise
+const defaultBlobChunkSize = 1 << 20
+
+// blobSourceAlias names the CTE wrapping the query of StreamBlobTo, its first column being the blob
+const blobSourceAlias = "blob_source"
+
+// WithBlobChunkSize sets how many bytes of a blob StreamBlobTo reads & StreamBlobFrom writes per statement. Defaults to 1MiB
+func WithBlobChunkSize(size int) Option {
+	return func(ac *Assister) {
+		ac.blobChunkSize = size
+	}
+}
+
+// WithMaxBlobSize makes StreamBlobTo & StreamBlobFrom fail once a blob is larger than size bytes,
+// before any of it is written out when its size is known up front. Blobs are unbounded by default
+func WithMaxBlobSize(size int64) Option {
+	return func(ac *Assister) {
+		ac.maxBlobSize = size
+	}
+}
+
+// WithBlobProgress returns a copy of the Assister calling progress with the number of bytes transferred so far
+// after every chunk StreamBlobTo or StreamBlobFrom transfers
+/*
+
+Example:
+
+	err := Assister.WithBlobProgress(func(transferred int64) {
+		log.Printf("%s: %d / %d bytes", name, transferred, size)
+	}).StreamBlobTo(ctx, `SELECT "content" FROM "files" WHERE "id" = $1`, w, fileId)
+	if err != nil {
+		return err
+	}
+*/
+func (ac Assister) WithBlobProgress(progress func(transferred int64)) *Assister {
+	ac.blobProgress = progress
+	return &ac
+}
+
+// StreamBlobTo writes the blob in the first column of query's first record to w without holding all of it in memory.
+// On Postgres & SQLite the blob is read WithBlobChunkSize bytes at a time, query being run again for every chunk within a transaction,
+// the Assister's own when it is bound to one. Other dialects read the whole blob in a single statement.
+// A NULL blob writes nothing. Returns sql.ErrNoRows when query returns no record
+/*
+
+Example:
+
+	err := Assister.StreamBlobTo(ctx, `SELECT "content" FROM "files" WHERE "id" = $1`, w, fileId)
+	if err != nil {
+		return err
+	}
+*/
+func (ac Assister) StreamBlobTo(ctx context.Context, query string, w io.Writer, args ...any) error {
+	err := utils.QueryChecker(query, ac.queryChecks()...)
+	if err != nil {
+		return err
+	}
+
+	if ac.dialect != Postgres && ac.dialect != SQLite {
+		return ac.readBlob(ctx, query, w, args)
+	}
+
+	run := func(tx *TxAssister) error {
+		return tx.readBlobChunks(ctx, query, w, args)
+	}
+	if ac.tx != nil {
+		return run(ac.tx)
+	}
+
+	if ac.dialect == Postgres {
+		// Every chunk must read the same version of the blob, not whatever was committed since the previous chunk
+		return ac.WithRepeatableRead(ctx, run)
+	}
+
+	return ac.WithTransaction(ctx, run)
+}
+
+// readBlob writes the blob of query to w read in a single statement
+func (ac Assister) readBlob(ctx context.Context, query string, w io.Writer, args []any) error {
+	rows, err := ac.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		err = rows.Err()
+		if err == nil {
+			err = sql.ErrNoRows
+		}
+		return err
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	// The blob is written straight from the driver's buffer rather than copied
+	var blob sql.RawBytes
+	dest := make([]any, len(columns))
+	dest[0] = &blob
+	for i := 1; i < len(dest); i++ {
+		dest[i] = new(any)
+	}
+	err = rows.Scan(dest...)
+	if err != nil {
+		return err
+	}
+
+	err = ac.checkBlobSize(int64(len(blob)))
+	if err != nil {
+		return err
+	}
+	if len(blob) > 0 {
+		_, err = w.Write(blob)
+		if err != nil {
+			return err
+		}
+		ac.reportBlobProgress(int64(len(blob)))
+	}
+
+	return rows.Close()
+}
+
+// readBlobChunks writes the blob of query to w reading it a chunk at a time with substring
+func (ac Assister) readBlobChunks(ctx context.Context, query string, w io.Writer, args []any) error {
+	var size sql.NullInt64
+	err := ac.conn().QueryRowContext(ctx, ac.blobSQL(query, len(args), "length"), args...).Scan(&size)
+	if err != nil {
+		return err
+	}
+	if !size.Valid {
+		return nil
+	}
+	err = ac.checkBlobSize(size.Int64)
+	if err != nil {
+		return err
+	}
+
+	chunkSize := int64(ac.blobChunkBytes())
+	chunkQuery := ac.blobSQL(query, len(args), "substring")
+	chunkArgs := append(args[:len(args):len(args)], nil, chunkSize)
+	var chunk []byte
+	for offset := int64(0); offset < size.Int64; offset += int64(len(chunk)) {
+		// substring counts from 1
+		chunkArgs[len(args)] = offset + 1
+		err = ac.conn().QueryRowContext(ctx, chunkQuery, chunkArgs...).Scan(&chunk)
+		if err != nil {
+			return err
+		}
+		if len(chunk) == 0 {
+			return fmt.Errorf("blob ended after %d of its %d bytes", offset, size.Int64)
+		}
+
+		_, err = w.Write(chunk)
+		if err != nil {
+			return err
+		}
+		ac.reportBlobProgress(offset + int64(len(chunk)))
+	}
+
+	return nil
+}
+
+// blobSQL wraps query, taking numArgs arguments, in a CTE reading either the length or a substring of its blob,
+// `WITH blob_source (blob) AS (query) SELECT substring(blob FROM $n FOR $m) FROM blob_source`.
+// A CTE is used rather than a subquery as SQLite can't name the columns of a subquery
+func (ac Assister) blobSQL(query string, numArgs int, read string) string {
+	blob := "blob"
+	if ac.dialect == SQLite {
+		// SQLite counts the length of text in characters & the blob may have been stored as text
+		blob = "CAST(blob AS BLOB)"
+	}
+
+	var expr string
+	switch {
+	case read == "length" && ac.dialect == SQLite:
+		expr = "length(" + blob + ")"
+	case read == "length":
+		expr = "octet_length(" + blob + ")"
+	case ac.dialect == SQLite:
+		expr = fmt.Sprintf("substr(%s, %s, %s)", blob, ac.dialect.Placeholder(numArgs+1), ac.dialect.Placeholder(numArgs+2))
+	default:
+		expr = fmt.Sprintf("substring(%s FROM %s FOR %s)", blob, ac.dialect.Placeholder(numArgs+1), ac.dialect.Placeholder(numArgs+2))
+	}
+
+	return "WITH " + blobSourceAlias + " (blob) AS (" + query + ") SELECT " + expr + " FROM " + blobSourceAlias
+}
+
+// StreamBlobFrom writes everything read from r into column of the single record in table where keyColumn equals key,
+// without holding all of it in memory. The blob is written WithBlobChunkSize bytes at a time, the first chunk replacing
+// the column's value & every other being appended to it, within a transaction, the Assister's own when it is bound to one,
+// so a failure part way through leaves the previous value in place. Returns the number of bytes written
+/*
+
+Example:
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	written, err := Assister.StreamBlobFrom(ctx, "files", "content", "id", fileId, f)
+	if err != nil {
+		return err
+	}
+*/
+func (ac Assister) StreamBlobFrom(ctx context.Context, table string, column string, keyColumn string, key any, r io.Reader) (int64, error) {
+	for _, name := range []string{table, column, keyColumn} {
+		err := utils.ValidateIdentifier(name)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var written int64
+	run := func(tx *TxAssister) error {
+		var err error
+		written, err = tx.writeBlobChunks(ctx, table, column, keyColumn, key, r)
+		return err
+	}
+
+	var err error
+	if ac.tx != nil {
+		err = run(ac.tx)
+	} else {
+		err = ac.WithTransaction(ctx, run)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return written, nil
+}
+
+// writeBlobChunks replaces column with the first chunk read from r & appends every other chunk to it
+func (ac Assister) writeBlobChunks(ctx context.Context, table string, column string, keyColumn string, key any, r io.Reader) (int64, error) {
+	chunk := make([]byte, ac.blobChunkBytes())
+	var written int64
+	for first := true; ; first = false {
+		n, readErr := io.ReadFull(r, chunk)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			return 0, readErr
+		}
+		if n == 0 && !first {
+			return written, nil
+		}
+		if ac.maxBlobSize > 0 && written+int64(n) > ac.maxBlobSize {
+			return 0, fmt.Errorf("blob exceeds the maximum blob size of %d bytes", ac.maxBlobSize)
+		}
+
+		query, args := ac.blobChunkSQL(table, column, keyColumn, key, chunk[:n], first)
+		results, err := ac.conn().ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+		if first {
+			err = ac.checkRowsAffected(results, 1)
+			if err != nil {
+				return 0, err
+			}
+		}
+		written += int64(n)
+		ac.reportBlobProgress(written)
+
+		if readErr != nil {
+			return written, nil
+		}
+	}
+}
+
+// blobChunkSQL renders the UPDATE setting column to chunk, or appending chunk to it when it isn't the first
+func (ac Assister) blobChunkSQL(table string, column string, keyColumn string, key any, chunk []byte, first bool) (string, []any) {
+	b := newSQLBuilder(&ac)
+	b.WriteString("UPDATE ")
+	b.writeIdentifier(table)
+	b.WriteString(" SET ")
+	b.writeIdentifier(column)
+	b.WriteString(" = ")
+	switch {
+	case first:
+		b.bind(chunk)
+	case ac.dialect == MySQL:
+		b.WriteString("CONCAT(")
+		b.writeIdentifier(column)
+		b.WriteString(", ")
+		b.bind(chunk)
+		b.WriteString(")")
+	case ac.dialect == SQLite:
+		// || concatenates as text on SQLite, the bytes are kept but must be cast back to a blob
+		b.WriteString("CAST(")
+		b.writeIdentifier(column)
+		b.WriteString(" || ")
+		b.bind(chunk)
+		b.WriteString(" AS BLOB)")
+	default:
+		b.writeIdentifier(column)
+		b.WriteString(" || ")
+		b.bind(chunk)
+	}
+	b.WriteString(" WHERE ")
+	b.writeIdentifier(keyColumn)
+	b.WriteString(" = ")
+	b.bind(key)
+
+	return b.String(), b.args
+}
+
+func (ac Assister) blobChunkBytes() int {
+	if ac.blobChunkSize > 0 {
+		return ac.blobChunkSize
+	}
+
+	return defaultBlobChunkSize
+}
+
+func (ac Assister) checkBlobSize(size int64) error {
+	if ac.maxBlobSize > 0 && size > ac.maxBlobSize {
+		return fmt.Errorf("blob of %d bytes exceeds the maximum blob size of %d bytes", size, ac.maxBlobSize)
+	}
+
+	return nil
+}
+
+func (ac Assister) reportBlobProgress(transferred int64) {
+	if ac.blobProgress != nil {
+		ac.blobProgress(transferred)
+	}
+}
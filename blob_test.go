@@ -0,0 +1,157 @@
+package sqlAssister
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+const fileTable = `CREATE TABLE "files" ("id" INTEGER PRIMARY KEY, "content" BLOB)`
+
+// payload returns size pseudo random bytes, every byte value included
+func payload(size int) []byte {
+	data := make([]byte, size)
+	rand.New(rand.NewSource(int64(size))).Read(data)
+
+	return data
+}
+
+func TestBlobRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	const chunkSize = 16 << 10
+	data := payload(3<<20 + 123)
+	db := openTestDB(t, fileTable, `INSERT INTO "files" ("id") VALUES (1)`)
+
+	var progress []int64
+	ac := New(db, WithDialect(SQLite), WithBlobChunkSize(chunkSize)).
+		WithBlobProgress(func(transferred int64) { progress = append(progress, transferred) })
+
+	written, err := ac.StreamBlobFrom(ctx, "files", "content", "id", 1, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != int64(len(data)) {
+		t.Errorf("expected %d bytes written, got %d", len(data), written)
+	}
+	chunks := (len(data) + chunkSize - 1) / chunkSize
+	if len(progress) != chunks || progress[len(progress)-1] != written {
+		t.Errorf("expected %d progress reports ending at %d, got %d ending at %v", chunks, written, len(progress), progress[len(progress)-1])
+	}
+
+	// The blob is stored whole, as a blob
+	var stored []byte
+	var storedType string
+	err = db.QueryRow(`SELECT "content", typeof("content") FROM "files" WHERE "id" = 1`).Scan(&stored, &storedType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if storedType != "blob" || !bytes.Equal(stored, data) {
+		t.Fatalf("expected the %d bytes stored as a blob, got %d bytes of %s", len(data), len(stored), storedType)
+	}
+
+	progress = nil
+	var read bytes.Buffer
+	err = ac.StreamBlobTo(ctx, `SELECT "content" FROM "files" WHERE "id" = ?`, &read, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(read.Bytes(), data) {
+		t.Errorf("expected the %d bytes read back, got %d different bytes", len(data), read.Len())
+	}
+	if len(progress) != chunks {
+		t.Errorf("expected the blob read in %d chunks, got %d", chunks, len(progress))
+	}
+	for i := 1; i < len(progress); i++ {
+		if progress[i]-progress[i-1] > chunkSize {
+			t.Fatalf("expected chunks of at most %d bytes, read %d", chunkSize, progress[i]-progress[i-1])
+		}
+	}
+}
+
+func TestBlobReplaced(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openTestDB(t, fileTable, `INSERT INTO "files" VALUES (1, x'0102030405060708090a')`), WithDialect(SQLite), WithBlobChunkSize(4))
+
+	_, err := ac.StreamBlobFrom(ctx, "files", "content", "id", 1, strings.NewReader("abc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var read bytes.Buffer
+	err = ac.StreamBlobTo(ctx, `SELECT "content" FROM "files" WHERE "id" = ?`, &read, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.String() != "abc" {
+		t.Errorf("expected the previous blob replaced, got %q", read.String())
+	}
+
+	// An empty reader leaves an empty blob
+	_, err = ac.StreamBlobFrom(ctx, "files", "content", "id", 1, strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	read.Reset()
+	err = ac.StreamBlobTo(ctx, `SELECT "content" FROM "files" WHERE "id" = ?`, &read, 1)
+	if err != nil || read.Len() != 0 {
+		t.Errorf("expected an empty blob, got %q & %v", read.String(), err)
+	}
+}
+
+func TestBlobNullAndMissing(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openTestDB(t, fileTable, `INSERT INTO "files" ("id") VALUES (1)`), WithDialect(SQLite))
+
+	var read bytes.Buffer
+	err := ac.StreamBlobTo(ctx, `SELECT "content" FROM "files" WHERE "id" = ?`, &read, 1)
+	if err != nil || read.Len() != 0 {
+		t.Errorf("expected a NULL blob to write nothing, got %d bytes & %v", read.Len(), err)
+	}
+
+	err = ac.StreamBlobTo(ctx, `SELECT "content" FROM "files" WHERE "id" = ?`, &read, 2)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows for a missing record, got %v", err)
+	}
+
+	_, err = ac.StreamBlobFrom(ctx, "files", "content", "id", 2, strings.NewReader("abc"))
+	if err == nil {
+		t.Error("expected writing the blob of a missing record to fail")
+	}
+}
+
+func TestBlobMaxSize(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t, fileTable, `INSERT INTO "files" VALUES (1, x'00')`)
+	ac := New(db, WithDialect(SQLite), WithBlobChunkSize(8), WithMaxBlobSize(20))
+
+	var progress int64
+	_, err := ac.WithBlobProgress(func(transferred int64) { progress = transferred }).
+		StreamBlobFrom(ctx, "files", "content", "id", 1, bytes.NewReader(payload(21)))
+	if err == nil || !strings.Contains(err.Error(), "maximum blob size") {
+		t.Fatalf("expected writing 21 bytes to exceed the maximum, got %v", err)
+	}
+	if progress != 16 {
+		t.Errorf("expected the chunks before the limit written, got %d bytes", progress)
+	}
+	var stored []byte
+	err = db.QueryRow(`SELECT "content" FROM "files" WHERE "id" = 1`).Scan(&stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stored, []byte{0}) {
+		t.Errorf("expected the failed write rolled back, got %v", stored)
+	}
+
+	_, err = New(db, WithDialect(SQLite)).StreamBlobFrom(ctx, "files", "content", "id", 1, bytes.NewReader(payload(21)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var read bytes.Buffer
+	err = ac.StreamBlobTo(ctx, `SELECT "content" FROM "files" WHERE "id" = ?`, &read, 1)
+	if err == nil || !strings.Contains(err.Error(), "maximum blob size") || read.Len() != 0 {
+		t.Errorf("expected reading 21 bytes to fail before writing any, got %d bytes & %v", read.Len(), err)
+	}
+}
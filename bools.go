@@ -0,0 +1,106 @@
+package sqlAssister
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+var (
+	// DefaultTruthy are the strings read as true when scanned into a bool, compared case insensitively.
+	// The first is what a true field tagged boolchar is written as
+	DefaultTruthy = []string{"Y", "true", "t", "yes", "1", "on"}
+	// DefaultFalsy are the strings read as false when scanned into a bool, compared case insensitively.
+	// The first is what a false field tagged boolchar is written as
+	DefaultFalsy = []string{"N", "false", "f", "no", "0", "off"}
+)
+
+// WithBoolStrings sets the strings read as true & false when a text column is scanned into a bool, compared case insensitively
+// after trimming spaces, for schemas storing booleans as char 'Y'/'N' or text 'true'/'false'. Numeric columns are read as
+// true for 1 & false for 0. A value matching neither list, or both, fails to scan rather than being guessed.
+// Fields tagged `db:"column,boolchar"` are written as the first truthy or falsy string. Defaults to DefaultTruthy & DefaultFalsy
+/*
+
+Example:
+
+	statementAssister = sqlAssister.New(db, sqlAssister.WithBoolStrings([]string{"J", "ja"}, []string{"N", "nein"}))
+
+	type Member struct {
+		ID     int64 `db:"id,pk"`
+		Active bool  `db:"active,boolchar"`
+	}
+*/
+func WithBoolStrings(truthy []string, falsy []string) Option {
+	return func(ac *Assister) {
+		ac.truthy = truthy
+		ac.falsy = falsy
+	}
+}
+
+func (d decoding) boolStrings() ([]string, []string) {
+	if d.truthy == nil && d.falsy == nil {
+		return DefaultTruthy, DefaultFalsy
+	}
+
+	return d.truthy, d.falsy
+}
+
+// parseBool reads a boolean stored as text
+func (d decoding) parseBool(text string) (bool, error) {
+	truthy, falsy := d.boolStrings()
+	text = strings.TrimSpace(text)
+	isTrue := containsFold(truthy, text)
+	isFalse := containsFold(falsy, text)
+
+	switch {
+	case isTrue && isFalse:
+		return false, fmt.Errorf("ambiguous boolean %q: it is both truthy %q & falsy %q", text, truthy, falsy)
+	case isTrue:
+		return true, nil
+	case isFalse:
+		return false, nil
+	}
+
+	return false, fmt.Errorf("boolean %q is neither truthy %q nor falsy %q", text, truthy, falsy)
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// boolArg returns the string a boolean field tagged boolchar is written as
+func (ac Assister) boolArg(v any) any {
+	truthy, falsy := ac.decoding().boolStrings()
+
+	var b bool
+	switch v := v.(type) {
+	case bool:
+		b = v
+	case *bool:
+		if v == nil {
+			return nil
+		}
+		b = *v
+	case sql.NullBool:
+		if !v.Valid {
+			return nil
+		}
+		b = v.Bool
+	default:
+		return v
+	}
+
+	if b && len(truthy) > 0 {
+		return truthy[0]
+	}
+	if !b && len(falsy) > 0 {
+		return falsy[0]
+	}
+	return b
+}
@@ -0,0 +1,148 @@
+package sqlAssister
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const flagTable = `CREATE TABLE "flags" ("id" INTEGER PRIMARY KEY, "int_flag" INTEGER, "char_flag" CHAR(1), "text_flag" TEXT)`
+
+type flagRecord struct {
+	ID       int64 `db:"id,pk"`
+	IntFlag  bool  `db:"int_flag"`
+	CharFlag bool  `db:"char_flag,boolchar"`
+	TextFlag *bool `db:"text_flag,boolchar"`
+}
+
+// storedFlags returns the flags of the record as stored
+func storedFlags(t *testing.T, ac *Assister, id int64) (intFlag any, charFlag any, textFlag any) {
+	t.Helper()
+	err := ac.DB.QueryRow(`SELECT "int_flag", "char_flag", "text_flag" FROM "flags" WHERE "id" = ?`, id).Scan(&intFlag, &charFlag, &textFlag)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return intFlag, charFlag, textFlag
+}
+
+func TestBoolRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	yes, no := true, false
+	tests := []struct {
+		name   string
+		opts   []Option
+		record flagRecord
+		// the text the boolchar fields are stored as, NULL as nil
+		char, text any
+	}{
+		{"default true", nil, flagRecord{ID: 1, IntFlag: true, CharFlag: true, TextFlag: &yes}, "Y", "Y"},
+		{"default false", nil, flagRecord{ID: 2, CharFlag: false, TextFlag: &no}, "N", "N"},
+		{"nil pointer", nil, flagRecord{ID: 3, CharFlag: true}, "Y", nil},
+		{"true & false strings", []Option{WithBoolStrings([]string{"true"}, []string{"false"})},
+			flagRecord{ID: 4, IntFlag: true, CharFlag: false, TextFlag: &yes}, "false", "true"},
+		{"localized", []Option{WithBoolStrings([]string{"J", "ja"}, []string{"N", "nein"})},
+			flagRecord{ID: 5, CharFlag: true, TextFlag: &no}, "J", "N"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ac := New(openTestDB(t, flagTable), append([]Option{WithDialect(SQLite)}, test.opts...)...)
+			_, err := InsertAll(ctx, ac, "flags", []flagRecord{test.record})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			intFlag, charFlag, textFlag := storedFlags(t, ac, test.record.ID)
+			expectedInt := int64(0)
+			if test.record.IntFlag {
+				expectedInt = 1
+			}
+			if intFlag != expectedInt {
+				t.Errorf("expected the untagged bool stored as %d, got %v", expectedInt, intFlag)
+			}
+			if asString(charFlag) != test.char || asString(textFlag) != test.text {
+				t.Errorf("expected the boolchar fields stored as %v & %v, got %v & %v", test.char, test.text, charFlag, textFlag)
+			}
+
+			read, err := GetByID[flagRecord](ctx, ac, "flags", Key{"id", test.record.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if read.IntFlag != test.record.IntFlag || read.CharFlag != test.record.CharFlag ||
+				(read.TextFlag == nil) != (test.record.TextFlag == nil) ||
+				(read.TextFlag != nil && *read.TextFlag != *test.record.TextFlag) {
+				t.Errorf("expected %+v read back, got %+v", test.record, read)
+			}
+
+			// Updating writes the same representation, false included
+			test.record.CharFlag = !test.record.CharFlag
+			err = ac.UpdateStructFields(ctx, "flags", &test.record, []string{"CharFlag"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			read, err = GetByID[flagRecord](ctx, ac, "flags", Key{"id", test.record.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if read.CharFlag != test.record.CharFlag {
+				t.Errorf("expected the updated flag %t read back, got %t", test.record.CharFlag, read.CharFlag)
+			}
+		})
+	}
+}
+
+// asString returns the text SQLite returned, as string or []byte, or nil for NULL
+func asString(v any) any {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+
+	return v
+}
+
+func TestBoolScanRepresentations(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openTestDB(t, `CREATE TABLE "stored" ("id" INTEGER PRIMARY KEY, "value")`,
+		`INSERT INTO "stored" VALUES (1, 1), (2, 0), (3, 'Y'), (4, 'n'), (5, 'TRUE'), (6, 'false'), (7, ' yes '), (8, 'Off'),
+			(9, 't'), (10, 'F'), (11, '1'), (12, '0')`), WithDialect(SQLite))
+
+	values, err := Select[bool](ctx, ac, `SELECT "value" FROM "stored" ORDER BY "id"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []bool{true, false, true, false, true, false, true, false, true, false, true, false}
+	if len(values) != len(expected) {
+		t.Fatalf("expected %d values, got %v", len(expected), values)
+	}
+	for i := range expected {
+		if values[i] != expected[i] {
+			t.Errorf("record %d: expected %t, got %t", i+1, expected[i], values[i])
+		}
+	}
+}
+
+func TestBoolScanRefusals(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []Option
+		value string
+		want  string
+	}{
+		{"unknown string", nil, `'maybe'`, "neither truthy"},
+		{"number other than 0 & 1", nil, `2`, "only 0 & 1 are booleans"},
+		{"empty string", nil, `''`, "neither truthy"},
+		{"ambiguous", []Option{WithBoolStrings([]string{"Y", "X"}, []string{"N", "x"})}, `'x'`, "ambiguous"},
+		{"default strings replaced", []Option{WithBoolStrings([]string{"J"}, []string{"N"})}, `'yes'`, "neither truthy"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ac := New(openTestDB(t), append([]Option{WithDialect(SQLite)}, test.opts...)...)
+			_, err := Get[bool](context.Background(), ac, `SELECT `+test.value)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("expected an error mentioning %q, got %v", test.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,488 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The query builder assembles the common single table statements without hand written SQL.
+// It is NOT an ORM: there are no relations & nothing is loaded lazily, it only renders SQL for the Assister's dialect.
+// Every identifier is quoted & every value is bound as an argument, the rendered SQL can be inspected with SQL()
+// A query is NOT safe for concurrent use while it is being built as Where, Set & friends modify it.
+// Once built it is only read, so it can be rendered & executed by several goroutines at once
+
+// TableQuery is the starting point of a statement against a single table
+type TableQuery struct {
+	ac    *Assister
+	table string
+}
+
+// Table starts building a statement against the table
+/*
+
+Example:
+
+	query := Assister.Table("books").
+		Select("id", "name").
+		Where(sqlAssister.Eq("author_id", authorId)).
+		OrderBy("created_at DESC").
+		Limit(20)
+
+	books, err := sqlAssister.Fetch[Book](ctx, query)
+	if err != nil {
+		return nil, err
+	}
+*/
+func (ac Assister) Table(name string) *TableQuery {
+	return &TableQuery{ac: &ac, table: name}
+}
+
+// Select starts a SELECT of the columns, selecting every column when none are given
+func (t *TableQuery) Select(columns ...string) *SelectQuery {
+	return &SelectQuery{table: t, columns: columns}
+}
+
+// Insert starts an INSERT, columns & values are added with Set
+func (t *TableQuery) Insert() *InsertQuery {
+	return &InsertQuery{table: t}
+}
+
+// Update starts an UPDATE, columns & values are added with Set
+func (t *TableQuery) Update() *UpdateQuery {
+	return &UpdateQuery{table: t}
+}
+
+// Delete starts a DELETE
+func (t *TableQuery) Delete() *DeleteQuery {
+	return &DeleteQuery{table: t}
+}
+
+// Cond is a condition rendered into a WHERE clause
+type Cond interface {
+	writeSQL(b *sqlBuilder)
+}
+
+type compareCond struct {
+	column string
+	op     string
+	value  any
+}
+
+func (c compareCond) writeSQL(b *sqlBuilder) {
+	b.writeIdentifier(c.column)
+	b.WriteString(" " + c.op + " ")
+	b.bind(c.value)
+}
+
+// Eq renders column = value
+func Eq(column string, value any) Cond { return compareCond{column, "=", value} }
+
+// Ne renders column <> value
+func Ne(column string, value any) Cond { return compareCond{column, "<>", value} }
+
+// Gt renders column > value
+func Gt(column string, value any) Cond { return compareCond{column, ">", value} }
+
+// Gte renders column >= value
+func Gte(column string, value any) Cond { return compareCond{column, ">=", value} }
+
+// Lt renders column < value
+func Lt(column string, value any) Cond { return compareCond{column, "<", value} }
+
+// Lte renders column <= value
+func Lte(column string, value any) Cond { return compareCond{column, "<=", value} }
+
+// Like renders column LIKE pattern
+func Like(column string, pattern any) Cond { return compareCond{column, "LIKE", pattern} }
+
+type nullCond struct {
+	column string
+	not    bool
+}
+
+func (c nullCond) writeSQL(b *sqlBuilder) {
+	b.writeIdentifier(c.column)
+	if c.not {
+		b.WriteString(" IS NOT NULL")
+		return
+	}
+	b.WriteString(" IS NULL")
+}
+
+// IsNull renders column IS NULL
+func IsNull(column string) Cond { return nullCond{column: column} }
+
+// IsNotNull renders column IS NOT NULL
+func IsNotNull(column string) Cond { return nullCond{column: column, not: true} }
+
+type inCond struct {
+	column string
+	values []any
+}
+
+func (c inCond) writeSQL(b *sqlBuilder) {
+	// An empty IN list is invalid SQL, it can never match so render a condition that is always false
+	if len(c.values) == 0 {
+		b.WriteString("1 = 0")
+		return
+	}
+
+	b.writeIdentifier(c.column)
+	b.WriteString(" IN (")
+	for i, value := range c.values {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.bind(value)
+	}
+	b.WriteString(")")
+}
+
+// In renders column IN (values...)
+func In(column string, values ...any) Cond { return inCond{column, values} }
+
+type groupCond struct {
+	op    string
+	conds []Cond
+}
+
+func (c groupCond) writeSQL(b *sqlBuilder) {
+	// An empty group is invalid SQL, render the identity of its operator: nothing to hold for AND, nothing to match for OR
+	if len(c.conds) == 0 {
+		if c.op == "AND" {
+			b.WriteString("1 = 1")
+		} else {
+			b.WriteString("1 = 0")
+		}
+		return
+	}
+
+	b.WriteString("(")
+	for i, cond := range c.conds {
+		if i > 0 {
+			b.WriteString(" " + c.op + " ")
+		}
+		cond.writeSQL(b)
+	}
+	b.WriteString(")")
+}
+
+// And renders (cond AND cond ...), 1 = 1 without conditions
+func And(conds ...Cond) Cond { return groupCond{"AND", conds} }
+
+// Or renders (cond OR cond ...), 1 = 0 without conditions
+func Or(conds ...Cond) Cond { return groupCond{"OR", conds} }
+
+// SelectQuery builds a SELECT statement
+type SelectQuery struct {
+	table   *TableQuery
+	columns []string
+	where   []Cond
+	orderBy []string
+	limit   int
+	offset  int
+}
+
+// Where adds conditions that must all hold, repeated calls are joined with AND
+func (q *SelectQuery) Where(conds ...Cond) *SelectQuery {
+	q.where = append(q.where, conds...)
+	return q
+}
+
+// OrderBy adds sort terms of the form "column" or "column ASC|DESC"
+func (q *SelectQuery) OrderBy(terms ...string) *SelectQuery {
+	q.orderBy = append(q.orderBy, terms...)
+	return q
+}
+
+// Limit caps the number of records returned
+func (q *SelectQuery) Limit(limit int) *SelectQuery {
+	q.limit = limit
+	return q
+}
+
+// Offset skips the first records returned. Without a Limit, MySQL & SQLite which only take OFFSET after a LIMIT are given
+// the largest one they take
+func (q *SelectQuery) Offset(offset int) *SelectQuery {
+	q.offset = offset
+	return q
+}
+
+// SQL renders the statement & its arguments without executing it
+func (q *SelectQuery) SQL() (string, []any, error) {
+	b := newSQLBuilder(q.table.ac)
+	b.WriteString("SELECT ")
+	if len(q.columns) == 0 {
+		b.WriteString("*")
+	}
+	for i, column := range q.columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.writeIdentifier(column)
+	}
+	b.WriteString(" FROM ")
+	b.writeIdentifier(q.table.table)
+	b.writeWhere(q.where)
+
+	for i, term := range q.orderBy {
+		if i == 0 {
+			b.WriteString(" ORDER BY ")
+		} else {
+			b.WriteString(", ")
+		}
+		err := b.writeOrderTerm(term)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	switch {
+	case q.limit > 0:
+		b.WriteString(" LIMIT " + strconv.Itoa(q.limit))
+	case q.offset > 0 && b.dialect == MySQL:
+		b.WriteString(" LIMIT 18446744073709551615")
+	case q.offset > 0 && b.dialect == SQLite:
+		b.WriteString(" LIMIT -1")
+	}
+	if q.offset > 0 {
+		b.WriteString(" OFFSET " + strconv.Itoa(q.offset))
+	}
+
+	return b.String(), b.args, nil
+}
+
+// Query executes the statement & returns the raw rows
+func (q *SelectQuery) Query(ctx context.Context) (*sql.Rows, error) {
+	query, args, err := q.SQL()
+	if err != nil {
+		return nil, err
+	}
+
+	return q.table.ac.conn().QueryContext(ctx, q.table.ac.limitQuery(query), args...)
+}
+
+// Fetch executes a built SELECT & scans every record into a T, see Select
+func Fetch[T any](ctx context.Context, q *SelectQuery) ([]T, error) {
+	query, args, err := q.SQL()
+	if err != nil {
+		return nil, err
+	}
+
+	return Select[T](ctx, q.table.ac, query, args...)
+}
+
+// FetchOne executes a built SELECT & scans the first record into a T, see Get
+func FetchOne[T any](ctx context.Context, q *SelectQuery) (T, error) {
+	query, args, err := q.SQL()
+	if err != nil {
+		var result T
+		return result, err
+	}
+
+	return Get[T](ctx, q.table.ac, query, args...)
+}
+
+// InsertQuery builds an INSERT statement
+type InsertQuery struct {
+	table   *TableQuery
+	columns []string
+	values  []any
+}
+
+// Set adds a column & the value inserted into it
+func (q *InsertQuery) Set(column string, value any) *InsertQuery {
+	q.columns = append(q.columns, column)
+	q.values = append(q.values, value)
+	return q
+}
+
+// SQL renders the statement & its arguments without executing it
+func (q *InsertQuery) SQL() (string, []any, error) {
+	if len(q.columns) == 0 {
+		return "", nil, errors.New("insert has no columns set")
+	}
+
+	b := newSQLBuilder(q.table.ac)
+	b.WriteString("INSERT INTO ")
+	b.writeIdentifier(q.table.table)
+	b.WriteString(" (")
+	for i, column := range q.columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.writeIdentifier(column)
+	}
+	b.WriteString(") VALUES (")
+	for i, value := range q.values {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.bind(value)
+	}
+	b.WriteString(")")
+
+	return b.String(), b.args, nil
+}
+
+// Exec executes the statement
+func (q *InsertQuery) Exec(ctx context.Context) (sql.Result, error) {
+	return execBuilt(ctx, q.table.ac, q.SQL)
+}
+
+// UpdateQuery builds an UPDATE statement
+type UpdateQuery struct {
+	table   *TableQuery
+	columns []string
+	values  []any
+	where   []Cond
+}
+
+// Set adds a column & the value it is updated to
+func (q *UpdateQuery) Set(column string, value any) *UpdateQuery {
+	q.columns = append(q.columns, column)
+	q.values = append(q.values, value)
+	return q
+}
+
+// Where adds conditions that must all hold, repeated calls are joined with AND
+func (q *UpdateQuery) Where(conds ...Cond) *UpdateQuery {
+	q.where = append(q.where, conds...)
+	return q
+}
+
+// SQL renders the statement & its arguments without executing it.
+// An UPDATE without any condition is refused rather than rewriting the whole table
+func (q *UpdateQuery) SQL() (string, []any, error) {
+	if len(q.columns) == 0 {
+		return "", nil, errors.New("update has no columns set")
+	}
+	if len(q.where) == 0 {
+		return "", nil, fmt.Errorf("update of %q has no where condition", q.table.table)
+	}
+
+	b := newSQLBuilder(q.table.ac)
+	b.WriteString("UPDATE ")
+	b.writeIdentifier(q.table.table)
+	b.WriteString(" SET ")
+	for i, column := range q.columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.writeIdentifier(column)
+		b.WriteString(" = ")
+		b.bind(q.values[i])
+	}
+	b.writeWhere(q.where)
+
+	return b.String(), b.args, nil
+}
+
+// Exec executes the statement
+func (q *UpdateQuery) Exec(ctx context.Context) (sql.Result, error) {
+	return execBuilt(ctx, q.table.ac, q.SQL)
+}
+
+// DeleteQuery builds a DELETE statement
+type DeleteQuery struct {
+	table *TableQuery
+	where []Cond
+}
+
+// Where adds conditions that must all hold, repeated calls are joined with AND
+func (q *DeleteQuery) Where(conds ...Cond) *DeleteQuery {
+	q.where = append(q.where, conds...)
+	return q
+}
+
+// SQL renders the statement & its arguments without executing it.
+// A DELETE without any condition is refused rather than emptying the whole table
+func (q *DeleteQuery) SQL() (string, []any, error) {
+	if len(q.where) == 0 {
+		return "", nil, fmt.Errorf("delete from %q has no where condition", q.table.table)
+	}
+
+	b := newSQLBuilder(q.table.ac)
+	b.WriteString("DELETE FROM ")
+	b.writeIdentifier(q.table.table)
+	b.writeWhere(q.where)
+
+	return b.String(), b.args, nil
+}
+
+// Exec executes the statement
+func (q *DeleteQuery) Exec(ctx context.Context) (sql.Result, error) {
+	return execBuilt(ctx, q.table.ac, q.SQL)
+}
+
+func execBuilt(ctx context.Context, ac *Assister, build func() (string, []any, error)) (sql.Result, error) {
+	query, args, err := build()
+	if err != nil {
+		return nil, err
+	}
+
+	return ac.conn().ExecContext(ctx, query, args...)
+}
+
+// sqlBuilder accumulates rendered SQL & the arguments bound to its placeholders
+type sqlBuilder struct {
+	strings.Builder
+	dialect Dialect
+	quoting QuotingMode
+	args    []any
+}
+
+func newSQLBuilder(ac *Assister) *sqlBuilder {
+	return &sqlBuilder{dialect: ac.dialect, quoting: ac.quoting}
+}
+
+// sqlExpr is SQL generated by the package itself that is written as is instead of being bound
+type sqlExpr string
+
+func (b *sqlBuilder) bind(value any) {
+	if expr, ok := value.(sqlExpr); ok {
+		b.WriteString(string(expr))
+		return
+	}
+
+	b.args = append(b.args, value)
+	b.WriteString(b.dialect.Placeholder(len(b.args)))
+}
+
+func (b *sqlBuilder) writeIdentifier(name string) {
+	b.WriteString(b.quoting.Quote(b.dialect, name))
+}
+
+func (b *sqlBuilder) writeWhere(conds []Cond) {
+	for i, cond := range conds {
+		if i == 0 {
+			b.WriteString(" WHERE ")
+		} else {
+			b.WriteString(" AND ")
+		}
+		cond.writeSQL(b)
+	}
+}
+
+// writeOrderTerm renders a "column [ASC|DESC]" sort term, refusing anything else
+func (b *sqlBuilder) writeOrderTerm(term string) error {
+	parts := strings.Fields(term)
+	if len(parts) == 0 || len(parts) > 2 {
+		return fmt.Errorf("invalid order by term %q", term)
+	}
+
+	b.writeIdentifier(parts[0])
+	if len(parts) == 2 {
+		direction := strings.ToUpper(parts[1])
+		if direction != "ASC" && direction != "DESC" {
+			return fmt.Errorf("invalid order by direction %q", parts[1])
+		}
+		b.WriteString(" " + direction)
+	}
+
+	return nil
+}
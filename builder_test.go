@@ -0,0 +1,141 @@
+package sqlAssister
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestBuilderGolden(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(ac *Assister) (string, []any, error)
+	}{
+		{"select_all", func(ac *Assister) (string, []any, error) {
+			return ac.Table("books").Select().SQL()
+		}},
+		{"select_where", func(ac *Assister) (string, []any, error) {
+			return ac.Table("books").
+				Select("id", "name").
+				Where(Eq("author_id", 7), Gte("price", 10), IsNotNull("published_at")).
+				Where(Or(Like("name", "Go%"), In("genre", "sci-fi", "fantasy"))).
+				OrderBy("created_at DESC", "id").
+				Limit(20).
+				Offset(40).
+				SQL()
+		}},
+		{"select_empty_in", func(ac *Assister) (string, []any, error) {
+			return ac.Table("books").Select("id").Where(In("id"), IsNull("deleted_at")).SQL()
+		}},
+		{"select_empty_groups", func(ac *Assister) (string, []any, error) {
+			return ac.Table("books").Select("id").Where(And(), Or(And(), Eq("id", 1)), Or()).SQL()
+		}},
+		{"select_offset_only", func(ac *Assister) (string, []any, error) {
+			return ac.Table("books").Select("id").OrderBy("id").Offset(40).SQL()
+		}},
+		{"select_schema_table", func(ac *Assister) (string, []any, error) {
+			return ac.Table("library.books").Select("order").Where(Ne("order", 1), Lt("stock", 5), Lte("price", 3)).SQL()
+		}},
+		{"select_mixed_case", func(ac *Assister) (string, []any, error) {
+			return ac.Table("Library.Books").Select("AuthorID", `Na"me`).Where(Eq("AuthorID", 7)).SQL()
+		}},
+		{"insert", func(ac *Assister) (string, []any, error) {
+			return ac.Table("books").Insert().Set("name", "Dune").Set("author_id", 7).Set("price", nil).SQL()
+		}},
+		{"update", func(ac *Assister) (string, []any, error) {
+			return ac.Table("books").Update().
+				Set("stock", 3).
+				Set("name", "Dune Messiah").
+				Where(Eq("id", 42), And(Gt("stock", 0), Ne("name", ""))).
+				SQL()
+		}},
+		{"delete", func(ac *Assister) (string, []any, error) {
+			return ac.Table("books").Delete().Where(Eq("author_id", 7), IsNull("published_at")).SQL()
+		}},
+	}
+
+	assisters := []struct {
+		name string
+		ac   *Assister
+	}{
+		{"postgres", New(nil, WithDialect(Postgres))},
+		{"mysql", New(nil, WithDialect(MySQL))},
+		{"sqlite", New(nil, WithDialect(SQLite))},
+		{"postgres_no_quoting", New(nil, WithDialect(Postgres), WithQuotingMode(NoQuoting))},
+		{"postgres_fold_to_lower", New(nil, WithDialect(Postgres), WithQuotingMode(FoldToLower))},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var golden strings.Builder
+			for _, assister := range assisters {
+				query, args, err := test.build(assister.ac)
+				if err != nil {
+					t.Fatalf("%s: %v", assister.name, err)
+				}
+				fmt.Fprintf(&golden, "-- %s\n%s\n-- args: %#v\n\n", assister.name, query, args)
+			}
+
+			checkGolden(t, "builder_"+test.name, golden.String())
+		})
+	}
+}
+
+func TestBuilderRefusals(t *testing.T) {
+	ac := New(nil)
+	tests := []struct {
+		name  string
+		build func() (string, []any, error)
+	}{
+		{"insert without columns", ac.Table("books").Insert().SQL},
+		{"update without columns", ac.Table("books").Update().Where(Eq("id", 1)).SQL},
+		{"update without where", ac.Table("books").Update().Set("stock", 0).SQL},
+		{"delete without where", ac.Table("books").Delete().SQL},
+		{"order by injection", ac.Table("books").Select().OrderBy("id; DROP TABLE books").SQL},
+		{"order by direction", ac.Table("books").Select().OrderBy("id SIDEWAYS").SQL},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query, _, err := test.build()
+			if err == nil {
+				t.Errorf("expected an error, got %q", query)
+			}
+		})
+	}
+}
+
+// TestBuilderSQLite runs the statements a database could refuse, empty groups & an offset without a limit, on SQLite
+func TestBuilderSQLite(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openTestDB(t, bookTable), WithDialect(SQLite))
+	for i := 1; i <= 5; i++ {
+		_, err := ac.DB.Exec(insertBook, fmt.Sprintf("book %d", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query *SelectQuery
+		want  []int64
+	}{
+		{"empty and", ac.Table("books").Select("id").Where(And()).OrderBy("id"), []int64{1, 2, 3, 4, 5}},
+		{"empty or", ac.Table("books").Select("id").Where(Or()), nil},
+		{"empty groups nested", ac.Table("books").Select("id").Where(Or(Or(), And(Eq("id", 2), And()))), []int64{2}},
+		{"offset without limit", ac.Table("books").Select("id").OrderBy("id").Offset(3), []int64{4, 5}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ids, err := Fetch[int64](ctx, test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(test.want) {
+				t.Errorf("expected %v, got %v", test.want, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,388 @@
+package sqlAssister
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// postgresBindParamLimit is the most bind parameters a single Postgres statement can take
+const postgresBindParamLimit = 65535
+
+// sqliteBindParamLimit is the most bind parameters a single statement can take on SQLite builds older than 3.32
+const sqliteBindParamLimit = 999
+
+// bulkValuesAlias names the VALUES list joined to the updated table
+const bulkValuesAlias = "bulk_values"
+
+// BulkUpdate updates many records of table in a single round trip per chunk, each with its own values, from maps of column to value
+// identifying the record by its keyColumn value. Every map must set the same columns, keyColumn included, & a nil value is set to NULL.
+// The records are joined to a VALUES list, `UPDATE table SET col = v.col FROM (VALUES ...) AS v(...) WHERE table.key = v.key`,
+// chunked to stay within the bind parameter limit. The chunks run in a transaction, the Assister's own when it is bound to one,
+// or each in its own WithCheckpoints. Returns the number of records updated, keys matching no record are skipped,
+// along with the number updated by the chunks committed when an *PartialCompletionError is returned. Postgres only
+/*
+
+Example:
+
+	updated, err := Assister.BulkUpdate(ctx, "books", "id", []map[string]any{
+		{"id": 1, "price": 12.5, "stock": 3},
+		{"id": 2, "price": 8.0, "stock": 0},
+	})
+	if err != nil {
+		return err
+	}
+*/
+func (ac Assister) BulkUpdate(ctx context.Context, table string, keyColumn string, updates []map[string]any) (int64, error) {
+	if ac.dialect != Postgres {
+		return 0, unsupportedDialectf("bulk updates are not supported on %s", ac.dialect)
+	}
+	if len(updates) == 0 {
+		return 0, nil
+	}
+
+	columns, err := bulkColumns(table, keyColumn, updates)
+	if err != nil {
+		return 0, err
+	}
+
+	// VALUES bind parameters are typed as text unless told otherwise, so the first row casts them to the table's column types
+	types, err := ac.columnTypes(ctx, table)
+	if err != nil {
+		return 0, err
+	}
+	castTypes := make([]string, len(columns))
+	for i, column := range columns {
+		castTypes[i] = types[column]
+		if castTypes[i] == "" {
+			castTypes[i] = types[strings.ToLower(column)]
+		}
+		if castTypes[i] == "" {
+			return 0, fmt.Errorf("column %q not found in table %q", column, table)
+		}
+	}
+
+	perChunk, err := chunkSize(ac.dialect, postgresBindParamLimit, len(columns))
+	if err != nil {
+		return 0, err
+	}
+	updated, err := ac.runChunks(ctx, len(updates), perChunk, func(tx *TxAssister, start int, end int) (int64, error) {
+		query, args := tx.bulkUpdateSQL(table, columns, castTypes, updates[start:end])
+		results, err := tx.conn().ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+		return results.RowsAffected()
+	})
+	if err != nil {
+		var partial *PartialCompletionError
+		if errors.As(err, &partial) {
+			return updated, err
+		}
+		return 0, err
+	}
+
+	return updated, nil
+}
+
+// bulkColumns validates the updates of a BulkUpdate, returning their columns with keyColumn first & the rest sorted
+func bulkColumns(table string, keyColumn string, updates []map[string]any) ([]string, error) {
+	sorted, err := mapColumns(table, updates[0])
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := updates[0][keyColumn]; !ok {
+		return nil, fmt.Errorf("update 1 has no value for key column %q", keyColumn)
+	}
+	if len(sorted) < 2 {
+		return nil, fmt.Errorf("update 1 sets no column besides key column %q", keyColumn)
+	}
+
+	columns := []string{keyColumn}
+	for _, column := range sorted {
+		if column != keyColumn {
+			columns = append(columns, column)
+		}
+	}
+
+	// The same key twice would update the record with whichever of its values the database happens to join first
+	keys := make(map[string]int, len(updates))
+	for i, update := range updates {
+		if len(update) != len(columns) {
+			return nil, fmt.Errorf("update %d sets %d columns, expected the %d columns of update 1", i+1, len(update), len(columns))
+		}
+		for _, column := range columns {
+			if _, ok := update[column]; !ok {
+				return nil, fmt.Errorf("update %d has no value for column %q", i+1, column)
+			}
+		}
+
+		key, err := utils.ArgsKey(update[keyColumn])
+		if err != nil {
+			return nil, fmt.Errorf("update %d key: %w", i+1, err)
+		}
+		if first, ok := keys[key]; ok {
+			return nil, fmt.Errorf("updates %d & %d have the same key", first, i+1)
+		}
+		keys[key] = i + 1
+	}
+
+	return columns, nil
+}
+
+// bulkUpdateSQL renders the UPDATE joining table to the VALUES list of updates, columns[0] being the key column
+func (ac Assister) bulkUpdateSQL(table string, columns []string, castTypes []string, updates []map[string]any) (string, []any) {
+	b := newSQLBuilder(&ac)
+	b.WriteString("UPDATE ")
+	b.writeIdentifier(table)
+	b.WriteString(" SET ")
+	for i, column := range columns[1:] {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.writeIdentifier(column)
+		b.WriteString(" = " + bulkValuesAlias + ".")
+		b.writeIdentifier(column)
+	}
+
+	b.WriteString(" FROM (VALUES ")
+	for i, update := range updates {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("(")
+		for j, column := range columns {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			b.bind(update[column])
+			if i == 0 {
+				b.WriteString("::" + castTypes[j])
+			}
+		}
+		b.WriteString(")")
+	}
+	b.WriteString(") AS " + bulkValuesAlias + " (")
+	for i, column := range columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.writeIdentifier(column)
+	}
+
+	b.WriteString(") WHERE ")
+	b.writeIdentifier(table)
+	b.WriteString(".")
+	b.writeIdentifier(columns[0])
+	b.WriteString(" = " + bulkValuesAlias + ".")
+	b.writeIdentifier(columns[0])
+
+	return b.String(), b.args
+}
+
+// columnTypes returns the SQL types of table's columns by column name, as written by Postgres' format_type, e.g. character varying(20)
+func (ac Assister) columnTypes(ctx context.Context, table string) (map[string]string, error) {
+	rows, err := ac.conn().QueryContext(ctx, "SELECT attname, format_type(atttypid, atttypmod) FROM pg_attribute "+
+		"WHERE attrelid = $1::regclass AND attnum > 0 AND NOT attisdropped", ac.QuoteIdentifier(table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types := make(map[string]string)
+	for rows.Next() {
+		var name, columnType string
+		err := rows.Scan(&name, &columnType)
+		if err != nil {
+			return nil, err
+		}
+		types[name] = columnType
+	}
+
+	return types, rows.Err()
+}
+
+// BulkInsert inserts rows into table in a single multi row INSERT per chunk, each row holding the values of columns in their order.
+// The rows are chunked to stay within the dialect's bind parameter limit & the chunks run in a transaction, the Assister's
+// own when it is bound to one, or each in its own WithCheckpoints. Returns the number of rows inserted, along with the number
+// inserted by the chunks committed when an *PartialCompletionError is returned. A driver that can't report the rows a chunk
+// inserted has them counted as the chunk's rows, unless WithStrictRowsAffected fails the insert, see InsertAll for structs
+/*
+
+Example:
+
+	inserted, err := Assister.BulkInsert(ctx, "books", []string{"id", "name"}, [][]any{
+		{1, "Dune"},
+		{2, "Emma"},
+	})
+	if err != nil {
+		return err
+	}
+*/
+func (ac Assister) BulkInsert(ctx context.Context, table string, columns []string, rows [][]any) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	if len(columns) == 0 {
+		return 0, errors.New("no columns present to insert")
+	}
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			return 0, fmt.Errorf("row %d holds %d values, expected one for each of the %d columns", i+1, len(row), len(columns))
+		}
+	}
+	perChunk, err := chunkSize(ac.dialect, bindParamLimit(ac.dialect), len(columns))
+	if err != nil {
+		return 0, err
+	}
+
+	inserted, err := ac.runChunks(ctx, len(rows), perChunk, func(tx *TxAssister, start int, end int) (int64, error) {
+		query, args := tx.bulkInsertSQL(table, columns, rows[start:end])
+		results, err := tx.conn().ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+		return tx.rowsAffected(results, int64(end-start))
+	})
+	if err != nil {
+		var partial *PartialCompletionError
+		if errors.As(err, &partial) {
+			return inserted, err
+		}
+		return 0, err
+	}
+
+	return inserted, nil
+}
+
+// chunkSize returns how many rows of columns values fit a statement within limit bind parameters, failing when not even one does
+func chunkSize(dialect Dialect, limit int, columns int) (int, error) {
+	if columns > limit {
+		return 0, fmt.Errorf("a row of %d columns exceeds the %d bind parameters a statement can take on %s", columns, limit, dialect)
+	}
+
+	return limit / columns, nil
+}
+
+// bindParamLimit returns the most bind parameters a single statement can take on the dialect, MySQL sharing Postgres' limit
+func bindParamLimit(dialect Dialect) int {
+	if dialect == SQLite {
+		return sqliteBindParamLimit
+	}
+
+	return postgresBindParamLimit
+}
+
+// bulkInsertSQL renders the INSERT of rows into table's columns, one row of the VALUES list per row
+func (ac Assister) bulkInsertSQL(table string, columns []string, rows [][]any) (string, []any) {
+	b := newSQLBuilder(&ac)
+	b.WriteString("INSERT INTO ")
+	b.writeIdentifier(table)
+	b.WriteString(" (")
+	for i, column := range columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.writeIdentifier(column)
+	}
+
+	b.WriteString(") VALUES ")
+	for i, row := range rows {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("(")
+		for j, value := range row {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			b.bind(value)
+		}
+		b.WriteString(")")
+	}
+
+	return b.String(), b.args
+}
+
+// InsertAll inserts records into table through BulkInsert, each record's columns being the fields of T mapped by their `db` tags,
+// in the order they are declared, as Args returns them. The mapping is read once for T, which may be a struct or a pointer to one,
+// & fields tagged timestr, boolchar, emptynull or encrypted are written as UpdateStruct writes them. Every mapped field
+// is inserted, leave columns the database fills itself, such as a generated id, out of T or tag them `db:"-"`.
+// Every record is bound before the first chunk runs, so a field failing to bind fails the insert before anything is written.
+// Returns the number of records inserted, along with the number inserted by the chunks committed when an *PartialCompletionError
+// is returned
+/*
+
+Example:
+
+	type NewBook struct {
+		ID       string `db:"id"`
+		Name     string `db:"name"`
+		AuthorID string `db:"author_id"`
+	}
+
+	inserted, err := sqlAssister.InsertAll(ctx, Assister, "books", books)
+	if err != nil {
+		return err
+	}
+*/
+func InsertAll[T any](ctx context.Context, ac *Assister, table string, records []T) (int64, error) {
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	structType := t
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return 0, fmt.Errorf("cannot insert %s records: expected a struct or a pointer to one", t)
+	}
+	info := getStructInfo(structType)
+	if len(info.fields) == 0 {
+		return 0, fmt.Errorf("cannot insert %s records: it has no mapped fields", t)
+	}
+
+	values := make([]reflect.Value, len(records))
+	for i, record := range records {
+		value, _, err := structValue(record)
+		if err != nil {
+			return 0, fmt.Errorf("record %d: %w", i+1, err)
+		}
+		values[i] = value
+	}
+	rows, err := ac.insertRows(info, values)
+	if err != nil {
+		return 0, err
+	}
+
+	columns := make([]string, len(info.fields))
+	for i, fi := range info.fields {
+		columns[i] = fi.column
+	}
+
+	return ac.BulkInsert(ctx, table, columns, rows)
+}
+
+// insertRows binds the fields of the records held by values, one row per record
+func (ac Assister) insertRows(info *structInfo, values []reflect.Value) (_ [][]any, err error) {
+	defer ac.panicRecovery().recover(&err)
+
+	rows := make([][]any, len(values))
+	for i, value := range values {
+		rows[i] = make([]any, len(info.fields))
+		for j, fi := range info.fields {
+			rows[i][j], err = ac.fieldArg(fi, fieldValue(value, fi))
+			if err != nil {
+				return nil, fmt.Errorf("record %d: %w", i+1, err)
+			}
+		}
+	}
+
+	return rows, nil
+}
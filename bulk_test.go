@@ -0,0 +1,100 @@
+package sqlAssister
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+func TestBulkInsert(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openTestDB(t, bookTable), WithDialect(SQLite))
+
+	// 2 columns make chunks of 499 rows on SQLite
+	rows := make([][]any, 1200)
+	for i := range rows {
+		rows[i] = []any{fmt.Sprintf("book %d", i+1), i % 3}
+	}
+	inserted, err := ac.BulkInsert(ctx, "books", []string{"name", "stock"}, rows)
+	if err != nil || inserted != 1200 {
+		t.Fatalf("expected 1200 rows inserted, got %d & %v", inserted, err)
+	}
+	stock, err := Get[int64](ctx, ac, `SELECT SUM("stock") FROM "books"`)
+	if err != nil || stock != 1200 {
+		t.Errorf("expected every row's stock stored, got %d & %v", stock, err)
+	}
+
+	wide := make([]string, 1000)
+	for i := range wide {
+		wide[i] = fmt.Sprintf("column_%d", i)
+	}
+	failures := []struct {
+		name    string
+		columns []string
+		rows    [][]any
+		want    string
+	}{
+		{"no columns", nil, [][]any{{}}, "no columns"},
+		{"short row", []string{"name", "stock"}, [][]any{{"Dune", 1}, {"Emma"}}, "row 2 holds 1 values"},
+		{"wider than the bind parameter limit", wide, [][]any{make([]any, 1000)}, "exceeds the 999 bind parameters"},
+	}
+	for _, failure := range failures {
+		_, err := ac.BulkInsert(ctx, "books", failure.columns, failure.rows)
+		if err == nil || !strings.Contains(err.Error(), failure.want) {
+			t.Errorf("%s: expected an error mentioning %q, got %v", failure.name, failure.want, err)
+		}
+	}
+	if count := countBooks(t, ac); count != 1200 {
+		t.Errorf("expected the failed inserts to write nothing, got %d books", count)
+	}
+
+	_, err = chunkSize(Postgres, postgresBindParamLimit, postgresBindParamLimit+1)
+	if err == nil {
+		t.Error("expected a row wider than Postgres' bind parameter limit refused")
+	}
+}
+
+func TestBulkInsertRowsAffectedUnavailable(t *testing.T) {
+	ctx := context.Background()
+	for _, strict := range []bool{false, true} {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		logger := &bufferLogger{}
+		opts := []Option{WithDialect(SQLite), WithLogger(logger)}
+		if strict {
+			opts = append(opts, WithStrictRowsAffected())
+		}
+		ac := New(db, opts...)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO "books" ("name") VALUES (?), (?)`).WithArgs("Dune", "Emma").
+			WillReturnResult(sqlmock.NewErrorResult(errors.New("rows affected not supported")))
+		if strict {
+			mock.ExpectRollback()
+		} else {
+			mock.ExpectCommit()
+		}
+
+		inserted, err := ac.BulkInsert(ctx, "books", []string{"name"}, [][]any{{"Dune"}, {"Emma"}})
+		switch {
+		case strict && (!errors.Is(err, utils.ErrRowsAffectedUnavailable) || inserted != 0):
+			t.Errorf("expected the strict insert to fail, got %d & %v", inserted, err)
+		case !strict && (err != nil || inserted != 2):
+			t.Errorf("expected the chunk's rows counted when the driver can't report them, got %d & %v", inserted, err)
+		case !strict && (len(logger.Lines()) != 1 || !strings.Contains(logger.Lines()[0], "NOTE: rows affected check skipped")):
+			t.Errorf("expected the skipped check noted, got %q", logger.Lines())
+		}
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package sqlAssister
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// QueryCache holds the results of CachedSelect, keyed by the query, the type its records are scanned into & the values of its args.
+// It keeps up to maxEntries results, evicting the least recently used beyond that, each for ttl after it was read from the DB.
+// Results are cached per Assister's DB so a QueryCache mustn't be shared by Assisters of different DBs.
+// A QueryCache is safe for concurrent use
+type QueryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	// entries lists the cached results from the most to the least recently used
+	entries *list.List
+	byKey   map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key         string
+	fingerprint string
+	results     any
+	expires     time.Time
+}
+
+// NewQueryCache returns an empty QueryCache holding up to maxEntries results, each for ttl
+func NewQueryCache(maxEntries int, ttl time.Duration) *QueryCache {
+	return &QueryCache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		entries:    list.New(),
+		byKey:      map[string]*list.Element{},
+	}
+}
+
+// WithQueryCache makes CachedSelect keep its results in cache. Without one CachedSelect reads from the DB every time
+/*
+
+Example:
+
+	cache := sqlAssister.NewQueryCache(1000, time.Minute)
+	Assister := sqlAssister.New(db, sqlAssister.WithQueryCache(cache))
+*/
+func WithQueryCache(cache *QueryCache) Option {
+	return func(ac *Assister) {
+		ac.queryCache = cache
+	}
+}
+
+// CachedSelect follows the same rules as Select, returning the records cached by an earlier call with the same query & arg values
+// while they are fresher than the cache's TTL, see WithQueryCache. Each combination of arg values is cached separately,
+// so it can replace Select in any read method. Errors aren't cached & an Assister bound to a transaction always reads from the DB,
+// the transaction may see its own writes. The records are shared with the cache: copy them before modifying them
+/*
+
+Example:
+
+	books, err := sqlAssister.CachedSelect[Book](ctx, Assister, `SELECT "id", "name" FROM "books" WHERE "author_id" = $1`, authorId)
+	if err != nil {
+		return nil, err
+	}
+*/
+func CachedSelect[T any](ctx context.Context, ac *Assister, query string, args ...any) ([]T, error) {
+	cache := ac.queryCache
+	if cache == nil || ac.tx != nil {
+		return Select[T](ctx, ac, query, args...)
+	}
+
+	fingerprint := utils.Fingerprint(query)
+	key, err := cacheKey(fingerprint, reflect.TypeOf((*T)(nil)).Elem(), query, args)
+	if err != nil {
+		return nil, err
+	}
+	if cached, ok := cache.get(key, ac.getClock().Now()); ok {
+		if results, ok := cached.([]T); ok {
+			return results[:len(results):len(results)], nil
+		}
+	}
+
+	results, err := Select[T](ctx, ac, query, args...)
+	if err != nil {
+		return results, err
+	}
+	cache.put(key, fingerprint, results, ac.getClock().Now())
+
+	return results[:len(results):len(results)], nil
+}
+
+// cacheKey keys a result by the query's fingerprint, so Invalidate can find it, & a hash of what makes it unique.
+// The fingerprint alone isn't enough as queries differing only in their literals share it
+func cacheKey(fingerprint string, typ reflect.Type, query string, args []any) (string, error) {
+	argsKey, err := utils.ArgsKey(args...)
+	if err != nil {
+		return "", fmt.Errorf("cache key: %w", err)
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s/%s\x00%d:%s\x00%s", typ.PkgPath(), typ, len(query), query, argsKey)
+	return fingerprint + ":" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (c *QueryCache) get(key string, now time.Time) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.byKey[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if !now.Before(entry.expires) {
+		c.remove(element)
+		return nil, false
+	}
+	c.entries.MoveToFront(element)
+
+	return entry.results, true
+}
+
+func (c *QueryCache) put(key string, fingerprint string, results any, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.byKey[key]; ok {
+		c.remove(element)
+	}
+	entry := &cacheEntry{key: key, fingerprint: fingerprint, results: results, expires: now.Add(c.ttl)}
+	c.byKey[key] = c.entries.PushFront(entry)
+
+	for c.maxEntries > 0 && c.entries.Len() > c.maxEntries {
+		c.remove(c.entries.Back())
+	}
+}
+
+func (c *QueryCache) remove(element *list.Element) {
+	c.entries.Remove(element)
+	delete(c.byKey, element.Value.(*cacheEntry).key)
+}
+
+// Invalidate drops the cached results of every query sharing query's fingerprint, whatever their args, e.g. after writing to its table
+/*
+
+Example:
+
+	err := Assister.UpdateMap(ctx, "books", map[string]any{"name": name}, "id", bookId)
+	if err != nil {
+		return err
+	}
+	cache.Invalidate(booksByAuthorQuery)
+*/
+func (c *QueryCache) Invalidate(query string) {
+	fingerprint := utils.Fingerprint(query)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for element := c.entries.Front(); element != nil; {
+		next := element.Next()
+		if element.Value.(*cacheEntry).fingerprint == fingerprint {
+			c.remove(element)
+		}
+		element = next
+	}
+}
+
+// Purge drops every cached result
+func (c *QueryCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries.Init()
+	c.byKey = map[string]*list.Element{}
+}
+
+// Len returns the number of cached results, including those that have expired but haven't been evicted yet
+func (c *QueryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.entries.Len()
+}
@@ -0,0 +1,123 @@
+package sqlAssister
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// errDeadlineNear is why a checkpointed batch stops when too little time is left to run another chunk
+var errDeadlineNear = errors.New("context deadline within the safety margin")
+
+// WithCheckpoints returns a copy of the Assister whose batch helpers, BulkUpdate & InsertAll, commit every chunk in its own transaction
+// instead of running the whole batch in one. After every commit progress is called with the number of records committed so far.
+// Before starting a chunk the helper stops cleanly when ctx is done or its deadline is less than margin away on the Assister's
+// clock (see WithClock), so a job running out of its window keeps the chunks already committed. A batch stopped part way through
+// returns an *PartialCompletionError whose ResumeOffset tells a rerun where to pick up
+/*
+
+Example:
+
+	ctx, cancel := context.WithDeadline(ctx, maintenanceWindowEnd)
+	defer cancel()
+
+	_, err := Assister.WithCheckpoints(time.Minute, func(committed int) {
+		saveResumeOffset(jobId, committed)
+	}).BulkUpdate(ctx, "products", "sku", updates[resumeOffset:])
+	var partial *sqlAssister.PartialCompletionError
+	if errors.As(err, &partial) {
+		saveResumeOffset(jobId, resumeOffset+partial.ResumeOffset)
+	}
+*/
+func (ac Assister) WithCheckpoints(margin time.Duration, progress func(committed int)) *Assister {
+	ac.checkpoints = &checkpoints{margin: margin, progress: progress}
+	return &ac
+}
+
+// checkpoints configures the batch helpers committing chunk by chunk, see WithCheckpoints
+type checkpoints struct {
+	margin   time.Duration
+	progress func(committed int)
+}
+
+// runChunks runs fn on every chunk of up to chunkSize of the total records, passing the chunk's bounds, & returns the sum of the
+// records fn reports its chunks affected once they are committed, so a chunk rolled back is never counted.
+// The chunks run in one transaction, the Assister's own when it is bound to one, or each in its own with checkpoints
+func (ac Assister) runChunks(ctx context.Context, total int, chunkSize int, fn func(tx *TxAssister, start int, end int) (int64, error)) (int64, error) {
+	chunk := func(tx *TxAssister, start int) (int64, error) {
+		end := start + chunkSize
+		if end > total {
+			end = total
+		}
+		return fn(tx, start, end)
+	}
+
+	if ac.checkpoints == nil {
+		var affected int64
+		run := func(tx *TxAssister) error {
+			affected = 0
+			for start := 0; start < total; start += chunkSize {
+				rows, err := chunk(tx, start)
+				if err != nil {
+					return err
+				}
+				affected += rows
+			}
+			return nil
+		}
+		var err error
+		if ac.tx != nil {
+			err = run(ac.tx)
+		} else {
+			err = ac.WithTransaction(ctx, run)
+		}
+		if err != nil {
+			return 0, err
+		}
+		return affected, nil
+	}
+
+	if ac.tx != nil {
+		return 0, errors.New("checkpoints commit every chunk, they can't run on an Assister bound to a transaction")
+	}
+	var committed int64
+	for start := 0; start < total; start += chunkSize {
+		var rows int64
+		err := ac.checkpoints.canStart(ctx, ac.getClock().Now())
+		if err == nil {
+			err = ac.WithTransaction(ctx, func(tx *TxAssister) error {
+				var err error
+				rows, err = chunk(tx, start)
+				return err
+			})
+		}
+		if err != nil {
+			return committed, &PartialCompletionError{ResumeOffset: start, Err: err}
+		}
+		committed += rows
+
+		end := start + chunkSize
+		if end > total {
+			end = total
+		}
+		if ac.checkpoints.progress != nil {
+			ac.checkpoints.progress(end)
+		}
+	}
+
+	return committed, nil
+}
+
+// canStart reports why another chunk mustn't start at now, if it mustn't
+func (c *checkpoints) canStart(ctx context.Context, now time.Time) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Sub(now) < c.margin {
+		return fmt.Errorf("%w: %s left", errDeadlineNear, deadline.Sub(now).Round(time.Millisecond))
+	}
+
+	return nil
+}
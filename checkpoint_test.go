@@ -0,0 +1,79 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// checkedBook references its author through a deferred foreign key, checked when the transaction commits
+type checkedBook struct {
+	ID       int64 `db:"id"`
+	AuthorID int64 `db:"author_id"`
+}
+
+// openDeferredDB opens a database whose "books" reference "authors" 1 through a foreign key checked on commit, a chunk
+// inserting a book of another author failing to commit rather than to insert
+func openDeferredDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "deferred.db")+"?_foreign_keys=1&_txlock=immediate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for _, statement := range []string{
+		`CREATE TABLE "authors" ("id" INTEGER PRIMARY KEY)`,
+		`INSERT INTO "authors" ("id") VALUES (1)`,
+		`CREATE TABLE "books" ("id" INTEGER PRIMARY KEY,
+			"author_id" INTEGER NOT NULL REFERENCES "authors" ("id") DEFERRABLE INITIALLY DEFERRED)`,
+	} {
+		_, err = db.Exec(statement)
+		if err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+
+	return db
+}
+
+func TestCheckpointCommitFailure(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openDeferredDB(t), WithDialect(SQLite))
+
+	// 2 fields make chunks of 499 records, the second chunk's commit fails on the record of an unknown author
+	books := make([]checkedBook, 1200)
+	for i := range books {
+		books[i] = checkedBook{ID: int64(i + 1), AuthorID: 1}
+	}
+	books[600].AuthorID = 2
+
+	var progress []int
+	inserted, err := InsertAll(ctx, ac.WithCheckpoints(0, func(committed int) {
+		progress = append(progress, committed)
+	}), "books", books)
+	var partial *PartialCompletionError
+	if !errors.As(err, &partial) || partial.ResumeOffset != 499 || !strings.Contains(err.Error(), "FOREIGN KEY") {
+		t.Fatalf("expected the batch stopped at 499 by the failed commit, got %v", err)
+	}
+	if inserted != 499 || len(progress) != 1 || progress[0] != 499 {
+		t.Errorf("expected only the committed chunk counted, got %d records & progress %v", inserted, progress)
+	}
+	var count int64
+	err = ac.DB.QueryRow(`SELECT COUNT(*) FROM "books"`).Scan(&count)
+	if err != nil || count != 499 {
+		t.Errorf("expected 499 books committed, got %d & %v", count, err)
+	}
+
+	// Without checkpoints the failed commit rolls back every chunk, none counted
+	_, err = ac.DB.Exec(`DELETE FROM "books"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inserted, err = InsertAll(ctx, ac, "books", books)
+	if err == nil || inserted != 0 {
+		t.Errorf("expected the failed commit to report no records, got %d & %v", inserted, err)
+	}
+}
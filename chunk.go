@@ -0,0 +1,19 @@
+package sqlAssister
+
+// inChunkSize is how many values an IN list is split into at most, keeping statements well below the bind parameter
+// limits of every dialect (999 on older SQLite builds, 65535 on Postgres & MySQL)
+const inChunkSize = 500
+
+// chunkValues splits values into consecutive chunks of at most size values
+func chunkValues(values []any, size int) [][]any {
+	var chunks [][]any
+	for len(values) > size {
+		chunks = append(chunks, values[:size:size])
+		values = values[size:]
+	}
+	if len(values) > 0 {
+		chunks = append(chunks, values)
+	}
+
+	return chunks
+}
@@ -0,0 +1,280 @@
+package sqlAssister
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Cipher encrypts & decrypts the values of the fields tagged encrypted, see WithFieldCipher. Encrypt must be safe for concurrent
+// use & is expected to produce a different ciphertext each time, e.g. by prefixing a random nonce; Decrypt must fail rather than
+// return garbage for a ciphertext it didn't produce, which authenticated encryption such as AES-GCM does
+type Cipher interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// WithFieldCipher sets the Cipher encrypting the fields tagged `db:"column,encrypted"`, for columns such as PII that must be
+// encrypted at rest by the application. An encrypted field must be a string, a []byte or a pointer to either:
+//   - the struct helpers (UpdateStruct, InsertAll, Args, ...) bind it encrypted, as bytes for a bytea or blob column or as
+//     base64 text with WithEncryptedAsText. A nil pointer or []byte is bound as NULL
+//   - the generic scanning helpers decrypt it when reading it, NULL being read as usual
+//
+// Encrypted columns can't be searched, compared or indexed by the database, as the same value encrypts differently each time.
+// Queries written by hand bind & read them with EncryptValue & DecryptValue. Rotate keys with NewRotatingCipher
+/*
+
+Example:
+
+	type Customer struct {
+		ID    int64  `db:"id,pk"`
+		Email string `db:"email,encrypted"`
+	}
+
+	cipher, err := sqlAssister.NewAESGCMCipher(key)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	statementAssister = sqlAssister.New(db, sqlAssister.WithFieldCipher(cipher))
+*/
+func WithFieldCipher(c Cipher) Option {
+	return func(ac *Assister) {
+		ac.fieldCipher = c
+	}
+}
+
+// WithEncryptedAsText stores the encrypted fields as base64 text rather than bytes, for text columns or databases without a
+// binary type, see WithFieldCipher
+func WithEncryptedAsText() Option {
+	return func(ac *Assister) {
+		ac.encryptedText = true
+	}
+}
+
+// EncryptValue returns plaintext encrypted as the fields tagged encrypted are bound, for queries written by hand.
+// plaintext is a string, a []byte or a pointer to either, a nil pointer or []byte being returned as nil to bind NULL
+/*
+
+Example:
+
+	email, err := Assister.EncryptValue(customer.Email)
+	if err != nil {
+		return err
+	}
+
+	err = Assister.UpdateSingleRow(`UPDATE "customers" SET "email" = $1 WHERE "id" = $2`, email, customer.ID)
+*/
+func (ac Assister) EncryptValue(plaintext any) (any, error) {
+	if plaintext == nil {
+		return nil, nil
+	}
+
+	return ac.encrypt(reflect.ValueOf(plaintext))
+}
+
+// DecryptValue returns the plaintext of a ciphertext read from an encrypted column by a query written by hand, as bytes or as
+// base64 text with WithEncryptedAsText. A nil ciphertext, as NULL is read, returns nil
+/*
+
+Example:
+
+	ciphertext, err := sqlAssister.Get[[]byte](ctx, Assister, `SELECT "email" FROM "customers" WHERE "id" = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	email, err := Assister.DecryptValue(ciphertext)
+*/
+func (ac Assister) DecryptValue(ciphertext any) ([]byte, error) {
+	return ac.decoding().decrypt(ciphertext)
+}
+
+// encryptField returns the value a field tagged encrypted is bound as
+func (ac Assister) encryptField(fi *fieldInfo, fv reflect.Value) (any, error) {
+	arg, err := ac.encrypt(fv)
+	if err != nil {
+		return nil, fmt.Errorf("field %s: %w", fi.name, err)
+	}
+
+	return arg, nil
+}
+
+// encrypt encrypts the string or []byte fv holds or points to, returning nil for a nil pointer or []byte
+func (ac Assister) encrypt(fv reflect.Value) (any, error) {
+	if ac.fieldCipher == nil {
+		return nil, errors.New("no cipher to encrypt with, set one with WithFieldCipher")
+	}
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			return nil, nil
+		}
+		fv = fv.Elem()
+	}
+
+	var plaintext []byte
+	switch {
+	case fv.Kind() == reflect.String:
+		plaintext = []byte(fv.String())
+	case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.Uint8:
+		if fv.IsNil() {
+			return nil, nil
+		}
+		plaintext = fv.Bytes()
+	default:
+		return nil, fmt.Errorf("cannot encrypt %s, only strings & []byte can be", fv.Type())
+	}
+
+	ciphertext, err := ac.fieldCipher.Encrypt(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	if ac.encryptedText {
+		return base64.StdEncoding.EncodeToString(ciphertext), nil
+	}
+
+	return ciphertext, nil
+}
+
+// decrypt returns the plaintext of src as read from an encrypted column, nil when src is NULL
+func (d decoding) decrypt(src any) ([]byte, error) {
+	if d.cipher == nil {
+		return nil, errors.New("no cipher to decrypt with, set one with WithFieldCipher")
+	}
+
+	var ciphertext []byte
+	switch src := src.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		ciphertext = src
+	case string:
+		ciphertext = []byte(src)
+	default:
+		return nil, fmt.Errorf("cannot decrypt %T, encrypted columns hold bytes or text", src)
+	}
+	if d.encryptedText {
+		decoded := make([]byte, base64.StdEncoding.DecodedLen(len(ciphertext)))
+		n, err := base64.StdEncoding.Decode(decoded, ciphertext)
+		if err != nil {
+			return nil, fmt.Errorf("decode encrypted text: %w", err)
+		}
+		ciphertext = decoded[:n]
+	}
+
+	return d.cipher.Decrypt(ciphertext)
+}
+
+// decryptDest scans an encrypted column, decrypting it before storing the plaintext as target's scanDest would
+type decryptDest struct {
+	target   reflect.Value
+	decoding decoding
+}
+
+func (d *decryptDest) Scan(src any) (err error) {
+	defer d.decoding.recovery.recover(&err)
+
+	dest := scanDest(d.target, d.decoding.plain())
+	if src == nil {
+		return assignDest(dest, nil)
+	}
+
+	plaintext, err := d.decoding.decrypt(src)
+	if err != nil {
+		return err
+	}
+
+	return assignDest(dest, plaintext)
+}
+
+// plain returns d for the plaintext of an encrypted column
+func (d decoding) plain() decoding {
+	d.encrypted = false
+	return d
+}
+
+// rotatingCipher encrypts with its first Cipher & decrypts with the first of them that can, see NewRotatingCipher
+type rotatingCipher []Cipher
+
+// NewRotatingCipher returns a Cipher for rotating keys: it encrypts with current & decrypts with current or, failing that, each
+// of previous in turn, so values encrypted with a retired key can still be read. Values are re-encrypted with current as they
+// are written again; rewrite the rows, e.g. by reading & updating each, before dropping a previous key
+/*
+
+Example:
+
+	current, err := sqlAssister.NewAESGCMCipher(keys["2024-06"])
+	...
+	previous, err := sqlAssister.NewAESGCMCipher(keys["2023-01"])
+	...
+
+	statementAssister = sqlAssister.New(db, sqlAssister.WithFieldCipher(sqlAssister.NewRotatingCipher(current, previous)))
+*/
+func NewRotatingCipher(current Cipher, previous ...Cipher) Cipher {
+	return append(rotatingCipher{current}, previous...)
+}
+
+func (c rotatingCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	return c[0].Encrypt(plaintext)
+}
+
+// Decrypt returns the error of the current Cipher when no Cipher can decrypt ciphertext
+func (c rotatingCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	var first error
+	for _, candidate := range c {
+		plaintext, err := candidate.Decrypt(ciphertext)
+		if err == nil {
+			return plaintext, nil
+		}
+		if first == nil {
+			first = err
+		}
+	}
+
+	return nil, first
+}
+
+// aesGCMCipher is the AES-GCM Cipher NewAESGCMCipher returns
+type aesGCMCipher struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCMCipher returns a Cipher encrypting with AES-GCM under key, which is 16, 24 or 32 bytes long for AES-128, AES-192
+// or AES-256. Each ciphertext is prefixed with the random 12 byte nonce it was sealed with & ends with a 16 byte tag, so it
+// is 28 bytes longer than its plaintext. Random nonces keep a key safe for about 4 billion encryptions, rotate it before then
+func NewAESGCMCipher(key []byte) (Cipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return aesGCMCipher{aead: aead}, nil
+}
+
+func (c aesGCMCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	_, err := io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (c aesGCMCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	size := c.aead.NonceSize()
+	if len(ciphertext) < size+c.aead.Overhead() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	return c.aead.Open(nil, ciphertext[:size], ciphertext[size:], nil)
+}
@@ -0,0 +1,314 @@
+package sqlAssister
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+// testCipher returns an AES-GCM Cipher under a key made of b repeated
+func testCipher(t *testing.T, b byte) Cipher {
+	t.Helper()
+	c, err := NewAESGCMCipher(bytes.Repeat([]byte{b}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func TestAESGCMCipher(t *testing.T) {
+	for _, size := range []int{16, 24, 32} {
+		_, err := NewAESGCMCipher(make([]byte, size))
+		if err != nil {
+			t.Errorf("expected a %d byte key accepted, got %v", size, err)
+		}
+	}
+	for _, size := range []int{0, 15, 31, 64} {
+		_, err := NewAESGCMCipher(make([]byte, size))
+		if err == nil {
+			t.Errorf("expected a %d byte key refused", size)
+		}
+	}
+
+	c := testCipher(t, 1)
+	for _, plaintext := range [][]byte{{}, []byte("kim@example.com"), bytes.Repeat([]byte{0, 0xff}, 1000)} {
+		first, err := c.Encrypt(plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := c.Encrypt(plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(first) != len(plaintext)+28 {
+			t.Errorf("expected the ciphertext 28 bytes longer than its plaintext, got %d for %d", len(first), len(plaintext))
+		}
+		if bytes.Equal(first, second) {
+			t.Error("expected each encryption to use a fresh nonce")
+		}
+		for _, ciphertext := range [][]byte{first, second} {
+			decrypted, err := c.Decrypt(ciphertext)
+			if err != nil || !bytes.Equal(decrypted, plaintext) {
+				t.Errorf("expected %q decrypted, got %q & %v", plaintext, decrypted, err)
+			}
+		}
+	}
+
+	ciphertext, err := c.Encrypt([]byte("kim@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = testCipher(t, 2).Decrypt(ciphertext)
+	if err == nil {
+		t.Error("expected the ciphertext refused under another key")
+	}
+	// Flipping a bit of the nonce, the sealed plaintext or the tag is detected
+	for _, i := range []int{0, 11, 12, len(ciphertext) - 17, len(ciphertext) - 1} {
+		tampered := append([]byte(nil), ciphertext...)
+		tampered[i] ^= 1
+		_, err = c.Decrypt(tampered)
+		if err == nil {
+			t.Errorf("expected the ciphertext tampered at byte %d refused", i)
+		}
+	}
+	for _, truncated := range [][]byte{nil, ciphertext[:27], ciphertext[:len(ciphertext)-1]} {
+		_, err = c.Decrypt(truncated)
+		if err == nil {
+			t.Errorf("expected a truncated ciphertext of %d bytes refused", len(truncated))
+		}
+	}
+}
+
+func TestRotatingCipher(t *testing.T) {
+	previous, current := testCipher(t, 1), testCipher(t, 2)
+	rotating := NewRotatingCipher(current, testCipher(t, 3), previous)
+
+	old, err := previous.Encrypt([]byte("kim@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err := rotating.Decrypt(old)
+	if err != nil || string(plaintext) != "kim@example.com" {
+		t.Errorf("expected a value encrypted with a previous key read, got %q & %v", plaintext, err)
+	}
+
+	// New values are encrypted with the current key only
+	fresh, err := rotating.Encrypt([]byte("kim@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = current.Decrypt(fresh)
+	if err != nil {
+		t.Errorf("expected the current key to encrypt, got %v", err)
+	}
+	_, err = previous.Decrypt(fresh)
+	if err == nil {
+		t.Error("expected the previous key unused for encrypting")
+	}
+
+	unknown, err := testCipher(t, 4).Encrypt([]byte("kim@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = rotating.Decrypt(unknown)
+	if err == nil {
+		t.Error("expected a value encrypted with none of the keys refused")
+	}
+}
+
+const encryptedTable = `CREATE TABLE "customers" ("id" INTEGER PRIMARY KEY, "email" BLOB, "phone" BLOB, "notes" BLOB)`
+
+type encryptedCustomer struct {
+	ID    int64   `db:"id,pk"`
+	Email string  `db:"email,encrypted"`
+	Phone *string `db:"phone,encrypted"`
+	Notes []byte  `db:"notes,encrypted"`
+}
+
+func TestEncryptedFields(t *testing.T) {
+	ctx := context.Background()
+	key := testCipher(t, 1)
+	ac := New(openTestDB(t, encryptedTable), WithDialect(SQLite), WithFieldCipher(key))
+	phone := "+44 20 7946 0000"
+
+	_, err := InsertAll(ctx, ac, "customers", []encryptedCustomer{
+		{ID: 1, Email: "kim@example.com", Phone: &phone, Notes: []byte("prefers email")},
+		{ID: 2, Email: ""},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The database only sees ciphertext, NULL left as it is
+	var email, phoneCiphertext, notes []byte
+	err = ac.DB.QueryRow(`SELECT "email", "phone", "notes" FROM "customers" WHERE "id" = 1`).Scan(&email, &phoneCiphertext, &notes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(email, []byte("kim")) || bytes.Contains(notes, []byte("email")) {
+		t.Errorf("expected the columns encrypted, got %q & %q", email, notes)
+	}
+	plaintext, err := key.Decrypt(email)
+	if err != nil || string(plaintext) != "kim@example.com" {
+		t.Errorf("expected the email encrypted with the key, got %q & %v", plaintext, err)
+	}
+	var nulls int
+	err = ac.DB.QueryRow(`SELECT COUNT(*) FROM "customers" WHERE "id" = 2 AND "email" IS NOT NULL AND "phone" IS NULL AND "notes" IS NULL`).Scan(&nulls)
+	if err != nil || nulls != 1 {
+		t.Errorf("expected the empty email encrypted & the nil fields stored as NULL, got %d & %v", nulls, err)
+	}
+
+	customers, err := Select[encryptedCustomer](ctx, ac, `SELECT * FROM "customers" ORDER BY "id"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(customers) != 2 || customers[0].Email != "kim@example.com" || customers[0].Phone == nil || *customers[0].Phone != phone ||
+		string(customers[0].Notes) != "prefers email" || customers[1].Email != "" || customers[1].Phone != nil || customers[1].Notes != nil {
+		t.Errorf("expected the customers decrypted, got %+v", customers)
+	}
+
+	err = ac.UpdateStructFields(ctx, "customers", encryptedCustomer{ID: 2, Email: "ann@example.com"}, []string{"Email"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	customer, err := Get[encryptedCustomer](ctx, ac, `SELECT * FROM "customers" WHERE "id" = 2`)
+	if err != nil || customer.Email != "ann@example.com" {
+		t.Errorf("expected the updated email decrypted, got %+v & %v", customer, err)
+	}
+
+	// The package level Args has no cipher, it refuses the struct rather than bind the fields as plaintext
+	func() {
+		defer func() {
+			if r := recover(); r == nil || !strings.Contains(r.(string), "field Email is tagged encrypted") {
+				t.Errorf("expected Args to panic on the encrypted field, got %v", r)
+			}
+		}()
+		_ = ac.UpdateSingleRow(`INSERT INTO "customers" ("id", "email", "phone", "notes") VALUES (?, ?, ?, ?)`,
+			Args(encryptedCustomer{ID: 3, Email: "kim@example.com"})...)
+		t.Error("expected Args to panic")
+	}()
+	args, err := ac.Args(encryptedCustomer{ID: 3, Email: "kim@example.com"}, "ID", "Email", "Phone", "Notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ac.UpdateSingleRow(`INSERT INTO "customers" ("id", "email", "phone", "notes") VALUES (?, ?, ?, ?)`, args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ac.DB.QueryRow(`SELECT "email" FROM "customers" WHERE "id" = 3`).Scan(&email)
+	if err != nil || bytes.Contains(email, []byte("kim")) {
+		t.Errorf("expected Assister.Args to bind the email encrypted, got %q & %v", email, err)
+	}
+	_, err = ac.DB.Exec(`DELETE FROM "customers" WHERE "id" = 3`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Read with another key, or with none, the scan fails rather than return ciphertext
+	_, err = Select[encryptedCustomer](ctx, New(ac.DB, WithDialect(SQLite), WithFieldCipher(testCipher(t, 2))), `SELECT * FROM "customers"`)
+	if err == nil {
+		t.Error("expected the scan to fail under the wrong key")
+	}
+	_, err = Select[encryptedCustomer](ctx, New(ac.DB, WithDialect(SQLite)), `SELECT * FROM "customers"`)
+	if err == nil || !strings.Contains(err.Error(), "WithFieldCipher") {
+		t.Errorf("expected the scan to fail without a cipher, got %v", err)
+	}
+	_, err = InsertAll(ctx, New(ac.DB, WithDialect(SQLite)), "customers", []encryptedCustomer{{ID: 3, Email: "kim@example.com"}})
+	if err == nil || !strings.Contains(err.Error(), "field Email") {
+		t.Errorf("expected the insert to fail without a cipher, got %v", err)
+	}
+
+	// A key rotated out still reads the rows written with it, which are re-encrypted as they are written again
+	rotated := New(ac.DB, WithDialect(SQLite), WithFieldCipher(NewRotatingCipher(testCipher(t, 2), key)))
+	customer, err = Get[encryptedCustomer](ctx, rotated, `SELECT * FROM "customers" WHERE "id" = 1`)
+	if err != nil || customer.Email != "kim@example.com" {
+		t.Fatalf("expected the row read with the previous key, got %+v & %v", customer, err)
+	}
+	err = rotated.UpdateStruct(ctx, "customers", customer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Get[encryptedCustomer](ctx, ac, `SELECT * FROM "customers" WHERE "id" = 1`)
+	if err == nil {
+		t.Error("expected the row re-encrypted with the current key")
+	}
+}
+
+func TestEncryptedAsText(t *testing.T) {
+	ctx := context.Background()
+	key := testCipher(t, 1)
+	ac := New(openTestDB(t, `CREATE TABLE "customers" ("id" INTEGER PRIMARY KEY, "email" TEXT, "phone" TEXT, "notes" TEXT)`),
+		WithDialect(SQLite), WithFieldCipher(key), WithEncryptedAsText())
+
+	_, err := InsertAll(ctx, ac, "customers", []encryptedCustomer{{ID: 1, Email: "kim@example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := Get[string](ctx, ac, `SELECT "email" FROM "customers"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(stored)
+	if err != nil {
+		t.Fatalf("expected base64 text stored, got %q & %v", stored, err)
+	}
+	plaintext, err := key.Decrypt(ciphertext)
+	if err != nil || string(plaintext) != "kim@example.com" {
+		t.Errorf("expected the email encrypted with the key, got %q & %v", plaintext, err)
+	}
+
+	customer, err := Get[encryptedCustomer](ctx, ac, `SELECT * FROM "customers"`)
+	if err != nil || customer.Email != "kim@example.com" {
+		t.Errorf("expected the email decrypted, got %+v & %v", customer, err)
+	}
+}
+
+func TestEncryptValue(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openTestDB(t, encryptedTable), WithDialect(SQLite), WithFieldCipher(testCipher(t, 1)))
+
+	email, err := ac.EncryptValue("kim@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ac.UpdateSingleRow(`INSERT INTO "customers" ("id", "email") VALUES (1, ?)`, email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Written by hand, read by the struct helpers & the other way round
+	customer, err := Get[encryptedCustomer](ctx, ac, `SELECT "id", "email" FROM "customers"`)
+	if err != nil || customer.Email != "kim@example.com" {
+		t.Errorf("expected the email decrypted, got %+v & %v", customer, err)
+	}
+	ciphertext, err := Get[[]byte](ctx, ac, `SELECT "email" FROM "customers"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err := ac.DecryptValue(ciphertext)
+	if err != nil || string(plaintext) != "kim@example.com" {
+		t.Errorf("expected the email decrypted, got %q & %v", plaintext, err)
+	}
+
+	var nilPhone *string
+	for _, value := range []any{nil, nilPhone, []byte(nil)} {
+		encrypted, err := ac.EncryptValue(value)
+		if err != nil || encrypted != nil {
+			t.Errorf("expected %#v encrypted as NULL, got %v & %v", value, encrypted, err)
+		}
+	}
+	plaintext, err = ac.DecryptValue(nil)
+	if err != nil || plaintext != nil {
+		t.Errorf("expected NULL decrypted as nil, got %q & %v", plaintext, err)
+	}
+	_, err = ac.EncryptValue(42)
+	if err == nil {
+		t.Error("expected an int refused")
+	}
+	_, err = ac.DecryptValue([]byte("not a ciphertext at all, too short"))
+	if err == nil {
+		t.Error("expected garbage refused")
+	}
+}
@@ -0,0 +1,97 @@
+package sqlAssister
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the source of time of an Assister, the real clock unless WithClock sets another. Tests inject a fake clock, such as
+// sqlAssistertest.FakeClock, to drive slow query thresholds, retry backoffs & budgets, cache & metadata TTLs & transaction
+// durations deterministically, without sleeping
+type Clock interface {
+	Now() time.Time
+	// Sleep blocks for d
+	Sleep(d time.Duration)
+	// After returns a channel receiving the time once d has elapsed
+	After(d time.Duration) <-chan time.Time
+	// NewTimer returns a Timer firing once d has elapsed
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single event timer returned by Clock.NewTimer, as time.Timer is by time.NewTimer
+type Timer interface {
+	// C returns the channel receiving the time the Timer fires at
+	C() <-chan time.Time
+	// Stop prevents the Timer from firing, reporting false when it already fired or was stopped
+	Stop() bool
+}
+
+// WithClock sets the Clock the Assister reads the time from & waits on, the deadlines set by WithDefaultQueryTimeout & WithTimeout
+// included: a statement times out once the clock is advanced past its deadline. The caller's context deadline is still enforced
+// by the runtime on the real clock, while the time left before it, which WithCheckpoints' margin & SQLite's busy retries
+// go by, is measured on the Assister's clock. A RetryBudget given to the Assister is refilled on its clock too
+/*
+
+Example:
+
+	clock := sqlAssistertest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	statementAssister = sqlAssister.New(db, sqlAssister.WithClock(clock), sqlAssister.WithQueryCache(cache))
+
+	clock.Advance(time.Hour)
+*/
+func WithClock(clock Clock) Option {
+	return func(ac *Assister) {
+		ac.clock = clock
+	}
+}
+
+func (ac Assister) getClock() Clock {
+	if ac.clock == nil {
+		return realClock{}
+	}
+
+	return ac.clock
+}
+
+// realClock is the Clock of the time package
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{timer: time.NewTimer(d)}
+}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t realTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+// sleepContext waits d on clock, reporting false when ctx is done first
+func sleepContext(ctx context.Context, clock Clock, d time.Duration) bool {
+	timer := clock.NewTimer(d)
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+		return false
+	case <-timer.C():
+		return true
+	}
+}
@@ -0,0 +1,176 @@
+package sqlAssister_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/zobstory/sqlAssister"
+	"github.com/zobstory/sqlAssister/sqlAssistertest"
+)
+
+// advanceUntilDone advances clock by step whenever a timer waits on it, until done receives the error it returns
+func advanceUntilDone(t *testing.T, clock *sqlAssistertest.FakeClock, step time.Duration, done <-chan error) error {
+	t.Helper()
+	giveUp := time.After(5 * time.Second)
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-giveUp:
+			t.Fatal("expected the statement to return as the clock advanced")
+			return nil
+		default:
+		}
+		if clock.Waiters() > 0 {
+			clock.Advance(step)
+		} else {
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+func TestRetryBudgetOnClock(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	clock := sqlAssistertest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	budget := sqlAssister.NewRetryBudget(2, 1)
+	ac := sqlAssister.New(db, sqlAssister.WithClock(clock), sqlAssister.WithRetryBudget(budget), sqlAssister.WithConnectionRetries(5))
+
+	if remaining := budget.Remaining(); remaining != 2 {
+		t.Fatalf("expected a full budget of 2, got %d", remaining)
+	}
+
+	// The first retry backs off 50ms & the second 100ms, refilling 0.15 of a token: the third retry finds the budget empty
+	for i := 0; i < 3; i++ {
+		mock.ExpectQuery(`SELECT 1`).WillReturnError(io.ErrUnexpectedEOF)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := sqlAssister.Get[int](context.Background(), ac, `SELECT 1`)
+		done <- err
+	}()
+	err = advanceUntilDone(t, clock, 50*time.Millisecond, done)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected the connection error once the budget ran out, got %v", err)
+	}
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Error(err)
+	}
+
+	// The budget refills as the clock moves, however long the test takes on the real one
+	if remaining := budget.Remaining(); remaining != 0 {
+		t.Errorf("expected the budget spent, got %d", remaining)
+	}
+	clock.Advance(849 * time.Millisecond)
+	if remaining := budget.Remaining(); remaining != 0 {
+		t.Errorf("expected 0.999 of a token refilled, got %d", remaining)
+	}
+	clock.Advance(time.Millisecond)
+	if remaining := budget.Remaining(); remaining != 1 {
+		t.Errorf("expected a token refilled after a second on the clock, got %d", remaining)
+	}
+	clock.Advance(time.Hour)
+	if remaining := budget.Remaining(); remaining != 2 {
+		t.Errorf("expected the budget refilled up to its burst, got %d", remaining)
+	}
+}
+
+// clockRecord is a record of the checkpointed insert, a single column so a chunk holds 999 of them on SQLite
+type clockRecord struct {
+	ID int64 `db:"id"`
+}
+
+func TestCheckpointMarginOnClock(t *testing.T) {
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "clock.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE "records" ("id" INTEGER PRIMARY KEY)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	clock := sqlAssistertest.NewFakeClock(now)
+	ac := sqlAssister.New(db, sqlAssister.WithDialect(sqlAssister.SQLite), sqlAssister.WithClock(clock))
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(time.Hour))
+	defer cancel()
+
+	records := make([]clockRecord, 3000)
+	for i := range records {
+		records[i].ID = int64(i + 1)
+	}
+	var progress []int
+	inserted, err := sqlAssister.InsertAll(ctx, ac.WithCheckpoints(30*time.Minute, func(committed int) {
+		progress = append(progress, committed)
+		// 45 minutes pass on the clock while the second chunk commits, leaving 15 of the hour
+		if len(progress) == 2 {
+			clock.Advance(45 * time.Minute)
+		}
+	}), "records", records)
+
+	var partial *sqlAssister.PartialCompletionError
+	if !errors.As(err, &partial) || partial.ResumeOffset != 1998 || !strings.Contains(err.Error(), "15m0s left") {
+		t.Fatalf("expected the batch stopped at 1998 with 15m left on the clock, got %v", err)
+	}
+	if inserted != 1998 || len(progress) != 2 {
+		t.Errorf("expected 2 chunks of 999 committed, got %d records & progress %v", inserted, progress)
+	}
+}
+
+func TestSQLiteBusyRetryOnClock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "busy.db")
+	locker, err := sql.Open("sqlite3", "file:"+path+"?_txlock=immediate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer locker.Close()
+	_, err = locker.Exec(`CREATE TABLE "records" ("id" INTEGER PRIMARY KEY)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite3", "file:"+path+"?_busy_timeout=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Another process holds the write lock for the whole test
+	tx, err := locker.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	clock := sqlAssistertest.NewFakeClock(now)
+	ac := sqlAssister.New(db, sqlAssister.WithDialect(sqlAssister.SQLite), sqlAssister.WithClock(clock))
+
+	// The write is retried until the deadline is a backoff away on the clock, not on the real clock an hour away
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(time.Hour))
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, err := ac.ExecExpecting(ctx, `INSERT INTO "records" ("id") VALUES (1)`, 1)
+		done <- err
+	}()
+	err = advanceUntilDone(t, clock, 10*time.Minute, done)
+	if err == nil || !strings.Contains(err.Error(), "locked") {
+		t.Errorf("expected the busy error once the deadline neared on the clock, got %v", err)
+	}
+	if elapsed := clock.Now().Sub(now); elapsed < 50*time.Minute || elapsed > time.Hour {
+		t.Errorf("expected the retries to stop within a backoff of the deadline, stopped after %v", elapsed)
+	}
+}
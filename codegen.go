@@ -0,0 +1,164 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// The functions of this file are the low level API code generated against the package, e.g. typed repositories generated from SQL
+// the way sqlc generates them, relies on: ExecExpecting, QueryOne & QueryMany with a ScanPlan built once, ColumnName &
+// Assister.QuoteIdentifier. They are a compatibility surface, the behaviour documented on each won't change within a major version,
+// so generated code keeps working as the rest of the package evolves. Common to all of them:
+//   - ctx bounds the statement & the reading of its rows, which are closed before they return whatever the outcome
+//   - statements run in the transaction or on the connection an Assister is bound to, with its logging, labels, timeouts & query checks
+//   - errors are returned as the rest of the package returns them, see errors.go, never wrapped in an error of their own
+
+// AnyRowsAffected makes ExecExpecting skip checking the number of records the statement affected
+const AnyRowsAffected = -1
+
+// ExecExpecting executes a statement expected to affect exactly expected records, returning a *RowsAffectedError when it affected
+// another number, none included, & the result of the statement along with it. With AnyRowsAffected any number is accepted.
+// When the driver can't report the number the check is skipped, or fails WithStrictRowsAffected
+/*
+
+Example:
+
+	func (r *BookRepository) RenameBook(ctx context.Context, id int64, name string) error {
+		_, err := r.ac.ExecExpecting(ctx, renameBook, 1, name, id)
+		return err
+	}
+*/
+func (ac Assister) ExecExpecting(ctx context.Context, query string, expected int64, args ...any) (sql.Result, error) {
+	err := utils.QueryChecker(query, ac.queryChecks()...)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := ac.conn().ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if expected == AnyRowsAffected {
+		return results, nil
+	}
+
+	return results, ac.checkRowsAffected(results, expected)
+}
+
+// ScanPlan is the mapping of the columns a query returns to a T, built once by NewScanPlan rather than on every call as Select does.
+// A ScanPlan is immutable & safe for concurrent use
+type ScanPlan[T any] struct {
+	plan *scanPlan[T]
+}
+
+// NewScanPlan maps columns, the columns a query returns in order, to T following the same rules as Select, failing when a column
+// has no matching field. The plan decodes values the way ac does, see WithTimeLayouts & WithBoolStrings, so it must only be used
+// with ac or Assisters sharing its options, e.g. ac's transactions
+/*
+
+Example:
+
+	var bookPlan = sqlAssister.MustScanPlan[Book](Assister, "id", "name", "published_at")
+*/
+func NewScanPlan[T any](ac *Assister, columns ...string) (*ScanPlan[T], error) {
+	plan, err := newScanPlan[T](ac, append([]string{}, columns...))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScanPlan[T]{plan: plan}, nil
+}
+
+// MustScanPlan is NewScanPlan panicking on error, for plans built when the generated code is initialized
+func MustScanPlan[T any](ac *Assister, columns ...string) *ScanPlan[T] {
+	plan, err := NewScanPlan[T](ac, columns...)
+	if err != nil {
+		panic(err)
+	}
+
+	return plan
+}
+
+// Columns returns the columns the plan expects, in order
+func (p *ScanPlan[T]) Columns() []string {
+	return append([]string{}, p.plan.columns...)
+}
+
+// QueryOne executes a query & scans its first record into a T with plan, returning ErrNotFound when there is none.
+// Records past the first are ignored. NULL is scanned as Select scans it: as nil into pointers & sql.Null types,
+// failing for any other field. The query must return the plan's columns, it fails before reading any record when the
+// number of columns differs
+/*
+
+Example:
+
+	func (r *BookRepository) GetBook(ctx context.Context, id int64) (Book, error) {
+		return sqlAssister.QueryOne(ctx, r.ac, bookPlan, getBook, id)
+	}
+*/
+func QueryOne[T any](ctx context.Context, ac *Assister, plan *ScanPlan[T], query string, args ...any) (T, error) {
+	var result T
+	rows, err := queryPlanned(ctx, ac, plan, query, args)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	return scanFirst(plan.plan, rows)
+}
+
+// QueryMany executes a query & scans each of its records into a T with plan, returning nil when there are none.
+// NULL, the columns returned & ContinueOnError are handled as QueryOne & Select handle them, the Assister's auto limit
+// isn't applied: the generated query is executed as it is
+/*
+
+Example:
+
+	func (r *BookRepository) ListBooksByAuthor(ctx context.Context, authorId int64) ([]Book, error) {
+		return sqlAssister.QueryMany(ctx, r.ac, bookPlan, listBooksByAuthor, authorId)
+	}
+*/
+func QueryMany[T any](ctx context.Context, ac *Assister, plan *ScanPlan[T], query string, args ...any) ([]T, error) {
+	rows, err := queryPlanned(ctx, ac, plan, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRows(plan.plan, rows, ac.continueOnError, ac.partialResults)
+}
+
+// queryPlanned executes a query whose columns are to be scanned with plan, closing its rows when they don't match the plan
+func queryPlanned[T any](ctx context.Context, ac *Assister, plan *ScanPlan[T], query string, args []any) (*sql.Rows, error) {
+	err := utils.QueryChecker(query, ac.queryChecks()...)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ac.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := rows.Columns()
+	if err == nil && len(columns) != len(plan.plan.columns) {
+		err = fmt.Errorf("query returned %d columns (%s), the scan plan expects %d (%s)",
+			len(columns), strings.Join(columns, ", "), len(plan.plan.columns), strings.Join(plan.plan.columns, ", "))
+	}
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// ColumnName returns the column an untagged struct field named fieldName is mapped to, e.g. user_id for UserID,
+// so generated structs can leave out the `db` tags of the columns following the convention
+func ColumnName(fieldName string) string {
+	return toSnakeCase(fieldName)
+}
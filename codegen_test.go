@@ -0,0 +1,211 @@
+package sqlAssister_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/zobstory/sqlAssister"
+	"github.com/zobstory/sqlAssister/testdata/bookrepo"
+)
+
+// openBookRepo returns the generated repository fixture on a fresh SQLite database, along with the database
+// to check its connections are released
+func openBookRepo(t *testing.T, opts ...sqlAssister.Option) (*bookrepo.Queries, *sqlAssister.Assister, *sql.DB) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "repo.db")+"?_busy_timeout=5000&_txlock=immediate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	_, err = db.Exec(bookrepo.Schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ac := sqlAssister.New(db, append([]sqlAssister.Option{sqlAssister.WithDialect(sqlAssister.SQLite)}, opts...)...)
+	return bookrepo.New(ac), ac, db
+}
+
+// expectReleased checks every connection went back to the pool, the rows of every query closed whatever its outcome
+func expectReleased(t *testing.T, db *sql.DB) {
+	t.Helper()
+	if inUse := db.Stats().InUse; inUse != 0 {
+		t.Errorf("expected every connection released, %d in use", inUse)
+	}
+}
+
+func TestGeneratedRepository(t *testing.T) {
+	ctx := context.Background()
+	repo, _, db := openBookRepo(t)
+	author := int64(7)
+
+	duneID, err := repo.CreateBook(ctx, "Dune", &author, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	emmaID, err := repo.CreateBook(ctx, "Emma", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dune, err := repo.GetBook(ctx, duneID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dune.Name != "Dune" || dune.AuthorID == nil || *dune.AuthorID != author || dune.Stock != 3 {
+		t.Errorf("expected Dune read back, got %+v", dune)
+	}
+	emma, err := repo.GetBook(ctx, emmaID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if emma.AuthorID != nil {
+		t.Errorf("expected the NULL author read as nil, got %d", *emma.AuthorID)
+	}
+
+	books, err := repo.ListBooksByAuthor(ctx, author)
+	if err != nil || len(books) != 1 || books[0].ID != duneID {
+		t.Errorf("expected Dune listed, got %+v & %v", books, err)
+	}
+	books, err = repo.ListBooksByAuthor(ctx, 8)
+	if err != nil || books != nil {
+		t.Errorf("expected nil for no books, got %+v & %v", books, err)
+	}
+
+	err = repo.RenameBook(ctx, "Dune Messiah", duneID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := repo.DeleteOutOfStock(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted, _ := result.RowsAffected(); deleted != 1 {
+		t.Errorf("expected Emma deleted, %d records were", deleted)
+	}
+	// AnyRowsAffected accepts none
+	_, err = repo.DeleteOutOfStock(ctx)
+	if err != nil {
+		t.Errorf("expected deleting no records accepted, got %v", err)
+	}
+
+	stock, err := repo.GetAuthorStock(ctx, author)
+	if err != nil || stock.TotalStock != 3 {
+		t.Errorf("expected a stock of 3, got %+v & %v", stock, err)
+	}
+	expectReleased(t, db)
+}
+
+func TestGeneratedRepositoryErrors(t *testing.T) {
+	ctx := context.Background()
+	for _, label := range []string{"", "inventory"} {
+		name := "unlabelled"
+		if label != "" {
+			name = "labelled"
+		}
+		t.Run(name, func(t *testing.T) {
+			repo, ac, db := openBookRepo(t)
+			if label != "" {
+				repo = repo.WithAssister(ac.Label(label))
+			}
+
+			_, err := repo.GetBook(ctx, 1)
+			if !errors.Is(err, sqlAssister.ErrNotFound) {
+				t.Errorf("expected ErrNotFound for a missing book, got %v", err)
+			}
+
+			err = repo.RenameBook(ctx, "Dune", 1)
+			var affected *sqlAssister.RowsAffectedError
+			if !errors.As(err, &affected) || affected.Affected != 0 || affected.Expected != 1 {
+				t.Errorf("expected a *RowsAffectedError renaming a missing book, got %v", err)
+			}
+
+			// NULL only scans into pointers: SUM over no records is NULL, & the generated int64 can't hold it
+			_, err = repo.GetAuthorStock(ctx, 8)
+			if err == nil || errors.Is(err, sqlAssister.ErrNotFound) || !strings.Contains(err.Error(), "total_stock") {
+				t.Errorf("expected NULL refused for the int64 total_stock, got %v", err)
+			}
+
+			// The database's errors are returned as they are, labelled or not
+			_, err = repo.CreateBook(ctx, "Dune", nil, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = ac.DB.Exec(`DROP TABLE "books"`)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = repo.ListBooksByAuthor(ctx, 7)
+			var opErr *sqlAssister.OperationError
+			if err == nil || !strings.Contains(err.Error(), "no such table") || errors.As(err, &opErr) != (label != "") {
+				t.Errorf("expected the driver's error, labelled %q, got %v", label, err)
+			}
+			if opErr != nil && opErr.Operation != label {
+				t.Errorf("expected the operation %q, got %q", label, opErr.Operation)
+			}
+			expectReleased(t, db)
+		})
+	}
+}
+
+func TestGeneratedRepositoryContext(t *testing.T) {
+	repo, ac, db := openBookRepo(t)
+	author := int64(7)
+	for i := 0; i < 3; i++ {
+		_, err := repo.CreateBook(context.Background(), "Dune", &author, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := repo.CreateBook(cancelled, "Emma", nil, 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a cancelled context to stop the statement, got %v", err)
+	}
+	_, err = repo.ListBooksByAuthor(cancelled, author)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a cancelled context to stop the query, got %v", err)
+	}
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	_, err = repo.GetBook(expired, 1)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a past deadline to stop the query, got %v", err)
+	}
+
+	// The queries run in the transaction of the Assister they are given, its context bounding them
+	txCtx, cancelTx := context.WithCancel(context.Background())
+	defer cancelTx()
+	err = ac.WithTransaction(txCtx, func(tx *sqlAssister.TxAssister) error {
+		txRepo := repo.WithAssister(tx.Assister)
+		_, err := txRepo.CreateBook(txCtx, "Emma", nil, 1)
+		if err != nil {
+			return err
+		}
+		books, err := txRepo.ListBooksByAuthor(txCtx, author)
+		if err != nil || len(books) != 3 {
+			t.Errorf("expected the books listed in the transaction, got %d & %v", len(books), err)
+		}
+
+		cancelTx()
+		_, err = txRepo.GetBook(context.Background(), 1)
+		return err
+	})
+	if !errors.Is(err, sqlAssister.ErrTxContextCanceled) || !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the transaction's cancelled context to stop the query, got %v", err)
+	}
+	var count int
+	err = db.QueryRow(`SELECT COUNT(*) FROM "books"`).Scan(&count)
+	if err != nil || count != 3 {
+		t.Errorf("expected the transaction rolled back leaving 3 books, got %d & %v", count, err)
+	}
+	expectReleased(t, db)
+}
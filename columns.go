@@ -0,0 +1,213 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// SelectColumn Executes Read operation returning a single column & scans every value into a slice of T.
+// Returns an error if the query returns more than one column.
+// NULL is scanned as nil when T is a pointer & into sql.Null* types as invalid, scanning NULL into any other T is an error.
+// Custom types are scanned through their registered converter, see utils.RegisterScanConverter
+/*
+
+Example:
+
+	ids, err := sqlAssister.SelectColumn[string](ctx, Assister, `SELECT "id" FROM "books" WHERE "author_id" = $1`, authorId)
+	if err != nil {
+		return nil, err
+	}
+*/
+func SelectColumn[T any](ctx context.Context, ac *Assister, query string, args ...any) ([]T, error) {
+	rows, err := queryColumns(ctx, ac, 1, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plan := &scanPlan[T]{isValue: true, decoding: ac.decoding(), recovery: ac.panicRecovery()}
+	var results []T
+	for rows.Next() {
+		result, err := plan.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// SelectSet Executes Read operation returning a single column & scans the values into a set for membership tests,
+// values returned more than once are kept once. Values are scanned following the same rules as SelectColumn
+/*
+
+Example:
+
+	allowed, err := sqlAssister.SelectSet[string](ctx, Assister, `SELECT "book_id" FROM "grants" WHERE "user_id" = $1`, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := allowed[bookId]; !ok {
+		return nil, ErrForbidden
+	}
+*/
+func SelectSet[T comparable](ctx context.Context, ac *Assister, query string, args ...any) (map[T]struct{}, error) {
+	rows, err := queryColumns(ctx, ac, 1, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plan := &scanPlan[T]{isValue: true, decoding: ac.decoding(), recovery: ac.panicRecovery()}
+	results := map[T]struct{}{}
+	for rows.Next() {
+		result, err := plan.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		results[result] = struct{}{}
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// SelectColumnMap Executes Read operation returning two columns & scans them into a map of the first column to the second.
+// Returns an error if the query doesn't return exactly two columns or returns the same key twice, see SelectColumnMapLastWins.
+// Values are scanned following the same rules as SelectColumn
+/*
+
+Example:
+
+	names, err := sqlAssister.SelectColumnMap[string, string](ctx, Assister, `SELECT "id", "name" FROM "books"`)
+	if err != nil {
+		return nil, err
+	}
+*/
+func SelectColumnMap[K comparable, V any](ctx context.Context, ac *Assister, query string, args ...any) (map[K]V, error) {
+	return selectColumnMap[K, V](ctx, ac, false, query, args)
+}
+
+// SelectColumnMapLastWins is SelectColumnMap except a key returned more than once maps to the last value returned for it
+func SelectColumnMapLastWins[K comparable, V any](ctx context.Context, ac *Assister, query string, args ...any) (map[K]V, error) {
+	return selectColumnMap[K, V](ctx, ac, true, query, args)
+}
+
+func selectColumnMap[K comparable, V any](ctx context.Context, ac *Assister, lastWins bool, query string, args []any) (map[K]V, error) {
+	rows, err := queryColumns(ctx, ac, 2, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := map[K]V{}
+	for rows.Next() {
+		var key K
+		var value V
+		err := rows.Scan(scanDest(valueOf(&key), ac.decoding()), scanDest(valueOf(&value), ac.decoding()))
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := results[key]; exists && !lastWins {
+			return nil, fmt.Errorf("key %v is returned more than once", key)
+		}
+		results[key] = value
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// SelectNestedMap Executes Read operation returning three columns & scans them into a two level map of the first column to
+// the second to the third, e.g. the totals of a pivot table by region & product. Returns an error if the query doesn't return
+// exactly three columns or returns the same pair of keys twice. Values are scanned following the same rules as SelectColumn
+/*
+
+Example:
+
+	totals, err := sqlAssister.SelectNestedMap[string, string, float64](ctx, Assister,
+		`SELECT "region", "product", SUM("amount") FROM "sales" WHERE "year" = $1 GROUP BY "region", "product"`, year)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Println(totals["EMEA"]["widgets"])
+*/
+func SelectNestedMap[K1, K2 comparable, V any](ctx context.Context, ac *Assister, query string, args ...any) (map[K1]map[K2]V, error) {
+	rows, err := queryColumns(ctx, ac, 3, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	d := ac.decoding()
+	results := map[K1]map[K2]V{}
+	for rows.Next() {
+		var outer K1
+		var inner K2
+		var value V
+		err := rows.Scan(scanDest(valueOf(&outer), d), scanDest(valueOf(&inner), d), scanDest(valueOf(&value), d))
+		if err != nil {
+			return nil, err
+		}
+		nested, ok := results[outer]
+		if !ok {
+			nested = map[K2]V{}
+			results[outer] = nested
+		}
+		if _, exists := nested[inner]; exists {
+			return nil, fmt.Errorf("keys %v, %v are returned more than once", outer, inner)
+		}
+		nested[inner] = value
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// queryColumns executes a query checking it returns the expected number of columns
+func queryColumns(ctx context.Context, ac *Assister, expected int, query string, args []any) (*sql.Rows, error) {
+	err := utils.QueryChecker(query, ac.queryChecks()...)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ac.conn().QueryContext(ctx, ac.limitQuery(query), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	if len(columns) != expected {
+		rows.Close()
+		return nil, fmt.Errorf("query returns %d columns, expected %d", len(columns), expected)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,178 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+)
+
+// ContextFields extracts the fields describing the request a statement is executed for from its context,
+// e.g. the trace id or endpoint, see WithContextFields
+type ContextFields func(ctx context.Context) map[string]string
+
+// WithStatementComments prepends a comment identifying who issued it to every statement the Assister executes, e.g.
+// /*app=checkout,db=primary,label=book.get_by_id,trace=abc123*/, so DBAs can trace a query seen in pg_stat_activity or
+// a slow query log back to the service & operation. The comment carries, when set:
+//   - app, the name given WithApplicationName
+//   - db, the Assister's name, see WithName
+//   - label, the operation named by Label
+//   - the fields WithContextFields extracts from the statement's context, sorted by key
+//
+// Keys & values are percent-encoded except for letters, digits & -._~ so no value can end the comment or inject SQL.
+// utils.Fingerprint ignores comments so fingerprints are unchanged. The statements are logged without their comment
+func WithStatementComments() Option {
+	return func(ac *Assister) {
+		ac.statementComments = true
+	}
+}
+
+// WithContextFields sets the extractor of the fields the comments of WithStatementComments carry from a statement's context
+/*
+
+Example:
+
+	Assister := sqlAssister.New(db, sqlAssister.WithStatementComments(), sqlAssister.WithApplicationName("checkout"),
+		sqlAssister.WithContextFields(func(ctx context.Context) map[string]string {
+			return map[string]string{"trace": trace.SpanContextFromContext(ctx).TraceID().String()}
+		}))
+*/
+func WithContextFields(extract ContextFields) Option {
+	return func(ac *Assister) {
+		ac.contextFields = extract
+	}
+}
+
+// WithSQLCommenter is WithStatementComments writing the comment in the sqlcommenter format APM & database monitoring tools parse,
+// e.g. /*app='checkout',label='book.get_by_id',traceparent='00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01'*/,
+// so a slow query they record is tied to the request trace that issued it. Following the convention the comment is appended to
+// the statement, before a trailing semicolon, keys are sorted & values quoted. A statement that already ends with a comment is
+// left as it is. Carry the trace context with WithContextFields, e.g. by setting traceparent
+/*
+
+Example:
+
+	Assister := sqlAssister.New(db, sqlAssister.WithSQLCommenter(), sqlAssister.WithContextFields(func(ctx context.Context) map[string]string {
+		carrier := propagation.MapCarrier{}
+		otel.GetTextMapPropagator().Inject(ctx, carrier)
+		carrier["controller"] = controllerFromContext(ctx)
+		return carrier
+	}))
+*/
+func WithSQLCommenter() Option {
+	return func(ac *Assister) {
+		ac.statementComments = true
+		ac.sqlCommenter = true
+	}
+}
+
+// commentFields returns the escaped key & value of every field the comment of a statement executed with ctx carries
+func (ac Assister) commentFields(ctx context.Context) [][2]string {
+	var fields [][2]string
+	add := func(key string, value string) {
+		if value != "" {
+			fields = append(fields, [2]string{escapeCommentField(key), escapeCommentField(value)})
+		}
+	}
+
+	add("app", ac.applicationName)
+	add("db", ac.name)
+	add("label", ac.label)
+	if ac.contextFields != nil {
+		extracted := ac.contextFields(ctx)
+		keys := make([]string, 0, len(extracted))
+		for key := range extracted {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			add(key, extracted[key])
+		}
+	}
+
+	return fields
+}
+
+// commentStatement returns query carrying the Assister's comment for ctx, unchanged when there is nothing to say
+func (ac Assister) commentStatement(ctx context.Context, query string) string {
+	fields := ac.commentFields(ctx)
+	if len(fields) == 0 {
+		return query
+	}
+
+	if !ac.sqlCommenter {
+		pairs := make([]string, len(fields))
+		for i, field := range fields {
+			pairs[i] = field[0] + "=" + field[1]
+		}
+		return "/*" + strings.Join(pairs, ",") + "*/ " + query
+	}
+
+	body := strings.TrimRight(query, " \t\r\n")
+	if strings.HasSuffix(body, "*/") {
+		return query
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i][0] < fields[j][0]
+	})
+	pairs := make([]string, len(fields))
+	for i, field := range fields {
+		pairs[i] = field[0] + "='" + field[1] + "'"
+	}
+	comment := "/*" + strings.Join(pairs, ",") + "*/"
+
+	terminator := ""
+	if strings.HasSuffix(body, ";") {
+		body, terminator = strings.TrimRight(strings.TrimSuffix(body, ";"), " \t\r\n"), ";"
+	}
+	// A line comment on the statement's last line would swallow the comment
+	separator := " "
+	if strings.Contains(body[strings.LastIndex(body, "\n")+1:], "--") {
+		separator = "\n"
+	}
+
+	return body + separator + comment + terminator
+}
+
+// escapeCommentField percent-encodes every byte of s other than letters, digits & -._~, which leaves nothing that can
+// close the comment, separate fields or be read as a bind parameter
+func escapeCommentField(s string) string {
+	const hex = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '-', c == '.', c == '_', c == '~':
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0xF])
+		}
+	}
+
+	return b.String()
+}
+
+// commentQuerier adds the Assister's statement comment to every statement executed on q, see WithStatementComments & WithSQLCommenter
+type commentQuerier struct {
+	q  querier
+	ac *Assister
+}
+
+func (q commentQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return q.q.ExecContext(ctx, q.ac.commentStatement(ctx, query), args...)
+}
+
+func (q commentQuerier) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return q.q.PrepareContext(ctx, q.ac.commentStatement(ctx, query))
+}
+
+func (q commentQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return q.q.QueryContext(ctx, q.ac.commentStatement(ctx, query), args...)
+}
+
+func (q commentQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return q.q.QueryRowContext(ctx, q.ac.commentStatement(ctx, query), args...)
+}
@@ -0,0 +1,117 @@
+package sqlAssister
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+type traceKey struct{}
+
+// traceFields extracts the trace id set under traceKey, along with the extra fields given
+func traceFields(extra map[string]string) ContextFields {
+	return func(ctx context.Context) map[string]string {
+		fields := map[string]string{}
+		if trace, ok := ctx.Value(traceKey{}).(string); ok {
+			fields["trace"] = trace
+		}
+		for key, value := range extra {
+			fields[key] = value
+		}
+		return fields
+	}
+}
+
+func TestStatementComments(t *testing.T) {
+	ctx := context.WithValue(context.Background(), traceKey{}, "abc123")
+	const query = `SELECT "id" FROM "books" WHERE "id" = $1`
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		label    string
+		query    string
+		expected string
+	}{
+		{"nothing to say", []Option{WithStatementComments()}, "", query, query},
+		{"every field", []Option{WithStatementComments(), WithApplicationName("checkout"), WithName("primary"), WithContextFields(traceFields(nil))},
+			"book.get_by_id", query, `/*app=checkout,db=primary,label=book.get_by_id,trace=abc123*/ ` + query},
+		{"context fields sorted", []Option{WithStatementComments(), WithContextFields(traceFields(map[string]string{"endpoint": "GET /books", "b": "2"}))},
+			"", query, `/*b=2,endpoint=GET%20%2Fbooks,trace=abc123*/ ` + query},
+		{"empty values left out", []Option{WithStatementComments(), WithContextFields(traceFields(map[string]string{"user": ""}))},
+			"", query, `/*trace=abc123*/ ` + query},
+		{"comment closed in a label", []Option{WithStatementComments()}, `x*/ DROP TABLE "books"; /*`, query,
+			`/*label=x%2A%2F%20DROP%20TABLE%20%22books%22%3B%20%2F%2A*/ ` + query},
+		{"mysql executable comment", []Option{WithStatementComments(), WithContextFields(traceFields(map[string]string{"!50000 key": "/*! x */"}))},
+			"", query, `/*%2150000%20key=%2F%2A%21%20x%20%2A%2F,trace=abc123*/ ` + query},
+		{"separators & parameters", []Option{WithStatementComments()}, `a=b,c='d' $1 ? :name`, query,
+			`/*label=a%3Db%2Cc%3D%27d%27%20%241%20%3F%20%3Aname*/ ` + query},
+		{"sqlcommenter", []Option{WithSQLCommenter(), WithApplicationName("checkout"), WithContextFields(traceFields(nil))},
+			"book.get_by_id", query + ";", query + ` /*app='checkout',label='book.get_by_id',trace='abc123'*/;`},
+		{"sqlcommenter after a line comment", []Option{WithSQLCommenter()}, "book.list", query + " -- by id",
+			query + " -- by id\n/*label='book.list'*/"},
+		{"sqlcommenter on a commented statement", []Option{WithSQLCommenter()}, "book.list", query + " /*hand written*/",
+			query + " /*hand written*/"},
+		{"sqlcommenter quote in a value", []Option{WithSQLCommenter()}, `it's`, query, query + ` /*label='it%27s'*/`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			ac := New(db, test.opts...)
+			if test.label != "" {
+				ac = ac.Label(test.label)
+			}
+
+			mock.ExpectExec(test.expected).WillReturnResult(sqlmock.NewResult(0, 1))
+			_, err = ac.ExecExpecting(ctx, test.query, 1, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = mock.ExpectationsWereMet()
+			if err != nil {
+				t.Error(err)
+			}
+
+			// No value closes the comment before the Assister does
+			if added := strings.Count(test.expected, "*/") - strings.Count(test.query, "*/"); added > 1 {
+				t.Errorf("expected a single comment added, got %d in %s", added, test.expected)
+			}
+
+			// Metrics aren't fragmented by the comment
+			if utils.Fingerprint(test.expected) != utils.Fingerprint(test.query) {
+				t.Errorf("expected the fingerprint unchanged, %q normalizes as %q & %q as %q",
+					test.query, utils.NormalizeQuery(test.query), test.expected, utils.NormalizeQuery(test.expected))
+			}
+		})
+	}
+}
+
+func TestStatementCommentsFingerprintStable(t *testing.T) {
+	const query = `SELECT "id" FROM "books" WHERE "id" IN ($1, $2)`
+	expected := utils.Fingerprint(query)
+	fields := WithContextFields(func(ctx context.Context) map[string]string {
+		return map[string]string{"trace": ctx.Value(traceKey{}).(string)}
+	})
+
+	for _, format := range []Option{WithStatementComments(), WithSQLCommenter()} {
+		ac := New(nil, format, WithApplicationName("checkout"), fields).Label("book.get")
+		// Every request carries another trace, the fingerprint stays the one of the statement
+		for _, trace := range []string{"abc123", "def456", "*/ SELECT 1 /*", "-- x\n"} {
+			commented := ac.commentStatement(context.WithValue(context.Background(), traceKey{}, trace), query)
+			if commented == query {
+				t.Fatalf("expected %q commented", query)
+			}
+			if fingerprint := utils.Fingerprint(commented); fingerprint != expected {
+				t.Errorf("expected the fingerprint %s for %q, got %s", expected, commented, fingerprint)
+			}
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package sqlAssister
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestAssisterConcurrentUse hammers one Assister, with its caches, registry, budget & logging shared, from many goroutines.
+// Run with -race, the data races it is after only fail the test under the race detector
+func TestAssisterConcurrentUse(t *testing.T) {
+	ctx := context.Background()
+	registry := NewQueryRegistry()
+	cache := NewQueryCache(16, time.Minute)
+	logger := &bufferLogger{}
+	ac := New(openTestDB(t, bookTable, `INSERT INTO "books" ("name", "stock") VALUES ('Dune', 1), ('Emma', 2)`),
+		WithDialect(SQLite),
+		WithQueryCache(cache),
+		WithQueryRegistry(registry),
+		WithRetryBudget(NewRetryBudget(10, 10)),
+		WithLogger(logger),
+		WithQueryLogging(),
+		WithSlowQueryThreshold(time.Nanosecond),
+		WithStatementComments(),
+		WithMetadataTTL(time.Minute),
+		WithDefaultQueryTimeout(time.Minute),
+	)
+
+	// A query once built is only read, so every goroutine may execute it
+	byStock := ac.Table("books").Select("id", "name", "author_id", "stock").Where(Gte("stock", 1)).OrderBy(`"id"`)
+
+	const workers, rounds = 16, 24
+	var committed, rolledBack atomic.Int64
+	errs := make(chan error, workers*rounds)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		w := w
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			labelled := ac.Label(fmt.Sprintf("worker-%d", w))
+			for i := 0; i < rounds; i++ {
+				var err error
+				switch i % 8 {
+				case 0:
+					_, err = Select[testBook](ctx, labelled, `SELECT * FROM "books" WHERE "stock" >= ?`, 0)
+				case 1:
+					_, err = Get[int](ctx, ac, `SELECT COUNT(*) FROM "books"`)
+				case 2:
+					_, err = ac.ExecExpecting(ctx, insertBook, 1, fmt.Sprintf("book %d-%d", w, i))
+				case 3:
+					err = ac.WithTransaction(ctx, func(tx *TxAssister) error {
+						tx.AfterCommit(func() { committed.Add(1) })
+						return tx.UpdateSingleRow(insertBook, fmt.Sprintf("tx book %d-%d", w, i))
+					})
+				case 4:
+					err = ac.WithTransaction(ctx, func(tx *TxAssister) error {
+						tx.AfterRollback(func(error) { rolledBack.Add(1) })
+						err := tx.UpdateSingleRow(insertBook, "rolled back")
+						if err != nil {
+							return err
+						}
+						return errRollBack
+					})
+					if err == errRollBack {
+						err = nil
+					}
+				case 5:
+					_, err = CachedSelect[testBook](ctx, ac, `SELECT * FROM "books" WHERE "id" = ?`, 1+i%2)
+					if i%3 == 0 {
+						cache.Invalidate(`SELECT * FROM "books" WHERE "id" = ?`)
+					}
+				case 6:
+					registry.Register(fmt.Sprintf("stock %d", w), `SELECT "stock" FROM "books" WHERE "id" = ?`)
+					_ = registry.Names()
+					_, err = ac.TableMeta(ctx, "books")
+				case 7:
+					_, err = Fetch[testBook](ctx, byStock)
+				}
+				if err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+	perCase := int64(workers * rounds / 8)
+	if committed.Load() != perCase || rolledBack.Load() != perCase {
+		t.Errorf("expected %d commits & rollbacks, got %d & %d", perCase, committed.Load(), rolledBack.Load())
+	}
+	if count := countBooks(t, ac); count != 2+2*int(perCase) {
+		t.Errorf("expected %d books, got %d", 2+2*perCase, count)
+	}
+	if len(registry.Names()) != workers {
+		t.Errorf("expected %d registered queries, got %d", workers, len(registry.Names()))
+	}
+}
+
+var errRollBack = fmt.Errorf("roll back")
+
+// TestBoundAssistersConcurrentUse checks the documented contract of the TxAssister & ConnAssister: goroutines started by the
+// function may share them, their statements taking turns on the connection, as long as they finish before it returns
+func TestBoundAssistersConcurrentUse(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openTestDB(t, bookTable), WithDialect(SQLite))
+	const workers = 8
+
+	// runAll runs fn from workers goroutines, waiting for them all
+	runAll := func(fn func(w int) error) error {
+		errs := make(chan error, workers)
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			w := w
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- fn(w)
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	err := ac.WithTransaction(ctx, func(tx *TxAssister) error {
+		return runAll(func(w int) error {
+			err := tx.UpdateSingleRow(insertBook, fmt.Sprintf("tx book %d", w))
+			if err != nil {
+				return err
+			}
+			_, err = Select[testBook](ctx, tx.Assister, `SELECT * FROM "books"`)
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ac.WithConn(ctx, func(ca *ConnAssister) error {
+		err := ca.CreateTempTableAs(ctx, "copies", `SELECT * FROM "books"`)
+		if err != nil {
+			return err
+		}
+		return runAll(func(w int) error {
+			_, err := Get[int](ctx, ca.Assister, `SELECT COUNT(*) FROM "copies"`)
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count := countBooks(t, ac); count != workers {
+		t.Errorf("expected %d books, got %d", workers, count)
+	}
+}
@@ -0,0 +1,240 @@
+package sqlAssister
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// Defaults NewFromConfig applies to the Config fields left zero
+const (
+	DefaultMaxOpenConns    = 10
+	DefaultConnMaxLifetime = 30 * time.Minute
+	DefaultPingTimeout     = 5 * time.Second
+)
+
+// Config describes the database an Assister is opened for by NewFromConfig, as a plain struct filled in code or from the environment
+// with LoadConfigFromEnv, each field being read from the variable named in its env tag. Fields left zero take their default
+type Config struct {
+	// Driver is the name the database/sql driver registers, e.g. postgres, pgx, mysql or sqlite3
+	Driver string `env:"DRIVER"`
+	DSN    string `env:"DSN"`
+	// MaxOpenConns caps the open connections, DefaultMaxOpenConns by default
+	MaxOpenConns int `env:"MAX_OPEN_CONNS"`
+	// MaxIdleConns caps the idle connections kept in the pool, MaxOpenConns by default
+	MaxIdleConns int `env:"MAX_IDLE_CONNS"`
+	// ConnMaxLifetime closes connections once they are this old, DefaultConnMaxLifetime by default
+	ConnMaxLifetime time.Duration `env:"CONN_MAX_LIFETIME"`
+	// DefaultTimeout bounds every statement, see WithDefaultQueryTimeout. Statements are unbounded by default
+	DefaultTimeout time.Duration `env:"DEFAULT_TIMEOUT"`
+	// SlowQueryThreshold logs the statements taking as long or longer, see WithSlowQueryThreshold. Off by default
+	SlowQueryThreshold time.Duration `env:"SLOW_QUERY_THRESHOLD"`
+	// Dialect is postgres, mysql or sqlite, inferred from Driver by default
+	Dialect string `env:"DIALECT"`
+	// ReadOnly makes every connection refuse writes: default_transaction_read_only on Postgres, a read only session on MySQL &
+	// query_only on SQLite
+	ReadOnly bool `env:"READ_ONLY"`
+	// PingTimeout bounds the ping checking the database can be reached, DefaultPingTimeout by default
+	PingTimeout time.Duration `env:"PING_TIMEOUT"`
+}
+
+// LoadConfigFromEnv reads a Config from the environment variables named by its fields' env tags, prefixed with prefix & an underscore,
+// e.g. ORDERS_DB_DSN & ORDERS_DB_MAX_OPEN_CONNS for the prefix ORDERS_DB. Unset variables leave their field zero. Durations are
+// written as time.ParseDuration reads them, e.g. 30s or 1h30m, & booleans as strconv.ParseBool reads them. A malformed value fails
+// naming its variable, the Config isn't validated until NewFromConfig
+/*
+
+Example:
+
+	cfg, err := sqlAssister.LoadConfigFromEnv("ORDERS_DB")
+	if err != nil {
+		log.Fatal(err)
+	}
+	Assister, err := sqlAssister.NewFromConfig(cfg, sqlAssister.WithLogOnErrorOnly())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer Assister.Close()
+*/
+func LoadConfigFromEnv(prefix string) (Config, error) {
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
+	}
+
+	var cfg Config
+	value := reflect.ValueOf(&cfg).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		name := prefix + value.Type().Field(i).Tag.Get("env")
+		text, ok := os.LookupEnv(name)
+		if !ok || strings.TrimSpace(text) == "" {
+			continue
+		}
+		text = strings.TrimSpace(text)
+
+		field := value.Field(i)
+		switch field.Interface().(type) {
+		case string:
+			field.SetString(text)
+		case int:
+			n, err := strconv.Atoi(text)
+			if err != nil {
+				return Config{}, fmt.Errorf("%s=%q is not a whole number", name, text)
+			}
+			field.SetInt(int64(n))
+		case time.Duration:
+			d, err := time.ParseDuration(text)
+			if err != nil {
+				return Config{}, fmt.Errorf("%s=%q is not a duration, write it with a unit such as 30s or 1h30m", name, text)
+			}
+			field.SetInt(int64(d))
+		case bool:
+			b, err := strconv.ParseBool(text)
+			if err != nil {
+				return Config{}, fmt.Errorf("%s=%q is not a boolean, use true or false", name, text)
+			}
+			field.SetBool(b)
+		}
+	}
+
+	return cfg, nil
+}
+
+// Validate reports every problem of the Config at once, each naming the field to fix
+func (cfg Config) Validate() error {
+	var problems []string
+	if cfg.Driver == "" {
+		problems = append(problems, "Driver is empty, set it to the name of a registered database/sql driver such as postgres or mysql")
+	} else if _, err := lookupDriver(cfg.Driver); err != nil {
+		problems = append(problems, fmt.Sprintf("Driver %q isn't registered, import its package e.g. _ \"github.com/lib/pq\"", cfg.Driver))
+	}
+	if strings.TrimSpace(cfg.DSN) == "" {
+		problems = append(problems, "DSN is empty, set it to the connection string of the database")
+	}
+	if cfg.MaxOpenConns < 0 {
+		problems = append(problems, fmt.Sprintf("MaxOpenConns is %d, it must be positive or 0 for the default", cfg.MaxOpenConns))
+	}
+	if cfg.MaxIdleConns < 0 {
+		problems = append(problems, fmt.Sprintf("MaxIdleConns is %d, it must be positive or 0 for the default", cfg.MaxIdleConns))
+	}
+	if cfg.MaxOpenConns > 0 && cfg.MaxIdleConns > cfg.MaxOpenConns {
+		problems = append(problems, fmt.Sprintf("MaxIdleConns (%d) exceeds MaxOpenConns (%d), lower it or raise MaxOpenConns",
+			cfg.MaxIdleConns, cfg.MaxOpenConns))
+	}
+	for _, d := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"ConnMaxLifetime", cfg.ConnMaxLifetime},
+		{"DefaultTimeout", cfg.DefaultTimeout},
+		{"SlowQueryThreshold", cfg.SlowQueryThreshold},
+		{"PingTimeout", cfg.PingTimeout},
+	} {
+		if d.value < 0 {
+			problems = append(problems, fmt.Sprintf("%s is %s, it must be positive or 0 for the default", d.name, d.value))
+		}
+	}
+	if _, err := cfg.dialect(); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid sqlAssister config: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// dialect returns the Config's dialect, inferring it from the driver when it isn't set
+func (cfg Config) dialect() (Dialect, error) {
+	if cfg.Dialect != "" {
+		dialect, err := utils.ParseDialect(cfg.Dialect)
+		if err != nil {
+			return dialect, fmt.Errorf("Dialect %q is unknown, set it to postgres, mysql or sqlite", cfg.Dialect)
+		}
+		return dialect, nil
+	}
+
+	dialect, err := utils.ParseDialect(cfg.Driver)
+	if err != nil && cfg.Driver != "" {
+		return dialect, fmt.Errorf("Dialect can't be inferred from Driver %q, set it to postgres, mysql or sqlite", cfg.Driver)
+	}
+	return dialect, nil
+}
+
+// readOnlySetup is the statement making a connection refuse writes on the dialect
+func readOnlySetup(dialect Dialect) string {
+	switch dialect {
+	case MySQL:
+		return "SET SESSION TRANSACTION READ ONLY"
+	case SQLite:
+		return "PRAGMA query_only = ON"
+	default:
+		return "SET default_transaction_read_only = on"
+	}
+}
+
+// NewFromConfig validates cfg, opens the database it describes as NewFromDSN does with the pool sized by cfg, & pings it, failing when
+// it can't be reached within cfg.PingTimeout. opts are applied after the options cfg translates to, e.g. to set a logger.
+// The Assister owns the database, Close closes it
+/*
+
+Example:
+
+	Assister, err := sqlAssister.NewFromConfig(sqlAssister.Config{
+		Driver:             "postgres",
+		DSN:                os.Getenv("DATABASE_URL"),
+		DefaultTimeout:     10 * time.Second,
+		SlowQueryThreshold: time.Second,
+	}, sqlAssister.WithLogger(logger))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer Assister.Close()
+*/
+func NewFromConfig(cfg Config, opts ...Option) (*Assister, error) {
+	err := cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MaxOpenConns == 0 {
+		cfg.MaxOpenConns = DefaultMaxOpenConns
+	}
+	if cfg.MaxIdleConns == 0 {
+		cfg.MaxIdleConns = cfg.MaxOpenConns
+	}
+	if cfg.ConnMaxLifetime == 0 {
+		cfg.ConnMaxLifetime = DefaultConnMaxLifetime
+	}
+	if cfg.PingTimeout == 0 {
+		cfg.PingTimeout = DefaultPingTimeout
+	}
+
+	dialect, _ := cfg.dialect()
+	configured := []Option{WithDialect(dialect), WithDefaultQueryTimeout(cfg.DefaultTimeout), WithSlowQueryThreshold(cfg.SlowQueryThreshold)}
+	if cfg.ReadOnly {
+		configured = append(configured, WithConnectionSetup(readOnlySetup(dialect)))
+	}
+
+	ac, err := NewFromDSN(cfg.Driver, cfg.DSN, append(configured, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	ac.DB.SetMaxOpenConns(cfg.MaxOpenConns)
+	ac.DB.SetMaxIdleConns(cfg.MaxIdleConns)
+	ac.DB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.PingTimeout)
+	defer cancel()
+	err = ac.DB.PingContext(ctx)
+	if err != nil {
+		ac.DB.Close()
+		return nil, fmt.Errorf("ping %s database: %w", dialect, err)
+	}
+
+	return ac, nil
+}
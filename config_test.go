@@ -0,0 +1,181 @@
+package sqlAssister
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigFromEnv(t *testing.T) {
+	t.Setenv("ORDERS_DB_DRIVER", "sqlite3")
+	t.Setenv("ORDERS_DB_DSN", " file:orders.db ")
+	t.Setenv("ORDERS_DB_MAX_OPEN_CONNS", "20")
+	t.Setenv("ORDERS_DB_MAX_IDLE_CONNS", "")
+	t.Setenv("ORDERS_DB_CONN_MAX_LIFETIME", "1h30m")
+	t.Setenv("ORDERS_DB_DEFAULT_TIMEOUT", " 2.5s ")
+	t.Setenv("ORDERS_DB_SLOW_QUERY_THRESHOLD", "750ms")
+	t.Setenv("ORDERS_DB_PING_TIMEOUT", "0")
+	t.Setenv("ORDERS_DB_READ_ONLY", "true")
+	// Another prefix's variables are ignored
+	t.Setenv("BILLING_DB_DSN", "file:billing.db")
+
+	expected := Config{
+		Driver:             "sqlite3",
+		DSN:                "file:orders.db",
+		MaxOpenConns:       20,
+		ConnMaxLifetime:    90 * time.Minute,
+		DefaultTimeout:     2500 * time.Millisecond,
+		SlowQueryThreshold: 750 * time.Millisecond,
+		ReadOnly:           true,
+	}
+	for _, prefix := range []string{"ORDERS_DB", "ORDERS_DB_"} {
+		cfg, err := LoadConfigFromEnv(prefix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg != expected {
+			t.Errorf("prefix %s: expected %+v, got %+v", prefix, expected, cfg)
+		}
+	}
+
+	cfg, err := LoadConfigFromEnv("MISSING")
+	if err != nil || cfg != (Config{}) {
+		t.Errorf("expected unset variables to leave the Config zero, got %+v & %v", cfg, err)
+	}
+}
+
+func TestLoadConfigFromEnvMalformed(t *testing.T) {
+	tests := []struct {
+		variable string
+		value    string
+		want     string
+	}{
+		{"APP_CONN_MAX_LIFETIME", "30", "not a duration, write it with a unit"},
+		{"APP_DEFAULT_TIMEOUT", "ten seconds", "not a duration"},
+		{"APP_SLOW_QUERY_THRESHOLD", "1d", "not a duration"},
+		{"APP_PING_TIMEOUT", "5 s", "not a duration"},
+		{"APP_MAX_OPEN_CONNS", "ten", "not a whole number"},
+		{"APP_MAX_IDLE_CONNS", "2.5", "not a whole number"},
+		{"APP_READ_ONLY", "yes", "not a boolean"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.variable, func(t *testing.T) {
+			t.Setenv(test.variable, test.value)
+			_, err := LoadConfigFromEnv("APP")
+			if err == nil || !strings.Contains(err.Error(), test.variable+"=") || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("expected an error naming %s & mentioning %q, got %v", test.variable, test.want, err)
+			}
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := Config{Driver: "sqlite3", DSN: "file:test.db"}
+	tests := []struct {
+		name   string
+		change func(cfg *Config)
+		want   []string
+	}{
+		{"valid", func(cfg *Config) {}, nil},
+		{"dialect inferred from pgx", func(cfg *Config) { cfg.Driver, cfg.Dialect = "sqlite3", "pgx" }, nil},
+		{"no driver", func(cfg *Config) { cfg.Driver = "" }, []string{"Driver is empty"}},
+		{"unregistered driver", func(cfg *Config) { cfg.Driver = "oracle" },
+			[]string{`Driver "oracle" isn't registered, import its package`, `Dialect can't be inferred from Driver "oracle"`}},
+		{"unknown dialect", func(cfg *Config) { cfg.Dialect = "oracle" }, []string{`Dialect "oracle" is unknown`}},
+		{"blank DSN", func(cfg *Config) { cfg.DSN = "  " }, []string{"DSN is empty"}},
+		{"negative pool", func(cfg *Config) { cfg.MaxOpenConns, cfg.MaxIdleConns = -1, -2 },
+			[]string{"MaxOpenConns is -1", "MaxIdleConns is -2"}},
+		{"more idle than open", func(cfg *Config) { cfg.MaxOpenConns, cfg.MaxIdleConns = 5, 10 },
+			[]string{"MaxIdleConns (10) exceeds MaxOpenConns (5)"}},
+		{"negative durations", func(cfg *Config) {
+			cfg.ConnMaxLifetime, cfg.DefaultTimeout, cfg.SlowQueryThreshold, cfg.PingTimeout = -time.Second, -time.Minute, -time.Millisecond, -time.Hour
+		}, []string{"ConnMaxLifetime is -1s", "DefaultTimeout is -1m0s", "SlowQueryThreshold is -1ms", "PingTimeout is -1h0m0s"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := valid
+			test.change(&cfg)
+			err := cfg.Validate()
+			if len(test.want) == 0 {
+				if err != nil {
+					t.Errorf("expected the config valid, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected the config refused")
+			}
+			// Every problem is reported at once
+			for _, want := range test.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected %q in %v", want, err)
+				}
+			}
+			if problems := strings.Count(err.Error(), ";") + 1; problems != len(test.want) {
+				t.Errorf("expected %d problems, got %d in %v", len(test.want), problems, err)
+			}
+		})
+	}
+}
+
+func TestNewFromConfig(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "config.db")
+	ac, err := NewFromConfig(Config{Driver: "sqlite3", DSN: "file:" + path, DefaultTimeout: time.Minute, SlowQueryThreshold: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ac.dialect != SQLite || ac.queryTimeout != time.Minute || ac.slowQueryThreshold != time.Second {
+		t.Errorf("expected the dialect, timeout & threshold configured, got %s, %s & %s", ac.dialect, ac.queryTimeout, ac.slowQueryThreshold)
+	}
+	if open := ac.DB.Stats().MaxOpenConnections; open != DefaultMaxOpenConns {
+		t.Errorf("expected the default of %d open connections, got %d", DefaultMaxOpenConns, open)
+	}
+	_, err = ac.ExecExpecting(ctx, `CREATE TABLE "books" ("id" INTEGER PRIMARY KEY)`, AnyRowsAffected)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The Assister owns the database
+	err = ac.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.DB.Ping(); err == nil || !strings.Contains(err.Error(), "closed") {
+		t.Errorf("expected Close to close the database, got %v", err)
+	}
+
+	readOnly, err := NewFromConfig(Config{Driver: "sqlite3", DSN: "file:" + path, ReadOnly: true, MaxOpenConns: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer readOnly.Close()
+	if open := readOnly.DB.Stats().MaxOpenConnections; open != 2 {
+		t.Errorf("expected 2 open connections, got %d", open)
+	}
+	_, err = readOnly.ExecExpecting(ctx, `INSERT INTO "books" ("id") VALUES (1)`, 1)
+	if err == nil || !strings.Contains(err.Error(), "readonly") {
+		t.Errorf("expected the read only connection to refuse writes, got %v", err)
+	}
+	count, err := Get[int](ctx, readOnly, `SELECT COUNT(*) FROM "books"`)
+	if err != nil || count != 0 {
+		t.Errorf("expected reads allowed, got %d & %v", count, err)
+	}
+}
+
+func TestNewFromConfigFailures(t *testing.T) {
+	_, err := NewFromConfig(Config{Driver: "sqlite3"})
+	if err == nil || !strings.Contains(err.Error(), "invalid sqlAssister config: DSN is empty") {
+		t.Errorf("expected the config refused before opening, got %v", err)
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing", "config.db")
+	_, err = NewFromConfig(Config{Driver: "sqlite3", DSN: "file:" + missing + "?mode=ro", PingTimeout: time.Second})
+	if err == nil || !strings.Contains(err.Error(), "ping sqlite database") {
+		t.Errorf("expected the ping to fail, got %v", err)
+	}
+}
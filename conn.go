@@ -0,0 +1,166 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// ConnAssister exposes the Assister methods pinned to a single connection from the pool.
+// Session state such as temp tables & SET values is shared by every statement executed through it.
+// It is safe for concurrent use, statements take turns on the connection, but goroutines using it must finish before WithConn's function returns
+type ConnAssister struct {
+	*Assister
+	Conn *sql.Conn
+}
+
+// WithConnReset sets the statements WithConn runs on a connection before returning it to the pool so session state doesn't leak
+// into the rest of the pool. Defaults to RESET ALL & DISCARD TEMP on Postgres & to nothing on other dialects
+func WithConnReset(statements ...string) Option {
+	return func(ac *Assister) {
+		ac.connReset = statements
+		ac.connResetSet = true
+	}
+}
+
+func (ac Assister) connResetStatements() []string {
+	if ac.connResetSet {
+		return ac.connReset
+	}
+	if ac.dialect == Postgres {
+		return []string{"RESET ALL", "DISCARD TEMP"}
+	}
+
+	return nil
+}
+
+// WithConn runs fn with every statement pinned to the same connection, for workflows such as creating, loading & joining a temp table.
+// The connection's session is reset before it is returned to the pool, see WithConnReset.
+// If the reset fails or fn panics the connection is closed rather than returned to the pool.
+// When the Assister wasn't created by NewFromDSN the connection setup, see WithConnectionSetup, runs on the connection before fn
+/*
+
+Example:
+
+	err := Assister.WithConn(ctx, func(conn *sqlAssister.ConnAssister) error {
+		err := conn.CreateTempTableAs(ctx, "recent_orders", `SELECT * FROM "orders" WHERE "created_at" > $1`, since)
+		if err != nil {
+			return err
+		}
+
+		totals, err = sqlAssister.Select[Total](ctx, conn.Assister, totalsQuery)
+		return err
+	})
+*/
+func (ac Assister) WithConn(ctx context.Context, fn func(ca *ConnAssister) error) (err error) {
+	if ac.tx != nil {
+		return errors.New("WithConn called on an Assister bound to a transaction")
+	}
+	if ac.transactionPooler {
+		return unsupportedWithPooler("pinning a connection", "run the statements sharing session state in a transaction")
+	}
+
+	conn, err := ac.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Without NewFromDSN the pool doesn't run the connection setup, so the pinned connection gets it here
+	if !ac.setupOnConnect {
+		err = ac.runConnSetup(ctx, conn)
+		if err != nil {
+			discardConn(conn)
+			return err
+		}
+	}
+
+	connAssister := &ConnAssister{Conn: conn}
+	bound := ac
+	bound.q = conn
+	connAssister.Assister = &bound
+
+	defer func() {
+		if p := recover(); p != nil {
+			discardConn(conn)
+			panic(p)
+		}
+	}()
+
+	err = fn(connAssister)
+
+	// The reset must run even when ctx is done, it is what keeps the session state out of the pool
+	resetCtx := markContext(context.Background())
+	resetStatements := ac.connResetStatements()
+	for _, statement := range resetStatements {
+		_, resetErr := conn.ExecContext(resetCtx, statement)
+		if resetErr != nil {
+			discardConn(conn)
+			if err != nil {
+				return err
+			}
+			return resetErr
+		}
+	}
+
+	// The reset also undid the setup the pool ran when it opened the connection
+	if ac.setupOnConnect && len(resetStatements) > 0 {
+		setupErr := ac.runConnSetup(resetCtx, conn)
+		if setupErr != nil {
+			discardConn(conn)
+			if err != nil {
+				return err
+			}
+			return setupErr
+		}
+	}
+
+	closeErr := conn.Close()
+	if err != nil {
+		return err
+	}
+
+	return closeErr
+}
+
+// discardConn closes conn's underlying connection instead of returning it to the pool
+func discardConn(conn *sql.Conn) {
+	_ = conn.Raw(func(any) error {
+		return driver.ErrBadConn
+	})
+	_ = conn.Close()
+}
+
+// CreateTempTableAs creates a temp table, visible only to this connection, holding the results of query
+func (ca *ConnAssister) CreateTempTableAs(ctx context.Context, name string, query string, args ...any) error {
+	err := utils.QueryChecker(query, ca.queryChecks()...)
+	if err != nil {
+		return err
+	}
+
+	_, err = ca.conn().ExecContext(ctx, "CREATE TEMPORARY TABLE "+ca.quote(name)+" AS "+query, args...)
+	return err
+}
+
+// DropTempTable drops a temp table created on this connection, doing nothing when it doesn't exist.
+// On Postgres & SQLite the name is resolved in the temp schema only so a permanent table can never be dropped by mistake
+func (ca *ConnAssister) DropTempTable(ctx context.Context, name string) error {
+	var statement string
+	switch ca.dialect {
+	case MySQL:
+		statement = "DROP TEMPORARY TABLE IF EXISTS " + ca.quote(name)
+	case SQLite:
+		statement = "DROP TABLE IF EXISTS temp." + ca.quote(name)
+	default:
+		statement = "DROP TABLE IF EXISTS pg_temp." + ca.quote(name)
+	}
+
+	_, err := ca.conn().ExecContext(ctx, statement)
+	return err
+}
+
+func (ca *ConnAssister) quote(name string) string {
+	return ca.QuoteIdentifier(name)
+}
@@ -0,0 +1,254 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WithConnectionSetup runs statements, such as SET search_path, on every connection before it is used.
+// With NewFromDSN they run on every new connection the pool opens, a connection whose setup fails is closed rather than pooled.
+// With New the pool's connections can't be intercepted so they only run on the connections pinned by WithConn
+func WithConnectionSetup(statements ...string) Option {
+	return func(ac *Assister) {
+		for _, statement := range statements {
+			statement := statement
+			ac.connSetup = append(ac.connSetup, func(ctx context.Context, conn *sql.Conn) error {
+				_, err := conn.ExecContext(ctx, statement)
+				return err
+			})
+		}
+	}
+}
+
+// WithConnectionSetupFunc runs fn on every connection before it is used, for setup that isn't a fixed statement.
+// It runs after the statements of WithConnectionSetup, on the same connections
+func WithConnectionSetupFunc(fn func(ctx context.Context, conn *sql.Conn) error) Option {
+	return func(ac *Assister) {
+		ac.connSetup = append(ac.connSetup, fn)
+	}
+}
+
+// WithApplicationName sets the application_name NewFromDSN reports to Postgres for every connection, shown in pg_stat_activity.
+// Defaults to the name of the running binary
+func WithApplicationName(name string) Option {
+	return func(ac *Assister) {
+		ac.applicationName = name
+	}
+}
+
+// NewFromDSN opens a database with the driver registered as driverName & returns an Assister for it, running the connection setup
+// of WithConnectionSetup & WithConnectionSetupFunc on every connection the pool opens. On Postgres the application_name is set first, see WithApplicationName.
+// The driver is instrumented as by WrapDriver so statements executed on the Assister's DB directly, e.g. by a library, are logged as well.
+// The Assister owns the DB, Close closes it
+/*
+
+Example:
+
+	Assister, err := sqlAssister.NewFromDSN("postgres", dsn,
+		sqlAssister.WithConnectionSetup(`SET search_path TO "library", public`, `SET statement_timeout = '30s'`),
+		sqlAssister.WithApplicationName("book-api"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+*/
+func NewFromDSN(driverName string, dsn string, opts ...Option) (*Assister, error) {
+	d, err := lookupDriver(driverName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Instrumented so statements executed on the DB directly are logged like the Assister's, see WrapDriver
+	ac := New(nil, opts...)
+	if ac.transactionPooler {
+		if len(ac.connSetup) > 0 {
+			return nil, unsupportedWithPooler("connection setup", "use SET LOCAL inside transactions")
+		}
+		dsn = poolerDSN(driverName, dsn)
+	}
+	connector, err := openConnector(newInstrumentedDriver(d, ac), dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	setup := ac.connSetup
+	// The application_name is a session setting, behind a transaction pooler set it in the pooler's own configuration
+	if ac.dialect == Postgres && !ac.transactionPooler {
+		name := ac.applicationName
+		if name == "" {
+			name = filepath.Base(os.Args[0])
+		}
+		setApplicationName := func(ctx context.Context, conn *sql.Conn) error {
+			_, err := conn.ExecContext(ctx, "SELECT set_config('application_name', $1, false)", name)
+			return err
+		}
+		setup = append([]func(context.Context, *sql.Conn) error{setApplicationName}, setup...)
+	}
+	if len(setup) > 0 {
+		connector = setupConnector{Connector: connector, setup: setup}
+	}
+	ac.connSetup = setup
+
+	ac.DB = sql.OpenDB(connector)
+	ac.setupOnConnect = true
+	ac.ownsDB = true
+	return ac, nil
+}
+
+// runConnSetup runs the connection setup on conn
+func (ac Assister) runConnSetup(ctx context.Context, conn *sql.Conn) error {
+	ctx = markContext(ctx)
+	for _, setup := range ac.connSetup {
+		err := setup(ctx, conn)
+		if err != nil {
+			return fmt.Errorf("connection setup: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// openConnector returns d's connector for dsn, or one calling d.Open when d doesn't provide its own
+func openConnector(d driver.Driver, dsn string) (driver.Connector, error) {
+	if driverContext, ok := d.(driver.DriverContext); ok {
+		return driverContext.OpenConnector(dsn)
+	}
+
+	return dsnConnector{driver: d, dsn: dsn}, nil
+}
+
+// dsnConnector opens connections with a driver that doesn't provide its own connector
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// setupConnector runs the connection setup on every connection the Connector opens
+type setupConnector struct {
+	driver.Connector
+	setup []func(ctx context.Context, conn *sql.Conn) error
+}
+
+func (c setupConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.runSetup(ctx, conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("connection setup: %w", err)
+	}
+
+	return conn, nil
+}
+
+// runSetup hands the setup a *sql.Conn for the new driver connection, through a throwaway pool holding only that connection
+func (c setupConnector) runSetup(ctx context.Context, conn driver.Conn) error {
+	ctx = markContext(ctx)
+	db := sql.OpenDB(&singleConnector{conn: keepOpenConn{conn}, driver: c.Driver()})
+	defer db.Close()
+
+	sqlConn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer sqlConn.Close()
+
+	for _, setup := range c.setup {
+		err = setup(ctx, sqlConn)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// singleConnector hands out its connection once
+type singleConnector struct {
+	conn   driver.Conn
+	driver driver.Driver
+}
+
+func (c *singleConnector) Connect(context.Context) (driver.Conn, error) {
+	if c.conn == nil {
+		return nil, errors.New("connection setup opened a second connection")
+	}
+	conn := c.conn
+	c.conn = nil
+
+	return conn, nil
+}
+
+func (c *singleConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// keepOpenConn delegates to a driver connection except for Close, so the throwaway pool running the setup doesn't close it.
+// Optional interfaces the connection doesn't implement report driver.ErrSkip so database/sql falls back as it would without them
+type keepOpenConn struct {
+	driver.Conn
+}
+
+func (c keepOpenConn) Close() error {
+	return nil
+}
+
+func (c keepOpenConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+
+	return c.Conn.Prepare(query)
+}
+
+func (c keepOpenConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if execer, ok := c.Conn.(driver.ExecerContext); ok {
+		return execer.ExecContext(ctx, query, args)
+	}
+
+	return nil, driver.ErrSkip
+}
+
+func (c keepOpenConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if queryer, ok := c.Conn.(driver.QueryerContext); ok {
+		return queryer.QueryContext(ctx, query, args)
+	}
+
+	return nil, driver.ErrSkip
+}
+
+func (c keepOpenConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) || opts.ReadOnly {
+		return nil, errors.New("driver doesn't support transaction options")
+	}
+
+	// The same fallback database/sql takes for drivers without BeginTx
+	return c.Conn.Begin()
+}
+
+func (c keepOpenConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+
+	return driver.ErrSkip
+}
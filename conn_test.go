@@ -0,0 +1,117 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+// sessionState reads the session state WithConn's function leaves behind from a fresh connection of db
+func sessionState(t *testing.T, db *sql.DB) (foreignKeys int, tempTables int) {
+	t.Helper()
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	err = conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM temp.sqlite_master WHERE "name" = 'scratch'`).Scan(&tempTables)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return foreignKeys, tempTables
+}
+
+func TestWithConnSessionDoesNotLeak(t *testing.T) {
+	reset := WithConnReset("PRAGMA foreign_keys = OFF", "DROP TABLE IF EXISTS temp.scratch")
+	tests := []struct {
+		name string
+		opts []Option
+		fn   func(ca *ConnAssister) error
+		err  bool
+	}{
+		{"reset", []Option{reset}, func(ca *ConnAssister) error { return nil }, false},
+		{"reset after failure", []Option{reset}, func(ca *ConnAssister) error { return context.Canceled }, true},
+		{"failing reset discards the connection", []Option{WithConnReset("NOT A STATEMENT")}, func(ca *ConnAssister) error { return nil }, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := openTestDB(t)
+			// A single connection, so the fresh one is the connection WithConn returned to the pool unless it was discarded
+			db.SetMaxOpenConns(1)
+			ac := New(db, append([]Option{WithDialect(SQLite)}, test.opts...)...)
+
+			ctx := context.Background()
+			err := ac.WithConn(ctx, func(ca *ConnAssister) error {
+				_, err := ca.conn().ExecContext(ctx, "PRAGMA foreign_keys = ON")
+				if err != nil {
+					return err
+				}
+				_, err = ca.conn().ExecContext(ctx, `CREATE TEMP TABLE "scratch" ("id" INTEGER)`)
+				if err != nil {
+					return err
+				}
+
+				foreignKeys, tempTables := 0, 0
+				err = ca.conn().QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys)
+				if err != nil {
+					return err
+				}
+				err = ca.conn().QueryRowContext(ctx, `SELECT COUNT(*) FROM temp.sqlite_master WHERE "name" = 'scratch'`).Scan(&tempTables)
+				if err != nil {
+					return err
+				}
+				if foreignKeys != 1 || tempTables != 1 {
+					t.Errorf("expected the session state to be set inside WithConn, got foreign_keys=%d & %d temp tables", foreignKeys, tempTables)
+				}
+
+				return test.fn(ca)
+			})
+			if (err != nil) != test.err {
+				t.Fatalf("expected an error %t, got %v", test.err, err)
+			}
+
+			foreignKeys, tempTables := sessionState(t, db)
+			if foreignKeys != 0 || tempTables != 0 {
+				t.Errorf("session state leaked into the pool: foreign_keys=%d & %d temp tables", foreignKeys, tempTables)
+			}
+		})
+	}
+}
+
+func TestWithConnPanicDiscardsTheConnection(t *testing.T) {
+	db := openTestDB(t)
+	db.SetMaxOpenConns(1)
+	// Without any reset only discarding the connection keeps its session state out of the pool
+	ac := New(db, WithDialect(SQLite), WithConnReset())
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected WithConn to re-raise the panic")
+			}
+		}()
+		_ = ac.WithConn(context.Background(), func(ca *ConnAssister) error {
+			_, err := ca.conn().ExecContext(context.Background(), `CREATE TEMP TABLE "scratch" ("id" INTEGER)`)
+			if err != nil {
+				t.Fatal(err)
+			}
+			panic("boom")
+		})
+	}()
+
+	_, tempTables := sessionState(t, db)
+	if tempTables != 0 {
+		t.Errorf("expected the connection to be discarded, its temp table is still there")
+	}
+	if inUse := db.Stats().InUse; inUse != 0 {
+		t.Errorf("expected no connection in use, %d leaked", inUse)
+	}
+}
@@ -0,0 +1,112 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// Cursor is a Postgres server side cursor declared by TxAssister.Cursor. The result set stays on the server & is read
+// a batch at a time with Fetch, so iterating a massive result set only holds one batch in memory.
+// A cursor belongs to its transaction & is closed by the transaction ending if Close isn't called first.
+// A Cursor is NOT meant to be shared between goroutines: concurrent Fetches don't fail but split the records between them unpredictably
+type Cursor struct {
+	tx   *TxAssister
+	name string
+}
+
+// Cursor declares a server side cursor named name over query within the transaction, `DECLARE name NO SCROLL CURSOR FOR query`.
+// Records are then read with Fetch or FetchCursor. Only Postgres supports cursors outside of stored procedures
+/*
+
+Example:
+
+	err := Assister.WithTransaction(ctx, func(tx *sqlAssister.TxAssister) error {
+		cursor, err := tx.Cursor(ctx, "all_books", `SELECT "id", "name" FROM "books" WHERE "year" > $1`, 1900)
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+
+		for {
+			books, err := sqlAssister.FetchCursor[Book](ctx, cursor, 1000)
+			if err != nil {
+				return err
+			}
+			if len(books) == 0 {
+				return nil
+			}
+			process(books)
+		}
+	})
+*/
+func (tx *TxAssister) Cursor(ctx context.Context, name string, query string, args ...any) (*Cursor, error) {
+	if tx.dialect != Postgres {
+		return nil, unsupportedDialectf("cursors are not supported on %s", tx.dialect)
+	}
+
+	err := utils.QueryChecker(query, tx.queryChecks()...)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor := &Cursor{tx: tx, name: tx.QuoteIdentifier(name)}
+	_, err = tx.conn().ExecContext(ctx, "DECLARE "+cursor.name+" NO SCROLL CURSOR FOR "+query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return cursor, nil
+}
+
+// Fetch reads the cursor's next n records, `FETCH FORWARD n FROM name`. Fewer than n records are returned
+// once the cursor nears the end of its result set & none once it has reached it
+/*
+
+Example:
+
+	rows, err := cursor.Fetch(ctx, 1000)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		...
+	}
+*/
+func (c *Cursor) Fetch(ctx context.Context, n int) (*sql.Rows, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("cursor fetch size must be positive, got %d", n)
+	}
+
+	return c.tx.conn().QueryContext(ctx, fmt.Sprintf("FETCH FORWARD %d FROM %s", n, c.name))
+}
+
+// FetchCursor reads the cursor's next n records as T, following the same rules as Select.
+// An empty result means the cursor has reached the end of its result set
+/*
+
+Example:
+
+	books, err := sqlAssister.FetchCursor[Book](ctx, cursor, 1000)
+	if err != nil {
+		return err
+	}
+*/
+func FetchCursor[T any](ctx context.Context, c *Cursor, n int) ([]T, error) {
+	rows, err := c.Fetch(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanAll[T](c.tx.Assister, rows)
+}
+
+// Close closes the cursor releasing its result set on the server, `CLOSE name`
+func (c *Cursor) Close(ctx context.Context) error {
+	_, err := c.tx.conn().ExecContext(ctx, "CLOSE "+c.name)
+	return err
+}
@@ -0,0 +1,36 @@
+package sqlAssister
+
+import (
+	"context"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// EnsureTable runs DDL creating a table (or index, schema, ...) treating "already exists" errors from Postgres, MySQL & SQLite as success.
+// Prefer CREATE ... IF NOT EXISTS where the dialect supports it, EnsureTable makes DDL re-runnable when it can't,
+// e.g. for test fixtures that are set up again without being torn down. The cached metadata of every table is invalidated, see TableMeta
+/*
+
+Example:
+
+	err := Assister.EnsureTable(ctx, `CREATE TABLE "books" ("id" TEXT PRIMARY KEY, "name" TEXT NOT NULL)`)
+	if err != nil {
+		return err
+	}
+*/
+func (ac Assister) EnsureTable(ctx context.Context, createSQL string) error {
+	err := utils.QueryChecker(createSQL, ac.queryChecks()...)
+	if err != nil {
+		return err
+	}
+
+	_, err = ac.conn().ExecContext(ctx, createSQL)
+	if utils.IsAlreadyExistsError(err) {
+		return nil
+	}
+	if err == nil {
+		ac.InvalidateMetadata()
+	}
+
+	return err
+}
@@ -0,0 +1,180 @@
+package sqlAssister
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact decimal number scanned from & bound to numeric & decimal columns without the rounding of a float64,
+// for amounts of money & other values that must add up to the cent. It holds a big.Rat & the number of decimal places it
+// was read with, so a numeric(12,2) read as 10.50 is written back as 10.50.
+// Scanned, a Decimal reads the text drivers return numeric & decimal columns as (Postgres' & MySQL's), integers & SQLite's
+// REALs, the latter through their shortest decimal form. NULL fails to scan, scan into a *Decimal to allow it.
+// Bound, it is written as its exact decimal text, which numeric & decimal columns parse without loss; values with no finite
+// decimal form, such as a third, fail to bind, Round them first. SQLite has no decimal type & converts the text to a REAL in
+// NUMERIC columns, keep exact amounts in TEXT columns there. The zero Decimal is 0
+/*
+
+Example:
+
+	type Entry struct {
+		ID     int64               `db:"id"`
+		Amount sqlAssister.Decimal `db:"amount"`
+	}
+
+	entries, err := sqlAssister.Select[Entry](ctx, Assister, `SELECT "id", "amount" FROM "ledger" WHERE "account_id" = $1`, accountID)
+	if err != nil {
+		return err
+	}
+
+	total := new(big.Rat)
+	for _, entry := range entries {
+		total.Add(total, entry.Amount.Rat())
+	}
+*/
+type Decimal struct {
+	rat *big.Rat
+	// places is the number of decimal places the Decimal is written with at least
+	places int
+}
+
+// NewDecimal returns a Decimal holding a copy of r
+func NewDecimal(r *big.Rat) Decimal {
+	return Decimal{rat: new(big.Rat).Set(r)}
+}
+
+// ParseDecimal reads a decimal number such as -1234.50 or 1.5e-3, keeping the decimal places it is written with
+func ParseDecimal(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || strings.Contains(s, "/") {
+		return Decimal{}, fmt.Errorf("%q is not a decimal number", s)
+	}
+
+	return Decimal{rat: r, places: writtenPlaces(s)}, nil
+}
+
+// writtenPlaces counts the decimal places of a number written as s, taking its exponent into account
+func writtenPlaces(s string) int {
+	mantissa, exponent := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		mantissa = s[:i]
+		exponent, _ = strconv.Atoi(s[i+1:])
+	}
+
+	places := 0
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		places = len(mantissa) - i - 1
+	}
+	if places -= exponent; places < 0 {
+		return 0
+	}
+
+	return places
+}
+
+// Rat returns the value of d as a big.Rat, a copy that can be modified freely
+func (d Decimal) Rat() *big.Rat {
+	if d.rat == nil {
+		return new(big.Rat)
+	}
+
+	return new(big.Rat).Set(d.rat)
+}
+
+// Round returns d rounded to places decimal places, halves rounded away from zero as Postgres' & MySQL's round do
+func (d Decimal) Round(places int) Decimal {
+	if places < 0 {
+		places = 0
+	}
+
+	r := d.Rat()
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)
+	numerator := new(big.Int).Mul(r.Num(), scale)
+	quotient, remainder := new(big.Int).QuoRem(numerator, r.Denom(), new(big.Int))
+	if remainder.Sign() != 0 && new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(r.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(remainder.Sign())))
+	}
+
+	return Decimal{rat: new(big.Rat).SetFrac(quotient, scale), places: places}
+}
+
+// String writes d as exact decimal text with at least the places it was read or rounded with, e.g. 10.50.
+// A value with no finite decimal form is written as a fraction, e.g. 1/3
+func (d Decimal) String() string {
+	r := d.Rat()
+	places, ok := decimalPlaces(r.Denom())
+	if !ok {
+		return r.RatString()
+	}
+	if places < d.places {
+		places = d.places
+	}
+
+	return r.FloatString(places)
+}
+
+// decimalPlaces returns the decimal places needed to write a fraction with the denominator exactly, false when it has none as the
+// denominator has prime factors other than 2 & 5
+func decimalPlaces(denominator *big.Int) (int, bool) {
+	q := new(big.Int).Set(denominator)
+	count := func(factor int64) int {
+		n := 0
+		f, quotient, remainder := big.NewInt(factor), new(big.Int), new(big.Int)
+		for {
+			quotient.QuoRem(q, f, remainder)
+			if remainder.Sign() != 0 {
+				return n
+			}
+			q.Set(quotient)
+			n++
+		}
+	}
+	twos, fives := count(2), count(5)
+	if q.Cmp(big.NewInt(1)) != 0 {
+		return 0, false
+	}
+	if twos > fives {
+		return twos, true
+	}
+
+	return fives, true
+}
+
+// Scan reads a decimal number from text, an integer or a float
+func (d *Decimal) Scan(src any) error {
+	var parsed Decimal
+	var err error
+	switch src := src.(type) {
+	case nil:
+		return fmt.Errorf("converting NULL to %T is unsupported, scan into a *sqlAssister.Decimal", *d)
+	case int64:
+		parsed = Decimal{rat: new(big.Rat).SetInt64(src)}
+	case float64:
+		parsed, err = ParseDecimal(strconv.FormatFloat(src, 'g', -1, 64))
+	case []byte:
+		parsed, err = ParseDecimal(string(src))
+	case string:
+		parsed, err = ParseDecimal(src)
+	default:
+		return fmt.Errorf("cannot scan %T into %T", src, *d)
+	}
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+	return nil
+}
+
+// Value binds d as its exact decimal text, failing when it has no finite decimal form
+func (d Decimal) Value() (driver.Value, error) {
+	if _, ok := decimalPlaces(d.Rat().Denom()); !ok {
+		return nil, fmt.Errorf("decimal %s has no exact decimal form, Round it before binding it", d)
+	}
+
+	return d.String(), nil
+}
@@ -0,0 +1,63 @@
+package sqlAssister
+
+import "github.com/zobstory/sqlAssister/utils"
+
+// Dialect identifies the flavour of SQL spoken by the DB behind an Assister.
+// It is used by every helper that generates SQL to pick placeholders & identifier quoting
+type Dialect = utils.Dialect
+
+const (
+	// Postgres is the default dialect
+	Postgres = utils.Postgres
+	MySQL    = utils.MySQL
+	SQLite   = utils.SQLite
+)
+
+// QuotingMode decides how generated SQL writes table & column names, see WithQuotingMode
+type QuotingMode = utils.QuotingMode
+
+const (
+	// AlwaysQuote quotes identifiers exactly as given, the default
+	AlwaysQuote = utils.AlwaysQuote
+	// FoldToLower lowercases identifiers before quoting them
+	FoldToLower = utils.FoldToLower
+	// NoQuoting writes plain identifiers unquoted
+	NoQuoting = utils.NoQuoting
+)
+
+// WithQuotingMode sets how the table & column names of generated SQL are written, by the query builder & every helper generating SQL.
+// Defaults to AlwaysQuote
+func WithQuotingMode(mode QuotingMode) Option {
+	return func(ac *Assister) {
+		ac.quoting = mode
+	}
+}
+
+// QuoteIdentifier renders a table or column name supplied at runtime for the Assister's dialect & quoting mode,
+// as every helper generating SQL does: in double quotes, or backticks on MySQL, with embedded quote characters doubled.
+// Dotted names such as schema.table are quoted part by part. With NoQuoting reserved words such as order are still quoted
+/*
+
+Example:
+
+	query := "SELECT " + Assister.QuoteIdentifier(sortColumn) + " FROM " + Assister.QuoteIdentifier(table)
+*/
+func (ac Assister) QuoteIdentifier(name string) string {
+	return ac.quoting.Quote(ac.dialect, name)
+}
+
+// QuoteLiteral renders value as an SQL literal for the Assister's dialect, see utils.QuoteLiteral for the values supported.
+// Bind parameters are ALWAYS preferred, QuoteLiteral is only for the few places a database refuses one, such as COPY options
+/*
+
+Example:
+
+	format, err := Assister.QuoteLiteral(delimiter)
+	if err != nil {
+		return err
+	}
+	_, err = conn.ExecContext(ctx, `COPY "books" FROM STDIN WITH (FORMAT csv, DELIMITER `+format+`)`)
+*/
+func (ac Assister) QuoteLiteral(value any) (string, error) {
+	return utils.QuoteLiteral(ac.dialect, value)
+}
@@ -0,0 +1,80 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestQuoteLiteralSQLite reads the literals back from SQLite, the value coming back exactly as it was quoted
+func TestQuoteLiteralSQLite(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openTestDB(t, bookTable), WithDialect(SQLite))
+
+	for _, s := range []string{"", "it's", "''", `C:\path\`, `\'; DROP TABLE "books"; --`, "line\nbreak", "héllo ☃", "/* not a comment */"} {
+		literal, err := ac.QuoteLiteral(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		read, err := Get[string](ctx, ac, `SELECT `+literal)
+		if err != nil || read != s {
+			t.Errorf("expected %q read back from %s, got %q & %v", s, literal, read, err)
+		}
+	}
+
+	// A negative number written after a minus doesn't start a -- comment
+	for _, test := range []struct {
+		value any
+		want  float64
+	}{{int64(-1), 11}, {-2.5, 12.5}, {int8(-128), 138}} {
+		literal, err := ac.QuoteLiteral(test.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		read, err := Get[float64](ctx, ac, `SELECT 10 -`+literal+` + 0`)
+		if err != nil || read != test.want {
+			t.Errorf("expected 10 -%s to be %v, got %v & %v", literal, test.want, read, err)
+		}
+	}
+
+	for _, test := range []struct {
+		value any
+		query string
+		want  string
+	}{
+		{true, `SELECT %s = 1`, "1"},
+		{false, `SELECT %s = 0`, "1"},
+		{nil, `SELECT %s IS NULL`, "1"},
+		{sql.NullInt64{}, `SELECT %s IS NULL`, "1"},
+		{time.Date(2024, 3, 1, 9, 30, 0, 0, time.FixedZone("", 2*60*60)), `SELECT datetime(%s)`, "2024-03-01 07:30:00"},
+	} {
+		literal, err := ac.QuoteLiteral(test.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		read, err := Get[string](ctx, ac, fmt.Sprintf(test.query, literal))
+		if err != nil || read != test.want {
+			t.Errorf("%v: expected %s, got %s & %v", test.value, test.want, read, err)
+		}
+	}
+
+	// A quoted default round trips through DDL, the place the database refuses a parameter
+	literal, err := ac.QuoteLiteral("it's untitled")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ac.ExecExpecting(ctx, `CREATE TABLE "drafts" ("id" INTEGER PRIMARY KEY, "name" TEXT NOT NULL DEFAULT `+literal+`)`, AnyRowsAffected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ac.ExecExpecting(ctx, `INSERT INTO "drafts" ("id") VALUES (1)`, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, err := Get[string](ctx, ac, `SELECT "name" FROM "drafts"`)
+	if err != nil || name != "it's untitled" {
+		t.Errorf("expected the default read back, got %q & %v", name, err)
+	}
+}
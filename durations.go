@@ -0,0 +1,103 @@
+package sqlAssister
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// Duration is a time.Duration bound & scanned as the database measures lengths of time. Passed as an arg through an Assister
+// it is bound for the Assister's dialect:
+//   - Postgres: as interval text such as "-90.5 seconds", for `now() - $1::interval`. The text is exact whatever the duration,
+//     where binding a number of seconds would overflow an int4 parameter past 68 years
+//   - MySQL & SQLite: as a number of seconds, an integer when the duration is whole seconds, for `INTERVAL ? SECOND`,
+//     TIMESTAMPDIFF(SECOND, ...) or unixepoch() arithmetic
+//
+// Negative durations are bound negative. Postgres keeps microseconds, sub-microsecond precision is rounded away by the database.
+// Scanned, a Duration reads a Postgres interval in any IntervalStyle, a MySQL TIME or a number of seconds, see utils.ParseInterval.
+// Intervals counting months or years fail to scan as they have no fixed length. NULL fails to scan, scan into a *Duration to allow it
+/*
+
+Example:
+
+	stale, err := sqlAssister.Select[Session](ctx, Assister,
+		`SELECT "id", "idle_for" FROM "sessions" WHERE "last_seen" < now() - $1::interval`, sqlAssister.Duration(30*time.Minute))
+	if err != nil {
+		return nil, err
+	}
+
+	type Session struct {
+		ID      string               `db:"id"`
+		IdleFor sqlAssister.Duration `db:"idle_for"`
+	}
+*/
+type Duration time.Duration
+
+// Scan reads a length of time from an interval, a time or a number of seconds
+func (d *Duration) Scan(src any) error {
+	var parsed time.Duration
+	var err error
+	switch src := src.(type) {
+	case nil:
+		return fmt.Errorf("converting NULL to %T is unsupported, scan into a *sqlAssister.Duration", *d)
+	case int64:
+		if src > math.MaxInt64/int64(time.Second) || src < math.MinInt64/int64(time.Second) {
+			return fmt.Errorf("%d seconds overflows a time.Duration", src)
+		}
+		parsed = time.Duration(src) * time.Second
+	case float64:
+		if math.IsNaN(src) || math.Abs(src) >= math.MaxInt64/float64(time.Second) {
+			return fmt.Errorf("%v seconds overflows a time.Duration", src)
+		}
+		// Whole seconds & their fraction are converted apart, as src * 1e9 loses the fraction of durations beyond 104 days
+		whole, fraction := math.Modf(src)
+		parsed = time.Duration(whole)*time.Second + time.Duration(math.Round(fraction*float64(time.Second)))
+	case []byte:
+		parsed, err = utils.ParseInterval(string(src))
+	case string:
+		parsed, err = utils.ParseInterval(src)
+	default:
+		return fmt.Errorf("cannot scan %T into %T", src, *d)
+	}
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(parsed)
+	return nil
+}
+
+// durationArg returns the value d is bound as on the dialect, see Duration
+func durationArg(dialect Dialect, d Duration) any {
+	if dialect == Postgres {
+		return formatSeconds(time.Duration(d)) + " seconds"
+	}
+	if time.Duration(d)%time.Second == 0 {
+		return int64(time.Duration(d) / time.Second)
+	}
+
+	return time.Duration(d).Seconds()
+}
+
+// formatSeconds writes d as an exact decimal number of seconds such as -90.5
+func formatSeconds(d time.Duration) string {
+	sign := ""
+	magnitude := uint64(d)
+	if d < 0 {
+		sign = "-"
+		// Negating as unsigned also holds the magnitude of math.MinInt64
+		magnitude = -magnitude
+	}
+
+	seconds := strconv.FormatUint(magnitude/uint64(time.Second), 10)
+	nanos := magnitude % uint64(time.Second)
+	if nanos == 0 {
+		return sign + seconds
+	}
+
+	return sign + seconds + "." + strings.TrimRight(fmt.Sprintf("%09d", nanos), "0")
+}
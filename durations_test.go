@@ -0,0 +1,131 @@
+package sqlAssister
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// century is beyond the ±68 years an int32 number of seconds holds
+const century = 36525 * 24 * time.Hour
+
+func TestDurationArg(t *testing.T) {
+	tests := []struct {
+		d        time.Duration
+		postgres string
+		seconds  any
+	}{
+		{0, "0 seconds", int64(0)},
+		{90 * time.Second, "90 seconds", int64(90)},
+		{-90*time.Second - 500*time.Millisecond, "-90.5 seconds", -90.5},
+		{time.Nanosecond, "0.000000001 seconds", 1e-9},
+		{-time.Microsecond, "-0.000001 seconds", -1e-6},
+		{math.MaxInt32*time.Second + time.Second, "2147483648 seconds", int64(math.MaxInt32) + 1},
+		{math.MinInt32*time.Second - time.Second, "-2147483649 seconds", int64(math.MinInt32) - 1},
+		{century, "3155760000 seconds", int64(3155760000)},
+		{-century, "-3155760000 seconds", int64(-3155760000)},
+		{math.MaxInt64, "9223372036.854775807 seconds", time.Duration(math.MaxInt64).Seconds()},
+		{math.MinInt64, "-9223372036.854775808 seconds", time.Duration(math.MinInt64).Seconds()},
+	}
+
+	for _, test := range tests {
+		if got := durationArg(Postgres, Duration(test.d)); got != test.postgres {
+			t.Errorf("postgres %v: expected %q, got %q", test.d, test.postgres, got)
+		}
+		for _, dialect := range []Dialect{MySQL, SQLite} {
+			if got := durationArg(dialect, Duration(test.d)); got != test.seconds {
+				t.Errorf("%s %v: expected %v (%T), got %v (%T)", dialect, test.d, test.seconds, test.seconds, got, got)
+			}
+		}
+
+		// The interval text reads back exactly
+		var scanned Duration
+		err := scanned.Scan(durationArg(Postgres, Duration(test.d)))
+		if err != nil || time.Duration(scanned) != test.d {
+			t.Errorf("expected %v read back from %q, got %v & %v", test.d, test.postgres, time.Duration(scanned), err)
+		}
+	}
+}
+
+func TestDurationScan(t *testing.T) {
+	tests := []struct {
+		src  any
+		want time.Duration
+	}{
+		{int64(-90), -90 * time.Second},
+		{int64(3155760000), century},
+		{int64(-3155760000), -century},
+		{-90.5, -90*time.Second - 500*time.Millisecond},
+		{3155760000.25, century + 250*time.Millisecond},
+		{"-1 days -02:00:00", -26 * time.Hour},
+		{[]byte("36525 days"), century},
+		{[]byte("@ 36525 days ago"), -century},
+		{"-838:59:59", -838*time.Hour - 59*time.Minute - 59*time.Second},
+	}
+	for _, test := range tests {
+		var d Duration
+		err := d.Scan(test.src)
+		if err != nil || time.Duration(d) != test.want {
+			t.Errorf("Scan(%v) = %v & %v, expected %v", test.src, time.Duration(d), err, test.want)
+		}
+	}
+
+	refusals := []struct {
+		src  any
+		want string
+	}{
+		{nil, "NULL"},
+		{int64(9223372037), "overflows"},
+		{int64(-9223372037), "overflows"},
+		{9.3e9, "overflows"},
+		{-9.3e9, "overflows"},
+		{math.NaN(), "overflows"},
+		{"106752 days", "overflows"},
+		{"1 mon", "no fixed duration"},
+		{true, "cannot scan"},
+	}
+	for _, test := range refusals {
+		d := Duration(time.Minute)
+		err := d.Scan(test.src)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("Scan(%v): expected an error mentioning %q, got %v", test.src, test.want, err)
+		}
+		if time.Duration(d) != time.Minute {
+			t.Errorf("Scan(%v): expected the Duration left as is, got %v", test.src, time.Duration(d))
+		}
+	}
+}
+
+func TestDurationSQLite(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openTestDB(t, `CREATE TABLE "sessions" ("id" INTEGER PRIMARY KEY, "idle_for" INTEGER)`), WithDialect(SQLite))
+
+	for _, d := range []time.Duration{-century, -time.Second, 0, 1500 * time.Millisecond, math.MaxInt32*time.Second + time.Second, century} {
+		read, err := Get[Duration](ctx, ac, `SELECT ?`, Duration(d))
+		if err != nil || time.Duration(read) != d {
+			t.Errorf("expected %v bound & read back, got %v & %v", d, time.Duration(read), err)
+		}
+	}
+
+	// Arithmetic on the database sees the seconds, negative ones included
+	since, err := Get[int64](ctx, ac, `SELECT unixepoch('2024-01-01 00:00:00') - unixepoch('2024-01-01 00:00:00', ? || ' seconds')`, Duration(-century))
+	if err != nil || since != int64(century/time.Second) {
+		t.Errorf("expected %d seconds, got %d & %v", int64(century/time.Second), since, err)
+	}
+
+	// NULL only scans into a *Duration
+	_, err = ac.ExecExpecting(ctx, `INSERT INTO "sessions" ("id") VALUES (1)`, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idle, err := Get[*Duration](ctx, ac, `SELECT "idle_for" FROM "sessions" WHERE "id" = 1`)
+	if err != nil || idle != nil {
+		t.Errorf("expected NULL read as a nil *Duration, got %v & %v", idle, err)
+	}
+	_, err = Get[Duration](ctx, ac, `SELECT "idle_for" FROM "sessions" WHERE "id" = 1`)
+	if err == nil || !strings.Contains(err.Error(), "NULL") {
+		t.Errorf("expected NULL refused for a Duration, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package sqlAssister
+
+import "reflect"
+
+// WithEmptyAsNull makes every string field behave as if tagged `db:"column,emptynull"`, for schemas where an empty string & NULL
+// mean the same, as they do in Oracle. A field tagged emptynull, or every string field with WithEmptyAsNull:
+//   - is written as NULL when it holds "", or points to "", by the struct helpers (UpdateStruct, InsertAll, Args, ...)
+//     & by their Build* counterparts, so the args they return & log are the values actually bound
+//   - is read as "" from NULL by the generic scanning helpers, rather than failing to scan. A pointer field still reads NULL as nil
+//
+// Only struct fields are converted: args passed to a query directly & the values of Keys are bound as they are, so
+// WHERE "name" = $1 given "" doesn't turn into a comparison with NULL that matches nothing
+/*
+
+Example:
+
+	type Customer struct {
+		ID         int64   `db:"id,pk"`
+		MiddleName string  `db:"middle_name,emptynull"`
+		Nickname   *string `db:"nickname,emptynull"`
+	}
+
+	statementAssister = sqlAssister.New(db, sqlAssister.WithEmptyAsNull())
+*/
+func WithEmptyAsNull() Option {
+	return func(ac *Assister) {
+		ac.emptyNull = true
+	}
+}
+
+// isEmptyString reports whether fv is a string holding "" or a pointer to one, what a field tagged emptynull binds as NULL.
+// A driver.Valuer decides what it is bound as for itself
+func isEmptyString(fv reflect.Value) bool {
+	if fv.Type().Implements(valuerType) {
+		return false
+	}
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			return false
+		}
+		fv = fv.Elem()
+	}
+
+	return fv.Kind() == reflect.String && fv.Len() == 0
+}
@@ -0,0 +1,133 @@
+package sqlAssister
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const customerTable = `CREATE TABLE "customers" ("id" INTEGER PRIMARY KEY, "middle_name" TEXT, "nickname" TEXT, "title" TEXT)`
+
+type customer struct {
+	ID         int64   `db:"id,pk"`
+	MiddleName string  `db:"middle_name,emptynull"`
+	Nickname   *string `db:"nickname,emptynull"`
+	Title      string  `db:"title"`
+}
+
+// storedNulls returns which of the columns of the customer with id are NULL
+func storedNulls(t *testing.T, ac *Assister, id int64) [3]bool {
+	t.Helper()
+	var nulls [3]bool
+	err := ac.DB.QueryRow(`SELECT "middle_name" IS NULL, "nickname" IS NULL, "title" IS NULL FROM "customers" WHERE "id" = ?`, id).
+		Scan(&nulls[0], &nulls[1], &nulls[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return nulls
+}
+
+func TestEmptyNullBind(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openTestDB(t, customerTable), WithDialect(SQLite))
+	empty, kim := "", "Kim"
+
+	records := []customer{
+		{ID: 1, MiddleName: "", Nickname: &empty, Title: ""},
+		{ID: 2, MiddleName: "Ann", Nickname: &kim, Title: "Dr"},
+		{ID: 3, MiddleName: "", Nickname: nil, Title: ""},
+	}
+	_, err := InsertAll(ctx, ac, "customers", records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only the fields tagged emptynull write "" as NULL, a pointer to "" included
+	for id, expected := range map[int64][3]bool{1: {true, true, false}, 2: {false, false, false}, 3: {true, true, false}} {
+		if nulls := storedNulls(t, ac, id); nulls != expected {
+			t.Errorf("customer %d: expected the NULLs %v, got %v", id, expected, nulls)
+		}
+	}
+
+	// The args returned are the values actually bound
+	args, err := ac.Args(records[0], "MiddleName", "Nickname", "Title")
+	if err != nil || !reflect.DeepEqual(args, []any{nil, nil, ""}) {
+		t.Errorf("expected the empty strings of tagged fields bound as NULL, got %#v & %v", args, err)
+	}
+	query, args, err := ac.BuildUpdateStructFields("customers", &customer{ID: 2, Nickname: &empty}, []string{"MiddleName", "Nickname"})
+	if err != nil || !reflect.DeepEqual(args, []any{nil, nil, int64(2)}) {
+		t.Errorf("expected %s to bind NULLs, got %#v & %v", query, args, err)
+	}
+	err = ac.UpdateStructFields(ctx, "customers", &customer{ID: 2, Nickname: &empty}, []string{"MiddleName", "Nickname", "Title"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nulls := storedNulls(t, ac, 2); nulls != [3]bool{true, true, false} {
+		t.Errorf("expected the update to write NULLs for the tagged fields only, got %v", nulls)
+	}
+
+	// Args passed to a query directly are bound as they are
+	count, err := Get[int](ctx, ac, `SELECT COUNT(*) FROM "customers" WHERE "title" = ?`, "")
+	if err != nil || count != 3 {
+		t.Errorf("expected \"\" compared as it is, got %d & %v", count, err)
+	}
+}
+
+func TestEmptyNullScan(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openTestDB(t, customerTable,
+		`INSERT INTO "customers" VALUES (1, NULL, NULL, 'Dr'), (2, 'Ann', 'Kim', NULL)`), WithDialect(SQLite))
+
+	read, err := Get[customer](ctx, ac, `SELECT "id", "middle_name", "nickname", "title" FROM "customers" WHERE "id" = 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// NULL reads into the tagged string as "", the pointer stays nil
+	if read.MiddleName != "" || read.Nickname != nil || read.Title != "Dr" {
+		t.Errorf("expected NULL read as \"\" & a nil pointer, got %+v", read)
+	}
+	read, err = Get[customer](ctx, ac, `SELECT "id", "middle_name", "nickname" FROM "customers" WHERE "id" = 2`)
+	if err != nil || read.MiddleName != "Ann" || read.Nickname == nil || *read.Nickname != "Kim" {
+		t.Errorf("expected the values read as they are, got %+v & %v", read, err)
+	}
+
+	// A string not tagged still refuses NULL
+	_, err = Get[customer](ctx, ac, `SELECT "id", "title" FROM "customers" WHERE "id" = 2`)
+	if err == nil || !strings.Contains(err.Error(), "NULL") {
+		t.Errorf("expected NULL refused for the untagged title, got %v", err)
+	}
+}
+
+func TestWithEmptyAsNull(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openTestDB(t, customerTable), WithDialect(SQLite), WithEmptyAsNull())
+	empty := ""
+
+	type plainCustomer struct {
+		ID       int64   `db:"id"`
+		Nickname *string `db:"nickname"`
+		Title    string  `db:"title"`
+	}
+	_, err := InsertAll(ctx, ac, "customers", []plainCustomer{{ID: 1, Nickname: &empty}, {ID: 2, Title: "Dr"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nulls := storedNulls(t, ac, 1); nulls != [3]bool{true, true, true} {
+		t.Errorf("expected every empty string written as NULL, got %v", nulls)
+	}
+
+	read, err := Select[plainCustomer](ctx, ac, `SELECT "id", "nickname", "title" FROM "customers" ORDER BY "id"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 2 || read[0].Title != "" || read[0].Nickname != nil || read[1].Title != "Dr" {
+		t.Errorf("expected NULL read back as \"\" for strings & nil for pointers, got %+v", read)
+	}
+
+	// Without the option the same struct fails to read the NULL title
+	_, err = Select[plainCustomer](ctx, New(ac.DB, WithDialect(SQLite)), `SELECT "id", "title" FROM "customers"`)
+	if err == nil {
+		t.Error("expected NULL refused without WithEmptyAsNull")
+	}
+}
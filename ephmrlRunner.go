@@ -0,0 +1,87 @@
+package sqlAssister
+
+import (
+	"context"
+	"os"
+	"os/signal"
+)
+
+// EphmrlRunner runs a function against a DB that is opened for it & closed once it returns, the lifecycle of a CLI tool or batch job,
+// superseding the Ephmrl functions. Unlike them it honours cancellation: the function is given an Assister & the run's context,
+// so a cancelled context, e.g. by Ctrl-C WithSignals, aborts the statement running & what follows it.
+//
+// What a cancelled context does depends on the method used within the function:
+//   - methods taking a ctx (Select, Get, Exec, QueryMultiple, the query builder, ...) fail with the context's error, rows they
+//     returned are closed by database/sql & a WithTransaction in progress is rolled back
+//   - methods without one (UpdateSingleRow, SingleRowScanner, MultipleRowScanner, ...) aren't cancelled, the function should
+//     check ctx.Err() between them or move to their ctx counterparts
+//
+// Teardown runs in order whether the function returns or panics: rows & transactions are released by the function's own
+// defers & WithTransaction, the DB is closed, then signal handling is restored
+type EphmrlRunner struct {
+	driverName string
+	dsn        string
+	opts       []Option
+	signals    []os.Signal
+}
+
+// NewEphmrlRunner returns an EphmrlRunner opening a DB with the driver registered as driverName & dsn for every run,
+// passing opts to the Assister as NewFromDSN does
+/*
+
+Example:
+
+	runner := sqlAssister.NewEphmrlRunner("postgres", dsn).WithSignals(os.Interrupt, syscall.SIGTERM)
+	err := runner.Run(context.Background(), func(ctx context.Context, ac *sqlAssister.Assister) error {
+		books, err := sqlAssister.Select[Book](ctx, ac, `SELECT "id", "name" FROM "books"`)
+		if err != nil {
+			return err
+		}
+		return export(books)
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+*/
+func NewEphmrlRunner(driverName string, dsn string, opts ...Option) *EphmrlRunner {
+	return &EphmrlRunner{driverName: driverName, dsn: dsn, opts: opts}
+}
+
+// WithSignals makes the runner cancel the context of its runs when one of signals is received, e.g. os.Interrupt for Ctrl-C.
+// Only the first signal is caught, a second one gets the default behaviour so a stuck run can still be killed
+func (r *EphmrlRunner) WithSignals(signals ...os.Signal) *EphmrlRunner {
+	r.signals = signals
+	return r
+}
+
+// Run opens the DB, checks it can be reached, runs fn & closes the DB, returning fn's error or else the error closing the DB.
+// fn's context is done once ctx is or, WithSignals, a signal is received. A panic in fn is re-raised once the DB is closed
+func (r *EphmrlRunner) Run(ctx context.Context, fn func(ctx context.Context, ac *Assister) error) (err error) {
+	if len(r.signals) > 0 {
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, r.signals...)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			stop()
+		}()
+	}
+
+	ac, err := NewFromDSN(r.driverName, r.dsn, r.opts...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeErr := ac.DB.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}()
+
+	err = ac.DB.PingContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	return fn(ctx, ac)
+}
@@ -1,6 +1,7 @@
 // Ephmrl (Ephemeral) sqlAssister functions allow for the DB connection to be opened, the function to be used for an operation, & the connection to be closed.
 // Use these when the DB connection is expected to be ephemeral.
 // The methods exposed by the interface are expecting a persistent connection to the DB
+// EphmrlRunner supersedes them with a lifecycle honouring cancellation, they are kept working for existing callers
 
 package sqlAssister
 
@@ -45,6 +46,7 @@ func EphmrlUpdateSingleRow(db *sql.DB, statement string, args ...any) (*sql.Resu
 
 // EphmrlSingleRowScannerWithArgs Executes Read operation on a single record & scans a single record into a struct.
 // Expects ONLY a single record to be returned
+// NOTE: the *sql.Row holds a connection of db until its Scan is called, a row that is never scanned leaks the connection
 /*
 
 Example:
@@ -78,6 +80,7 @@ func EphmrlSingleRowScannerWithArgs(db *sql.DB, query string, args ...any) (*sql
 
 // EphmrlSingleRowScanner Executes Read operation on a single record & scans a single record into a struct.
 // Expects ONLY a single record to be returned
+// NOTE: the *sql.Row holds a connection of db until its Scan is called, a row that is never scanned leaks the connection
 /*
 
 Example:
@@ -0,0 +1,371 @@
+package sqlAssister
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// The errors the package returns fall into:
+//   - sentinels compared with errors.Is: ErrNotFound, ErrMultipleRows, ErrOptimisticLock, ErrTxContextCanceled, ErrUnbalanced,
+//     ErrAlreadyApplied, ErrUnsupportedWithPooler, ErrReadOnly, ErrUnsupportedDialect & ErrInvalidEnum
+//   - types carrying details, matched with errors.As: RowsAffectedError, RowError, ScanErrors, PartialCompletionError,
+//     PartialResultsError, QueryError, WarmupError, StepError, BatchError & InternalError, the latter only with WithRecoverPanics
+//   - OperationError, wrapping the error of a failing statement with the operation named by Label
+//
+// Every sentinel & type implements Error, telling what failed & under which label whatever the type.
+// Every wrapper unwraps to what it wraps, so a sentinel or type is matched whether or not it was labelled.
+// Errors from the driver & database/sql, such as sql.ErrNoRows & sql.ErrTxDone, are returned as they are, wrapped by OperationError
+// at most, see utils.IsAlreadyExistsError, utils.IsUniqueViolation & utils.IsBusyError to tell some of them apart
+
+// Error is implemented by every sentinel & error type of the package, so failures can be reported by what failed & the operation it
+// belongs to without telling the types apart
+/*
+
+Example:
+
+	var sqlErr sqlAssister.Error
+	if errors.As(err, &sqlErr) {
+		metrics.Failures.WithLabelValues(sqlErr.Op(), sqlErr.Label()).Inc()
+	}
+*/
+type Error interface {
+	error
+	// Op names what failed, e.g. "statement", "scan" or "saga step"
+	Op() string
+	// Label returns the operation the Assister whose statement failed was labelled with, see Assister.Label,
+	// "" when it wasn't labelled or the error isn't that of a statement
+	Label() string
+}
+
+// labelOf returns the label of the first *OperationError found in errs' chains, "" when there is none
+func labelOf(errs ...error) string {
+	for _, err := range errs {
+		var opErr *OperationError
+		if errors.As(err, &opErr) {
+			return opErr.Operation
+		}
+	}
+
+	return ""
+}
+
+// sentinelError is the type of the package's sentinels, implementing Error so they are reported by what failed like the other
+// errors. Label is "" as a sentinel is shared, an OperationError wrapping it carries the label
+type sentinelError struct {
+	message string
+	op      string
+}
+
+func (e *sentinelError) Error() string { return e.message }
+
+func (e *sentinelError) Op() string { return e.op }
+
+func (e *sentinelError) Label() string { return "" }
+
+// ErrNotFound is returned by the generic helpers when a query expected to return a record returned none
+var ErrNotFound error = &sentinelError{message: "no record found", op: "record lookup"}
+
+// ErrMultipleRows is returned by GetExactlyOne when its query returned more than one record
+var ErrMultipleRows error = &sentinelError{message: "more than one record found", op: "record lookup"}
+
+// ErrOptimisticLock is returned by UpdateWithVersion when the record's version no longer matches, i.e. it was changed by someone else
+var ErrOptimisticLock error = &sentinelError{message: "record was modified concurrently: version mismatch", op: "version check"}
+
+// ErrTxContextCanceled is returned for statements executed in a transaction after its context was cancelled or timed out.
+// The error also matches the context's own error (context.Canceled or context.DeadlineExceeded) with errors.Is
+var ErrTxContextCanceled error = &sentinelError{message: "transaction context canceled", op: "transaction"}
+
+// ErrUnsupportedWithPooler is returned for what relies on session state a transaction pooler doesn't preserve, see WithTransactionPooler
+var ErrUnsupportedWithPooler error = &sentinelError{message: "unsupported behind a transaction pooler", op: "pooler check"}
+
+// ErrReadOnly is returned for a statement that may write run on an Assister made read only, see ReadOnly
+var ErrReadOnly error = &sentinelError{message: "read only Assister", op: "read only check"}
+
+// ErrUnsupportedDialect is returned for what the Assister's dialect can't do, e.g. cursors or UPDATE ... RETURNING on MySQL.
+// The error names what isn't supported & the alternative when there is one
+var ErrUnsupportedDialect error = &sentinelError{message: "unsupported on this dialect", op: "dialect check"}
+
+// ErrAlreadyApplied is returned by RunOnce for an operation that already ran to completion
+var ErrAlreadyApplied error = &sentinelError{message: "operation already applied", op: "run once"}
+
+// ErrUnbalanced is wrapped by the errors of the balance check rejecting a query, see WithBalanceCheck
+var ErrUnbalanced = utils.ErrUnbalanced
+
+// ErrInvalidEnum is wrapped by the error scanning a value into a registered enum type it isn't one of, or binding a struct field
+// of the type holding such a value, see utils.RegisterEnum
+var ErrInvalidEnum = utils.ErrInvalidEnum
+
+// txContextError matches both ErrTxContextCanceled & the context error that caused it
+type txContextError struct {
+	cause error
+}
+
+func (e txContextError) Error() string {
+	return ErrTxContextCanceled.Error() + ": " + e.cause.Error()
+}
+
+func (e txContextError) Is(target error) bool {
+	return target == ErrTxContextCanceled
+}
+
+func (e txContextError) Unwrap() error {
+	return e.cause
+}
+
+func (e txContextError) Op() string { return "transaction" }
+
+func (e txContextError) Label() string { return "" }
+
+// unsupportedDialectError matches ErrUnsupportedDialect, carrying a message of its own
+type unsupportedDialectError struct {
+	message string
+}
+
+func unsupportedDialectf(format string, args ...any) error {
+	return unsupportedDialectError{message: fmt.Sprintf(format, args...)}
+}
+
+func (e unsupportedDialectError) Error() string {
+	return e.message
+}
+
+func (e unsupportedDialectError) Is(target error) bool {
+	return target == ErrUnsupportedDialect
+}
+
+func (e unsupportedDialectError) Op() string { return "dialect check" }
+
+func (e unsupportedDialectError) Label() string { return "" }
+
+// RowsAffectedError is returned when a statement expected to affect a single record affected none or several, see utils.CheckRowsAffected.
+// Helpers reporting a missing record with ErrNotFound don't return it for none
+type RowsAffectedError = utils.RowsAffectedError
+
+// OperationError labels an error with the operation whose statement failed, see Label
+type OperationError struct {
+	Operation string
+	Err       error
+}
+
+func (e *OperationError) Error() string {
+	return "operation \"" + e.Operation + "\" failed: " + e.Err.Error()
+}
+
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
+
+// Op returns the Op of the error it wraps when it is an Error, "statement" otherwise
+func (e *OperationError) Op() string {
+	var wrapped Error
+	if errors.As(e.Err, &wrapped) {
+		return wrapped.Op()
+	}
+
+	return "statement"
+}
+
+func (e *OperationError) Label() string { return e.Operation }
+
+// RowError is the error scanning a single row, Row counts the rows of the result set from 1
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e RowError) Error() string {
+	return "row " + strconv.Itoa(e.Row) + ": " + e.Err.Error()
+}
+
+func (e RowError) Unwrap() error {
+	return e.Err
+}
+
+func (e RowError) Op() string { return "scan" }
+
+func (e RowError) Label() string { return labelOf(e.Err) }
+
+// ScanErrors lists the rows that failed to scan when scanning continues past them, see ContinueOnError
+type ScanErrors struct {
+	Rows []RowError
+}
+
+func (e *ScanErrors) Error() string {
+	messages := make([]string, len(e.Rows))
+	for i, rowErr := range e.Rows {
+		messages[i] = rowErr.Error()
+	}
+
+	return strconv.Itoa(len(e.Rows)) + " rows failed to scan:\n" + strings.Join(messages, "\n")
+}
+
+// Unwrap returns the error of every row, matched by errors.Is & errors.As from Go 1.20
+func (e *ScanErrors) Unwrap() []error {
+	errs := make([]error, len(e.Rows))
+	for i, rowErr := range e.Rows {
+		errs[i] = rowErr
+	}
+
+	return errs
+}
+
+func (e *ScanErrors) Op() string { return "scan" }
+
+func (e *ScanErrors) Label() string { return labelOf(e.Unwrap()...) }
+
+// StepError is the error of a Saga's step, or of its compensation when Compensation is set. Step counts the steps from 1
+type StepError struct {
+	Step         int
+	Compensation bool
+	Err          error
+}
+
+func (e StepError) Error() string {
+	if e.Compensation {
+		return "saga compensation of step " + strconv.Itoa(e.Step) + ": " + e.Err.Error()
+	}
+
+	return "saga step " + strconv.Itoa(e.Step) + ": " + e.Err.Error()
+}
+
+func (e StepError) Unwrap() error {
+	return e.Err
+}
+
+// Op returns "saga step", or "saga compensation" for the error of a compensation
+func (e StepError) Op() string {
+	if e.Compensation {
+		return "saga compensation"
+	}
+
+	return "saga step"
+}
+
+func (e StepError) Label() string { return labelOf(e.Err) }
+
+// BatchError is returned by Saga.Run when a step failed: the step's error first, then those of the compensations that failed
+type BatchError struct {
+	Errors []StepError
+}
+
+func (e *BatchError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, stepErr := range e.Errors {
+		messages[i] = stepErr.Error()
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns the error of every step & compensation, matched by errors.Is & errors.As from Go 1.20
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, stepErr := range e.Errors {
+		errs[i] = stepErr
+	}
+
+	return errs
+}
+
+func (e *BatchError) Op() string { return "saga" }
+
+func (e *BatchError) Label() string { return labelOf(e.Unwrap()...) }
+
+// CompensationFailed reports whether a compensation failed, leaving a committed step to clean up by hand
+func (e *BatchError) CompensationFailed() bool {
+	for _, stepErr := range e.Errors {
+		if stepErr.Compensation {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PartialCompletionError is returned by a batch helper running WithCheckpoints that stopped part way through, be it on an error,
+// a cancelled context or its deadline drawing near. The records before ResumeOffset, the resume token, are committed:
+// a rerun given the records from ResumeOffset onwards completes the job. Err is why it stopped
+type PartialCompletionError struct {
+	ResumeOffset int
+	Err          error
+}
+
+func (e *PartialCompletionError) Error() string {
+	return "batch stopped with " + strconv.Itoa(e.ResumeOffset) + " records committed: " + e.Err.Error()
+}
+
+func (e *PartialCompletionError) Unwrap() error {
+	return e.Err
+}
+
+func (e *PartialCompletionError) Op() string { return "batch" }
+
+func (e *PartialCompletionError) Label() string { return labelOf(e.Err) }
+
+// PartialResultsError is returned along with the records read before the context was cancelled or its deadline passed
+// by a read run with PartialResults. Err is the context's error, also matched by errors.Is
+type PartialResultsError struct {
+	Err error
+}
+
+func (e *PartialResultsError) Error() string {
+	return "partial results: " + e.Err.Error()
+}
+
+func (e *PartialResultsError) Unwrap() error {
+	return e.Err
+}
+
+func (e *PartialResultsError) Op() string { return "read" }
+
+func (e *PartialResultsError) Label() string { return labelOf(e.Err) }
+
+// QueryError is the error of a registered query, Name being the name it is registered as, see QueryRegistry
+type QueryError struct {
+	Name string
+	Err  error
+}
+
+func (e QueryError) Error() string {
+	return "query \"" + e.Name + "\": " + e.Err.Error()
+}
+
+func (e QueryError) Unwrap() error {
+	return e.Err
+}
+
+func (e QueryError) Op() string { return "registered query" }
+
+func (e QueryError) Label() string { return labelOf(e.Err) }
+
+// WarmupError lists the registered queries that failed to prepare, see Warmup
+type WarmupError struct {
+	Queries []QueryError
+}
+
+func (e *WarmupError) Error() string {
+	messages := make([]string, len(e.Queries))
+	for i, queryErr := range e.Queries {
+		messages[i] = queryErr.Error()
+	}
+
+	return strconv.Itoa(len(e.Queries)) + " queries failed to prepare:\n" + strings.Join(messages, "\n")
+}
+
+// Unwrap returns the error of every query, matched by errors.Is & errors.As from Go 1.20
+func (e *WarmupError) Unwrap() []error {
+	errs := make([]error, len(e.Queries))
+	for i, queryErr := range e.Queries {
+		errs[i] = queryErr
+	}
+
+	return errs
+}
+
+func (e *WarmupError) Op() string { return "warmup" }
+
+func (e *WarmupError) Label() string { return labelOf(e.Unwrap()...) }
@@ -0,0 +1,192 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// errorStatus is an enum registered for the tests, 1 & 2 being its valid values
+type errorStatus int
+
+// panickingValue panics when scanned, its converter standing for a bug of the package, see TestErrorTaxonomy
+type panickingValue struct{}
+
+func init() {
+	utils.RegisterEnum[errorStatus](1, 2)
+	utils.RegisterScanConverter(panickingValue{}, func(src any) (any, error) {
+		panic("converter bug")
+	})
+}
+
+// TestErrorTaxonomy produces every error of the errors.go list through the path returning it, on an Assister labelled & not,
+// checking it matches its sentinel or type & implements Error with the Op & Label expected
+func TestErrorTaxonomy(t *testing.T) {
+	ctx := context.Background()
+	const twoBooks = `INSERT INTO "books" ("id", "name", "stock") VALUES (1, 'Dune', 1), (2, 'Emma', 2)`
+
+	tests := []struct {
+		name string
+		opts []Option
+		run  func(ac *Assister) error
+		// is is the sentinel the error matches, if any
+		is error
+		// as are pointers to the types the error matches
+		as []any
+		// op is the Op of the outermost Error
+		op string
+		// statement is true for the failure of a statement, which Label wraps in an *OperationError
+		statement bool
+		// labelledOnly is true for an error only returned by a labelled Assister, the unlabelled one returning the driver's
+		labelledOnly bool
+	}{
+		{name: "ErrNotFound", run: func(ac *Assister) error {
+			_, err := Get[testBook](ctx, ac, `SELECT * FROM "books" WHERE "id" = ?`, 3)
+			return err
+		}, is: ErrNotFound, op: "record lookup"},
+		{name: "ErrMultipleRows", run: func(ac *Assister) error {
+			_, err := GetExactlyOne[testBook](ctx, ac, `SELECT * FROM "books"`)
+			return err
+		}, is: ErrMultipleRows, op: "record lookup"},
+		{name: "ErrOptimisticLock", run: func(ac *Assister) error {
+			_, err := ac.DB.Exec(`CREATE TABLE "accounts" ("id" INTEGER PRIMARY KEY, "balance" INTEGER NOT NULL, "version" INTEGER NOT NULL);
+				INSERT INTO "accounts" VALUES (1, 100, 2)`)
+			if err != nil {
+				return err
+			}
+			return ac.UpdateWithVersion(ctx, "accounts", &versionedAccount{ID: 1, Balance: 80, Version: 1}, nil, "version")
+		}, is: ErrOptimisticLock, op: "version check"},
+		{name: "ErrTxContextCanceled", run: func(ac *Assister) error {
+			txCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			return ac.WithTransaction(txCtx, func(tx *TxAssister) error {
+				cancel()
+				return tx.UpdateSingleRow(insertBook, "Kim")
+			})
+		}, is: ErrTxContextCanceled, op: "transaction", statement: true},
+		{name: "ErrUnbalanced", opts: []Option{WithBalanceCheck()}, run: func(ac *Assister) error {
+			_, err := Select[testBook](ctx, ac, `SELECT * FROM "books" WHERE "name" = 'Dune`)
+			return err
+		}, is: ErrUnbalanced, op: "balance check"},
+		{name: "ErrAlreadyApplied", run: func(ac *Assister) error {
+			noop := func(tx *TxAssister) error { return nil }
+			err := ac.RunOnce(ctx, "backfill", noop)
+			if err != nil {
+				return err
+			}
+			return ac.RunOnce(ctx, "backfill", noop)
+		}, is: ErrAlreadyApplied, op: "run once"},
+		{name: "ErrUnsupportedWithPooler", opts: []Option{WithTransactionPooler()}, run: func(ac *Assister) error {
+			_, err := ac.ExecExpecting(ctx, `SET search_path TO "archive"`, 0)
+			return err
+		}, is: ErrUnsupportedWithPooler, op: "pooler check", statement: true},
+		{name: "ErrReadOnly", run: func(ac *Assister) error {
+			_, err := ac.ReadOnly().ExecExpecting(ctx, insertBook, 1, "Kim")
+			return err
+		}, is: ErrReadOnly, op: "read only check", statement: true},
+		{name: "ErrUnsupportedDialect", run: func(ac *Assister) error {
+			return ac.WithTransaction(ctx, func(tx *TxAssister) error {
+				_, err := tx.Cursor(ctx, "books", `SELECT * FROM "books"`)
+				return err
+			})
+		}, is: ErrUnsupportedDialect, op: "dialect check"},
+		{name: "ErrInvalidEnum", run: func(ac *Assister) error {
+			_, err := Get[errorStatus](ctx, ac, `SELECT 3`)
+			return err
+		}, is: ErrInvalidEnum, op: "enum check"},
+		{name: "RowsAffectedError", run: func(ac *Assister) error {
+			return ac.UpdateSingleRow(`UPDATE "books" SET "stock" = 0`)
+		}, as: []any{new(*RowsAffectedError)}, op: "rows affected check"},
+		{name: "RowError", run: func(ac *Assister) error {
+			_, err := MapRows(ctx, ac, func(rows *sql.Rows) (int, error) {
+				return 0, errors.New("bad row")
+			}, `SELECT "id" FROM "books"`)
+			return err
+		}, as: []any{new(RowError)}, op: "scan"},
+		{name: "ScanErrors", run: func(ac *Assister) error {
+			_, err := Select[testBook](ctx, ac.ContinueOnError(), `SELECT "id", NULLIF("name", 'Emma') AS "name" FROM "books"`)
+			return err
+		}, as: []any{new(*ScanErrors), new(RowError)}, op: "scan"},
+		{name: "PartialCompletionError", run: func(ac *Assister) error {
+			_, err := InsertAll(ctx, ac.WithCheckpoints(0, nil), "books", []testBook{{ID: 1, Name: "Dune again"}})
+			return err
+		}, as: []any{new(*PartialCompletionError)}, op: "batch", statement: true},
+		{name: "PartialResultsError", run: func(ac *Assister) error {
+			readCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			_, err := MapRows(readCtx, ac.PartialResults(), func(rows *sql.Rows) (int, error) {
+				cancel()
+				return 0, nil
+			}, `WITH RECURSIVE "n" ("i") AS (SELECT 1 UNION ALL SELECT "i" + 1 FROM "n" LIMIT 1000000) SELECT "i" FROM "n"`)
+			return err
+		}, is: context.Canceled, as: []any{new(*PartialResultsError)}, op: "read"},
+		{name: "QueryError & WarmupError", opts: []Option{WithQueryRegistry(NewQueryRegistry())}, run: func(ac *Assister) error {
+			ac.queryRegistry.Register("missing", `SELECT * FROM "missing"`)
+			return ac.Warmup(ctx)
+		}, as: []any{new(*WarmupError), new(QueryError)}, op: "warmup", statement: true},
+		{name: "StepError & BatchError", run: func(ac *Assister) error {
+			return NewSaga().Step(ac, func(tx *TxAssister) error {
+				return tx.UpdateSingleRow(`INSERT INTO "missing" ("name") VALUES (?)`, "Kim")
+			}, nil).Run(ctx)
+		}, as: []any{new(*BatchError), new(StepError)}, op: "saga", statement: true},
+		{name: "InternalError", opts: []Option{WithRecoverPanics(nil), WithLogger(&bufferLogger{})}, run: func(ac *Assister) error {
+			_, err := Get[panickingValue](ctx, ac, `SELECT 1`)
+			return err
+		}, as: []any{new(*InternalError)}, op: "panic recovery"},
+		{name: "OperationError", run: func(ac *Assister) error {
+			_, err := Select[testBook](ctx, ac, `SELECT * FROM "missing"`)
+			return err
+		}, op: "statement", statement: true, labelledOnly: true},
+	}
+
+	for _, test := range tests {
+		for _, label := range []string{"", "audit"} {
+			if label == "" && test.labelledOnly {
+				continue
+			}
+			name := test.name
+			if label != "" {
+				name += " labelled"
+			}
+			t.Run(name, func(t *testing.T) {
+				opts := append([]Option{WithDialect(SQLite)}, test.opts...)
+				ac := New(openTestDB(t, bookTable, twoBooks), opts...)
+				if label != "" {
+					ac = ac.Label(label)
+				}
+
+				err := test.run(ac)
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if test.is != nil && !errors.Is(err, test.is) {
+					t.Errorf("expected %v to match %v", err, test.is)
+				}
+				for _, target := range test.as {
+					if !errors.As(err, target) {
+						t.Errorf("expected %v to match %T", err, target)
+					}
+				}
+
+				expectedLabel := ""
+				if label != "" && test.statement {
+					expectedLabel = label
+				}
+				var opErr *OperationError
+				if errors.As(err, &opErr) != (expectedLabel != "") {
+					t.Errorf("expected an *OperationError only for a labelled statement, got %#v", err)
+				}
+				var pkgErr Error
+				if !errors.As(err, &pkgErr) {
+					t.Fatalf("expected %v to implement Error", err)
+				}
+				if pkgErr.Op() == "" || pkgErr.Op() != test.op || pkgErr.Label() != expectedLabel {
+					t.Errorf("expected Op %q & Label %q, got %q & %q", test.op, expectedLabel, pkgErr.Op(), pkgErr.Label())
+				}
+			})
+		}
+	}
+}
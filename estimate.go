@@ -0,0 +1,56 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// EstimateCount returns the approximate number of records in table from the database's statistics, without counting them.
+// Postgres reads reltuples from pg_class & MySQL reads TABLE_ROWS from information_schema, both are only as fresh as the last
+// ANALYZE (or autovacuum) & may be off by a wide margin. SQLite keeps no such statistic so the records are counted exactly,
+// as they are on Postgres when the table has never been analyzed. Returns ErrNotFound when MySQL has no such table
+/*
+
+Example:
+
+	count, err := Assister.EstimateCount(ctx, "events")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("~%.1fM rows\n", float64(count)/1e6)
+*/
+func (ac Assister) EstimateCount(ctx context.Context, table string) (int64, error) {
+	err := utils.ValidateIdentifier(table)
+	if err != nil {
+		return 0, err
+	}
+
+	var estimate sql.NullFloat64
+	switch ac.dialect {
+	case Postgres:
+		// reltuples is -1 for a table that has never been vacuumed or analyzed
+		err = ac.conn().QueryRowContext(ctx, "SELECT reltuples FROM pg_class WHERE oid = $1::regclass",
+			ac.QuoteIdentifier(table)).Scan(&estimate)
+	case MySQL:
+		schema, name := "", table
+		if i := strings.LastIndex(table, "."); i >= 0 {
+			schema, name = table[:i], table[i+1:]
+		}
+		err = ac.conn().QueryRowContext(ctx, "SELECT TABLE_ROWS FROM information_schema.TABLES "+
+			"WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND TABLE_NAME = ?", schema, name).Scan(&estimate)
+		if err == sql.ErrNoRows {
+			return 0, ErrNotFound
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	if estimate.Valid && estimate.Float64 >= 0 {
+		return int64(estimate.Float64), nil
+	}
+
+	return countRows(ctx, &ac, "SELECT 1 FROM "+ac.QuoteIdentifier(table), nil)
+}
@@ -0,0 +1,211 @@
+package sqlAssister_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/zobstory/sqlAssister"
+)
+
+type Book struct {
+	ID       int64  `db:"id,pk"`
+	AuthorID int64  `db:"author_id"`
+	Name     string `db:"name"`
+	Active   bool   `db:"active,always"`
+}
+
+// openBookstore returns an Assister on an in memory SQLite DB holding three books by two authors
+func openBookstore() *sqlAssister.Assister {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Every connection to :memory: is a separate DB
+	db.SetMaxOpenConns(1)
+
+	statementAssister := sqlAssister.New(db, sqlAssister.WithDialect(sqlAssister.SQLite))
+	ctx := context.Background()
+	err = statementAssister.EnsureTable(ctx, `CREATE TABLE "books" ("id" INTEGER PRIMARY KEY, "author_id" INTEGER NOT NULL,
+		"name" TEXT NOT NULL, "active" BOOLEAN NOT NULL DEFAULT TRUE)`)
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = sqlAssister.InsertAll(ctx, statementAssister, "books", []Book{
+		{ID: 1, AuthorID: 1, Name: "The Go Programming Language", Active: true},
+		{ID: 2, AuthorID: 1, Name: "Concurrency in Go", Active: true},
+		{ID: 3, AuthorID: 2, Name: "Database Internals", Active: true},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return statementAssister
+}
+
+func ExampleNew() {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	statementAssister := sqlAssister.New(db, sqlAssister.WithDialect(sqlAssister.SQLite))
+
+	version, err := sqlAssister.Get[string](context.Background(), statementAssister, "SELECT 'connected'")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(version)
+	// Output: connected
+}
+
+func ExampleSelect() {
+	statementAssister := openBookstore()
+
+	books, err := sqlAssister.Select[Book](context.Background(), statementAssister,
+		`SELECT "id", "author_id", "name", "active" FROM "books" WHERE "author_id" = ? ORDER BY "id"`, 1)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, book := range books {
+		fmt.Println(book.ID, book.Name)
+	}
+	// Output:
+	// 1 The Go Programming Language
+	// 2 Concurrency in Go
+}
+
+func ExampleGet() {
+	statementAssister := openBookstore()
+	ctx := context.Background()
+
+	book, err := sqlAssister.Get[Book](ctx, statementAssister, `SELECT * FROM "books" WHERE "id" = ?`, 3)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(book.Name)
+
+	_, err = sqlAssister.Get[Book](ctx, statementAssister, `SELECT * FROM "books" WHERE "id" = ?`, 42)
+	fmt.Println(errors.Is(err, sqlAssister.ErrNotFound))
+	// Output:
+	// Database Internals
+	// true
+}
+
+func ExampleGetByID() {
+	statementAssister := openBookstore()
+
+	book, err := sqlAssister.GetByID[Book](context.Background(), statementAssister, "books", sqlAssister.Key{Column: "id", Value: 2})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(book.Name)
+	// Output: Concurrency in Go
+}
+
+func ExampleAssister_WithTransaction() {
+	statementAssister := openBookstore()
+	ctx := context.Background()
+
+	// The duplicate id fails the second insert, rolling back the first
+	err := statementAssister.WithTransaction(ctx, func(tx *sqlAssister.TxAssister) error {
+		err := tx.InsertMap(ctx, "books", map[string]any{"id": 4, "author_id": 2, "name": "Designing Data-Intensive Applications"})
+		if err != nil {
+			return err
+		}
+		return tx.InsertMap(ctx, "books", map[string]any{"id": 1, "author_id": 2, "name": "Duplicate"})
+	})
+	fmt.Println(err != nil)
+
+	count, err := sqlAssister.Get[int](ctx, statementAssister, `SELECT COUNT(*) FROM "books"`)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(count)
+	// Output:
+	// true
+	// 3
+}
+
+func ExampleAssister_InsertMap() {
+	statementAssister := openBookstore()
+	ctx := context.Background()
+
+	err := statementAssister.InsertMap(ctx, "books", map[string]any{"id": 4, "author_id": 2, "name": "Designing Data-Intensive Applications"})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	book, err := sqlAssister.GetByID[Book](ctx, statementAssister, "books", sqlAssister.Key{Column: "id", Value: 4})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(book.Name, book.Active)
+	// Output: Designing Data-Intensive Applications true
+}
+
+func ExampleAssister_UpdateStruct() {
+	statementAssister := openBookstore()
+	ctx := context.Background()
+
+	// Active is tagged always, so false is written despite being the zero value
+	err := statementAssister.UpdateStruct(ctx, "books", Book{ID: 2, AuthorID: 1, Name: "Concurrency in Go, 2nd edition"})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	book, err := sqlAssister.GetByID[Book](ctx, statementAssister, "books", sqlAssister.Key{Column: "id", Value: 2})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(book.Name, book.Active)
+	// Output: Concurrency in Go, 2nd edition false
+}
+
+func ExampleInsertAll() {
+	statementAssister := openBookstore()
+	ctx := context.Background()
+
+	inserted, err := sqlAssister.InsertAll(ctx, statementAssister, "books", []Book{
+		{ID: 4, AuthorID: 3, Name: "Learning Go", Active: true},
+		{ID: 5, AuthorID: 3, Name: "100 Go Mistakes", Active: true},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(inserted)
+	// Output: 2
+}
+
+func ExamplePaginate() {
+	statementAssister := openBookstore()
+
+	books, total, err := sqlAssister.Paginate[Book](context.Background(), statementAssister,
+		`SELECT * FROM "books" ORDER BY "id"`, sqlAssister.PageRequest{Page: 2, PageSize: 2})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(total, len(books), books[0].Name)
+	// Output: 3 1 Database Internals
+}
+
+func ExampleFetch() {
+	statementAssister := openBookstore()
+
+	query := statementAssister.Table("books").
+		Select("id", "name").
+		Where(sqlAssister.Eq("author_id", 1)).
+		OrderBy("id DESC").
+		Limit(1)
+
+	books, err := sqlAssister.Fetch[Book](context.Background(), query)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(books[0].Name)
+	// Output: Concurrency in Go
+}
@@ -0,0 +1,10 @@
+module github.com/zobstory/sqlAssister/examples
+
+go 1.19
+
+require (
+	github.com/mattn/go-sqlite3 v1.14.33
+	github.com/zobstory/sqlAssister v0.0.0
+)
+
+replace github.com/zobstory/sqlAssister => ../
@@ -0,0 +1,216 @@
+// Command examples is a small runnable bookstore exercising sqlAssister end to end against an in memory SQLite DB.
+// It doubles as a smoke test: it exits with a non zero status when any step fails.
+//
+//	cd examples && go run .
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/zobstory/sqlAssister"
+)
+
+type Book struct {
+	ID       int64  `db:"id,pk" json:"id"`
+	AuthorID int64  `db:"author_id" json:"author_id"`
+	Name     string `db:"name" json:"name"`
+	Active   bool   `db:"active,always" json:"active"`
+}
+
+const schema = `
+	CREATE TABLE "books" (
+		"id"        INTEGER PRIMARY KEY,
+		"author_id" INTEGER NOT NULL,
+		"name"      TEXT NOT NULL,
+		"active"    BOOLEAN NOT NULL DEFAULT TRUE
+	);`
+
+type BookStore struct {
+	assister *sqlAssister.Assister
+}
+
+// AddBooks inserts every book in a single transaction, none are inserted if any fails
+func (s BookStore) AddBooks(ctx context.Context, books []Book) error {
+	return s.assister.WithTransaction(ctx, func(tx *sqlAssister.TxAssister) error {
+		for _, book := range books {
+			err := tx.InsertMap(ctx, "books", map[string]any{
+				"id":        book.ID,
+				"author_id": book.AuthorID,
+				"name":      book.Name,
+				"active":    book.Active,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// BooksByAuthor returns a page of an author's books & the total number of books they have
+func (s BookStore) BooksByAuthor(ctx context.Context, authorID int64, page int) ([]Book, int64, error) {
+	const statement = `SELECT "id", "author_id", "name", "active" FROM "books" WHERE "author_id" = ? ORDER BY "id"`
+
+	return sqlAssister.Paginate[Book](ctx, s.assister, statement, sqlAssister.PageRequest{Page: page, PageSize: 2, WindowCount: true}, authorID)
+}
+
+// ServeBooks lists an author's books as a JSON Page, a page at a time: GET /books?author=1&cursor=...
+// The first page also carries the total, counted once rather than for every page
+func (s BookStore) ServeBooks(w http.ResponseWriter, r *http.Request) {
+	authorID, err := strconv.ParseInt(r.URL.Query().Get("author"), 10, 64)
+	if err != nil {
+		http.Error(w, "author must be a book author's id", http.StatusBadRequest)
+		return
+	}
+	cursor := r.URL.Query().Get("cursor")
+
+	page, err := sqlAssister.PaginateKeyset[Book](r.Context(), s.assister,
+		`SELECT "id", "author_id", "name", "active" FROM "books" WHERE "author_id" = ?`,
+		sqlAssister.KeysetRequest{Column: "id", Cursor: cursor, PageSize: 2, WithTotal: cursor == ""}, authorID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// listBooks follows ServeBooks' cursors through every page of an author's books, as an API client would
+func listBooks(store BookStore, authorID int64) ([]Book, int64, error) {
+	var books []Book
+	var total int64
+	cursor := ""
+	for {
+		query := url.Values{"author": {strconv.FormatInt(authorID, 10)}}
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+		recorder := httptest.NewRecorder()
+		store.ServeBooks(recorder, httptest.NewRequest(http.MethodGet, "/books?"+query.Encode(), nil))
+		if recorder.Code != http.StatusOK {
+			return nil, 0, fmt.Errorf("GET /books: %d %s", recorder.Code, recorder.Body)
+		}
+
+		var page sqlAssister.Page[Book]
+		err := json.NewDecoder(recorder.Body).Decode(&page)
+		if err != nil {
+			return nil, 0, err
+		}
+		if cursor == "" {
+			total = page.Total
+		}
+		books = append(books, page.Items...)
+		if !page.HasNext {
+			return books, total, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// Book returns a single book
+func (s BookStore) Book(ctx context.Context, id int64) (Book, error) {
+	return sqlAssister.GetByID[Book](ctx, s.assister, "books", sqlAssister.Key{Column: "id", Value: id})
+}
+
+// Retire marks a book inactive, Active is tagged always so false is written despite being the zero value
+func (s BookStore) Retire(ctx context.Context, book Book) error {
+	book.Active = false
+	return s.assister.UpdateStruct(ctx, "books", book)
+}
+
+func run(ctx context.Context) error {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	// Every connection to :memory: is a separate DB
+	db.SetMaxOpenConns(1)
+
+	store := BookStore{assister: sqlAssister.New(db, sqlAssister.WithDialect(sqlAssister.SQLite))}
+
+	err = store.assister.EnsureTable(ctx, schema)
+	if err != nil {
+		return fmt.Errorf("create schema: %w", err)
+	}
+
+	err = store.AddBooks(ctx, []Book{
+		{ID: 1, AuthorID: 1, Name: "The Go Programming Language", Active: true},
+		{ID: 2, AuthorID: 1, Name: "Concurrency in Go", Active: true},
+		{ID: 3, AuthorID: 1, Name: "Learning Go", Active: true},
+		{ID: 4, AuthorID: 2, Name: "Database Internals", Active: true},
+	})
+	if err != nil {
+		return fmt.Errorf("add books: %w", err)
+	}
+
+	// A failing transaction must leave nothing behind
+	err = store.AddBooks(ctx, []Book{{ID: 5, AuthorID: 2, Name: "Designing Data-Intensive Applications"}, {ID: 1, AuthorID: 2, Name: "Duplicate"}})
+	if err == nil {
+		return errors.New("add books: expected a duplicate key error")
+	}
+	_, err = store.Book(ctx, 5)
+	if !errors.Is(err, sqlAssister.ErrNotFound) {
+		return fmt.Errorf("rolled back book: expected ErrNotFound, got %v", err)
+	}
+
+	books, total, err := store.BooksByAuthor(ctx, 1, 2)
+	if err != nil {
+		return fmt.Errorf("paginate: %w", err)
+	}
+	if total != 3 || len(books) != 1 || books[0].ID != 3 {
+		return fmt.Errorf("paginate: unexpected page %v of %d", books, total)
+	}
+
+	listed, total, err := listBooks(store, 1)
+	if err != nil {
+		return fmt.Errorf("list books: %w", err)
+	}
+	if total != 3 || len(listed) != 3 || listed[0].ID != 1 || listed[2].ID != 3 {
+		return fmt.Errorf("list books: unexpected books %v of %d", listed, total)
+	}
+
+	book, err := store.Book(ctx, 2)
+	if err != nil {
+		return fmt.Errorf("get book: %w", err)
+	}
+	err = store.Retire(ctx, book)
+	if err != nil {
+		return fmt.Errorf("retire book: %w", err)
+	}
+
+	active, err := sqlAssister.Fetch[Book](ctx, store.assister.Table("books").
+		Select("id", "author_id", "name", "active").
+		Where(sqlAssister.Eq("active", true)).
+		OrderBy("id"))
+	if err != nil {
+		return fmt.Errorf("fetch active books: %w", err)
+	}
+	if len(active) != 3 {
+		return fmt.Errorf("fetch active books: expected 3, got %d", len(active))
+	}
+
+	for _, book := range active {
+		fmt.Printf("%d\t%s\n", book.ID, book.Name)
+	}
+
+	return nil
+}
+
+func main() {
+	err := run(context.Background())
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/zobstory/sqlAssister"
+)
+
+func TestRun(t *testing.T) {
+	err := run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newBookStore returns a BookStore on an in memory DB holding books, for the handler tests
+func newBookStore(t *testing.T, books ...Book) BookStore {
+	t.Helper()
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+
+	store := BookStore{assister: sqlAssister.New(db, sqlAssister.WithDialect(sqlAssister.SQLite))}
+	err = store.assister.EnsureTable(ctx, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.AddBooks(ctx, books)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return store
+}
+
+// TestServeBooks walks every page of ServeBooks over HTTP, checking the JSON envelope a client receives
+func TestServeBooks(t *testing.T) {
+	store := newBookStore(t,
+		Book{ID: 1, AuthorID: 1, Name: "The Go Programming Language", Active: true},
+		Book{ID: 2, AuthorID: 2, Name: "Database Internals", Active: true},
+		Book{ID: 3, AuthorID: 1, Name: "Concurrency in Go"},
+		Book{ID: 7, AuthorID: 1, Name: "Learning Go", Active: true},
+		Book{ID: 9, AuthorID: 1, Name: "Go in Action", Active: true},
+	)
+	server := httptest.NewServer(http.HandlerFunc(store.ServeBooks))
+	defer server.Close()
+
+	get := func(query url.Values) (int, map[string]json.RawMessage) {
+		t.Helper()
+		response, err := http.Get(server.URL + "/books?" + query.Encode())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if response.StatusCode != http.StatusOK {
+			return response.StatusCode, nil
+		}
+		if contentType := response.Header.Get("Content-Type"); contentType != "application/json" {
+			t.Errorf("expected a JSON response, got %s", contentType)
+		}
+		var envelope map[string]json.RawMessage
+		err = json.Unmarshal(body, &envelope)
+		if err != nil {
+			t.Fatalf("%s: %v", body, err)
+		}
+		return response.StatusCode, envelope
+	}
+
+	var pages [][]Book
+	var ids []int64
+	cursor := ""
+	for len(pages) < 5 {
+		query := url.Values{"author": {"1"}}
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+		_, envelope := get(query)
+
+		var items []Book
+		var hasNext bool
+		err := json.Unmarshal(envelope["items"], &items)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = json.Unmarshal(envelope["has_next"], &hasNext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The total is counted for the first page only & omitted after
+		_, hasTotal := envelope["total"]
+		if hasTotal != (cursor == "") {
+			t.Errorf("page %d: expected the total only on the first page, got %s", len(pages)+1, envelope["total"])
+		}
+		if cursor == "" && string(envelope["total"]) != "4" {
+			t.Errorf("expected a total of 4, got %s", envelope["total"])
+		}
+		_, hasCursor := envelope["next_cursor"]
+		if hasCursor != hasNext {
+			t.Errorf("page %d: expected a next_cursor only with a next page, got %s", len(pages)+1, envelope["next_cursor"])
+		}
+
+		pages = append(pages, items)
+		for _, book := range items {
+			ids = append(ids, book.ID)
+		}
+		if !hasNext {
+			break
+		}
+		err = json.Unmarshal(envelope["next_cursor"], &cursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(pages) != 2 || !reflect.DeepEqual(ids, []int64{1, 3, 7, 9}) {
+		t.Fatalf("expected the author's books 1, 3, 7 & 9 on 2 pages, got %v on %d", ids, len(pages))
+	}
+	// The struct scanning reads every field back, the inactive book included
+	if expected := (Book{ID: 3, AuthorID: 1, Name: "Concurrency in Go"}); pages[0][1] != expected {
+		t.Errorf("expected %+v, got %+v", expected, pages[0][1])
+	}
+
+	// An author without books gets an empty page, not null
+	_, envelope := get(url.Values{"author": {"3"}})
+	if string(envelope["items"]) != "[]" || string(envelope["has_next"]) != "false" {
+		t.Errorf("expected an empty last page, got %v", envelope)
+	}
+
+	for _, query := range []url.Values{{}, {"author": {"Tolkien"}}} {
+		status, _ := get(query)
+		if status != http.StatusBadRequest {
+			t.Errorf("%v: expected %d, got %d", query, http.StatusBadRequest, status)
+		}
+	}
+	status, _ := get(url.Values{"author": {"1"}, "cursor": {"not a cursor"}})
+	if status != http.StatusInternalServerError {
+		t.Errorf("expected a malformed cursor to fail, got %d", status)
+	}
+}
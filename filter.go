@@ -0,0 +1,184 @@
+package sqlAssister
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// filterOps are the comparisons a filter struct's field can be tagged with, besides in. A field without one is compared with =
+var filterOps = map[string]func(column string, value any) Cond{
+	"eq":   Eq,
+	"ne":   Ne,
+	"gt":   Gt,
+	"gte":  Gte,
+	"lt":   Lt,
+	"lte":  Lte,
+	"like": Like,
+}
+
+// FilterConds returns the conditions of a filter struct, such as the query parameters of a list endpoint decoded into a struct,
+// one per field that is set, to pass to SelectQuery.Where. A pointer or slice field is set when it isn't nil, so a pointer
+// to a zero value filters on it, any other field when it isn't its zero value. A field is compared with its column, named as
+// the struct helpers name it, by = or by the operator its tag gives:
+//   - `db:"created_at,gte"` renders "created_at" >= $1, likewise eq, ne, gt, lt & lte
+//   - `db:"name,like"` renders "name" LIKE $1, the field holding the pattern
+//   - `db:"status,in"` renders "status" IN ($1, $2, ...) from a slice field, an empty slice matching nothing
+//
+// Several fields may filter the same column, e.g. both ends of a range. Anonymous embedded structs are flattened
+/*
+
+Example:
+
+	type BookFilter struct {
+		AuthorID      *int64     `db:"author_id"`
+		Status        []string   `db:"status,in"`
+		CreatedAfter  *time.Time `db:"created_at,gte"`
+		CreatedBefore *time.Time `db:"created_at,lt"`
+	}
+
+	conds, err := Assister.FilterConds(filter)
+	if err != nil {
+		return nil, err
+	}
+	books, err := sqlAssister.Fetch[Book](ctx, Assister.Table("books").Select("id", "name").Where(conds...).OrderBy("id"))
+*/
+func (ac Assister) FilterConds(filter any) ([]Cond, error) {
+	value := reflect.ValueOf(filter)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil, nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a filter struct but got %T", filter)
+	}
+
+	return appendFilterConds(nil, value)
+}
+
+// appendFilterConds appends the conditions of the set fields of the struct value to conds
+func appendFilterConds(conds []Cond, value reflect.Value) ([]Cond, error) {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, hasTag := field.Tag.Lookup("db")
+		if tag == "-" {
+			continue
+		}
+		fv := value.Field(i)
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && !hasTag && fieldType.Kind() == reflect.Struct && !isValueType(fieldType) {
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			var err error
+			conds, err = appendFilterConds(conds, fv)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		column, options := parseTag(tag)
+		if column == "" {
+			column = toSnakeCase(field.Name)
+		}
+		err := utils.ValidateIdentifier(column)
+		if err != nil {
+			return nil, fmt.Errorf("filter field %s: %w", field.Name, err)
+		}
+		op := ""
+		for option := range options {
+			if _, ok := filterOps[option]; !ok && option != "in" {
+				continue
+			}
+			if op != "" {
+				return nil, fmt.Errorf("filter field %s is tagged with both %s & %s", field.Name, op, option)
+			}
+			op = option
+		}
+
+		switch fv.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+			if fv.IsNil() {
+				continue
+			}
+		default:
+			if fv.IsZero() {
+				continue
+			}
+		}
+
+		if op != "in" {
+			if fv.Kind() == reflect.Pointer && !fv.Type().Implements(valuerType) {
+				fv = fv.Elem()
+			}
+			compare, ok := filterOps[op]
+			if !ok {
+				compare = Eq
+			}
+			conds = append(conds, compare(column, fv.Interface()))
+			continue
+		}
+
+		if fv.Kind() == reflect.Pointer {
+			fv = fv.Elem()
+		}
+		if fv.Kind() != reflect.Slice && fv.Kind() != reflect.Array {
+			return nil, fmt.Errorf("filter field %s is tagged in but is a %s, not a slice", field.Name, fv.Type())
+		}
+		values := make([]any, fv.Len())
+		for j := range values {
+			values[j] = fv.Index(j).Interface()
+		}
+		conds = append(conds, In(column, values...))
+	}
+
+	return conds, nil
+}
+
+// WhereFromStruct renders the conditions FilterConds returns for filter as the body of a WHERE clause, joined by AND, & the args
+// bound to its placeholders, numbered from 1. A filter with no field set renders 1 = 1, so the clause can always follow WHERE.
+// Bind the query's other args after args, numbering their placeholders on from len(args)+1
+/*
+
+Example:
+
+	where, args, err := Assister.WhereFromStruct(filter)
+	if err != nil {
+		return nil, err
+	}
+	books, err := sqlAssister.Select[Book](ctx, Assister, `SELECT "id", "name" FROM "books" WHERE `+where+` ORDER BY "id"`, args...)
+*/
+func (ac Assister) WhereFromStruct(filter any) (clause string, args []any, err error) {
+	conds, err := ac.FilterConds(filter)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(conds) == 0 {
+		return "1 = 1", nil, nil
+	}
+
+	b := newSQLBuilder(&ac)
+	for i, cond := range conds {
+		if i > 0 {
+			b.WriteString(" AND ")
+		}
+		cond.writeSQL(b)
+	}
+
+	return b.String(), b.args, nil
+}
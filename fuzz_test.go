@@ -0,0 +1,278 @@
+package sqlAssister
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// shapeFieldTypes are the types a fuzzed struct shape picks its fields from, the scalars the scanner reads along with
+// the types it can't & must refuse without panicking
+var shapeFieldTypes = []reflect.Type{
+	reflect.TypeOf(int64(0)),
+	reflect.TypeOf(""),
+	reflect.TypeOf((*string)(nil)),
+	reflect.TypeOf([]byte(nil)),
+	reflect.TypeOf(false),
+	reflect.TypeOf(0.0),
+	reflect.TypeOf(time.Time{}),
+	reflect.TypeOf((*time.Time)(nil)),
+	reflect.TypeOf(sql.NullString{}),
+	reflect.TypeOf((*sql.NullInt64)(nil)),
+	reflect.TypeOf(Duration(0)),
+	reflect.TypeOf(errorStatus(0)),
+	reflect.TypeOf((**int)(nil)),
+	reflect.TypeOf([2]int{}),
+	reflect.TypeOf([]int(nil)),
+	reflect.TypeOf(map[string]int(nil)),
+	reflect.TypeOf((chan int)(nil)),
+	reflect.TypeOf((func())(nil)),
+	reflect.TypeOf((*any)(nil)).Elem(),
+	reflect.TypeOf(unsafe.Pointer(nil)),
+	reflect.TypeOf(struct{}{}),
+}
+
+// shapeTags are the `db` tags a fuzzed struct shape picks from, "" leaving the field untagged
+var shapeTags = []string{"", "-", "id", "id,pk", "name", "name,emptynull", "secret,encrypted", "stamp,timestr", "flag,boolchar",
+	"active,always", "a.b", ",pk", " spaced , pk ", ",,", "F0", "f0", "ID", "count(*)"}
+
+const (
+	// shapeMaxDepth bounds the nesting of fuzzed struct shapes
+	shapeMaxDepth = 3
+	// shapeMaxFields bounds the fields of a fuzzed struct
+	shapeMaxFields = 6
+)
+
+// shapeReader hands out the bytes describing a struct shape, 0 once they run out
+type shapeReader struct {
+	data []byte
+}
+
+func (r *shapeReader) next() int {
+	if len(r.data) == 0 {
+		return 0
+	}
+	b := r.data[0]
+	r.data = r.data[1:]
+
+	return int(b)
+}
+
+// buildShape returns the struct type described by shape: its fields are scalars, unsupported types, nested structs &
+// struct pointers, & anonymous embedded structs & struct pointers, tagged or not. Reports false for a shape reflect can't build
+func buildShape(shape []byte) (t reflect.Type, ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+
+	return buildStruct(&shapeReader{data: shape}, 0), true
+}
+
+func buildStruct(r *shapeReader, depth int) reflect.Type {
+	n := r.next() % (shapeMaxFields + 1)
+	fields := make([]reflect.StructField, n)
+	for i := range fields {
+		kind := r.next() % (len(shapeFieldTypes) + 4)
+		tag := shapeTags[r.next()%len(shapeTags)]
+
+		field := reflect.StructField{Name: fmt.Sprintf("F%d", i)}
+		switch {
+		case kind < len(shapeFieldTypes):
+			field.Type = shapeFieldTypes[kind]
+		case depth >= shapeMaxDepth:
+			field.Type = shapeFieldTypes[0]
+		default:
+			field.Type = buildStruct(r, depth+1)
+			// Pointers & embedding alternate over the 4 struct kinds
+			if (kind-len(shapeFieldTypes))%2 == 1 {
+				field.Type = reflect.PointerTo(field.Type)
+			}
+			field.Anonymous = kind-len(shapeFieldTypes) >= 2
+		}
+		if tag != "" {
+			field.Tag = reflect.StructTag(`db:"` + tag + `"`)
+		}
+		fields[i] = field
+	}
+
+	return reflect.StructOf(fields)
+}
+
+// shapeColumns returns the columns a query reading a value of info's struct could return, its own columns, those of its
+// nested structs prefixed by theirs, & columns matching nothing
+func shapeColumns(info *structInfo, prefix string, depth int) []string {
+	var columns []string
+	for _, fi := range info.fields {
+		columns = append(columns, prefix+fi.column, prefix+strings.ToUpper(fi.name))
+	}
+	if depth < shapeMaxDepth {
+		for column, nested := range info.nested {
+			t := nested.typ
+			if t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			columns = append(columns, shapeColumns(getStructInfo(t), prefix+column+".", depth+1)...)
+		}
+	}
+
+	return append(columns, prefix+"missing", prefix+"COUNT(*)", prefix+".", prefix)
+}
+
+// FuzzStructShapes maps, scans & binds adversarial struct shapes built with reflect.StructOf, failing on any panic: the mapping
+// must refuse what it can't handle with an error rather than crash
+func FuzzStructShapes(f *testing.F) {
+	f.Add([]byte{}, "id", []byte("1"))
+	f.Add([]byte{3, 0, 3, 1, 4, 2, 5, 6}, "name", []byte("Dune"))
+	f.Add([]byte{2, 21, 0, 2, 0, 3, 1, 1, 4}, "f0.id", []byte(""))
+	f.Add([]byte{2, 23, 0, 2, 0, 2, 1, 1, 4}, "id", []byte("2024-03-01 09:30:00"))
+	f.Add([]byte{4, 24, 0, 1, 6, 6, 10, 11, 7, 16, 17, 18, 19}, "a.b", []byte{0xff, 0})
+	f.Add([]byte{1, 22, 10, 1, 24, 10, 1, 22, 10, 1, 0, 10}, "a.b.a.b.a.b", []byte("x"))
+	f.Add([]byte{6, 11, 0, 10, 5, 1, 6, 6, 12, 12, 13, 3, 14}, "secret", []byte("kim@example.com"))
+
+	cipher, err := NewAESGCMCipher(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, shape []byte, column string, value []byte) {
+		typ, ok := buildShape(shape)
+		if !ok {
+			return
+		}
+		ac := New(nil, WithDialect(Postgres), WithFieldCipher(cipher))
+		info := getStructInfo(typ)
+
+		// Scan every column of the shape, & some matching nothing, from every kind of value a driver returns
+		ciphertext, err := cipher.Encrypt(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		srcs := []any{nil, int64(len(value)) - 3, float64(len(value)) / 3, value, string(value), len(value)%2 == 0,
+			time.Unix(int64(len(value)), 0), ciphertext}
+		plan := &scanPlan[struct{}]{decoding: ac.decoding()}
+		result := reflect.New(typ).Elem()
+		for _, c := range append(shapeColumns(info, "", 0), column) {
+			fi, err := info.lookup(c)
+			if err != nil {
+				continue
+			}
+			for _, src := range srcs {
+				_ = assignDest(scanDest(fieldByIndex(result, fi.index), plan.fieldDecoding(fi)), src)
+			}
+		}
+
+		// Bind the zero value & the value scanned through every helper reading struct fields
+		for _, v := range []any{reflect.New(typ).Interface(), result.Addr().Interface(), result.Interface()} {
+			names := make([]string, len(info.fields))
+			for i, fi := range info.fields {
+				names[i] = fi.name
+			}
+			_, _ = ac.Args(v, append(names, column)...)
+			_, _, _ = ac.BuildUpdateStruct("records", v)
+			_, _, _ = ac.BuildUpdateStruct("records", v, column)
+			_, _ = KeyOf(v)
+			_, _ = ac.insertRows(info, []reflect.Value{reflect.Indirect(reflect.ValueOf(v))})
+		}
+	})
+}
+
+// The shapes reflect.StructOf can't build: recursive types & unexported fields
+
+type selfEmbedding struct {
+	*selfEmbedding
+	ID int64 `db:"id"`
+}
+
+type cycleA struct {
+	*CycleB
+	ID int64 `db:"id"`
+}
+
+type CycleB struct {
+	*cycleA
+	Name string `db:"name"`
+}
+
+type unexportedEmbed struct {
+	ID int64 `db:"id"`
+}
+
+type embedsUnexported struct {
+	*unexportedEmbed
+	unexported string
+	Name       string `db:"name"`
+}
+
+type treeNode struct {
+	ID     int64     `db:"id"`
+	Parent *treeNode `db:"parent"`
+}
+
+type unsupportedFields struct {
+	ID      int64          `db:"id"`
+	Updates chan int       `db:"updates"`
+	OnScan  func()         `db:"on_scan"`
+	Counts  map[string]int `db:"counts"`
+	Raw     unsafe.Pointer `db:"raw"`
+	Dup     string         `db:"id"`
+}
+
+// selectShape runs query through Select[T], reporting the error of the scan
+func selectShape[T any](ac *Assister, query string) error {
+	_, err := Select[T](context.Background(), ac, query)
+	return err
+}
+
+// TestAdversarialStructShapes scans the shapes FuzzStructShapes can't build through Select on SQLite, each failing with an error
+// or scanning, none panicking
+func TestAdversarialStructShapes(t *testing.T) {
+	ac := New(openTestDB(t), WithDialect(SQLite))
+
+	tests := []struct {
+		name  string
+		run   func() error
+		fails bool
+	}{
+		{"embedding itself", func() error { return selectShape[selfEmbedding](ac, `SELECT 1 AS "id"`) }, false},
+		{"embedding cycle", func() error { return selectShape[cycleA](ac, `SELECT 1 AS "id", 'x' AS "name"`) }, false},
+		{"unexported embed", func() error { return selectShape[embedsUnexported](ac, `SELECT 1 AS "id"`) }, true},
+		{"unexported field", func() error { return selectShape[embedsUnexported](ac, `SELECT 'x' AS "unexported"`) }, true},
+		{"recursive nesting", func() error {
+			return selectShape[treeNode](ac, `SELECT 1 AS "id", 2 AS "parent.id", 3 AS "parent.parent.parent.parent.id"`)
+		}, false},
+		{"recursive nesting, no field", func() error { return selectShape[treeNode](ac, `SELECT 1 AS "parent.parent.name"`) }, true},
+		{"chan", func() error { return selectShape[unsupportedFields](ac, `SELECT 1 AS "updates"`) }, true},
+		{"func", func() error { return selectShape[unsupportedFields](ac, `SELECT 'x' AS "on_scan"`) }, true},
+		{"map", func() error { return selectShape[unsupportedFields](ac, `SELECT '{}' AS "counts"`) }, true},
+		{"unsafe.Pointer", func() error { return selectShape[unsupportedFields](ac, `SELECT 1 AS "raw"`) }, true},
+		{"duplicate tag", func() error { return selectShape[unsupportedFields](ac, `SELECT 1 AS "id"`) }, false},
+		{"pointer to pointer", func() error { return selectShape[**int64](ac, `SELECT 1`) }, false},
+		{"not a struct", func() error { return selectShape[chan int](ac, `SELECT 1`) }, true},
+		{"too many columns", func() error { return selectShape[int64](ac, `SELECT 1, 2`) }, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.run()
+			if (err != nil) != test.fails {
+				t.Errorf("expected failing %v, got %v", test.fails, err)
+			}
+		})
+	}
+
+	// The binding helpers handle the same shapes
+	for _, v := range []any{&selfEmbedding{ID: 1}, &cycleA{ID: 1}, &embedsUnexported{Name: "x"}, &treeNode{ID: 1, Parent: &treeNode{ID: 2}},
+		&unsupportedFields{ID: 1}} {
+		_, _ = KeyOf(v)
+		_, _, _ = ac.BuildUpdateStruct("records", v, "id")
+		_, _ = ac.Args(v, "ID")
+	}
+}
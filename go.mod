@@ -1,3 +1,8 @@
 module github.com/zobstory/sqlAssister
 
 go 1.19
+
+require (
+	github.com/DATA-DOG/go-sqlmock v1.5.2
+	github.com/mattn/go-sqlite3 v1.14.33
+)
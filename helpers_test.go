@@ -0,0 +1,85 @@
+package sqlAssister
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// update rewrites the golden files under testdata with the output of the tests: go test -run Golden -update
+var update = flag.Bool("update", false, "update the golden files under testdata")
+
+// checkGolden compares got to the golden file testdata/name.golden, writing it instead with -update
+func checkGolden(t *testing.T, name string, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		err := os.WriteFile(path, []byte(got), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s, run with -update to create it: %v", path, err)
+	}
+	if got != string(want) {
+		t.Errorf("%s doesn't match, run with -update if the change is intended\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// openTestDB opens a SQLite database in a temporary file, rather than in memory, so every connection of the pool sees the same
+// database, running the statements given to create its tables. It is closed once the test completes
+func openTestDB(t *testing.T, statements ...string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "test.db")+"?_busy_timeout=5000&_txlock=immediate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	for _, statement := range statements {
+		_, err = db.Exec(statement)
+		if err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+
+	return db
+}
+
+// bookTable is the table most tests run their statements against
+const bookTable = `CREATE TABLE "books" ("id" INTEGER PRIMARY KEY, "name" TEXT NOT NULL, "author_id" INTEGER, "stock" INTEGER NOT NULL DEFAULT 0)`
+
+type testBook struct {
+	ID       int64  `db:"id"`
+	Name     string `db:"name"`
+	AuthorID *int64 `db:"author_id"`
+	Stock    int64  `db:"stock"`
+}
+
+// bufferLogger records the lines logged through it
+type bufferLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *bufferLogger) Printf(format string, v ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func (l *bufferLogger) Lines() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.lines...)
+}
@@ -0,0 +1,183 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// defaultIDCounterTable is the table AllocateIDBlock reads counters from when WithIDCounters isn't set
+const defaultIDCounterTable = "id_counters"
+
+// WithIDCounters sets the table AllocateIDBlock allocates IDs from, with a "name" column identifying each counter & a "value" column
+// holding the last ID allocated from it, defaulting to id_counters. With autoCreate a counter allocated from for the first time is
+// created at 0, so its first ID is 1, rather than AllocateIDBlock failing with ErrNotFound
+/*
+
+Example:
+
+	CREATE TABLE "id_counters" ("name" TEXT PRIMARY KEY, "value" BIGINT NOT NULL)
+
+	Assister := sqlAssister.New(db, sqlAssister.WithIDCounters("id_counters", true))
+*/
+func WithIDCounters(table string, autoCreate bool) Option {
+	return func(ac *Assister) {
+		ac.idCounterTable = table
+		ac.autoCreateCounters = autoCreate
+	}
+}
+
+// AllocateIDBlock reserves the next blockSize IDs of the counter named counterName, returning the first & last of them, both included.
+// The counter is advanced atomically so concurrent callers, in this process or others, never get overlapping blocks: with a single
+// UPDATE ... RETURNING on Postgres & SQLite, & a short transaction locking the counter's record on MySQL.
+// IDs of a block that isn't used up are skipped, not reused. Called on a TxAssister the allocation is part of the transaction, which
+// holds the counter's lock until it ends & undoes the allocation if rolled back, call it outside of transactions where possible
+/*
+
+Example:
+
+	start, end, err := Assister.AllocateIDBlock(ctx, "orders", 1000)
+	if err != nil {
+		return err
+	}
+*/
+func (ac Assister) AllocateIDBlock(ctx context.Context, counterName string, blockSize int64) (start int64, end int64, err error) {
+	if blockSize < 1 {
+		return 0, 0, fmt.Errorf("ID block size must be positive, got %d", blockSize)
+	}
+
+	end, err = ac.advanceCounter(ctx, counterName, blockSize)
+	if errors.Is(err, ErrNotFound) && ac.autoCreateCounters {
+		err = ac.createCounter(ctx, counterName)
+		if err != nil {
+			return 0, 0, err
+		}
+		end, err = ac.advanceCounter(ctx, counterName, blockSize)
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return end - blockSize + 1, end, nil
+}
+
+// counterTable returns the quoted table counters are kept in, see WithIDCounters
+func (ac Assister) counterTable() string {
+	if ac.idCounterTable == "" {
+		return ac.QuoteIdentifier(defaultIDCounterTable)
+	}
+
+	return ac.QuoteIdentifier(ac.idCounterTable)
+}
+
+// advanceCounter adds blockSize to the counter named counterName & returns its new value, ErrNotFound when there is no such counter
+func (ac Assister) advanceCounter(ctx context.Context, counterName string, blockSize int64) (int64, error) {
+	table, name, value := ac.counterTable(), ac.QuoteIdentifier("name"), ac.QuoteIdentifier("value")
+	if ac.dialect != MySQL {
+		query := fmt.Sprintf("UPDATE %s SET %s = %s + %s WHERE %s = %s RETURNING %s",
+			table, value, value, ac.dialect.Placeholder(1), name, ac.dialect.Placeholder(2), value)
+		var end int64
+		err := ac.conn().QueryRowContext(ctx, query, blockSize, counterName).Scan(&end)
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("ID counter %q: %w", counterName, ErrNotFound)
+		}
+
+		return end, err
+	}
+
+	// MySQL has no RETURNING, the counter's record is locked from reading it until the update commits instead
+	var end int64
+	advance := func(tx *TxAssister) error {
+		query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = ? FOR UPDATE", value, table, name)
+		err := tx.conn().QueryRowContext(ctx, query, counterName).Scan(&end)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("ID counter %q: %w", counterName, ErrNotFound)
+		}
+		if err != nil {
+			return err
+		}
+		end += blockSize
+
+		query = fmt.Sprintf("UPDATE %s SET %s = ? WHERE %s = ?", table, value, name)
+		_, err = tx.conn().ExecContext(ctx, query, end, counterName)
+		return err
+	}
+	var err error
+	if ac.tx != nil {
+		err = advance(ac.tx)
+	} else {
+		err = ac.WithTransaction(ctx, advance)
+	}
+
+	return end, err
+}
+
+// createCounter creates the counter named counterName at 0 unless another caller just did
+func (ac Assister) createCounter(ctx context.Context, counterName string) error {
+	insert, conflict := "INSERT INTO", " ON CONFLICT DO NOTHING"
+	if ac.dialect == MySQL {
+		insert, conflict = "INSERT IGNORE INTO", ""
+	}
+	query := fmt.Sprintf("%s %s (%s, %s) VALUES (%s, 0)%s", insert, ac.counterTable(),
+		ac.QuoteIdentifier("name"), ac.QuoteIdentifier("value"), ac.dialect.Placeholder(1), conflict)
+	_, err := ac.conn().ExecContext(ctx, query, counterName)
+	return err
+}
+
+// BlockAllocator hands out the IDs of a counter one at a time from blocks reserved with AllocateIDBlock, reserving the next block
+// when one is used up, so most IDs cost no round trip to the database. IDs are unique across every BlockAllocator & process sharing
+// the counter, increasing within a BlockAllocator but not across them, & the rest of a block is skipped when the process exits.
+// A BlockAllocator is safe for concurrent use
+type BlockAllocator struct {
+	ac        *Assister
+	counter   string
+	blockSize int64
+
+	mu sync.Mutex
+	// next & end are the IDs left in the current block, which is used up once next passes end
+	next int64
+	end  int64
+}
+
+// NewBlockAllocator returns a BlockAllocator of the counter named counterName reserving blockSize IDs at a time through ac,
+// which mustn't be bound to a transaction: the block it reserves outlives the transaction
+/*
+
+Example:
+
+	orderIDs := sqlAssister.NewBlockAllocator(Assister, "orders", 1000)
+
+	id, err := orderIDs.Next(ctx)
+	if err != nil {
+		return err
+	}
+*/
+func NewBlockAllocator(ac *Assister, counterName string, blockSize int64) *BlockAllocator {
+	return &BlockAllocator{
+		ac:        ac,
+		counter:   counterName,
+		blockSize: blockSize,
+		// An empty block, the first is reserved by the first call to Next
+		next: 1,
+	}
+}
+
+// Next returns the next ID, reserving a new block first when the current one is used up
+func (a *BlockAllocator) Next(ctx context.Context) (int64, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.next > a.end {
+		start, end, err := a.ac.AllocateIDBlock(ctx, a.counter, a.blockSize)
+		if err != nil {
+			return 0, err
+		}
+		a.next, a.end = start, end
+	}
+
+	id := a.next
+	a.next++
+	return id, nil
+}
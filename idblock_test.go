@@ -0,0 +1,178 @@
+package sqlAssister
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const counterTable = `CREATE TABLE "id_counters" ("name" TEXT PRIMARY KEY, "value" INTEGER NOT NULL)`
+
+func TestAllocateIDBlockConcurrent(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t, counterTable)
+	const workers, rounds = 16, 20
+
+	type block struct{ start, end int64 }
+	blocks := make(chan block, workers*rounds)
+	errs := make(chan error, workers*rounds)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		w := w
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Every worker its own Assister, as separate processes would have, the first call of each racing to create the counter
+			ac := New(db, WithDialect(SQLite), WithIDCounters("id_counters", true))
+			for i := 0; i < rounds; i++ {
+				size := int64(1 + (w+i)%5)
+				start, end, err := ac.AllocateIDBlock(ctx, "orders", size)
+				if err != nil {
+					errs <- err
+					continue
+				}
+				if end-start+1 != size {
+					t.Errorf("expected a block of %d, got %d to %d", size, start, end)
+				}
+				blocks <- block{start, end}
+			}
+		}()
+	}
+	wg.Wait()
+	close(blocks)
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	// The blocks tile the IDs from 1 without overlapping or leaving gaps
+	var sorted []block
+	for b := range blocks {
+		sorted = append(sorted, b)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].start < sorted[j].start })
+	next := int64(1)
+	for _, b := range sorted {
+		if b.start != next {
+			t.Fatalf("expected a block starting at %d, got %d to %d", next, b.start, b.end)
+		}
+		next = b.end + 1
+	}
+	var value int64
+	err := db.QueryRow(`SELECT "value" FROM "id_counters" WHERE "name" = 'orders'`).Scan(&value)
+	if err != nil || value != next-1 {
+		t.Errorf("expected the counter at %d, got %d & %v", next-1, value, err)
+	}
+}
+
+func TestBlockAllocatorConcurrent(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t, counterTable, `INSERT INTO "id_counters" VALUES ('orders', 100)`)
+	const allocators, workers, ids = 3, 8, 50
+
+	seen := make(map[int64]int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for a := 0; a < allocators; a++ {
+		allocator := NewBlockAllocator(New(db, WithDialect(SQLite)), "orders", 7)
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				previous := int64(0)
+				for i := 0; i < ids; i++ {
+					id, err := allocator.Next(ctx)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					if id <= previous {
+						t.Errorf("expected the IDs of an allocator increasing, got %d after %d", id, previous)
+					}
+					previous = id
+					mu.Lock()
+					seen[id]++
+					mu.Unlock()
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	if len(seen) != allocators*workers*ids {
+		t.Errorf("expected %d distinct IDs, got %d", allocators*workers*ids, len(seen))
+	}
+	for id, count := range seen {
+		if count > 1 {
+			t.Errorf("ID %d handed out %d times", id, count)
+		}
+		if id <= 100 {
+			t.Errorf("ID %d handed out below the counter's start", id)
+		}
+	}
+}
+
+func TestAllocateIDBlockFailures(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openTestDB(t, counterTable, `INSERT INTO "id_counters" VALUES ('orders', 10)`), WithDialect(SQLite))
+
+	_, _, err := ac.AllocateIDBlock(ctx, "orders", 0)
+	if err == nil {
+		t.Error("expected a block of 0 refused")
+	}
+	_, _, err = ac.AllocateIDBlock(ctx, "invoices", 10)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a counter not created, got %v", err)
+	}
+
+	// Allocated in a transaction rolled back, the block is handed out again
+	err = ac.WithTransaction(ctx, func(tx *TxAssister) error {
+		start, _, err := tx.AllocateIDBlock(ctx, "orders", 5)
+		if err != nil {
+			return err
+		}
+		if start != 11 {
+			t.Errorf("expected the block to start at 11, got %d", start)
+		}
+		return errRollBack
+	})
+	if err != errRollBack {
+		t.Fatal(err)
+	}
+	start, end, err := ac.AllocateIDBlock(ctx, "orders", 5)
+	if err != nil || start != 11 || end != 15 {
+		t.Errorf("expected 11 to 15 allocated again, got %d to %d & %v", start, end, err)
+	}
+}
+
+func TestAllocateIDBlockMySQL(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ac := New(db, WithDialect(MySQL), WithIDCounters("counters", true))
+
+	// The first allocation creates the counter, the record is locked from its read to the update's commit
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT `value` FROM `counters` WHERE `name` = ? FOR UPDATE").WithArgs("orders").WillReturnRows(sqlmock.NewRows([]string{"value"}))
+	mock.ExpectRollback()
+	mock.ExpectExec("INSERT IGNORE INTO `counters` (`name`, `value`) VALUES (?, 0)").WithArgs("orders").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT `value` FROM `counters` WHERE `name` = ? FOR UPDATE").WithArgs("orders").WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(0))
+	mock.ExpectExec("UPDATE `counters` SET `value` = ? WHERE `name` = ?").WithArgs(100, "orders").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	start, end, err := ac.AllocateIDBlock(context.Background(), "orders", 100)
+	if err != nil || start != 1 || end != 100 {
+		t.Errorf("expected 1 to 100, got %d to %d & %v", start, end, err)
+	}
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Error(err)
+	}
+}
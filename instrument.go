@@ -0,0 +1,349 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strconv"
+	"sync"
+)
+
+// ExternalLabel is the operation the statements logged by a wrapped driver are labelled with, see WrapDriver
+const ExternalLabel = "external"
+
+var (
+	wrappedDriversMu sync.Mutex
+	wrappedDrivers   int
+)
+
+// WrapDriver registers an instrumented version of the database/sql driver registered as driverName & returns the name it is registered under.
+// A *sql.DB opened with that name, e.g. for a library that takes a raw *sql.DB, logs its statements according to the logging options
+// in opts just as an Assister does, labelled "external". Statements executed through an Assister are left to the Assister to log.
+// The instrumented driver delegates everything else to the original, context methods, named values & transactions included
+/*
+
+Example:
+
+	driverName, err := sqlAssister.WrapDriver("postgres", sqlAssister.WithLogOnErrorOnly())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	migrations.Run(db)
+*/
+func WrapDriver(driverName string, opts ...Option) (wrappedName string, err error) {
+	d, err := lookupDriver(driverName)
+	if err != nil {
+		return "", err
+	}
+
+	wrappedDriversMu.Lock()
+	defer wrappedDriversMu.Unlock()
+
+	wrappedDrivers++
+	wrappedName = driverName + "-sqlAssister-" + strconv.Itoa(wrappedDrivers)
+	sql.Register(wrappedName, newInstrumentedDriver(d, New(nil, opts...)))
+
+	return wrappedName, nil
+}
+
+// lookupDriver returns the driver registered as driverName, sql.Open only looks it up without connecting
+func lookupDriver(driverName string) (driver.Driver, error) {
+	db, err := sql.Open(driverName, "")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return db.Driver(), nil
+}
+
+// newInstrumentedDriver wraps d so the statements executed on it outside an Assister are logged according to ac's logging options
+func newInstrumentedDriver(d driver.Driver, ac *Assister) driver.Driver {
+	logger := loggingQuerier{logger: ac.getLogger(), logOnErrorOnly: ac.logOnErrorOnly, label: ExternalLabel}
+	log := func(ctx context.Context, query string, args []driver.NamedValue, err error) {
+		if !(ac.logQueries || ac.logOnErrorOnly) || err == driver.ErrSkip || ctx.Value(assisterCallKey{}) != nil {
+			return
+		}
+		values := make([]any, len(args))
+		for i, arg := range args {
+			values[i] = arg.Value
+		}
+		logger.log(query, values, err)
+	}
+
+	if _, ok := d.(driver.DriverContext); ok {
+		return instrumentedDriverContext{instrumentedDriver{d: d, log: log}}
+	}
+
+	return instrumentedDriver{d: d, log: log}
+}
+
+// assisterCallKey marks the context of the statements executed through an Assister, which the Assister logs itself
+type assisterCallKey struct{}
+
+// markedQuerier marks the context of every statement executed on q as executed through an Assister
+type markedQuerier struct {
+	q querier
+}
+
+func markContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, assisterCallKey{}, true)
+}
+
+func (q markedQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return q.q.ExecContext(markContext(ctx), query, args...)
+}
+
+func (q markedQuerier) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return q.q.PrepareContext(markContext(ctx), query)
+}
+
+func (q markedQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return q.q.QueryContext(markContext(ctx), query, args...)
+}
+
+func (q markedQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return q.q.QueryRowContext(markContext(ctx), query, args...)
+}
+
+type logFunc func(ctx context.Context, query string, args []driver.NamedValue, err error)
+
+type instrumentedDriver struct {
+	d   driver.Driver
+	log logFunc
+}
+
+func (d instrumentedDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.d.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &instrumentedConn{conn: conn, log: d.log}, nil
+}
+
+// instrumentedDriverContext is an instrumentedDriver whose driver provides its own connector
+type instrumentedDriverContext struct {
+	instrumentedDriver
+}
+
+func (d instrumentedDriverContext) OpenConnector(name string) (driver.Connector, error) {
+	connector, err := d.d.(driver.DriverContext).OpenConnector(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return instrumentedConnector{connector: connector, driver: d}, nil
+}
+
+type instrumentedConnector struct {
+	connector driver.Connector
+	driver    instrumentedDriverContext
+}
+
+func (c instrumentedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &instrumentedConn{conn: conn, log: c.driver.log}, nil
+}
+
+func (c instrumentedConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// instrumentedConn logs the statements executed on conn. Optional interfaces conn doesn't implement
+// report driver.ErrSkip or fall back the way database/sql does, so the wrapper behaves as conn would
+type instrumentedConn struct {
+	conn driver.Conn
+	log  logFunc
+}
+
+func (c *instrumentedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *instrumentedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.conn.Prepare(query)
+	}
+	if err != nil {
+		c.log(ctx, query, nil, err)
+		return nil, err
+	}
+
+	return &instrumentedStmt{stmt: stmt, conn: c, query: query}, nil
+}
+
+func (c *instrumentedConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *instrumentedConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *instrumentedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) || opts.ReadOnly {
+		return nil, errors.New("driver doesn't support transaction options")
+	}
+
+	// The same fallback database/sql takes for drivers without BeginTx
+	return c.conn.Begin()
+}
+
+func (c *instrumentedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	result, err := execer.ExecContext(ctx, query, args)
+	c.log(ctx, query, args, err)
+	return result, err
+}
+
+func (c *instrumentedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	rows, err := queryer.QueryContext(ctx, query, args)
+	c.log(ctx, query, args, err)
+	return rows, err
+}
+
+func (c *instrumentedConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+
+	return driver.ErrSkip
+}
+
+func (c *instrumentedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+
+	return nil
+}
+
+func (c *instrumentedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+func (c *instrumentedConn) IsValid() bool {
+	if validator, ok := c.conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+
+	return true
+}
+
+// instrumentedStmt logs the executions of a prepared statement
+type instrumentedStmt struct {
+	stmt  driver.Stmt
+	conn  *instrumentedConn
+	query string
+}
+
+func (s *instrumentedStmt) Close() error {
+	return s.stmt.Close()
+}
+
+func (s *instrumentedStmt) NumInput() int {
+	return s.stmt.NumInput()
+}
+
+func (s *instrumentedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *instrumentedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *instrumentedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	var result driver.Result
+	var err error
+	if execer, ok := s.stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		values, err = positionalValues(args)
+		if err == nil {
+			result, err = s.stmt.Exec(values)
+		}
+	}
+
+	s.conn.log(ctx, s.query, args, err)
+	return result, err
+}
+
+func (s *instrumentedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	var rows driver.Rows
+	var err error
+	if queryer, ok := s.stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		values, err = positionalValues(args)
+		if err == nil {
+			rows, err = s.stmt.Query(values)
+		}
+	}
+
+	s.conn.log(ctx, s.query, args, err)
+	return rows, err
+}
+
+func (s *instrumentedStmt) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := s.stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+
+	return s.conn.CheckNamedValue(value)
+}
+
+func namedValues(values []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(values))
+	for i, value := range values {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: value}
+	}
+
+	return named
+}
+
+// positionalValues converts args for a driver that only binds positional args, as database/sql does
+func positionalValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("driver doesn't support named values")
+		}
+		values[i] = arg.Value
+	}
+
+	return values, nil
+}
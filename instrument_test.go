@@ -0,0 +1,216 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// checkLines checks each line logged contains the fragment expected at its position
+func checkLines(t *testing.T, lines []string, expected []string) {
+	t.Helper()
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d lines logged, got %d:\n%s", len(expected), len(lines), strings.Join(lines, "\n"))
+	}
+	for i, line := range lines {
+		if !strings.Contains(line, expected[i]) || !strings.Contains(line, "OPERATION: "+ExternalLabel) {
+			t.Errorf("line %d: expected %q labelled %q, got %q", i, expected[i], ExternalLabel, line)
+		}
+	}
+}
+
+func TestWrapDriverSQLite(t *testing.T) {
+	ctx := context.Background()
+	logger := &bufferLogger{}
+	driverName, err := WrapDriver("sqlite3", WithLogger(logger), WithQueryLogging())
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open(driverName, "file:"+filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	_, err = db.ExecContext(ctx, bookTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.ExecContext(ctx, `INSERT INTO "books" ("name", "stock") VALUES (:name, :stock)`, sql.Named("name", "Dune"), sql.Named("stock", 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tx.ExecContext(ctx, insertBook, "Emma")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tx.Rollback()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err = db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt, err := tx.PrepareContext(ctx, insertBook)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = stmt.ExecContext(ctx, "Persuasion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt.Close()
+	err = tx.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT "name" FROM "books" ORDER BY "id"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		err = rows.Scan(&name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if strings.Join(names, ",") != "Dune,Persuasion" {
+		t.Errorf("expected the rolled back insert missing, got %q", names)
+	}
+
+	// The context reaches the driver, which interrupts the statement once it is done
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = db.ExecContext(timeoutCtx, `WITH RECURSIVE "n"("x") AS (SELECT 1 UNION ALL SELECT "x" + 1 FROM "n") SELECT COUNT(*) FROM "n"`)
+	if err == nil {
+		t.Error("expected the endless statement interrupted by its context")
+	}
+
+	_, err = db.ExecContext(ctx, `INSERT INTO "missing" VALUES (1)`)
+	if err == nil {
+		t.Error("expected inserting into a missing table to fail")
+	}
+
+	// The statements executed through an Assister are left to the Assister, which doesn't log them here
+	ac := New(db, WithDialect(SQLite))
+	if count := countBooks(t, ac); count != 2 {
+		t.Errorf("expected 2 books, got %d", count)
+	}
+
+	checkLines(t, logger.Lines(), []string{
+		`QUERY: CREATE TABLE "books"`,
+		`VALUES (:name, :stock) ARGS: [<string> <int64>]`,
+		`QUERY: INSERT INTO "books" ("name") VALUES (?) ARGS: [<string>]`,
+		`QUERY: INSERT INTO "books" ("name") VALUES (?) ARGS: [<string>]`,
+		`QUERY: SELECT "name" FROM "books"`,
+		`ERROR: `,
+		`ERROR: no such table: missing`,
+	})
+}
+
+func TestWrapDriverSQLMock(t *testing.T) {
+	ctx := context.Background()
+	mockDB, mock, err := sqlmock.NewWithDSN("wrap_driver_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mockDB.Close()
+
+	logger := &bufferLogger{}
+	driverName, err := WrapDriver("sqlmock", WithLogger(logger), WithLogOnErrorOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open(driverName, "wrap_driver_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	failure := errors.New("connection reset")
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "books" SET "stock" = :stock WHERE "id" = :id`).
+		WithArgs(sql.Named("stock", 2), sql.Named("id", 1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(`SELECT "name" FROM "books" WHERE "id" = \?`).
+		ExpectQuery().WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Dune"))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM "books"`).WillReturnError(failure)
+	mock.ExpectRollback()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := tx.ExecContext(ctx, `UPDATE "books" SET "stock" = :stock WHERE "id" = :id`, sql.Named("stock", 2), sql.Named("id", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if affected, _ := result.RowsAffected(); affected != 1 {
+		t.Errorf("expected the driver's result, got %d rows affected", affected)
+	}
+	stmt, err := tx.PrepareContext(ctx, `SELECT "name" FROM "books" WHERE "id" = ?`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var name string
+	err = stmt.QueryRowContext(ctx, 1).Scan(&name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "Dune" {
+		t.Errorf("expected Dune, got %q", name)
+	}
+	stmt.Close()
+	err = tx.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err = db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM "books"`)
+	if !errors.Is(err, failure) {
+		t.Errorf("expected the driver's error, got %v", err)
+	}
+	err = tx.Rollback()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Error(err)
+	}
+	// Only the failure is logged
+	checkLines(t, logger.Lines(), []string{`ERROR: connection reset OPERATION: external QUERY: DELETE FROM "books"`})
+}
+
+func TestWrapDriverUnknown(t *testing.T) {
+	_, err := WrapDriver("no-such-driver")
+	if err == nil {
+		t.Error("expected wrapping an unregistered driver to fail")
+	}
+}
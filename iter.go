@@ -0,0 +1,59 @@
+//go:build go1.23
+
+package sqlAssister
+
+import (
+	"context"
+	"iter"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// Iter Executes Read operation on multiple records & streams them one at a time as T, following the same rules as Select.
+// The rows are closed when the loop ends, including when it is left early with break or return.
+// A record that fails to scan is yielded with its error & iteration continues if the loop does
+/*
+
+Example:
+
+	for book, err := range sqlAssister.Iter[Book](ctx, Assister, `SELECT "id", "name" FROM "books"`) {
+		if err != nil {
+			return err
+		}
+		process(book)
+	}
+*/
+func Iter[T any](ctx context.Context, ac *Assister, query string, args ...any) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		err := utils.QueryChecker(query, ac.queryChecks()...)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		rows, err := ac.conn().QueryContext(ctx, ac.limitQuery(query), args...)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer rows.Close()
+
+		plan, err := newRowsScanPlan[T](ac, rows)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		for rows.Next() {
+			if !yield(plan.scan(rows)) {
+				return
+			}
+		}
+
+		err = rows.Err()
+		if err != nil {
+			yield(zero, err)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package sqlAssister
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// Pair holds a row of a two table JOIN scanned into two structs.
+// Valid is false when every column of Second was NULL, as for a row a LEFT JOIN found no match for, Second then holds its zero value
+type Pair[A, B any] struct {
+	First  A
+	Second B
+	Valid  bool
+}
+
+// SelectJoined Executes Read operation on a JOIN of two tables & scans the columns before splitColumn into A & the rest into B,
+// each following the same mapping rules as Select. The split is made at the first column named splitColumn after the first column,
+// so both tables may return a column with the same name such as id. Returns an error if no such column is returned
+/*
+
+Example:
+
+	type Author struct {
+		ID   string `db:"id"`
+		Name string `db:"name"`
+	}
+
+	pairs, err := sqlAssister.SelectJoined[Author, Book](ctx, Assister,
+		`SELECT a."id", a."name", b."id", b."name" FROM "authors" a LEFT JOIN "books" b ON b."author_id" = a."id"`, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pair := range pairs {
+		if !pair.Valid {
+			// the author has no books
+		}
+	}
+*/
+func SelectJoined[A, B any](ctx context.Context, ac *Assister, query string, splitColumn string, args ...any) ([]Pair[A, B], error) {
+	err := utils.QueryChecker(query, ac.queryChecks()...)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ac.conn().QueryContext(ctx, ac.limitQuery(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	split := -1
+	for i := 1; i < len(columns); i++ {
+		if columns[i] == splitColumn {
+			split = i
+			break
+		}
+	}
+	if split == -1 {
+		return nil, fmt.Errorf("split column %q is not returned after the first column, columns are %q", splitColumn, columns)
+	}
+
+	firstPlan, err := newScanPlan[A](ac, columns[:split])
+	if err != nil {
+		return nil, err
+	}
+	secondPlan, err := newScanPlan[B](ac, columns[split:])
+	if err != nil {
+		return nil, err
+	}
+
+	// The columns of B are read raw first so a row without a match can be told apart before assigning them
+	values := make([]any, len(columns)-split)
+	extra := make([]any, len(values))
+	for i := range values {
+		extra[i] = &values[i]
+	}
+
+	var results []Pair[A, B]
+	for rows.Next() {
+		var pair Pair[A, B]
+		pair.First, err = firstPlan.scan(rows, extra...)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, value := range values {
+			if value != nil {
+				pair.Valid = true
+				break
+			}
+		}
+		if pair.Valid {
+			pair.Second, err = secondPlan.assign(values)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		results = append(results, pair)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// FoldRows groups the children of a one-to-many JOIN under their parents. Parents are deduplicated by parentKey, keeping the
+// first-seen order, & every valid child is attached to its parent with attach, so a parent a LEFT JOIN found no children for has none.
+// parentKey must return a comparable value such as an ID
+/*
+
+Example:
+
+	type Author struct {
+		ID    string `db:"id"`
+		Name  string `db:"name"`
+		Books []Book `db:"-"`
+	}
+
+	authors := sqlAssister.FoldRows(pairs,
+		func(a Author) any { return a.ID },
+		func(a *Author, b Book) { a.Books = append(a.Books, b) },
+	)
+*/
+func FoldRows[P, C any](pairs []Pair[P, C], parentKey func(P) any, attach func(*P, C)) []P {
+	var parents []P
+	seen := map[any]int{}
+	for _, pair := range pairs {
+		key := parentKey(pair.First)
+		i, ok := seen[key]
+		if !ok {
+			i = len(parents)
+			seen[key] = i
+			parents = append(parents, pair.First)
+		}
+		if pair.Valid {
+			attach(&parents[i], pair.Second)
+		}
+	}
+
+	return parents
+}
+
+// SelectFolded Executes Read operation on a one-to-many JOIN scanning it with SelectJoined & grouping the children under their parents with FoldRows
+/*
+
+Example:
+
+	authors, err := sqlAssister.SelectFolded(ctx, Assister,
+		`SELECT a."id", a."name", b."id", b."name" FROM "authors" a LEFT JOIN "books" b ON b."author_id" = a."id" ORDER BY a."name"`, "id",
+		func(a Author) any { return a.ID },
+		func(a *Author, b Book) { a.Books = append(a.Books, b) },
+	)
+	if err != nil {
+		return nil, err
+	}
+*/
+func SelectFolded[P, C any](ctx context.Context, ac *Assister, query string, splitColumn string, parentKey func(P) any, attach func(*P, C), args ...any) ([]P, error) {
+	pairs, err := SelectJoined[P, C](ctx, ac, query, splitColumn, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return FoldRows(pairs, parentKey, attach), nil
+}
@@ -0,0 +1,90 @@
+package sqlAssister
+
+import (
+	"context"
+	"testing"
+)
+
+type joinedAuthor struct {
+	ID    int64  `db:"id"`
+	Name  string `db:"name"`
+	Books []joinedBook
+}
+
+type joinedPublisher struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+type joinedBook struct {
+	ID        int64            `db:"id"`
+	Name      string           `db:"name"`
+	Publisher *joinedPublisher `db:"publisher"`
+}
+
+const authorsBooksQuery = `SELECT "a"."id", "a"."name", "b"."id", "b"."name", "p"."id" AS "publisher.id", "p"."name" AS "publisher.name"
+	FROM "authors" AS "a"
+	LEFT JOIN "books" AS "b" ON "b"."author_id" = "a"."id"
+	LEFT JOIN "publishers" AS "p" ON "p"."id" = "b"."publisher_id"
+	ORDER BY "a"."id", "b"."id"`
+
+func openAuthorsBooks(t *testing.T) *Assister {
+	db := openTestDB(t,
+		`CREATE TABLE "authors" ("id" INTEGER PRIMARY KEY, "name" TEXT NOT NULL)`,
+		`CREATE TABLE "publishers" ("id" INTEGER PRIMARY KEY, "name" TEXT NOT NULL)`,
+		`CREATE TABLE "books" ("id" INTEGER PRIMARY KEY, "name" TEXT NOT NULL, "author_id" INTEGER NOT NULL, "publisher_id" INTEGER)`,
+		`INSERT INTO "authors" VALUES (1, 'Kernighan'), (2, 'Petrov')`,
+		`INSERT INTO "publishers" VALUES (1, 'Addison-Wesley')`,
+		`INSERT INTO "books" VALUES (1, 'The Go Programming Language', 1, 1), (2, 'The Practice of Programming', 1, NULL)`,
+	)
+
+	return New(db, WithDialect(SQLite))
+}
+
+func TestSelectJoinedLeftJoin(t *testing.T) {
+	ac := openAuthorsBooks(t)
+
+	pairs, err := SelectJoined[joinedAuthor, joinedBook](context.Background(), ac, authorsBooksQuery, "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pairs) != 3 {
+		t.Fatalf("expected 3 rows, got %d: %+v", len(pairs), pairs)
+	}
+
+	published := pairs[0]
+	if published.First.Name != "Kernighan" || !published.Valid || published.Second.ID != 1 ||
+		published.Second.Publisher == nil || published.Second.Publisher.Name != "Addison-Wesley" {
+		t.Errorf("expected Kernighan's published book, got %+v", published)
+	}
+
+	// The book's columns are set, only its publisher's are all NULL
+	unpublished := pairs[1]
+	if !unpublished.Valid || unpublished.Second.Name != "The Practice of Programming" || unpublished.Second.Publisher != nil {
+		t.Errorf("expected Kernighan's book without a publisher, got %+v", unpublished)
+	}
+
+	// Every column of the book & its publisher is NULL
+	bookless := pairs[2]
+	if bookless.First.Name != "Petrov" || bookless.Valid || bookless.Second.ID != 0 || bookless.Second.Publisher != nil {
+		t.Errorf("expected Petrov without a book, got %+v", bookless)
+	}
+}
+
+func TestSelectFoldedLeftJoin(t *testing.T) {
+	ac := openAuthorsBooks(t)
+
+	authors, err := SelectFolded[joinedAuthor, joinedBook](context.Background(), ac, authorsBooksQuery, "id",
+		func(a joinedAuthor) any { return a.ID },
+		func(a *joinedAuthor, b joinedBook) { a.Books = append(a.Books, b) })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(authors) != 2 || len(authors[0].Books) != 2 || authors[1].Books != nil {
+		t.Fatalf("expected Kernighan with 2 books & Petrov with none, got %+v", authors)
+	}
+	if authors[0].Books[0].Publisher == nil || authors[0].Books[1].Publisher != nil {
+		t.Errorf("expected only the first book to have a publisher, got %+v", authors[0].Books)
+	}
+}
@@ -0,0 +1,232 @@
+package sqlAssister
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// Key is a column & the value identifying a record by it. Several Keys form a composite key
+type Key struct {
+	Column string
+	Value  any
+}
+
+// KeyOf returns the primary key of a struct from its fields tagged `db:"column,pk"`, in field order.
+// Every key field must be set, a nil key field is reported as a missing part of the key. Zero values such as 0 or "" are valid keys
+/*
+
+Example:
+
+	type Membership struct {
+		TenantID string `db:"tenant_id,pk"`
+		UserID   string `db:"user_id,pk"`
+		Role     string `db:"role"`
+	}
+
+	keys, err := sqlAssister.KeyOf(membership)
+	if err != nil {
+		return err
+	}
+
+	err = Assister.DeleteByID(ctx, "memberships", keys...)
+*/
+func KeyOf(v any) ([]Key, error) {
+	value, info, err := structValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	pkFields := info.pkFields()
+	if len(pkFields) == 0 {
+		return nil, fmt.Errorf("%s has no fields tagged as primary key `db:\"column,pk\"`", info.typ)
+	}
+
+	keys := make([]Key, len(pkFields))
+	for i, fi := range pkFields {
+		keyValue := fieldValue(value, fi).Interface()
+		if isNilKey(keyValue) {
+			return nil, fmt.Errorf("primary key of %s is incomplete: %q is not set", info.typ, fi.column)
+		}
+		keys[i] = Key{Column: fi.column, Value: keyValue}
+	}
+
+	return keys, nil
+}
+
+// isNilKey reports whether a key's value is nil, which can't identify a record as column = NULL never holds
+func isNilKey(v any) bool {
+	return v == nil || isTypedNil(v)
+}
+
+// pkFields returns the fields tagged as primary key
+func (info *structInfo) pkFields() []*fieldInfo {
+	var pkFields []*fieldInfo
+	for _, fi := range info.fields {
+		if fi.options["pk"] {
+			pkFields = append(pkFields, fi)
+		}
+	}
+
+	return pkFields
+}
+
+// checkKeys validates keys are present, name distinct columns & aren't nil.
+// When info declares primary key fields the keys must name exactly those columns so part of a composite key can't be left out
+func checkKeys(info *structInfo, keys []Key) error {
+	if len(keys) == 0 {
+		return errors.New("no key present")
+	}
+
+	seen := map[string]bool{}
+	for _, key := range keys {
+		err := utils.ValidateIdentifier(key.Column)
+		if err != nil {
+			return fmt.Errorf("key column: %w", err)
+		}
+		if seen[key.Column] {
+			return fmt.Errorf("key column %q is given more than once", key.Column)
+		}
+		if isNilKey(key.Value) {
+			return fmt.Errorf("key column %q has a nil value, which can never match a record", key.Column)
+		}
+		seen[key.Column] = true
+	}
+
+	if info == nil {
+		return nil
+	}
+	pkFields := info.pkFields()
+	if len(pkFields) == 0 {
+		return nil
+	}
+	for _, fi := range pkFields {
+		if !seen[fi.column] {
+			return fmt.Errorf("primary key of %s is incomplete: no value given for %q", info.typ, fi.column)
+		}
+	}
+	if len(keys) != len(pkFields) {
+		return fmt.Errorf("primary key of %s has %d columns but %d were given", info.typ, len(pkFields), len(keys))
+	}
+
+	return nil
+}
+
+// keyConds renders keys as the conjunctive conditions identifying a record
+func keyConds(keys []Key) []Cond {
+	conds := make([]Cond, len(keys))
+	for i, key := range keys {
+		conds[i] = Eq(key.Column, key.Value)
+	}
+
+	return conds
+}
+
+// GetByID Executes Read operation on the single record in table identified by keys & scans it into a T, see Get.
+// Several keys identify a record by a composite key. When T tags its primary key fields `db:"column,pk"` the keys must cover all of them.
+// Returns ErrNotFound when no record is found
+/*
+
+Example:
+
+	book, err := sqlAssister.GetByID[Book](ctx, Assister, "books", sqlAssister.Key{Column: "id", Value: bookId})
+	if err != nil {
+		return nil, err
+	}
+*/
+func GetByID[T any](ctx context.Context, ac *Assister, table string, keys ...Key) (T, error) {
+	var result T
+	err := utils.ValidateIdentifier(table)
+	if err != nil {
+		return result, err
+	}
+
+	err = checkKeys(structInfoOf[T](), keys)
+	if err != nil {
+		return result, err
+	}
+
+	return FetchOne[T](ctx, ac.Table(table).Select().Where(keyConds(keys)...))
+}
+
+// DeleteByID deletes the single record in table identified by keys, several keys identify a record by a composite key.
+// Returns ErrNotFound when no record matched
+/*
+
+Example:
+
+	err := Assister.DeleteByID(ctx, "memberships",
+		sqlAssister.Key{Column: "tenant_id", Value: tenantId},
+		sqlAssister.Key{Column: "user_id", Value: userId},
+	)
+	if err != nil {
+		return err
+	}
+*/
+func (ac Assister) DeleteByID(ctx context.Context, table string, keys ...Key) error {
+	remove, err := ac.deleteByID(table, keys)
+	if err != nil {
+		return err
+	}
+
+	results, err := remove.Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := results.RowsAffected()
+	if err == nil && rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return ac.checkRowsAffected(results, 1)
+}
+
+// BuildDeleteByID returns the statement & args DeleteByID executes without executing it, to inspect, log or wrap it
+/*
+
+Example:
+
+	query, args, err := Assister.BuildDeleteByID("books", sqlAssister.Key{Column: "id", Value: bookId})
+	if err != nil {
+		return err
+	}
+*/
+func (ac Assister) BuildDeleteByID(table string, keys ...Key) (query string, args []any, err error) {
+	remove, err := ac.deleteByID(table, keys)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return remove.SQL()
+}
+
+func (ac Assister) deleteByID(table string, keys []Key) (*DeleteQuery, error) {
+	err := utils.ValidateIdentifier(table)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkKeys(nil, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return ac.Table(table).Delete().Where(keyConds(keys)...), nil
+}
+
+// structInfoOf returns the field mapping of T when T is a struct or a pointer to one
+func structInfoOf[T any]() *structInfo {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || isValueType(t) {
+		return nil
+	}
+
+	return getStructInfo(t)
+}
@@ -0,0 +1,102 @@
+package sqlAssister
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const membershipTable = `CREATE TABLE "memberships" ("tenant_id" INTEGER NOT NULL, "user_id" TEXT NOT NULL, "role" TEXT NOT NULL,
+	PRIMARY KEY ("tenant_id", "user_id"))`
+
+type testMembership struct {
+	TenantID int64  `db:"tenant_id,pk"`
+	UserID   string `db:"user_id,pk"`
+	Role     string `db:"role"`
+}
+
+func TestCompositeKeys(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t, membershipTable,
+		`INSERT INTO "memberships" VALUES (0, 'ann', 'owner'), (0, 'bob', 'viewer'), (1, 'ann', 'viewer')`)
+	ac := New(db, WithDialect(SQLite))
+
+	// The default tenant 0 is a zero value but a valid key
+	keys, err := KeyOf(testMembership{TenantID: 0, UserID: "ann"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0] != (Key{"tenant_id", int64(0)}) || keys[1] != (Key{"user_id", "ann"}) {
+		t.Fatalf("expected both key columns in field order, got %v", keys)
+	}
+
+	membership, err := GetByID[testMembership](ctx, ac, "memberships", keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if membership.Role != "owner" {
+		t.Errorf("expected ann's membership of tenant 0, got %+v", membership)
+	}
+
+	err = ac.UpdateStruct(ctx, "memberships", testMembership{TenantID: 0, UserID: "ann", Role: "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	membership, err = GetByID[testMembership](ctx, ac, "memberships", Key{"tenant_id", 0}, Key{"user_id", "ann"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if membership.Role != "admin" {
+		t.Errorf("expected the update to identify the record by both key columns, got %+v", membership)
+	}
+
+	err = ac.DeleteByID(ctx, "memberships", Key{"tenant_id", 0}, Key{"user_id", "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ac.DeleteByID(ctx, "memberships", Key{"tenant_id", 0}, Key{"user_id", "bob"})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting a deleted record, got %v", err)
+	}
+
+	var remaining int
+	err = db.QueryRow(`SELECT COUNT(*) FROM "memberships"`).Scan(&remaining)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remaining != 2 {
+		t.Errorf("expected a single record deleted, %d remain", remaining)
+	}
+}
+
+func TestPartialCompositeKey(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t, membershipTable, `INSERT INTO "memberships" VALUES (1, 'ann', 'owner'), (1, 'bob', 'viewer')`)
+	ac := New(db, WithDialect(SQLite))
+
+	_, err := GetByID[testMembership](ctx, ac, "memberships", Key{"tenant_id", 1})
+	if err == nil || !strings.Contains(err.Error(), "incomplete") || !strings.Contains(err.Error(), "user_id") {
+		t.Errorf("expected an error naming the missing user_id, got %v", err)
+	}
+
+	var role *string
+	_, err = GetByID[testMembership](ctx, ac, "memberships", Key{"tenant_id", 1}, Key{"user_id", role})
+	if err == nil || !strings.Contains(err.Error(), "nil") {
+		t.Errorf("expected a nil key value to be refused, got %v", err)
+	}
+
+	type pointerMembership struct {
+		TenantID *int64 `db:"tenant_id,pk"`
+		UserID   string `db:"user_id,pk"`
+		Role     string `db:"role"`
+	}
+	_, err = KeyOf(pointerMembership{UserID: "ann"})
+	if err == nil || !strings.Contains(err.Error(), "tenant_id") {
+		t.Errorf("expected the nil tenant_id to be reported as missing, got %v", err)
+	}
+	err = ac.UpdateStruct(ctx, "memberships", pointerMembership{UserID: "ann", Role: "admin"})
+	if err == nil || !strings.Contains(err.Error(), "tenant_id") {
+		t.Errorf("expected the nil tenant_id to be reported as missing, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Label returns a copy of the Assister whose failing statements return an *OperationError naming operation,
+// reading `operation "create_order" failed: ...`, so failures of generic SQL shared by several callers can be told apart & grouped.
+// The label is also written to the query logs. The original error is still matched by errors.Is & errors.As.
+// Errors reading a single row returned by QueryRow can't be labelled as they are only reported by its Scan
+/*
+
+Example:
+
+	err := Assister.Label("create_order").UpdateSingleRow(insertOrderStatement, orderId, customerId)
+	if err != nil {
+		return err
+	}
+
+	var opErr *sqlAssister.OperationError
+	if errors.As(err, &opErr) {
+		metrics.Failures.WithLabelValues(opErr.Operation).Inc()
+	}
+*/
+func (ac Assister) Label(operation string) *Assister {
+	ac.label = operation
+	return &ac
+}
+
+// labelQuerier wraps the errors of every statement executed on q in an *OperationError
+type labelQuerier struct {
+	q     querier
+	label string
+}
+
+func (q labelQuerier) wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &OperationError{Operation: q.label, Err: err}
+}
+
+func (q labelQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	result, err := q.q.ExecContext(ctx, query, args...)
+	return result, q.wrap(err)
+}
+
+func (q labelQuerier) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	stmt, err := q.q.PrepareContext(ctx, query)
+	return stmt, q.wrap(err)
+}
+
+func (q labelQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	rows, err := q.q.QueryContext(ctx, query, args...)
+	return rows, q.wrap(err)
+}
+
+func (q labelQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return q.q.QueryRowContext(ctx, query, args...)
+}
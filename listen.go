@@ -0,0 +1,123 @@
+package sqlAssister
+
+import (
+	"context"
+	"errors"
+)
+
+// NotificationWaiter blocks until a notification arrives on the driver connection of a connection listening with Listen,
+// returning its payload, or until ctx is done. database/sql has no notion of notifications, the waiter adapts the driver's own API.
+// With pgx's stdlib driver:
+//
+//	func(ctx context.Context, driverConn any) (string, error) {
+//		notification, err := driverConn.(*stdlib.Conn).Conn().WaitForNotification(ctx)
+//		if err != nil {
+//			return "", err
+//		}
+//		return notification.Payload, nil
+//	}
+//
+// lib/pq only delivers notifications to its own pq.Listener, which opens a connection outside the pool
+type NotificationWaiter func(ctx context.Context, driverConn any) (payload string, err error)
+
+// WithNotificationWaiter sets how Listen waits for notifications on its connection, see NotificationWaiter. Listen fails without one
+func WithNotificationWaiter(waiter NotificationWaiter) Option {
+	return func(ac *Assister) {
+		ac.notificationWaiter = waiter
+	}
+}
+
+// Listen pins a connection from the pool, runs `LISTEN channel` on it & sends the payload of every notification on the returned
+// channel until ctx is done, the statements being logged like any other. The returned channel is then closed & the connection
+// returned to the pool once it has stopped listening, or closed when that fails. An error waiting for a notification is logged &
+// also closes the channel, listen again to resume. Postgres only, see WithNotificationWaiter
+/*
+
+Example:
+
+	payloads, err := Assister.Listen(ctx, "books_changed")
+	if err != nil {
+		return err
+	}
+
+	for bookId := range payloads {
+		cache.Delete("book:" + bookId)
+	}
+*/
+func (ac Assister) Listen(ctx context.Context, channel string) (<-chan string, error) {
+	if ac.dialect != Postgres {
+		return nil, unsupportedDialectf("LISTEN is not supported on %s", ac.dialect)
+	}
+	if ac.transactionPooler {
+		return nil, unsupportedWithPooler("LISTEN", "listen on a direct connection to the database")
+	}
+	if ac.notificationWaiter == nil {
+		return nil, errors.New("listening requires a NotificationWaiter, see WithNotificationWaiter")
+	}
+	if ac.tx != nil {
+		return nil, errors.New("Listen called on an Assister bound to a transaction")
+	}
+
+	quoted := ac.QuoteIdentifier(channel)
+	payloads := make(chan string)
+	listening := make(chan error, 1)
+	go func() {
+		defer close(payloads)
+
+		listened := false
+		err := ac.WithConn(ctx, func(ca *ConnAssister) error {
+			_, err := ca.conn().ExecContext(ctx, "LISTEN "+quoted)
+			if err != nil {
+				return err
+			}
+			listened = true
+			listening <- nil
+
+			waitErr := ca.waitForNotifications(ctx, payloads)
+			// ctx is likely done by now, unlistening must run regardless
+			_, err = ca.conn().ExecContext(markContext(context.Background()), "UNLISTEN "+quoted)
+			if waitErr != nil {
+				return waitErr
+			}
+			return err
+		})
+		if !listened {
+			listening <- err
+			return
+		}
+		if err != nil && ctx.Err() == nil {
+			ac.getLogger().Printf("ERROR: listening on %s: %s", quoted, err)
+		}
+	}()
+
+	err := <-listening
+	if err != nil {
+		return nil, err
+	}
+
+	return payloads, nil
+}
+
+// waitForNotifications sends the payloads of the notifications arriving on the connection until ctx is done
+func (ca *ConnAssister) waitForNotifications(ctx context.Context, payloads chan<- string) error {
+	for {
+		var payload string
+		err := ca.Conn.Raw(func(driverConn any) error {
+			var err error
+			payload, err = ca.notificationWaiter(ctx, driverConn)
+			return err
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		select {
+		case payloads <- payload:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
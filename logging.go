@@ -0,0 +1,196 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Logger receives the Assister's query logs. *log.Logger satisfies it
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// WithLogger routes the Assister's query logs to logger instead of the standard library's default logger
+func WithLogger(logger Logger) Option {
+	return func(ac *Assister) {
+		ac.logger = logger
+	}
+}
+
+// WithQueryLogging logs every statement the Assister executes along with its redacted args
+func WithQueryLogging() Option {
+	return func(ac *Assister) {
+		ac.logQueries = true
+	}
+}
+
+// WithLogOnErrorOnly logs a statement along with its redacted args only when executing it fails.
+// Quiet on the happy path while every failure carries the exact query that caused it
+func WithLogOnErrorOnly() Option {
+	return func(ac *Assister) {
+		ac.logOnErrorOnly = true
+	}
+}
+
+// LogOnErrorOnly reports whether the Assister only logs statements that fail
+func (ac Assister) LogOnErrorOnly() bool {
+	return ac.logOnErrorOnly
+}
+
+// WithName names the Assister, e.g. "primary" or "analytics-replica", so the logs of a service with several DBs tell them apart.
+// Every line the Assister logs is prefixed with the name in brackets & Name returns it for the metrics & spans recorded around its calls
+/*
+
+Example:
+
+	replica := sqlAssister.New(replicaDB, sqlAssister.WithName("analytics-replica"), sqlAssister.WithLogOnErrorOnly())
+
+	// [analytics-replica] ERROR: pq: canceling statement due to statement timeout QUERY: SELECT ... ARGS: [<string>]
+*/
+func WithName(name string) Option {
+	return func(ac *Assister) {
+		ac.name = name
+	}
+}
+
+// WithSlowQueryThreshold logs the statements taking threshold or longer to execute along with how long they took & their redacted args,
+// whether or not other statements are logged. The time measured is the statement's execution until its first rows are returned,
+// not the reading of its rows
+func WithSlowQueryThreshold(threshold time.Duration) Option {
+	return func(ac *Assister) {
+		ac.slowQueryThreshold = threshold
+	}
+}
+
+// Name returns the name the Assister was given WithName, empty when it wasn't
+func (ac Assister) Name() string {
+	return ac.name
+}
+
+func (ac Assister) getLogger() Logger {
+	var logger Logger = log.Default()
+	if ac.logger != nil {
+		logger = ac.logger
+	}
+	if ac.name != "" {
+		logger = namedLogger{logger: logger, prefix: "[" + ac.name + "] "}
+	}
+
+	return logger
+}
+
+// namedLogger prefixes every line logged with the Assister's name, see WithName
+type namedLogger struct {
+	logger Logger
+	prefix string
+}
+
+func (l namedLogger) Printf(format string, v ...any) {
+	l.logger.Printf(l.prefix+"%s", fmt.Sprintf(format, v...))
+}
+
+// redactArgs describes args by type only so values such as passwords & personal data never reach the logs
+func redactArgs(args []any) string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == nil {
+			redacted[i] = "<nil>"
+			continue
+		}
+		redacted[i] = fmt.Sprintf("<%T>", arg)
+	}
+
+	return "[" + strings.Join(redacted, " ") + "]"
+}
+
+// loggingQuerier logs the statements executed on q according to the Assister's logging mode
+type loggingQuerier struct {
+	q              querier
+	logger         Logger
+	logOnErrorOnly bool
+	label          string
+}
+
+func (q loggingQuerier) log(query string, args []any, err error) {
+	operation := ""
+	if q.label != "" {
+		operation = "OPERATION: " + q.label + " "
+	}
+
+	switch {
+	case err != nil:
+		q.logger.Printf("ERROR: %s %sQUERY: %s ARGS: %s", err, operation, query, redactArgs(args))
+	case !q.logOnErrorOnly:
+		q.logger.Printf("%sQUERY: %s ARGS: %s", operation, query, redactArgs(args))
+	}
+}
+
+func (q loggingQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	result, err := q.q.ExecContext(ctx, query, args...)
+	q.log(query, args, err)
+	return result, err
+}
+
+func (q loggingQuerier) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	stmt, err := q.q.PrepareContext(ctx, query)
+	q.log(query, nil, err)
+	return stmt, err
+}
+
+func (q loggingQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	rows, err := q.q.QueryContext(ctx, query, args...)
+	q.log(query, args, err)
+	return rows, err
+}
+
+func (q loggingQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	row := q.q.QueryRowContext(ctx, query, args...)
+	q.log(query, args, row.Err())
+	return row
+}
+
+// slowQuerier logs the statements executed on q taking threshold or longer, see WithSlowQueryThreshold
+type slowQuerier struct {
+	q         querier
+	logger    Logger
+	threshold time.Duration
+	label     string
+	clock     Clock
+}
+
+func (q slowQuerier) log(start time.Time, query string, args []any) {
+	elapsed := q.clock.Now().Sub(start)
+	if elapsed < q.threshold {
+		return
+	}
+
+	operation := ""
+	if q.label != "" {
+		operation = "OPERATION: " + q.label + " "
+	}
+	q.logger.Printf("SLOW: %s %sQUERY: %s ARGS: %s", elapsed, operation, query, redactArgs(args))
+}
+
+func (q slowQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	defer q.log(q.clock.Now(), query, args)
+	return q.q.ExecContext(ctx, query, args...)
+}
+
+func (q slowQuerier) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	defer q.log(q.clock.Now(), query, nil)
+	return q.q.PrepareContext(ctx, query)
+}
+
+func (q slowQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	defer q.log(q.clock.Now(), query, args)
+	return q.q.QueryContext(ctx, query, args...)
+}
+
+func (q slowQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	defer q.log(q.clock.Now(), query, args)
+	return q.q.QueryRowContext(ctx, query, args...)
+}
@@ -0,0 +1,272 @@
+package sqlAssister
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// The struct mapper decides which column a struct field is read from & written to.
+// A field's column is taken from its `db` tag, or the snake_case form of the field name when untagged.
+// Fields tagged `db:"-"` & unexported fields are ignored. Anonymous embedded structs are flattened into their parent, except
+// an embedded struct already being flattened, as a struct embedding a pointer to itself does, & pointers to unexported struct
+// types, which can't be allocated when scanning.
+// Options may follow the column name in the tag, e.g. `db:"id,pk"`.
+// A named struct field, such as the author of a book read through a JOIN, is nested: it is read from the columns prefixed
+// with its own column & a dot, `a.name AS "author.name"`, & isn't written by the helpers generating INSERTs & UPDATEs.
+// A nested struct pointer is left nil when all of its columns are NULL, telling a LEFT JOIN's missing match from a match with empty fields
+
+type fieldInfo struct {
+	column  string
+	name    string
+	index   []int
+	typ     reflect.Type
+	options map[string]bool
+	// nullGroup is the index of the innermost nested struct pointer the field is read through, see scanGroup
+	nullGroup []int
+}
+
+type structInfo struct {
+	typ      reflect.Type
+	fields   []*fieldInfo
+	byColumn map[string]*fieldInfo
+	byName   map[string]*fieldInfo
+	// byFolded holds the fields by their lowercased column & Go field name, more than one field under a key is ambiguous
+	byFolded map[string][]*fieldInfo
+	// nested holds the nested struct fields by column, the prefix of the columns read into them
+	nested map[string]*fieldInfo
+}
+
+var structCache sync.Map
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// getStructInfo returns the cached field mapping of a struct type
+func getStructInfo(t reflect.Type) *structInfo {
+	if cached, ok := structCache.Load(t); ok {
+		return cached.(*structInfo)
+	}
+
+	info := &structInfo{
+		typ:      t,
+		byColumn: map[string]*fieldInfo{},
+		byName:   map[string]*fieldInfo{},
+		byFolded: map[string][]*fieldInfo{},
+		nested:   map[string]*fieldInfo{},
+	}
+	collectFields(info, t, nil, map[reflect.Type]bool{t: true})
+	for _, fi := range info.fields {
+		info.byName[fi.name] = fi
+		column := strings.ToLower(fi.column)
+		info.byFolded[column] = append(info.byFolded[column], fi)
+		if name := strings.ToLower(fi.name); name != column {
+			info.byFolded[name] = append(info.byFolded[name], fi)
+		}
+	}
+
+	cached, _ := structCache.LoadOrStore(t, info)
+	return cached.(*structInfo)
+}
+
+// collectFields maps the fields of t, flattening its anonymous embedded structs. embedding holds the structs being flattened
+func collectFields(info *structInfo, t reflect.Type, parentIndex []int, embedding map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, hasTag := field.Tag.Lookup("db")
+		if tag == "-" {
+			continue
+		}
+
+		index := make([]int, len(parentIndex)+1)
+		copy(index, parentIndex)
+		index[len(parentIndex)] = i
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && !hasTag && fieldType.Kind() == reflect.Struct && !isValueType(fieldType) {
+			if embedding[fieldType] || !field.IsExported() && field.Type.Kind() == reflect.Pointer {
+				continue
+			}
+			embedding[fieldType] = true
+			collectFields(info, fieldType, index, embedding)
+			delete(embedding, fieldType)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		column, options := parseTag(tag)
+		if column == "" {
+			column = toSnakeCase(field.Name)
+		}
+
+		fi := &fieldInfo{
+			column:  column,
+			name:    field.Name,
+			index:   index,
+			typ:     field.Type,
+			options: options,
+		}
+		if isNestedType(fieldType) {
+			info.nested[column] = fi
+			continue
+		}
+		if _, exists := info.byColumn[column]; exists {
+			continue
+		}
+		info.fields = append(info.fields, fi)
+		info.byColumn[column] = fi
+	}
+}
+
+// parseTag splits a `db` tag into the column name & its options
+func parseTag(tag string) (string, map[string]bool) {
+	parts := strings.Split(tag, ",")
+	options := map[string]bool{}
+	for _, opt := range parts[1:] {
+		opt = strings.TrimSpace(opt)
+		if opt != "" {
+			options[opt] = true
+		}
+	}
+
+	return strings.TrimSpace(parts[0]), options
+}
+
+// isValueType reports whether a struct type is scanned as a single column rather than mapped field by field
+func isValueType(t reflect.Type) bool {
+	if t == timeType {
+		return true
+	}
+	if _, ok := utils.LookupScanConverter(t); ok {
+		return true
+	}
+
+	return t.Implements(scannerType) || reflect.PointerTo(t).Implements(scannerType)
+}
+
+// isNestedType reports whether a named field of struct type t is a nested struct rather than a single column
+func isNestedType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !isValueType(t) && !t.Implements(valuerType) && !reflect.PointerTo(t).Implements(valuerType)
+}
+
+// toSnakeCase converts a Go field name such as UserID into its default column name user_id
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// lookup finds the field mapped to a result column, trying in order:
+//  1. the column exactly as mapped, by tag or default snake_case name
+//  2. the Go field name exactly, so a quoted "CamelCase" column finds its untagged field
+//  3. the column & Go field names case insensitively, an error when more than one field matches
+//  4. the column's snake_case form, so an alias such as AvgAmount finds avg_amount
+//  5. a nested struct field by the column's prefix up to the first dot, the rest of the column being looked up in the nested struct
+//
+// Matching doesn't depend on the quoting mode, the result columns are named however the database folded them
+func (info *structInfo) lookup(column string) (*fieldInfo, error) {
+	if fi, ok := info.byColumn[column]; ok {
+		return fi, nil
+	}
+
+	if fi, ok := info.byName[column]; ok {
+		return fi, nil
+	}
+
+	folded := info.byFolded[strings.ToLower(column)]
+	if len(folded) == 1 {
+		return folded[0], nil
+	}
+	if len(folded) > 1 {
+		names := make([]string, len(folded))
+		for i, fi := range folded {
+			names[i] = fi.name
+		}
+		return nil, fmt.Errorf("column %q matches several fields of %s case insensitively: %s, tag the intended field with its exact column name",
+			column, info.typ, strings.Join(names, ", "))
+	}
+
+	if fi, ok := info.byColumn[toSnakeCase(column)]; ok {
+		return fi, nil
+	}
+
+	if prefix, rest, ok := strings.Cut(column, "."); ok {
+		if nested, ok := info.nested[prefix]; ok {
+			return info.nestedLookup(nested, rest)
+		}
+	}
+
+	// Unaliased aggregates come back named after the expression, e.g. COUNT(*) or avg
+	if strings.ContainsAny(column, "()*") {
+		return nil, fmt.Errorf("column %q has no matching field in %s: alias computed columns with AS to match a field", column, info.typ)
+	}
+
+	return nil, fmt.Errorf("column %q has no matching field in %s", column, info.typ)
+}
+
+// nestedLookup finds the field of the nested struct field mapped to column, returning it with its index from info's struct
+func (info *structInfo) nestedLookup(nested *fieldInfo, column string) (*fieldInfo, error) {
+	t := nested.typ
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	inner, err := getStructInfo(t).lookup(column)
+	if err != nil {
+		return nil, fmt.Errorf("nested %s: %w", nested.name, err)
+	}
+
+	fi := *inner
+	fi.column = nested.column + "." + inner.column
+	fi.name = nested.name + "." + inner.name
+	fi.index = append(append([]int{}, nested.index...), inner.index...)
+	switch {
+	case inner.nullGroup != nil:
+		fi.nullGroup = append(append([]int{}, nested.index...), inner.nullGroup...)
+	case nested.typ.Kind() == reflect.Pointer:
+		fi.nullGroup = nested.index
+	}
+
+	return &fi, nil
+}
+
+// fieldByIndex returns the field at index, allocating nil embedded struct pointers on the way
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+
+	return v
+}
@@ -0,0 +1,156 @@
+package sqlAssister
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// InsertMap inserts a single record into table from a map of column to value. A nil value is inserted as NULL.
+// Columns are sorted so the generated SQL is the same for the same set of columns
+/*
+
+Example:
+
+	err := Assister.InsertMap(ctx, "books", map[string]any{
+		"id":   bookId,
+		"name": name,
+	})
+	if err != nil {
+		return err
+	}
+*/
+func (ac Assister) InsertMap(ctx context.Context, table string, values map[string]any) error {
+	insert, err := ac.insertMap(table, values)
+	if err != nil {
+		return err
+	}
+
+	results, err := insert.Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return ac.checkRowsAffected(results, 1)
+}
+
+// BuildInsertMap returns the statement & args InsertMap executes without executing it, to inspect, log or wrap it
+/*
+
+Example:
+
+	query, args, err := Assister.BuildInsertMap("books", map[string]any{"id": bookId, "name": name})
+	if err != nil {
+		return err
+	}
+*/
+func (ac Assister) BuildInsertMap(table string, values map[string]any) (query string, args []any, err error) {
+	insert, err := ac.insertMap(table, values)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return insert.SQL()
+}
+
+func (ac Assister) insertMap(table string, values map[string]any) (*InsertQuery, error) {
+	columns, err := mapColumns(table, values)
+	if err != nil {
+		return nil, err
+	}
+
+	insert := ac.Table(table).Insert()
+	for _, column := range columns {
+		insert.Set(column, values[column])
+	}
+
+	return insert, nil
+}
+
+// UpdateMap updates the single record in table where whereCol equals whereVal from a map of column to value. A nil value is set to NULL.
+// Columns are sorted so the generated SQL is the same for the same set of columns
+/*
+
+Example:
+
+	err := Assister.UpdateMap(ctx, "books", map[string]any{"name": name}, "id", bookId)
+	if err != nil {
+		return err
+	}
+*/
+func (ac Assister) UpdateMap(ctx context.Context, table string, values map[string]any, whereCol string, whereVal any) error {
+	update, err := ac.updateMap(table, values, whereCol, whereVal)
+	if err != nil {
+		return err
+	}
+
+	results, err := update.Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return ac.checkRowsAffected(results, 1)
+}
+
+// BuildUpdateMap returns the statement & args UpdateMap executes without executing it, to inspect, log or wrap it
+/*
+
+Example:
+
+	query, args, err := Assister.BuildUpdateMap("books", map[string]any{"name": name}, "id", bookId)
+	if err != nil {
+		return err
+	}
+*/
+func (ac Assister) BuildUpdateMap(table string, values map[string]any, whereCol string, whereVal any) (query string, args []any, err error) {
+	update, err := ac.updateMap(table, values, whereCol, whereVal)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return update.SQL()
+}
+
+func (ac Assister) updateMap(table string, values map[string]any, whereCol string, whereVal any) (*UpdateQuery, error) {
+	columns, err := mapColumns(table, values)
+	if err != nil {
+		return nil, err
+	}
+	err = utils.ValidateIdentifier(whereCol)
+	if err != nil {
+		return nil, err
+	}
+
+	update := ac.Table(table).Update().Where(Eq(whereCol, whereVal))
+	for _, column := range columns {
+		update.Set(column, values[column])
+	}
+
+	return update, nil
+}
+
+// mapColumns validates the table & the map's columns, returning the columns sorted
+func mapColumns(table string, values map[string]any) ([]string, error) {
+	err := utils.ValidateIdentifier(table)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, errors.New("no columns present: values map is empty")
+	}
+
+	columns := make([]string, 0, len(values))
+	for column := range values {
+		err := utils.ValidateIdentifier(column)
+		if err != nil {
+			return nil, fmt.Errorf("column: %w", err)
+		}
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	return columns, nil
+}
@@ -0,0 +1,238 @@
+package sqlAssister
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// DefaultMetadataTTL is how long TableMeta caches a table's metadata when WithMetadataTTL isn't set
+const DefaultMetadataTTL = 5 * time.Minute
+
+// TableMeta describes the columns of a table as the database reports them
+type TableMeta struct {
+	// Table is the name the metadata was read for
+	Table   string
+	Columns []ColumnMeta
+}
+
+// ColumnMeta describes a column of a table
+type ColumnMeta struct {
+	Name string
+	// DataType is the column's type as the database writes it, e.g. character varying(255) on Postgres, varchar(255) on MySQL
+	// & the declared type on SQLite
+	DataType   string
+	Nullable   bool
+	HasDefault bool
+	PrimaryKey bool
+}
+
+// Column returns the metadata of the column named name
+func (t TableMeta) Column(name string) (ColumnMeta, bool) {
+	for _, column := range t.Columns {
+		if column.Name == name {
+			return column, true
+		}
+	}
+
+	return ColumnMeta{}, false
+}
+
+// WithMetadataTTL sets how long TableMeta caches a table's metadata before reading it again, DefaultMetadataTTL by default.
+// A TTL of 0 or less turns the cache off, every call then reads the metadata from the database
+func WithMetadataTTL(ttl time.Duration) Option {
+	return func(ac *Assister) {
+		ac.metadataTTL = ttl
+		ac.metadataTTLSet = true
+	}
+}
+
+// metadataCache holds the TableMeta read for an Assister & its copies, by table
+type metadataCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]metadataEntry
+	// generation counts the invalidations, metadata read across one may predate the change & isn't cached
+	generation uint64
+}
+
+type metadataEntry struct {
+	meta    TableMeta
+	expires time.Time
+}
+
+// newMetadataCache returns the metadata cache of an Assister once its options are applied, nil when caching is off
+func (ac Assister) newMetadataCache() *metadataCache {
+	ttl := DefaultMetadataTTL
+	if ac.metadataTTLSet {
+		ttl = ac.metadataTTL
+	}
+	if ttl <= 0 {
+		return nil
+	}
+
+	return &metadataCache{ttl: ttl, entries: map[string]metadataEntry{}}
+}
+
+// get returns the cached metadata of table if it is fresh, along with the generation to put the metadata read instead with
+func (c *metadataCache) get(table string, now time.Time) (TableMeta, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[table]
+	if !ok || !now.Before(entry.expires) {
+		delete(c.entries, table)
+		return TableMeta{}, c.generation, false
+	}
+
+	return entry.meta, c.generation, true
+}
+
+// put caches meta unless the cache was invalidated since generation
+func (c *metadataCache) put(meta TableMeta, generation uint64, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	c.entries[meta.Table] = metadataEntry{meta: meta, expires: now.Add(c.ttl)}
+}
+
+// TableMeta returns the columns of table, in the order they are declared, from the Assister's cache when they were read less than
+// the metadata TTL ago & from the database otherwise, see WithMetadataTTL. The cache is shared by the Assister's copies &
+// transactions & is safe for concurrent use. Only metadata read successfully is cached: a missing table is looked up again on the
+// next call, & metadata read inside a transaction, which may hold uncommitted DDL, is never cached. EnsureTable invalidates the
+// whole cache, call InvalidateMetadata after changing the schema otherwise, e.g. after running migrations, or when the metadata
+// lacks a column the caller expects, to read it again rather than fail on a schema that may have changed since it was cached.
+// Postgres reads pg_attribute, resolving table along the search_path, MySQL reads information_schema.COLUMNS & SQLite
+// pragma_table_info. Returns ErrNotFound when MySQL or SQLite have no such table, Postgres reports its own error
+/*
+
+Example:
+
+	meta, err := Assister.TableMeta(ctx, "books")
+	if err != nil {
+		return err
+	}
+	if _, ok := meta.Column("isbn"); !ok {
+		return errors.New("books has no isbn column, run the migrations first")
+	}
+*/
+func (ac Assister) TableMeta(ctx context.Context, table string) (TableMeta, error) {
+	err := utils.ValidateIdentifier(table)
+	if err != nil {
+		return TableMeta{}, err
+	}
+
+	cache := ac.metadata
+	if ac.tx != nil {
+		cache = nil
+	}
+	var generation uint64
+	if cache != nil {
+		var meta TableMeta
+		var ok bool
+		meta, generation, ok = cache.get(table, ac.getClock().Now())
+		if ok {
+			return meta.copy(), nil
+		}
+	}
+
+	meta, err := ac.readTableMeta(ctx, table)
+	if err != nil {
+		return TableMeta{}, err
+	}
+	if cache != nil {
+		cache.put(meta.copy(), generation, ac.getClock().Now())
+	}
+
+	return meta, nil
+}
+
+// InvalidateMetadata drops the cached metadata of tables, or of every table when none are given, so TableMeta reads it again
+func (ac Assister) InvalidateMetadata(tables ...string) {
+	cache := ac.metadata
+	if cache == nil {
+		return
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.generation++
+	if len(tables) == 0 {
+		cache.entries = map[string]metadataEntry{}
+		return
+	}
+	for _, table := range tables {
+		delete(cache.entries, table)
+	}
+}
+
+// copy returns a TableMeta whose Columns can be modified without affecting the cache
+func (t TableMeta) copy() TableMeta {
+	t.Columns = append([]ColumnMeta{}, t.Columns...)
+	return t
+}
+
+// readTableMeta reads the metadata of table from the database
+func (ac Assister) readTableMeta(ctx context.Context, table string) (TableMeta, error) {
+	schema, name := "", table
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		schema, name = table[:i], table[i+1:]
+	}
+
+	var query string
+	var args []any
+	switch ac.dialect {
+	case Postgres:
+		query = `SELECT a.attname, format_type(a.atttypid, a.atttypmod), NOT a.attnotnull, a.atthasdef,
+				COALESCE(a.attnum = ANY(i.indkey), false)
+			FROM pg_attribute a
+			LEFT JOIN pg_index i ON i.indrelid = a.attrelid AND i.indisprimary
+			WHERE a.attrelid = $1::regclass AND a.attnum > 0 AND NOT a.attisdropped
+			ORDER BY a.attnum`
+		args = []any{ac.QuoteIdentifier(table)}
+	case MySQL:
+		query = "SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE = 'YES', COLUMN_DEFAULT IS NOT NULL, COLUMN_KEY = 'PRI' " +
+			"FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND TABLE_NAME = ? " +
+			"ORDER BY ORDINAL_POSITION"
+		args = []any{schema, name}
+	case SQLite:
+		query = `SELECT "name", "type", "notnull" = 0, "dflt_value" IS NOT NULL, "pk" > 0 ` +
+			`FROM pragma_table_info(?, COALESCE(NULLIF(?, ''), 'main')) ORDER BY "cid"`
+		args = []any{name, schema}
+	default:
+		return TableMeta{}, unsupportedDialectf("reading table metadata is not supported on %s", ac.dialect)
+	}
+
+	rows, err := ac.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return TableMeta{}, err
+	}
+	defer rows.Close()
+
+	meta := TableMeta{Table: table}
+	for rows.Next() {
+		var column ColumnMeta
+		err = rows.Scan(&column.Name, &column.DataType, &column.Nullable, &column.HasDefault, &column.PrimaryKey)
+		if err != nil {
+			return TableMeta{}, err
+		}
+		meta.Columns = append(meta.Columns, column)
+	}
+	err = rows.Err()
+	if err != nil {
+		return TableMeta{}, err
+	}
+	if len(meta.Columns) == 0 {
+		return TableMeta{}, fmt.Errorf("table %q: %w", table, ErrNotFound)
+	}
+
+	return meta, nil
+}
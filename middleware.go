@@ -0,0 +1,103 @@
+package sqlAssister
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// txContextKey is the context key of the transaction TxMiddleware runs a request in
+type txContextKey struct{}
+
+// errServerErrorResponse rolls back the transaction of a request the handler responded to with a 5xx status
+var errServerErrorResponse = errors.New("handler responded with a server error")
+
+// TxMiddleware runs every request handled by next in a transaction of its own, a unit of work per request, that the handlers
+// retrieve with TxFromContext. The transaction commits once next returns, unless it responded with a 5xx status or panicked,
+// which roll it back, the panic being re-raised for the server to handle. It is begun with the request's context, so a client
+// going away part way through rolls it back too, see WithTransaction. A transaction that can't begin is answered with a 500.
+// The response isn't buffered: a commit failing after next wrote a successful response can't change it & is only logged
+// as an error with the Assister's logger, handlers that must report it should run their own transaction with WithTransaction
+/*
+
+Example:
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orders", placeOrder)
+	http.ListenAndServe(":8080", Assister.TxMiddleware(mux))
+
+	func placeOrder(w http.ResponseWriter, r *http.Request) {
+		tx, _ := sqlAssister.TxFromContext(r.Context())
+		err := tx.UpdateSingleRow(insertOrderStatement, orderID, customerID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}
+*/
+func (ac Assister) TxMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		began := false
+		err := ac.WithTransaction(r.Context(), func(tx *TxAssister) error {
+			began = true
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), txContextKey{}, tx)))
+			if recorder.status >= http.StatusInternalServerError {
+				return errServerErrorResponse
+			}
+
+			return nil
+		})
+		if err == nil || errors.Is(err, errServerErrorResponse) {
+			return
+		}
+
+		if !began {
+			ac.getLogger().Printf("ERROR: beginning the transaction of %s %s: %v", r.Method, r.URL.Path, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		ac.getLogger().Printf("ERROR: committing the transaction of %s %s: %v", r.Method, r.URL.Path, err)
+	})
+}
+
+// TxFromContext returns the transaction TxMiddleware runs the request of ctx in, reporting false outside of TxMiddleware
+func TxFromContext(ctx context.Context) (*TxAssister, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(*TxAssister)
+	return tx, ok
+}
+
+// statusRecorder records the status of the response written through it
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	// Informational statuses, such as 103 Early Hints, precede the final one
+	if !r.wroteHeader && status >= http.StatusOK {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush flushes the response when the underlying ResponseWriter supports it, for handlers streaming their response
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		r.wroteHeader = true
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter, for http.ResponseController from Go 1.20
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,77 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// MultiRows holds the result sets of a query returning more than one, such as a stored procedure returning data & a summary
+type MultiRows struct {
+	rows *sql.Rows
+	ac   *Assister
+}
+
+// QueryMultiple Executes Read operation returning several result sets.
+// The first result set is current, scan it with ScanResultSet & advance to the next with NextResultSet.
+// The MultiRows must be closed once done with
+/*
+
+Example:
+
+	results, err := Assister.QueryMultiple(ctx, `CALL "order_report"($1)`, customerId)
+	if err != nil {
+		return err
+	}
+	defer results.Close()
+
+	orders, err := sqlAssister.ScanResultSet[Order](results)
+	if err != nil {
+		return err
+	}
+
+	if !results.NextResultSet() {
+		return results.Err()
+	}
+	summary, err := sqlAssister.ScanResultSet[Summary](results)
+*/
+func (ac Assister) QueryMultiple(ctx context.Context, query string, args ...any) (*MultiRows, error) {
+	err := utils.QueryChecker(query, ac.queryChecks()...)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ac.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MultiRows{rows: rows, ac: &ac}, nil
+}
+
+// NextResultSet advances to the next result set, reporting false when there are no more or advancing failed, see Err
+func (m *MultiRows) NextResultSet() bool {
+	return m.rows.NextResultSet()
+}
+
+// Rows returns the underlying rows for scanning the current result set by hand
+func (m *MultiRows) Rows() *sql.Rows {
+	return m.rows
+}
+
+// Err returns the error, if any, encountered while advancing through the result sets
+func (m *MultiRows) Err() error {
+	return m.rows.Err()
+}
+
+// Close closes the result sets, releasing the connection
+func (m *MultiRows) Close() error {
+	return m.rows.Close()
+}
+
+// ScanResultSet scans every record of the current result set into a T following the same rules as Select.
+// The MultiRows is left open so the following result sets can be scanned
+func ScanResultSet[T any](m *MultiRows) ([]T, error) {
+	return scanResultSet[T](m.ac, m.rows, false, false)
+}
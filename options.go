@@ -0,0 +1,50 @@
+package sqlAssister
+
+import "github.com/zobstory/sqlAssister/utils"
+
+// Option configures an Assister when passed to New
+type Option func(*Assister)
+
+// WithDialect sets the SQL dialect used when the Assister generates SQL. Defaults to Postgres
+func WithDialect(dialect Dialect) Option {
+	return func(ac *Assister) {
+		ac.dialect = dialect
+	}
+}
+
+// WithStrictRowsAffected makes rows affected checks fail when the driver can't report rows affected (an error or -1 from RowsAffected())
+// instead of skipping the check, which is noted with the Assister's logger. See utils.CheckRowsAffected for the driver quirks involved
+func WithStrictRowsAffected() Option {
+	return func(ac *Assister) {
+		ac.strictRows = true
+	}
+}
+
+// WithAutoLimit appends LIMIT limit to the SELECTs run by the Assister's multi record read methods (Select, Fetch, SelectColumn,
+// Iter, MultipleRowScanner, ...) that don't already limit their results, as a guardrail against accidental full table scans,
+// e.g. in an interactive query console. Single record reads & Paginate aren't affected. Off by default, see utils.EnsureLimit
+func WithAutoLimit(limit int) Option {
+	return func(ac *Assister) {
+		ac.autoLimit = limit
+	}
+}
+
+// WithBalanceCheck makes the Assister check the quotes & parentheses of the queries it is given are balanced before sending them,
+// failing fast with an error wrapping ErrUnbalanced on SQL mangled by string concatenation. Off by default as it is
+// a heuristic rather than a parser, see utils.CheckBalanced. SQL generated by the Assister itself isn't checked
+func WithBalanceCheck() Option {
+	return func(ac *Assister) {
+		ac.balanceCheck = true
+	}
+}
+
+// queryChecks returns the optional checks utils.QueryChecker runs on the queries the Assister is given
+func (ac Assister) queryChecks() []utils.QueryCheck {
+	if !ac.balanceCheck {
+		return nil
+	}
+
+	return []utils.QueryCheck{func(query string) error {
+		return utils.CheckBalanced(ac.dialect, query)
+	}}
+}
@@ -0,0 +1,62 @@
+package sqlAssister
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SafeOrderBy turns user supplied sort keys into an ORDER BY list using only vetted column expressions.
+// allowed maps each user facing key to the column expression it sorts by, the expression is trusted & used as is.
+// input is a comma separated list of keys, each optionally followed by asc or desc or prefixed with - for descending.
+// Any key not in allowed is rejected so untrusted input never reaches the query
+/*
+
+Example:
+
+	allowed := map[string]string{
+		"name":    `"name"`,
+		"created": `"created_at"`,
+	}
+
+	orderBy, err := sqlAssister.SafeOrderBy(r.URL.Query().Get("sort"), allowed) // "-created" -> "created_at" DESC
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := Assister.MultipleRowScanner(`SELECT "id", "name" FROM "books" ORDER BY ` + orderBy)
+*/
+func SafeOrderBy(input string, allowed map[string]string) (string, error) {
+	if strings.TrimSpace(input) == "" {
+		return "", fmt.Errorf("no sort key present")
+	}
+
+	terms := strings.Split(input, ",")
+	rendered := make([]string, len(terms))
+	for i, term := range terms {
+		parts := strings.Fields(term)
+		if len(parts) == 0 || len(parts) > 2 {
+			return "", fmt.Errorf("invalid sort term %q", term)
+		}
+
+		key := parts[0]
+		direction := "ASC"
+		if strings.HasPrefix(key, "-") && len(parts) == 1 {
+			key = key[1:]
+			direction = "DESC"
+		}
+		if len(parts) == 2 {
+			direction = strings.ToUpper(parts[1])
+			if direction != "ASC" && direction != "DESC" {
+				return "", fmt.Errorf("invalid sort direction %q", parts[1])
+			}
+		}
+
+		expression, ok := allowed[key]
+		if !ok {
+			return "", fmt.Errorf("sort key %q is not allowed", key)
+		}
+		rendered[i] = expression + " " + direction
+	}
+
+	return strings.Join(rendered, ", "), nil
+}
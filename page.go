@@ -0,0 +1,185 @@
+package sqlAssister
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// Page is a page of records shaped as list endpoints return them, ready to be marshalled to JSON:
+//
+//	{"items": [...], "total": 42, "has_next": true, "next_cursor": "..."}
+//
+// Items is never nil so an empty page marshals as []. Total is omitted when it wasn't counted, see KeysetRequest.WithTotal,
+// & NextCursor when there is no next page
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int64  `json:"total,omitempty"`
+	HasNext    bool   `json:"has_next"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// PaginatePage returns the page of records Paginate reads as a Page, with the total always counted.
+// Its NextCursor is the number of the next page, to be parsed back into PageRequest.Page
+/*
+
+Example:
+
+	page, err := sqlAssister.PaginatePage[Book](ctx, Assister, `SELECT "id", "name" FROM "books" ORDER BY "id"`, sqlAssister.PageRequest{Page: 2, PageSize: 20})
+	if err != nil {
+		return err
+	}
+	json.NewEncoder(w).Encode(page)
+*/
+func PaginatePage[T any](ctx context.Context, ac *Assister, query string, page PageRequest, args ...any) (Page[T], error) {
+	items, total, err := Paginate[T](ctx, ac, query, page, args...)
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	result := Page[T]{Items: items, Total: total, HasNext: int64(page.Page)*int64(page.PageSize) < total}
+	if result.Items == nil {
+		result.Items = []T{}
+	}
+	if result.HasNext {
+		result.NextCursor = strconv.Itoa(page.Page + 1)
+	}
+
+	return result, nil
+}
+
+// KeysetRequest describes which page of a query's results PaginateKeyset returns
+type KeysetRequest struct {
+	// Column is the column the records are ordered by, it must be unique & never NULL, typically the primary key,
+	// & be mapped to a field of T the cursor is read from
+	Column string
+	// Descending orders the records from the greatest Column down
+	Descending bool
+	// Cursor is the NextCursor of the previous page, empty for the first page
+	Cursor string
+	// PageSize is the maximum number of records on a page
+	PageSize int
+	// WithTotal also counts every record the query matches, costing a COUNT query that scans them all
+	WithTotal bool
+}
+
+// PaginateKeyset returns a page of records ordered by a unique column, reading the records after the previous page's last
+// rather than skipping an OFFSET, so a page costs the same however deep it is & records inserted meanwhile neither repeat
+// nor go missing. The query must have no ORDER BY, LIMIT or OFFSET of its own: it is wrapped as a subselect, filtered past
+// the cursor, ordered by Column & limited to a record more than the page size, which tells whether there is a next page.
+// The NextCursor is opaque, encoding the value of Column of the page's last record
+/*
+
+Example:
+
+	page, err := sqlAssister.PaginateKeyset[Book](ctx, Assister, `SELECT "id", "name" FROM "books" WHERE "author_id" = $1`,
+		sqlAssister.KeysetRequest{Column: "id", Cursor: r.URL.Query().Get("cursor"), PageSize: 20}, authorId)
+	if err != nil {
+		return err
+	}
+	json.NewEncoder(w).Encode(page)
+*/
+func PaginateKeyset[T any](ctx context.Context, ac *Assister, query string, req KeysetRequest, args ...any) (Page[T], error) {
+	err := utils.QueryChecker(query, ac.queryChecks()...)
+	if err != nil {
+		return Page[T]{}, err
+	}
+	if req.PageSize < 1 {
+		return Page[T]{}, errors.New("page size must be at least 1")
+	}
+	info := structInfoOf[T]()
+	if info == nil {
+		var zero T
+		return Page[T]{}, fmt.Errorf("keyset pagination reads its cursor from a struct field, %T isn't a struct", zero)
+	}
+	fi, err := info.lookup(req.Column)
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	query = strings.TrimRight(strings.TrimSpace(query), ";")
+	column := ac.QuoteIdentifier(req.Column)
+	comparison, order := ">", "ASC"
+	if req.Descending {
+		comparison, order = "<", "DESC"
+	}
+
+	pageQuery := "SELECT * FROM (" + query + ") AS keyset"
+	pageArgs := append([]any{}, args...)
+	if req.Cursor != "" {
+		after, err := decodeKeysetCursor(req.Cursor, fi)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		arg, err := ac.fieldArg(fi, after)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		pageArgs = append(pageArgs, arg)
+		pageQuery += " WHERE " + column + " " + comparison + " " + ac.dialect.Placeholder(len(pageArgs))
+	}
+	pageQuery += " ORDER BY " + column + " " + order + " LIMIT " + strconv.Itoa(req.PageSize+1)
+
+	items, err := Select[T](ctx, ac, pageQuery, pageArgs...)
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	result := Page[T]{Items: items}
+	if result.Items == nil {
+		result.Items = []T{}
+	}
+	if len(items) > req.PageSize {
+		result.Items = items[:req.PageSize]
+		result.HasNext = true
+		result.NextCursor, err = encodeKeysetCursor(result.Items[req.PageSize-1], fi)
+		if err != nil {
+			return Page[T]{}, err
+		}
+	}
+	if req.WithTotal {
+		result.Total, err = countRows(ctx, ac, query, args)
+		if err != nil {
+			return Page[T]{}, err
+		}
+	}
+
+	return result, nil
+}
+
+// encodeKeysetCursor encodes the value of the field fi of item as an opaque, URL safe cursor
+func encodeKeysetCursor(item any, fi *fieldInfo) (string, error) {
+	value, _, err := structValue(item)
+	if err != nil {
+		return "", err
+	}
+	encoded, err := json.Marshal(fieldValue(value, fi).Interface())
+	if err != nil {
+		return "", fmt.Errorf("encode cursor from %q: %w", fi.column, err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(encoded), nil
+}
+
+// decodeKeysetCursor decodes a cursor made by encodeKeysetCursor into a value of the type of the field fi
+func decodeKeysetCursor(cursor string, fi *fieldInfo) (reflect.Value, error) {
+	encoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("malformed cursor %q", cursor)
+	}
+
+	value := reflect.New(fi.typ)
+	err = json.Unmarshal(encoded, value.Interface())
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("malformed cursor %q: %w", cursor, err)
+	}
+
+	return value.Elem(), nil
+}
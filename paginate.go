@@ -0,0 +1,126 @@
+package sqlAssister
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// windowCountColumn is the column the window function total is selected into
+const windowCountColumn = "sql_assister_total_count"
+
+// PageRequest describes which page of a query's results to return
+type PageRequest struct {
+	// Page is the 1 based page number
+	Page int
+	// PageSize is the maximum number of records on a page
+	PageSize int
+	// WindowCount fetches the total with a COUNT(*) OVER() column in the same query instead of a separate COUNT query.
+	// This saves a round trip & keeps the total consistent with the page under concurrent writes.
+	// It requires the query to be wrappable as a subselect: SELECT ..., COUNT(*) OVER() FROM (query) LIMIT ... OFFSET ...,
+	// whose ORDER BY Postgres & SQLite keep through the subselect. MySQL ignores the ORDER BY of a subselect without a LIMIT,
+	// returning pages in any order, so WindowCount fails there with ErrUnsupportedDialect
+	WindowCount bool
+}
+
+// Paginate Executes Read operation on a single page of records & scans them into a slice of T, see Select.
+// Returns the page along with the total number of records the query matches.
+// The query must not contain its own LIMIT or OFFSET & should contain an ORDER BY so pages are stable
+/*
+
+Example:
+
+	books, total, err := sqlAssister.Paginate[Book](ctx, Assister, `SELECT "id", "name" FROM "books" ORDER BY "id"`, sqlAssister.PageRequest{Page: 2, PageSize: 20})
+	if err != nil {
+		return nil, err
+	}
+*/
+func Paginate[T any](ctx context.Context, ac *Assister, query string, page PageRequest, args ...any) ([]T, int64, error) {
+	err := utils.QueryChecker(query, ac.queryChecks()...)
+	if err != nil {
+		return nil, 0, err
+	}
+	if page.Page < 1 || page.PageSize < 1 {
+		return nil, 0, errors.New("page & page size must be at least 1")
+	}
+
+	query = strings.TrimRight(strings.TrimSpace(query), ";")
+	limit := " LIMIT " + strconv.Itoa(page.PageSize) + " OFFSET " + strconv.Itoa((page.Page-1)*page.PageSize)
+
+	if page.WindowCount {
+		if ac.dialect == MySQL {
+			return nil, 0, unsupportedDialectf("WindowCount pagination is not supported on %s, which ignores the ORDER BY of the "+
+				"subselect, leave WindowCount unset to count with a separate query", ac.dialect)
+		}
+		items, total, err := paginateWindowCount[T](ctx, ac, query, limit, args)
+		if err != nil {
+			return nil, 0, err
+		}
+		// Past the last page there is no row to read the total from
+		if len(items) > 0 || page.Page == 1 {
+			return items, total, nil
+		}
+		total, err = countRows(ctx, ac, query, args)
+		return items, total, err
+	}
+
+	total, err := countRows(ctx, ac, query, args)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items, err := Select[T](ctx, ac, query+limit, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return items, total, nil
+}
+
+func paginateWindowCount[T any](ctx context.Context, ac *Assister, query, limit string, args []any) ([]T, int64, error) {
+	windowQuery := "SELECT paginated.*, COUNT(*) OVER() AS " + windowCountColumn + " FROM (" + query + ") AS paginated" + limit
+	rows, err := ac.conn().QueryContext(ctx, windowQuery, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, 0, err
+	}
+	plan, err := newScanPlan[T](ac, columns[:len(columns)-1])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var items []T
+	var total int64
+	for rows.Next() {
+		item, err := plan.scan(rows, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return items, total, nil
+}
+
+func countRows(ctx context.Context, ac *Assister, query string, args []any) (int64, error) {
+	var total int64
+	err := ac.conn().QueryRowContext(ctx, "SELECT COUNT(*) FROM ("+query+") AS counted", args...).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
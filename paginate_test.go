@@ -0,0 +1,69 @@
+package sqlAssister
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestPaginateWindowCount(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openTestDB(t, bookTable), WithDialect(SQLite))
+	// The names sort against the ids, so pages read in id order would come out wrong
+	for i := 1; i <= 25; i++ {
+		_, err := ac.DB.Exec(`INSERT INTO "books" ("id", "name", "stock") VALUES (?, ?, ?)`, i, fmt.Sprintf("book %02d", 26-i), i%4)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		args  []any
+		total int64
+		sizes []int
+	}{
+		{"every book", `SELECT * FROM "books" ORDER BY "name"`, nil, 25, []int{10, 10, 5, 0}},
+		{"in stock", `SELECT * FROM "books" WHERE "stock" > ? ORDER BY "stock" DESC, "name";`, []any{0}, 19, []int{10, 9, 0, 0}},
+		{"none", `SELECT * FROM "books" WHERE "stock" > ? ORDER BY "name"`, []any{3}, 0, []int{0, 0}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			all, err := Select[testBook](ctx, ac, test.query, test.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// First page, last page & past the last page, both ways of counting agreeing with each other & the query
+			for i, size := range test.sizes {
+				page := i + 1
+				counted, countedTotal, err := Paginate[testBook](ctx, ac, test.query, PageRequest{Page: page, PageSize: 10}, test.args...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				windowed, windowTotal, err := Paginate[testBook](ctx, ac, test.query, PageRequest{Page: page, PageSize: 10, WindowCount: true}, test.args...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if countedTotal != test.total || windowTotal != test.total {
+					t.Errorf("page %d: expected a total of %d, got %d counting & %d with the window", page, test.total, countedTotal, windowTotal)
+				}
+				if len(windowed) != size || !reflect.DeepEqual(windowed, counted) {
+					t.Errorf("page %d: expected the window to read the %d records of the COUNT path %v, got %v", page, size, counted, windowed)
+				}
+				if start := i * 10; size > 0 && !reflect.DeepEqual(windowed, all[start:start+size]) {
+					t.Errorf("page %d: expected the records in the query's order %v, got %v", page, all[start:start+size], windowed)
+				}
+			}
+		})
+	}
+
+	_, _, err := Paginate[testBook](ctx, New(ac.DB, WithDialect(MySQL)), `SELECT * FROM "books" ORDER BY "name"`,
+		PageRequest{Page: 1, PageSize: 10, WindowCount: true})
+	if !errors.Is(err, ErrUnsupportedDialect) {
+		t.Errorf("expected WindowCount refused on MySQL, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// WithTransactionPooler adapts the Assister to a transaction pooler such as pgbouncer in transaction mode, which hands the server
+// connection to another client after every transaction, & after every statement run outside one. Session state set by a statement
+// is then seen by other clients' statements rather than the Assister's next, so the Assister refuses what relies on it with
+// ErrUnsupportedWithPooler:
+//   - statements changing the session, SET (use SET LOCAL inside a transaction), RESET, DISCARD, LISTEN & PREPARE,
+//     see utils.IsSessionStatement
+//   - preparing a statement outside of a transaction, Warmup prepares each query in a transaction it rolls back instead
+//   - Listen & WithConn, as pinning a client connection doesn't pin a server connection
+//   - NewFromDSN with a connection setup, see WithConnectionSetup. The application_name isn't set either
+//
+// With pgx's stdlib driver NewFromDSN makes pgx use the simple protocol, adding default_query_exec_mode=simple_protocol to the DSN
+// unless it sets a mode of its own, as pgx otherwise caches named prepared statements. lib/pq only uses unnamed statements which
+// pgbouncer supports. The pooler can't be detected reliably from a client connection, set the option wherever one is deployed
+/*
+
+Example:
+
+	Assister, err := sqlAssister.NewFromDSN("pgx", "postgres://app@pgbouncer:6432/orders", sqlAssister.WithTransactionPooler())
+	if err != nil {
+		log.Fatal(err)
+	}
+*/
+func WithTransactionPooler() Option {
+	return func(ac *Assister) {
+		ac.transactionPooler = true
+	}
+}
+
+// poolerDSN returns dsn with the settings driverName needs behind a transaction pooler, see WithTransactionPooler
+func poolerDSN(driverName string, dsn string) string {
+	if driverName != "pgx" && driverName != "pgx/v5" || strings.Contains(dsn, "default_query_exec_mode") {
+		return dsn
+	}
+
+	const simpleProtocol = "default_query_exec_mode=simple_protocol"
+	if !strings.Contains(dsn, "://") {
+		return strings.TrimSpace(dsn + " " + simpleProtocol)
+	}
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + simpleProtocol
+	}
+	return dsn + "?" + simpleProtocol
+}
+
+// unsupportedWithPooler returns the error refusing what needs session state behind a transaction pooler
+func unsupportedWithPooler(what string, instead string) error {
+	return fmt.Errorf("%s: %w, %s", what, ErrUnsupportedWithPooler, instead)
+}
+
+// poolerQuerier refuses the statements changing session state & the statements prepared outside of a transaction,
+// see WithTransactionPooler
+type poolerQuerier struct {
+	q    querier
+	inTx bool
+}
+
+// check refuses query when it changes the session's state
+func (q poolerQuerier) check(query string) error {
+	if utils.IsSessionStatement(query) {
+		return unsupportedWithPooler("statement changing the session", "use SET LOCAL inside a transaction")
+	}
+
+	return nil
+}
+
+func (q poolerQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	err := q.check(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.q.ExecContext(ctx, query, args...)
+}
+
+// PrepareContext refuses to prepare outside of a transaction, where the statement would be prepared on a server connection the
+// pooler may not hand back for its execution
+func (q poolerQuerier) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	if !q.inTx {
+		return nil, unsupportedWithPooler("preparing a statement outside of a transaction", "prepare it inside one")
+	}
+	err := q.check(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.q.PrepareContext(ctx, query)
+}
+
+func (q poolerQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	err := q.check(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.q.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext can't return an error, a session statement run through it isn't refused. None returns a row
+func (q poolerQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return q.q.QueryRowContext(ctx, query, args...)
+}
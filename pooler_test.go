@@ -0,0 +1,312 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// The pooler simulation stands in for pgbouncer in transaction mode on top of SQLite: every statement run outside a transaction,
+// & every transaction, gets a server connection of its own, so a statement prepared outside a transaction is gone by the time
+// it is executed, failing as pgbouncer's would
+func init() {
+	sql.Register("sqlite3_pooler", poolerDriver{})
+}
+
+type poolerDriver struct{}
+
+func (poolerDriver) Open(name string) (driver.Conn, error) {
+	conn, err := (&sqlite3.SQLiteDriver{}).Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pooledConn{SQLiteConn: conn.(*sqlite3.SQLiteConn)}, nil
+}
+
+// pooledConn is a client connection to the pooler, server counting the server connections it was handed
+type pooledConn struct {
+	*sqlite3.SQLiteConn
+	mu       sync.Mutex
+	server   int
+	inTx     bool
+	prepared int
+}
+
+// released hands the server connection back to the pooler unless a transaction holds it
+func (c *pooledConn) released() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.inTx {
+		c.server++
+	}
+}
+
+func (c *pooledConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *pooledConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := c.SQLiteConn.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.prepared++
+	prepared := &pooledStmt{Stmt: stmt, conn: c, name: fmt.Sprintf("S_%d", c.prepared), server: c.server}
+	c.mu.Unlock()
+	c.released()
+
+	return prepared, nil
+}
+
+func (c *pooledConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	defer c.released()
+	return c.SQLiteConn.ExecContext(ctx, query, args)
+}
+
+func (c *pooledConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	defer c.released()
+	return c.SQLiteConn.QueryContext(ctx, query, args)
+}
+
+func (c *pooledConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	tx, err := c.SQLiteConn.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.inTx = true
+	c.mu.Unlock()
+
+	return pooledTx{Tx: tx, conn: c}, nil
+}
+
+type pooledTx struct {
+	driver.Tx
+	conn *pooledConn
+}
+
+func (tx pooledTx) end(err error) error {
+	tx.conn.mu.Lock()
+	tx.conn.inTx = false
+	tx.conn.mu.Unlock()
+	tx.conn.released()
+	return err
+}
+
+func (tx pooledTx) Commit() error {
+	return tx.end(tx.Tx.Commit())
+}
+
+func (tx pooledTx) Rollback() error {
+	return tx.end(tx.Tx.Rollback())
+}
+
+// pooledStmt is a named prepared statement, existing only on the server connection it was prepared on
+type pooledStmt struct {
+	driver.Stmt
+	conn   *pooledConn
+	name   string
+	server int
+}
+
+func (s *pooledStmt) check() error {
+	s.conn.mu.Lock()
+	defer s.conn.mu.Unlock()
+	if s.conn.server != s.server {
+		return fmt.Errorf("prepared statement %q does not exist", s.name)
+	}
+
+	return nil
+}
+
+func (s *pooledStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	err := s.check()
+	if err != nil {
+		return nil, err
+	}
+	defer s.conn.released()
+	return s.Stmt.(driver.StmtExecContext).ExecContext(ctx, args)
+}
+
+func (s *pooledStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	err := s.check()
+	if err != nil {
+		return nil, err
+	}
+	defer s.conn.released()
+	return s.Stmt.(driver.StmtQueryContext).QueryContext(ctx, args)
+}
+
+// openPoolerDB opens a database behind the pooler simulation, with a single client connection so every statement shares it
+func openPoolerDB(t *testing.T, statements ...string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3_pooler", "file:"+filepath.Join(t.TempDir(), "pooler.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+
+	for _, statement := range statements {
+		_, err = db.Exec(statement)
+		if err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+
+	return db
+}
+
+// TestPoolerSimulation checks the simulation fails a named prepared statement outside of a transaction,
+// so the tests run through it prove WithTransactionPooler keeps clear of them
+func TestPoolerSimulation(t *testing.T) {
+	ctx := context.Background()
+	db := openPoolerDB(t, bookTable)
+
+	stmt, err := db.PrepareContext(ctx, insertBook)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	_, err = stmt.ExecContext(ctx, "Dune")
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("expected the prepared statement gone from the server connection, got %v", err)
+	}
+
+	// Prepared & executed in a transaction it is on the same server connection
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	stmt, err = tx.PrepareContext(ctx, insertBook)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Dune", "Emma"} {
+		_, err = stmt.ExecContext(ctx, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTransactionPooler(t *testing.T) {
+	ctx := context.Background()
+	queries := NewQueryRegistry()
+	queries.Register("book", `SELECT "id", "name" FROM "books" WHERE "id" = ?`)
+	queries.Register("stock", `SELECT SUM("stock") FROM "books"`)
+	db := openPoolerDB(t, bookTable)
+
+	// Without the mode statements are prepared outside of a transaction, on server connections the pooler then takes back:
+	// Warmup, closing them right away, doesn't notice but executing one fails
+	err := New(db, WithDialect(SQLite), WithQueryRegistry(queries)).Warmup(ctx)
+	if err != nil {
+		t.Fatalf("expected Warmup to prepare & close the statements, got %v", err)
+	}
+	stmt, err := New(db, WithDialect(SQLite)).conn().PrepareContext(ctx, `SELECT "name" FROM "books"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	_, err = stmt.QueryContext(ctx)
+	if err == nil {
+		t.Fatal("expected a statement prepared outside of a transaction to fail behind the pooler")
+	}
+
+	ac := New(db, WithDialect(SQLite), WithQueryRegistry(queries), WithTransactionPooler())
+	err = ac.Warmup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ac.conn().PrepareContext(ctx, `SELECT "name" FROM "books"`)
+	if !errors.Is(err, ErrUnsupportedWithPooler) {
+		t.Errorf("expected a prepare outside of a transaction refused, got %v", err)
+	}
+
+	// The helpers run through the simulation without preparing a named statement
+	_, err = InsertAll(ctx, ac, "books", []testBook{{ID: 1, Name: "Dune", Stock: 2}, {ID: 2, Name: "Emma", Stock: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ac.UpdateStructFields(ctx, "books", testBook{ID: 1, Stock: 5}, []string{"Stock"}, "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ac.WithTransaction(ctx, func(tx *TxAssister) error {
+		stmt, err := tx.conn().PrepareContext(ctx, insertBook)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for _, name := range []string{"Kim", "Ulysses"} {
+			_, err = stmt.ExecContext(ctx, name)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected statements prepared inside a transaction to run, got %v", err)
+	}
+	books, err := Select[testBook](ctx, ac, `SELECT * FROM "books" ORDER BY "id"`)
+	if err != nil || len(books) != 4 || books[0].Stock != 5 {
+		t.Errorf("expected 4 books, Dune's stock updated, got %+v & %v", books, err)
+	}
+
+	for _, statement := range []string{`SET search_path TO "archive"`, `RESET ALL`, `DISCARD ALL`, `LISTEN "orders"`, `PREPARE "q" AS SELECT 1`} {
+		_, err = ac.ExecExpecting(ctx, statement, AnyRowsAffected)
+		if !errors.Is(err, ErrUnsupportedWithPooler) {
+			t.Errorf("%s: expected ErrUnsupportedWithPooler, got %v", statement, err)
+		}
+	}
+	// SET LOCAL is let through, SQLite then refusing it
+	err = ac.WithTransaction(ctx, func(tx *TxAssister) error {
+		_, err := tx.ExecExpecting(ctx, `SET LOCAL statement_timeout = '5s'`, AnyRowsAffected)
+		return err
+	})
+	if err == nil || errors.Is(err, ErrUnsupportedWithPooler) {
+		t.Errorf("expected SET LOCAL left to the database, got %v", err)
+	}
+
+	err = ac.WithConn(ctx, func(ca *ConnAssister) error { return nil })
+	if !errors.Is(err, ErrUnsupportedWithPooler) {
+		t.Errorf("expected WithConn refused, got %v", err)
+	}
+	_, err = New(db, WithDialect(Postgres), WithTransactionPooler()).Listen(ctx, "orders")
+	if !errors.Is(err, ErrUnsupportedWithPooler) {
+		t.Errorf("expected Listen refused, got %v", err)
+	}
+	_, err = NewFromDSN("sqlite3", "file::memory:", WithTransactionPooler(), WithConnectionSetup("PRAGMA foreign_keys = ON"))
+	if !errors.Is(err, ErrUnsupportedWithPooler) {
+		t.Errorf("expected a connection setup refused, got %v", err)
+	}
+}
+
+func TestPoolerDSN(t *testing.T) {
+	tests := []struct {
+		driver, dsn, want string
+	}{
+		{"pgx", "postgres://app@pgbouncer:6432/orders", "postgres://app@pgbouncer:6432/orders?default_query_exec_mode=simple_protocol"},
+		{"pgx/v5", "postgres://app@pgbouncer/orders?sslmode=disable", "postgres://app@pgbouncer/orders?sslmode=disable&default_query_exec_mode=simple_protocol"},
+		{"pgx", "host=pgbouncer dbname=orders", "host=pgbouncer dbname=orders default_query_exec_mode=simple_protocol"},
+		{"pgx", "postgres://pgbouncer/orders?default_query_exec_mode=exec", "postgres://pgbouncer/orders?default_query_exec_mode=exec"},
+		{"postgres", "postgres://pgbouncer/orders", "postgres://pgbouncer/orders"},
+	}
+	for _, test := range tests {
+		if got := poolerDSN(test.driver, test.dsn); got != test.want {
+			t.Errorf("poolerDSN(%s, %s) = %s, expected %s", test.driver, test.dsn, got, test.want)
+		}
+	}
+}
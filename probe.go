@@ -0,0 +1,67 @@
+package sqlAssister
+
+import (
+	"context"
+	"time"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// DefaultProbeQuery is the query Probe runs when WithProbeQuery isn't set
+const DefaultProbeQuery = "SELECT 1"
+
+// WithProbeQuery sets the lightweight query Probe runs, e.g. one reading a table the service can't work without.
+// Defaults to DefaultProbeQuery
+func WithProbeQuery(query string) Option {
+	return func(ac *Assister) {
+		ac.probeQuery = query
+	}
+}
+
+// Probe runs the probe query, see WithProbeQuery, & returns how long the round trip took, reading its rows included, for health
+// endpoints reporting the database's latency. It runs like any other statement, bounded by ctx & the default query timeout & logged.
+// The time taken is returned along with the error when it fails, e.g. how long it took to time out
+/*
+
+Example:
+
+	func (h *Health) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		defer cancel()
+
+		latency, err := h.ac.Probe(ctx)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("database unreachable after %s: %s", latency, err), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, "database latency %s", latency)
+	}
+*/
+func (ac Assister) Probe(ctx context.Context) (time.Duration, error) {
+	query := ac.probeQuery
+	if query == "" {
+		query = DefaultProbeQuery
+	}
+	err := utils.QueryChecker(query, ac.queryChecks()...)
+	if err != nil {
+		return 0, err
+	}
+
+	clock := ac.getClock()
+	start := clock.Now()
+	rows, err := ac.conn().QueryContext(ctx, query)
+	if err != nil {
+		return clock.Now().Sub(start), err
+	}
+	defer rows.Close()
+
+	// The rows are read so their transfer is part of the round trip
+	for rows.Next() {
+	}
+	err = rows.Err()
+	if err == nil {
+		err = rows.Close()
+	}
+
+	return clock.Now().Sub(start), err
+}
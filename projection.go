@@ -0,0 +1,106 @@
+package sqlAssister
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// Columns renders the comma separated list of the columns T's fields are mapped to, in the order the fields are declared, quoted for
+// the Assister's dialect & prefixed with the quoted prefix when it isn't empty, e.g. "b"."id", "b"."name". Spelling out the columns
+// rather than SELECT * only reads what T needs & keeps working when unrelated columns are added or dropped.
+// Panics when T is not a struct or a pointer to one, as Columns is meant to be called inline
+/*
+
+Example:
+
+	query := fmt.Sprintf(`SELECT %s FROM "books" b JOIN "authors" a ON a."id" = b."author_id" WHERE a."name" = $1`,
+		sqlAssister.Columns[Book](Assister, "b"))
+	books, err := sqlAssister.Select[Book](ctx, Assister, query, authorName)
+	if err != nil {
+		return nil, err
+	}
+*/
+func Columns[T any](ac *Assister, prefix string) string {
+	info := structInfoOf[T]()
+	if info == nil {
+		panic(fmt.Sprintf("sqlAssister.Columns: expected a struct but got %s", reflect.TypeOf((*T)(nil)).Elem()))
+	}
+
+	return ac.columnList(prefix, info.fields)
+}
+
+// SelectFields Executes Read operation on multiple records reading only the named fields of T, given by their Go field name
+// or column, & scans every record into a T following the same rules as Select, the other fields being left zero.
+// from is the rest of the query, starting at its FROM clause, the SELECT list is generated from the fields' columns.
+// An unknown field fails the call before anything is executed
+/*
+
+Example:
+
+	books, err := sqlAssister.SelectFields[Book](ctx, Assister, []string{"ID", "Name", "Price"},
+		`FROM "books" WHERE "author_id" = $1`, authorId)
+	if err != nil {
+		return nil, err
+	}
+*/
+func SelectFields[T any](ctx context.Context, ac *Assister, fields []string, from string, args ...any) ([]T, error) {
+	info := structInfoOf[T]()
+	if info == nil {
+		return nil, fmt.Errorf("cannot select fields of %s: expected a struct", reflect.TypeOf((*T)(nil)).Elem())
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no fields of %s to select", info.typ)
+	}
+	trimmed := strings.TrimSpace(from)
+	if len(trimmed) < len("FROM") || !strings.EqualFold(trimmed[:len("FROM")], "FROM") {
+		return nil, fmt.Errorf("query must start at its FROM clause, got %q", from)
+	}
+
+	selected := make([]*fieldInfo, len(fields))
+	columns := make([]string, len(fields))
+	for i, name := range fields {
+		fi, err := info.field(name)
+		if err != nil {
+			return nil, err
+		}
+		selected[i] = fi
+		columns[i] = fi.column
+	}
+
+	plan, err := newScanPlan[T](ac, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "SELECT " + ac.columnList("", selected) + " " + from
+	err = utils.QueryChecker(query, ac.queryChecks()...)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ac.conn().QueryContext(ctx, ac.limitQuery(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRows(plan, rows, ac.continueOnError, ac.partialResults)
+}
+
+// columnList renders the quoted columns of fields, each prefixed with the quoted prefix when it isn't empty
+func (ac Assister) columnList(prefix string, fields []*fieldInfo) string {
+	if prefix != "" {
+		prefix = ac.QuoteIdentifier(prefix) + "."
+	}
+
+	columns := make([]string, len(fields))
+	for i, fi := range fields {
+		columns[i] = prefix + ac.QuoteIdentifier(fi.column)
+	}
+
+	return strings.Join(columns, ", ")
+}
@@ -0,0 +1,97 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// ReadOnly returns a copy of the Assister refusing every statement that may write with ErrReadOnly before it reaches the database,
+// for code such as a reporting module that must never write whatever it is handed. Reads, EXPLAIN, SHOW, SET LOCAL & SET TRANSACTION
+// run as usual, writes, DDL, a session wide SET or RESET, which would outlive the statement on a pooled connection, a SET ROLE
+// & anything else fail, see utils.IsReadOnlyStatement, whether run by UpdateSingleRow, InsertMap, a query builder or
+// a generic helper. Transactions begun from the copy, & Assisters derived from it, are read only as well.
+// The check reads the statements run through the Assister, not those run on its exported DB or a TxAssister's *sql.Tx, & a SELECT
+// calling a function that writes gets through: pair it with a read only database role or replica where that matters
+/*
+
+Example:
+
+	reports := NewReportModule(Assister.ReadOnly())
+
+	err := reports.Assister.UpdateSingleRow(`UPDATE "orders" SET "status" = 'void'`)
+	// errors.Is(err, sqlAssister.ErrReadOnly) == true
+*/
+func (ac Assister) ReadOnly() *Assister {
+	ac.readOnly = true
+	return &ac
+}
+
+// IsReadOnly reports whether the Assister refuses writes, see ReadOnly
+func (ac Assister) IsReadOnly() bool {
+	return ac.readOnly
+}
+
+// readOnlyQuerier refuses the statements that may write, see ReadOnly
+type readOnlyQuerier struct {
+	q querier
+}
+
+// check refuses query when it may write
+func (q readOnlyQuerier) check(query string) error {
+	if utils.IsReadOnlyStatement(query) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: refused %s statement", ErrReadOnly, utils.DetectStatementKind(query))
+}
+
+func (q readOnlyQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	err := q.check(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.q.ExecContext(ctx, query, args...)
+}
+
+func (q readOnlyQuerier) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	err := q.check(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.q.PrepareContext(ctx, query)
+}
+
+func (q readOnlyQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	err := q.check(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.q.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext can't return an error, a refused statement is given a single arg failing to convert instead, which
+// database/sql reports from the row's Scan without the statement reaching the database
+func (q readOnlyQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	err := q.check(query)
+	if err != nil {
+		return q.q.QueryRowContext(ctx, query, refusedArg{err: err})
+	}
+
+	return q.q.QueryRowContext(ctx, query, args...)
+}
+
+// refusedArg fails the conversion of the args of a statement so it is never executed, see readOnlyQuerier.QueryRowContext
+type refusedArg struct {
+	err error
+}
+
+func (a refusedArg) Value() (driver.Value, error) {
+	return nil, a.err
+}
@@ -0,0 +1,62 @@
+package sqlAssister
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestReadOnly(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openTestDB(t, bookTable, `INSERT INTO "books" ("name") VALUES ('Dune')`), WithDialect(SQLite))
+	readOnly := ac.ReadOnly()
+	if !readOnly.IsReadOnly() || ac.IsReadOnly() {
+		t.Fatal("expected only the copy read only")
+	}
+
+	names, err := Select[string](ctx, readOnly, `SELECT "name" FROM "books"`)
+	if err != nil || len(names) != 1 {
+		t.Errorf("expected reads to run, got %v & %v", names, err)
+	}
+
+	// Writes & the statements outliving themselves on a pooled connection, lifting the guarantee for the next ones, are refused
+	for _, statement := range []string{
+		insertBook,
+		`DELETE FROM "books"`,
+		`CREATE TABLE "authors" ("id" INTEGER PRIMARY KEY)`,
+		`SET default_transaction_read_only = off`,
+		`SET SESSION CHARACTERISTICS AS TRANSACTION READ WRITE`,
+		`SET ROLE admin`,
+		`SET LOCAL ROLE admin`,
+		`SET LOCAL transaction_read_only = off`,
+		`SET search_path = "archive"`,
+		`RESET ALL`,
+		`SET LOCAL statement_timeout = '5s'; DELETE FROM "books"`,
+	} {
+		_, err = readOnly.ExecExpecting(ctx, statement, AnyRowsAffected, "Kim")
+		if !errors.Is(err, ErrReadOnly) {
+			t.Errorf("%s: expected ErrReadOnly, got %v", statement, err)
+		}
+	}
+	err = readOnly.WithTransaction(ctx, func(tx *TxAssister) error {
+		return tx.UpdateSingleRow(insertBook, "Kim")
+	})
+	if !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected a transaction of the copy read only, got %v", err)
+	}
+
+	// The settings lasting until the transaction ends are let through, SQLite then refusing them
+	for _, statement := range []string{`SET LOCAL statement_timeout = '5s'`, `SET TRANSACTION READ ONLY`} {
+		err = readOnly.WithTransaction(ctx, func(tx *TxAssister) error {
+			_, err := tx.ExecExpecting(ctx, statement, AnyRowsAffected)
+			return err
+		})
+		if err == nil || errors.Is(err, ErrReadOnly) {
+			t.Errorf("%s: expected the statement left to the database, got %v", statement, err)
+		}
+	}
+
+	if count := countBooks(t, ac); count != 1 {
+		t.Errorf("expected nothing written, got %d books", count)
+	}
+}
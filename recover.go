@@ -0,0 +1,85 @@
+package sqlAssister
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// WithRecoverPanics turns a panic inside the package's reflection, mapping structs to columns, scanning rows & binding struct fields,
+// into an *InternalError returned by the call, for services that prefer failing a request to crashing. The InternalError carries
+// the stack of the panic, is logged with the Assister's logger & passed to hook when it isn't nil, e.g. to report it to an error
+// tracker. By default panics propagate, as a panic there is a bug of the package to fix rather than an error to handle.
+// Only the package's own code is covered, the functions given to MapRows, WithTransaction & the like panic as usual, as do
+// the Scan methods of the caller's types: database/sql keeps the rows locked after such a panic, so they can't be closed
+// & the call couldn't return anyway. Registered scan converters are covered. The documented panics of Args, Columns &
+// MustScanPlan on a misuse aren't recovered either
+/*
+
+Example:
+
+	statementAssister = sqlAssister.New(db, sqlAssister.WithRecoverPanics(func(err *sqlAssister.InternalError) {
+		sentry.CaptureException(err)
+	}))
+*/
+func WithRecoverPanics(hook func(err *InternalError)) Option {
+	return func(ac *Assister) {
+		ac.recoverPanics = true
+		ac.panicHook = hook
+	}
+}
+
+// panicRecovery turns panics into errors when WithRecoverPanics is set, carried by what outlives the call setting it up
+type panicRecovery struct {
+	enabled bool
+	logger  Logger
+	hook    func(err *InternalError)
+}
+
+func (ac Assister) panicRecovery() panicRecovery {
+	if !ac.recoverPanics {
+		return panicRecovery{}
+	}
+
+	return panicRecovery{enabled: true, logger: ac.getLogger(), hook: ac.panicHook}
+}
+
+// recover is deferred by the functions returning *err, setting it to an *InternalError when they panic. It doesn't recover the
+// panic unless WithRecoverPanics is set, & must be deferred directly for recover to stop the panic
+func (r panicRecovery) recover(err *error) {
+	if !r.enabled {
+		return
+	}
+	p := recover()
+	if p == nil {
+		return
+	}
+
+	internal := &InternalError{Panic: p, Stack: debug.Stack()}
+	r.logger.Printf("ERROR: %s\n%s", internal, internal.Stack)
+	if r.hook != nil {
+		r.hook(internal)
+	}
+	*err = internal
+}
+
+// InternalError is returned in place of a panic inside the package when WithRecoverPanics is set
+type InternalError struct {
+	// Panic is the value the package panicked with
+	Panic any
+	// Stack is the stack of the goroutine that panicked, as debug.Stack formats it
+	Stack []byte
+}
+
+func (e *InternalError) Error() string {
+	return fmt.Sprintf("sqlAssister internal error: %v", e.Panic)
+}
+
+// Unwrap returns the panic's value when it is an error, such as a runtime.Error
+func (e *InternalError) Unwrap() error {
+	err, _ := e.Panic.(error)
+	return err
+}
+
+func (e *InternalError) Op() string { return "panic recovery" }
+
+func (e *InternalError) Label() string { return "" }
@@ -0,0 +1,156 @@
+package sqlAssister
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// QueryRegistry names the queries an application runs so they can be looked up by name & checked all at once by Warmup.
+// Queries are typically registered into package level variables, the query being returned for the caller to run as usual.
+// A QueryRegistry is safe for concurrent use & may be shared by several Assisters
+/*
+
+Example:
+
+	var queries = sqlAssister.NewQueryRegistry()
+
+	var booksByAuthor = queries.Register("booksByAuthor", `SELECT "id", "name" FROM "books" WHERE "author_id" = $1`)
+
+	Assister := sqlAssister.New(db, sqlAssister.WithQueryRegistry(queries))
+*/
+type QueryRegistry struct {
+	mu      sync.RWMutex
+	queries map[string]string
+}
+
+// NewQueryRegistry returns an empty QueryRegistry
+func NewQueryRegistry() *QueryRegistry {
+	return &QueryRegistry{queries: map[string]string{}}
+}
+
+// Register names query & returns it. Registering a name twice panics, unless with the same query, as two queries sharing
+// a name is a programming error best caught when the package initializes
+func (r *QueryRegistry) Register(name string, query string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if registered, ok := r.queries[name]; ok && registered != query {
+		panic(fmt.Sprintf("sqlAssister: query %q registered twice with different statements", name))
+	}
+	r.queries[name] = query
+
+	return query
+}
+
+// Query returns the query registered as name
+func (r *QueryRegistry) Query(name string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	query, ok := r.queries[name]
+	return query, ok
+}
+
+// Names returns the names of the registered queries, sorted
+func (r *QueryRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.queries))
+	for name := range r.queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// WithQueryRegistry sets the queries Warmup prepares
+func WithQueryRegistry(registry *QueryRegistry) Option {
+	return func(ac *Assister) {
+		ac.queryRegistry = registry
+	}
+}
+
+// Warmup prepares every query of the Assister's QueryRegistry against the database & closes the statement again, failing with
+// a *WarmupError naming each query that didn't prepare, e.g. for a syntax error or a missing table or column. Call it at startup
+// so a deploy shipping a broken query fails at once, rather than at the first request running it. The Assister's query checks,
+// such as WithBalanceCheck, run first. Preparing checks what the database checks before running a statement, which is most but
+// not all of what could fail: a query only failing for some args, or MySQL with the driver's interpolateParams set, isn't caught.
+// Behind a transaction pooler each query is prepared in a transaction of its own, see WithTransactionPooler.
+// Fails when no QueryRegistry is set, as warming up nothing is most likely a mistake
+/*
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err := Assister.Warmup(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+*/
+func (ac Assister) Warmup(ctx context.Context) error {
+	if ac.queryRegistry == nil {
+		return errors.New("no queries to warm up, set a QueryRegistry with WithQueryRegistry")
+	}
+
+	var failures []QueryError
+	for _, name := range ac.queryRegistry.Names() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		query, _ := ac.queryRegistry.Query(name)
+
+		err := ac.prepareCheck(ctx, query)
+		if err != nil {
+			failures = append(failures, QueryError{Name: name, Err: err})
+		}
+	}
+	if len(failures) > 0 {
+		return &WarmupError{Queries: failures}
+	}
+
+	return nil
+}
+
+// prepareCheck prepares query & closes the statement, returning why it didn't prepare. Behind a transaction pooler the statement
+// is prepared in a transaction that is rolled back, keeping it on a single server connection
+func (ac Assister) prepareCheck(ctx context.Context, query string) error {
+	err := utils.QueryChecker(query, ac.queryChecks()...)
+	if err != nil {
+		return err
+	}
+
+	prepare := func(q querier) error {
+		stmt, err := q.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		return stmt.Close()
+	}
+	if !ac.transactionPooler || ac.tx != nil {
+		return prepare(ac.conn())
+	}
+
+	err = ac.WithTransaction(ctx, func(tx *TxAssister) error {
+		err := prepare(tx.conn())
+		if err != nil {
+			return err
+		}
+		return errRollbackPrepared
+	})
+	if err == errRollbackPrepared {
+		return nil
+	}
+	return err
+}
+
+// errRollbackPrepared rolls back the transaction prepareCheck prepared a statement in
+var errRollbackPrepared = errors.New("rollback after preparing")
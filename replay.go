@@ -0,0 +1,165 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+)
+
+// The replay DB hands out *sql.Rows & *sql.Row holding results the package has already read, or errors it has already decided on,
+// as database/sql only creates them from a driver. A statement executed on it returns the replay given as its single arg
+// without reaching any database
+
+// replay is what a statement executed on the replay DB returns: its error, or its columns & records followed by the error
+// reading them stopped on, if any, & the next result set
+type replay struct {
+	columns []string
+	records [][]driver.Value
+	readErr error
+	next    *replay
+	err     error
+}
+
+var (
+	replayDB     *sql.DB
+	replayDBOnce sync.Once
+)
+
+func getReplayDB() *sql.DB {
+	replayDBOnce.Do(func() {
+		replayDB = sql.OpenDB(replayConnector{})
+	})
+
+	return replayDB
+}
+
+// replayRows returns rows holding r's records, or r's error
+func replayRows(ctx context.Context, query string, r *replay) (*sql.Rows, error) {
+	return getReplayDB().QueryContext(ctx, query, r)
+}
+
+// replayRow returns a row holding r's first record, or whose Scan fails with r's error
+func replayRow(ctx context.Context, query string, r *replay) *sql.Row {
+	return getReplayDB().QueryRowContext(ctx, query, r)
+}
+
+// readReplay reads up to limit records of rows, every record & result set when limit is negative, & closes them, so they can be
+// replayed once whatever they were read under is released. The replay holds the records read before an error, which is returned
+func readReplay(rows *sql.Rows, limit int) (*replay, error) {
+	defer rows.Close()
+
+	first := &replay{}
+	for r := first; ; r = r.next {
+		columns, err := rows.Columns()
+		if err != nil {
+			return first, err
+		}
+		r.columns = columns
+
+		for (limit < 0 || len(r.records) < limit) && rows.Next() {
+			// Scanning into an any keeps the value the driver returned, copying its bytes
+			values := make([]any, len(columns))
+			dest := make([]any, len(columns))
+			for i := range values {
+				dest[i] = &values[i]
+			}
+			err = rows.Scan(dest...)
+			if err != nil {
+				return first, err
+			}
+
+			record := make([]driver.Value, len(values))
+			for i, value := range values {
+				record[i] = value
+			}
+			r.records = append(r.records, record)
+		}
+
+		err = rows.Err()
+		if err != nil || limit >= 0 || !rows.NextResultSet() {
+			return first, rows.Err()
+		}
+		r.next = &replay{}
+	}
+}
+
+type replayConnector struct{}
+
+func (c replayConnector) Connect(context.Context) (driver.Conn, error) { return replayConn{}, nil }
+
+func (c replayConnector) Driver() driver.Driver { return replayDriver{} }
+
+type replayDriver struct{}
+
+func (d replayDriver) Open(string) (driver.Conn, error) { return replayConn{}, nil }
+
+// replayConn answers every query with the replay given as its arg
+type replayConn struct{}
+
+var errNotReplayed = errors.New("statement executed on the replay DB without a replay")
+
+// CheckNamedValue passes the replay arg to QueryContext as is
+func (c replayConn) CheckNamedValue(value *driver.NamedValue) error {
+	if _, ok := value.Value.(*replay); ok {
+		return nil
+	}
+
+	return driver.ErrSkip
+}
+
+func (c replayConn) QueryContext(_ context.Context, _ string, args []driver.NamedValue) (driver.Rows, error) {
+	if len(args) != 1 {
+		return nil, errNotReplayed
+	}
+	r, ok := args[0].Value.(*replay)
+	if !ok {
+		return nil, errNotReplayed
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return &replayedRows{replay: r}, nil
+}
+
+func (c replayConn) Prepare(string) (driver.Stmt, error) { return nil, errNotReplayed }
+
+func (c replayConn) Close() error { return nil }
+
+func (c replayConn) Begin() (driver.Tx, error) { return nil, errNotReplayed }
+
+type replayedRows struct {
+	replay *replay
+	next   int
+}
+
+func (r *replayedRows) Columns() []string { return r.replay.columns }
+
+func (r *replayedRows) Close() error { return nil }
+
+func (r *replayedRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.replay.records) {
+		if r.replay.readErr != nil {
+			return r.replay.readErr
+		}
+		return io.EOF
+	}
+	copy(dest, r.replay.records[r.next])
+	r.next++
+
+	return nil
+}
+
+func (r *replayedRows) HasNextResultSet() bool { return r.replay.next != nil }
+
+func (r *replayedRows) NextResultSet() error {
+	if r.replay.next == nil {
+		return io.EOF
+	}
+	r.replay, r.next = r.replay.next, 0
+
+	return nil
+}
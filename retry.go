@@ -0,0 +1,199 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// RetryBudget caps the retries of every Assister sharing it, a token bucket holding up to burst retries & refilled at perSecond.
+// Each retry spends a token, once the bucket is empty statements that would be retried fail at once with their error instead.
+// Retrying per call multiplies the load on a database that is already struggling, a shared budget stops an outage from
+// turning into a retry storm while still smoothing over the occasional transient failure. A RetryBudget is safe for concurrent use
+type RetryBudget struct {
+	mu        sync.Mutex
+	tokens    float64
+	burst     float64
+	perSecond float64
+	// refilled is when the tokens were last refilled, zero until the budget is first used
+	refilled time.Time
+	// clock is the Clock of the Assisters spending the budget, the real clock unless one given the budget has another
+	clock Clock
+}
+
+// NewRetryBudget returns a full RetryBudget allowing bursts of up to burst retries & perSecond retries a second once spent.
+// The budget is refilled on the Clock of the Assisters spending it, see WithClock
+func NewRetryBudget(burst int, perSecond float64) *RetryBudget {
+	return &RetryBudget{
+		tokens:    float64(burst),
+		burst:     float64(burst),
+		perSecond: perSecond,
+	}
+}
+
+// WithRetryBudget caps the retries of the Assister's statements with budget, which may be shared with other Assisters.
+// Without a budget retries are only bounded per call
+/*
+
+Example:
+
+	budget := sqlAssister.NewRetryBudget(50, 10)
+	orders := sqlAssister.New(ordersDB, sqlAssister.WithRetryBudget(budget))
+	users := sqlAssister.New(usersDB, sqlAssister.WithRetryBudget(budget))
+*/
+func WithRetryBudget(budget *RetryBudget) Option {
+	return func(ac *Assister) {
+		ac.retryBudget = budget
+	}
+}
+
+// Remaining returns the number of retries the budget currently allows, on the Clock of the Assisters spending it
+func (b *RetryBudget) Remaining() int {
+	b.mu.Lock()
+	clock := b.clock
+	b.mu.Unlock()
+	if clock == nil {
+		clock = realClock{}
+	}
+
+	return b.RemainingAt(clock.Now())
+}
+
+// RemainingAt returns the number of retries the budget allows at now, for budgets spent by Assisters with a Clock of their own
+func (b *RetryBudget) RemainingAt(now time.Time) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now)
+	return int(b.tokens)
+}
+
+// useClock refills the budget on clock, the Clock of an Assister given the budget, see WithClock
+func (b *RetryBudget) useClock(clock Clock) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.clock = clock
+}
+
+// take spends a token for a retry at now, reporting false when the budget is exhausted. A nil budget allows every retry
+func (b *RetryBudget) take(now time.Time) bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+
+	return true
+}
+
+// refill adds the tokens earned since the last refill, none when now isn't after it
+func (b *RetryBudget) refill(now time.Time) {
+	if b.refilled.IsZero() {
+		b.refilled = now
+		return
+	}
+	if !now.After(b.refilled) {
+		return
+	}
+
+	b.tokens += now.Sub(b.refilled).Seconds() * b.perSecond
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.refilled = now
+}
+
+// DefaultConnectionRetries is the number of times a read failing with a connection error is retried when WithConnectionRetries isn't set
+const DefaultConnectionRetries = 2
+
+const (
+	connRetryBackoffMin = 50 * time.Millisecond
+	connRetryBackoffMax = time.Second
+)
+
+// WithConnectionRetries retries reads failing because their connection was lost, see utils.IsConnectionError, up to retries times
+// on a new connection with backoff. It smooths over the statements in flight when the database fails over, which lib/pq reports as
+// `unexpected EOF` or `connection reset by peer`. Defaults to DefaultConnectionRetries, 0 turns it off.
+// Only reads are retried, writes may have been applied before the connection was lost, & only reads executed on the pool: those of a
+// transaction or an Assister bound to a connection fail with it. Errors reading rows once the query returned aren't retried.
+// Retries are spent from the Assister's RetryBudget, see WithRetryBudget
+func WithConnectionRetries(retries int) Option {
+	return func(ac *Assister) {
+		ac.connRetries = retries
+		ac.connRetriesSet = true
+	}
+}
+
+// connectionRetries returns the number of times a read failing with a connection error is retried, see WithConnectionRetries
+func (ac Assister) connectionRetries() int {
+	if ac.connRetriesSet {
+		return ac.connRetries
+	}
+
+	return DefaultConnectionRetries
+}
+
+// connRetryQuerier retries the reads executed on q failing with a connection error
+type connRetryQuerier struct {
+	q       querier
+	retries int
+	budget  *RetryBudget
+	clock   Clock
+}
+
+func (q connRetryQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return q.q.ExecContext(ctx, query, args...)
+}
+
+func (q connRetryQuerier) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return q.q.PrepareContext(ctx, query)
+}
+
+func (q connRetryQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return retryConnection(ctx, q.retries, query, q.budget, q.clock, func() (*sql.Rows, error) {
+		return q.q.QueryContext(ctx, query, args...)
+	})
+}
+
+func (q connRetryQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	row, _ := retryConnection(ctx, q.retries, query, q.budget, q.clock, func() (*sql.Row, error) {
+		row := q.q.QueryRowContext(ctx, query, args...)
+		return row, row.Err()
+	})
+	return row
+}
+
+// retryConnection runs fn, a read of query, until it doesn't fail with a connection error, up to retries more times, backing off
+// on clock between attempts for as long as ctx allows & budget has retries left
+func retryConnection[R any](ctx context.Context, retries int, query string, budget *RetryBudget, clock Clock, fn func() (R, error)) (R, error) {
+	result, err := fn()
+	if !utils.IsConnectionError(err) || utils.DetectStatementKind(query) != utils.StatementSelect {
+		return result, err
+	}
+
+	backoff := connRetryBackoffMin
+	for attempt := 0; attempt < retries && utils.IsConnectionError(err) && budget.take(clock.Now()); attempt++ {
+		if !sleepContext(ctx, clock, backoff) {
+			return result, err
+		}
+
+		result, err = fn()
+		backoff *= 2
+		if backoff > connRetryBackoffMax {
+			backoff = connRetryBackoffMax
+		}
+	}
+
+	return result, err
+}
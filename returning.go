@@ -0,0 +1,181 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// WithAppendReturning makes UpdateReturning, UpdateReturningMany & DeleteReturning append a RETURNING clause listing T's columns to a statement
+// without one, rather than failing it, so the statement & the struct it is read into can't drift apart
+/*
+
+Example:
+
+	statementAssister = sqlAssister.New(db, sqlAssister.WithAppendReturning())
+
+	book, err := sqlAssister.UpdateReturning[Book](ctx, statementAssister, `UPDATE "books" SET "stock" = "stock" - 1 WHERE "id" = $1`, bookId)
+*/
+func WithAppendReturning() Option {
+	return func(ac *Assister) {
+		ac.appendReturning = true
+	}
+}
+
+// UpdateReturning executes an UPDATE expected to update a single record & scans the record its RETURNING clause returns into a T,
+// following the same rules as Get, sparing a second SELECT to read the record as updated. The number of records returned stands
+// for the number updated: ErrNotFound is returned when there is none & a *RowsAffectedError when there are several, as
+// UpdateSingleRow returns, the records being updated regardless unless the transaction running it rolls back on the error.
+// The statement must have a RETURNING clause, see WithAppendReturning. Postgres & SQLite from 3.35 support RETURNING, other
+// dialects fail with ErrUnsupportedDialect: run the UPDATE with UpdateSingleRow & read the record with Get in a transaction instead
+/*
+
+Example:
+
+	book, err := sqlAssister.UpdateReturning[Book](ctx, Assister,
+		`UPDATE "books" SET "stock" = "stock" - 1 WHERE "id" = $1 RETURNING "id", "name", "stock"`, bookId)
+	if err != nil {
+		return nil, err
+	}
+*/
+func UpdateReturning[T any](ctx context.Context, ac *Assister, query string, args ...any) (T, error) {
+	var result T
+	rows, err := queryReturning[T](ctx, ac, "UPDATE", updateAlternative, query, args)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	plan, err := newRowsScanPlan[T](ac, rows)
+	if err != nil {
+		return result, err
+	}
+
+	result, err = scanFirst(plan, rows)
+	if err != nil {
+		return result, err
+	}
+
+	// Every record returned was updated, count them all rather than stop at the second
+	updated := int64(1)
+	for rows.Next() {
+		updated++
+	}
+	err = rows.Err()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if updated > 1 {
+		var zero T
+		return zero, &RowsAffectedError{Affected: updated, Expected: 1}
+	}
+
+	return result, nil
+}
+
+// UpdateReturningMany executes an UPDATE & scans every record its RETURNING clause returns into a T following the same rules
+// as Select, returning nil when no record was updated. The statement & dialects are those of UpdateReturning
+/*
+
+Example:
+
+	books, err := sqlAssister.UpdateReturningMany[Book](ctx, Assister,
+		`UPDATE "books" SET "price" = "price" * 0.9 WHERE "author_id" = $1 RETURNING "id", "name", "price"`, authorId)
+	if err != nil {
+		return nil, err
+	}
+*/
+func UpdateReturningMany[T any](ctx context.Context, ac *Assister, query string, args ...any) ([]T, error) {
+	rows, err := queryReturning[T](ctx, ac, "UPDATE", updateAlternative, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanAll[T](ac, rows)
+}
+
+// DeleteReturning executes a DELETE & scans every record its RETURNING clause returns into a T following the same rules as
+// Select, capturing the records deleted in the same statement, e.g. to archive them. Returns nil when no record was deleted.
+// The statement & dialects are those of UpdateReturning, see ArchiveRows to move records to an archive table on Postgres
+/*
+
+Example:
+
+	sessions, err := sqlAssister.DeleteReturning[Session](ctx, Assister,
+		`DELETE FROM "sessions" WHERE "expires_at" < $1 RETURNING "id", "user_id", "expires_at"`, time.Now())
+	if err != nil {
+		return err
+	}
+*/
+func DeleteReturning[T any](ctx context.Context, ac *Assister, query string, args ...any) ([]T, error) {
+	rows, err := queryReturning[T](ctx, ac, "DELETE", "read the records with Select & run the DELETE in a transaction", query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanAll[T](ac, rows)
+}
+
+// updateAlternative is the alternative to UPDATE ... RETURNING on the dialects without RETURNING
+const updateAlternative = "run the UPDATE with UpdateSingleRow & read the record with Get in a transaction"
+
+// queryReturning executes the statement of UpdateReturning or DeleteReturning, appending its RETURNING clause WithAppendReturning.
+// statement names the statement & alternative what to do instead in the errors of the dialects without RETURNING
+func queryReturning[T any](ctx context.Context, ac *Assister, statement string, alternative string, query string, args []any) (*sql.Rows, error) {
+	if ac.dialect != Postgres && ac.dialect != SQLite {
+		return nil, unsupportedDialectf("%s ... RETURNING is not supported on %s, %s instead", statement, ac.dialect, alternative)
+	}
+	err := utils.QueryChecker(query, ac.queryChecks()...)
+	if err != nil {
+		return nil, err
+	}
+
+	if !utils.HasReturning(query) {
+		if !ac.appendReturning {
+			return nil, errors.New("expected a statement with a RETURNING clause, add one or set WithAppendReturning")
+		}
+		info := structInfoOf[T]()
+		if info == nil {
+			return nil, fmt.Errorf("cannot append a RETURNING clause for %s: expected a struct", reflect.TypeOf((*T)(nil)).Elem())
+		}
+		// On its own line so a trailing -- comment doesn't swallow it
+		query = strings.TrimRight(query, " \t\r\n;") + "\nRETURNING " + ac.columnList("", info.fields)
+	}
+
+	rows, err := ac.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		if ac.dialect == SQLite && strings.Contains(strings.ToLower(err.Error()), `near "returning": syntax error`) &&
+			sqliteLacksReturning(ctx, ac) {
+			return nil, unsupportedDialectf("%s ... RETURNING is not supported before SQLite 3.35, %s instead: %s",
+				statement, alternative, err)
+		}
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// sqliteLacksReturning reports whether the SQLite library ac's statements run on predates RETURNING, added by 3.35,
+// telling a statement it can't parse from one whose RETURNING clause is malformed
+func sqliteLacksReturning(ctx context.Context, ac *Assister) bool {
+	rows, err := ac.conn().QueryContext(ctx, "SELECT sqlite_version()")
+	if err != nil {
+		return false
+	}
+	defer rows.Close()
+
+	var version string
+	if !rows.Next() || rows.Scan(&version) != nil {
+		return false
+	}
+	var major, minor int
+	_, err = fmt.Sscanf(version, "%d.%d", &major, &minor)
+
+	return err == nil && (major < 3 || major == 3 && minor < 35)
+}
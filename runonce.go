@@ -0,0 +1,81 @@
+package sqlAssister
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// defaultRunOnceTable is the table RunOnce records completed operations in when WithRunOnceTable isn't set
+const defaultRunOnceTable = "sql_assister_run_once"
+
+// WithRunOnceTable sets the table RunOnce records the operations it completed in, sql_assister_run_once by default.
+// The table is created by the first RunOnce, with a "name" primary key & the "applied_at" time of the operation
+func WithRunOnceTable(table string) Option {
+	return func(ac *Assister) {
+		ac.runOnceTable = table
+	}
+}
+
+// RunOnce runs fn, a one off operation such as a data fix from a runbook, unless the operation named name already completed,
+// in which case it returns ErrAlreadyApplied without calling fn. fn runs in a transaction along with the record of its
+// completion: if fn fails the transaction is rolled back & the operation can be run again, if it succeeds it is never run again.
+// The record is inserted before fn is called, its primary key locking the name, so a concurrent RunOnce of the same operation
+// waits for the first to end & returns ErrAlreadyApplied once it commits, or runs fn itself if it rolled back. SQLite fails the
+// concurrent call with a busy error rather than wait, unless the connection's busy_timeout is set.
+// The table recording the operations is created on demand, see WithRunOnceTable. RunOnce can't be called on a TxAssister,
+// the operation needs a transaction of its own
+/*
+
+Example:
+
+	err := Assister.RunOnce(ctx, "2024-03-backfill-order-currency", func(tx *sqlAssister.TxAssister) error {
+		_, err := tx.Table("orders").Update().Set("currency", "EUR").Where(sqlAssister.IsNull("currency")).Exec(ctx)
+		return err
+	})
+	if errors.Is(err, sqlAssister.ErrAlreadyApplied) {
+		log.Println("backfill already applied")
+	} else if err != nil {
+		return err
+	}
+*/
+func (ac Assister) RunOnce(ctx context.Context, name string, fn func(tx *TxAssister) error) error {
+	if ac.tx != nil {
+		return errors.New("RunOnce called on an Assister already bound to a transaction")
+	}
+	if name == "" {
+		return errors.New("RunOnce needs the name of the operation")
+	}
+
+	table := ac.runOnceTableName()
+	err := ac.EnsureTable(ctx, fmt.Sprintf("CREATE TABLE %s (%s VARCHAR(255) PRIMARY KEY, %s TIMESTAMP NOT NULL)",
+		ac.QuoteIdentifier(table), ac.QuoteIdentifier("name"), ac.QuoteIdentifier("applied_at")))
+	if err != nil {
+		return fmt.Errorf("create run once table %q: %w", table, err)
+	}
+
+	insert := fmt.Sprintf("INSERT INTO %s (%s, %s) VALUES (%s, CURRENT_TIMESTAMP)",
+		ac.QuoteIdentifier(table), ac.QuoteIdentifier("name"), ac.QuoteIdentifier("applied_at"), ac.dialect.Placeholder(1))
+	return ac.WithTransaction(ctx, func(tx *TxAssister) error {
+		_, err := tx.conn().ExecContext(ctx, insert, name)
+		if utils.IsUniqueViolation(err) {
+			return fmt.Errorf("operation %q: %w", name, ErrAlreadyApplied)
+		}
+		if err != nil {
+			return err
+		}
+
+		return fn(tx)
+	})
+}
+
+// runOnceTableName returns the table RunOnce records completed operations in, see WithRunOnceTable
+func (ac Assister) runOnceTableName() string {
+	if ac.runOnceTable == "" {
+		return defaultRunOnceTable
+	}
+
+	return ac.runOnceTable
+}
@@ -0,0 +1,139 @@
+package sqlAssister
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// raceRunOnce calls RunOnce of name from workers goroutines at once, each through its own Assister as separate processes would,
+// returning the errors they got
+func raceRunOnce(t *testing.T, ac *Assister, workers int, name string, fn func(tx *TxAssister) error) []error {
+	t.Helper()
+	errs := make([]error, workers)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		w := w
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			errs[w] = New(ac.DB, WithDialect(SQLite)).RunOnce(context.Background(), name, fn)
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	return errs
+}
+
+func TestRunOnceConcurrent(t *testing.T) {
+	ac := New(openTestDB(t, bookTable), WithDialect(SQLite))
+	const workers = 20
+
+	var runs atomic.Int64
+	errs := raceRunOnce(t, ac, workers, "backfill", func(tx *TxAssister) error {
+		runs.Add(1)
+		// Hold the transaction open, the other calls arriving meanwhile
+		time.Sleep(50 * time.Millisecond)
+		return tx.UpdateSingleRow(insertBook, "Dune")
+	})
+
+	applied := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			applied++
+		case !errors.Is(err, ErrAlreadyApplied):
+			t.Errorf("expected ErrAlreadyApplied, got %v", err)
+		}
+	}
+	if applied != 1 || runs.Load() != 1 {
+		t.Errorf("expected the operation run & applied once, got %d runs & %d applied", runs.Load(), applied)
+	}
+	if count := countBooks(t, ac); count != 1 {
+		t.Errorf("expected a single book inserted, got %d", count)
+	}
+}
+
+func TestRunOnceConcurrentFailure(t *testing.T) {
+	ac := New(openTestDB(t, bookTable), WithDialect(SQLite))
+	const workers = 10
+
+	// The first run fails while the others wait on its lock, one of them then runs the operation itself
+	var runs atomic.Int64
+	failure := errors.New("disk full")
+	errs := raceRunOnce(t, ac, workers, "backfill", func(tx *TxAssister) error {
+		err := tx.UpdateSingleRow(insertBook, "Dune")
+		if err != nil {
+			return err
+		}
+		if runs.Add(1) == 1 {
+			time.Sleep(50 * time.Millisecond)
+			return failure
+		}
+		return nil
+	})
+
+	applied, failed := 0, 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			applied++
+		case errors.Is(err, failure):
+			failed++
+		case !errors.Is(err, ErrAlreadyApplied):
+			t.Errorf("expected ErrAlreadyApplied, got %v", err)
+		}
+	}
+	if runs.Load() != 2 || failed != 1 || applied != 1 {
+		t.Errorf("expected a failed run & a successful one, got %d runs, %d failed & %d applied", runs.Load(), failed, applied)
+	}
+	if count := countBooks(t, ac); count != 1 {
+		t.Errorf("expected the failed run's book rolled back, got %d books", count)
+	}
+}
+
+func TestRunOnce(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openTestDB(t, bookTable), WithDialect(SQLite), WithRunOnceTable("operations"))
+
+	runs := 0
+	fn := func(tx *TxAssister) error {
+		runs++
+		return nil
+	}
+	for _, name := range []string{"backfill", "reindex"} {
+		err := ac.RunOnce(ctx, name, fn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ac.RunOnce(ctx, name, fn)
+		if !errors.Is(err, ErrAlreadyApplied) {
+			t.Errorf("%s: expected ErrAlreadyApplied the second time, got %v", name, err)
+		}
+	}
+	if runs != 2 {
+		t.Errorf("expected each operation run once, got %d runs", runs)
+	}
+
+	names, err := Select[string](ctx, ac, `SELECT "name" FROM "operations" ORDER BY "name"`)
+	if err != nil || len(names) != 2 || names[0] != "backfill" || names[1] != "reindex" {
+		t.Errorf("expected the operations recorded in the table set, got %v & %v", names, err)
+	}
+
+	err = ac.RunOnce(ctx, "", fn)
+	if err == nil {
+		t.Error("expected an unnamed operation refused")
+	}
+	err = ac.WithTransaction(ctx, func(tx *TxAssister) error {
+		return tx.RunOnce(ctx, "nested", fn)
+	})
+	if err == nil || errors.Is(err, ErrAlreadyApplied) {
+		t.Errorf("expected RunOnce refused within a transaction, got %v", err)
+	}
+}
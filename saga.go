@@ -0,0 +1,107 @@
+package sqlAssister
+
+import (
+	"context"
+	"fmt"
+)
+
+// Saga runs steps on one or more databases, each in a transaction of its own committed before the next starts, & undoes the
+// committed steps with their compensations when a later step fails. It is a best effort short of two phase commit: between the
+// commits the databases disagree, & a compensation that fails leaves them so until someone cleans up by hand, which is reported
+// loudly, see OnCompensationFailure. Compensations must be idempotent & undo what their step did semantically, e.g. refund a
+// charge, as other transactions may have read or built on it meanwhile
+/*
+
+Example:
+
+	err := sqlAssister.NewSaga().
+		Step(orders, func(tx *sqlAssister.TxAssister) error {
+			return tx.UpdateSingleRow(placeOrderStatement, orderID)
+		}, func(tx *sqlAssister.TxAssister) error {
+			return tx.UpdateSingleRow(cancelOrderStatement, orderID)
+		}).
+		Step(billing, func(tx *sqlAssister.TxAssister) error {
+			return tx.UpdateSingleRow(chargeStatement, orderID, amount)
+		}, nil).
+		OnCompensationFailure(func(err sqlAssister.StepError) {
+			alerting.Page("saga compensation failed, manual cleanup needed", err)
+		}).
+		Run(ctx)
+*/
+type Saga struct {
+	steps               []sagaStep
+	compensationFailure func(err StepError)
+}
+
+type sagaStep struct {
+	ac         *Assister
+	fn         func(tx *TxAssister) error
+	compensate func(tx *TxAssister) error
+}
+
+// NewSaga returns a Saga without steps
+func NewSaga() *Saga {
+	return &Saga{}
+}
+
+// Step adds a step running fn in a transaction on ac. compensate undoes it, in a transaction on ac too, when a later step fails,
+// & may be nil for a step with nothing to undo, typically the last
+func (s *Saga) Step(ac *Assister, fn func(tx *TxAssister) error, compensate func(tx *TxAssister) error) *Saga {
+	s.steps = append(s.steps, sagaStep{ac: ac, fn: fn, compensate: compensate})
+	return s
+}
+
+// OnCompensationFailure sets a hook called with every compensation that fails, e.g. to page someone, besides the failure being
+// logged as an error with the step's Assister's logger
+func (s *Saga) OnCompensationFailure(fn func(err StepError)) *Saga {
+	s.compensationFailure = fn
+	return s
+}
+
+// Run runs the steps in the order they were added, stopping at the first that fails. The compensations of the steps committed
+// before it then run in reverse order, each attempted even when another failed, & Run returns a *BatchError holding the step's
+// error followed by those of the compensations that failed. Compensations keep ctx's values but not its cancellation, as a
+// cancelled ctx is a likely reason for the step to have failed
+func (s *Saga) Run(ctx context.Context) error {
+	for i, step := range s.steps {
+		if step.ac == nil || step.fn == nil {
+			return fmt.Errorf("saga step %d has no Assister or function", i+1)
+		}
+	}
+
+	for i, step := range s.steps {
+		err := step.ac.WithTransaction(ctx, step.fn)
+		if err == nil {
+			continue
+		}
+
+		batch := &BatchError{Errors: []StepError{{Step: i + 1, Err: err}}}
+		for j := i - 1; j >= 0; j-- {
+			batch.Errors = append(batch.Errors, s.compensate(ctx, j)...)
+		}
+		return batch
+	}
+
+	return nil
+}
+
+// compensate runs the compensation of the step at index, returning its failure if it failed
+func (s *Saga) compensate(ctx context.Context, index int) []StepError {
+	step := s.steps[index]
+	if step.compensate == nil {
+		return nil
+	}
+
+	err := step.ac.WithTransaction(detachedContext{ctx}, step.compensate)
+	if err == nil {
+		return nil
+	}
+
+	failure := StepError{Step: index + 1, Compensation: true, Err: err}
+	step.ac.getLogger().Printf("ERROR: %s, the step stays committed & needs cleaning up by hand", failure)
+	if s.compensationFailure != nil {
+		s.compensationFailure(failure)
+	}
+
+	return []StepError{failure}
+}
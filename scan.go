@@ -0,0 +1,677 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// Select Executes Read operation on multiple records & scans every record into a T.
+// When T is a struct (or a pointer to one) each column is scanned into the field mapped to it by its `db` tag.
+// Computed columns such as aggregates are mapped by their alias, so alias them with AS.
+// Any other T is scanned from a query returning a single column
+/*
+
+Example:
+
+	type Book struct {
+		ID   string `db:"id"`
+		Name string `db:"name"`
+	}
+
+	books, err := sqlAssister.Select[Book](ctx, Assister, `SELECT "id", "name" FROM "books" WHERE "author_id" = $1`, authorId)
+	if err != nil {
+		return nil, err
+	}
+
+	type StatusTotal struct {
+		Status    string  `db:"status"`
+		Cnt       int     `db:"cnt"`
+		AvgAmount float64 `db:"avg_amount"`
+	}
+
+	totals, err := sqlAssister.Select[StatusTotal](ctx, Assister, `SELECT "status", COUNT(*) AS cnt, AVG("amount") AS avg_amount FROM "orders" GROUP BY "status"`)
+	if err != nil {
+		return nil, err
+	}
+*/
+func Select[T any](ctx context.Context, ac *Assister, query string, args ...any) ([]T, error) {
+	err := utils.QueryChecker(query, ac.queryChecks()...)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ac.conn().QueryContext(ctx, ac.limitQuery(query), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanAll[T](ac, rows)
+}
+
+// SelectIntoSlice follows the same rules as Select, appending the records to *dst rather than allocating a new slice,
+// so hot paths can reuse a slice, e.g. one taken from a sync.Pool, across queries. *dst is appended to, NOT reset:
+// truncate it with (*dst)[:0] to reuse its capacity. When the call fails *dst is left as it was, except for the records
+// ContinueOnError & PartialResults return along with their error, which are appended
+/*
+
+Example:
+
+	books := booksPool.Get().(*[]Book)
+	defer booksPool.Put(books)
+
+	*books = (*books)[:0]
+	err := sqlAssister.SelectIntoSlice(ctx, Assister, books, `SELECT "id", "name" FROM "books" WHERE "author_id" = $1`, authorId)
+	if err != nil {
+		return err
+	}
+*/
+func SelectIntoSlice[T any](ctx context.Context, ac *Assister, dst *[]T, query string, args ...any) error {
+	err := utils.QueryChecker(query, ac.queryChecks()...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := ac.conn().QueryContext(ctx, ac.limitQuery(query), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	plan, err := newRowsScanPlan[T](ac, rows)
+	if err != nil {
+		return err
+	}
+
+	results, err := appendRows(plan, rows, *dst, ac.continueOnError, ac.partialResults)
+	if results != nil {
+		*dst = results
+	}
+
+	return err
+}
+
+// Get Executes Read operation on a single record & scans it into a T following the same rules as Select.
+// Returns ErrNotFound when no record is found
+/*
+
+Example:
+
+	book, err := sqlAssister.Get[Book](ctx, Assister, `SELECT "id", "name" FROM "books" WHERE "id" = $1`, bookId)
+	if err != nil {
+		return nil, err
+	}
+*/
+func Get[T any](ctx context.Context, ac *Assister, query string, args ...any) (T, error) {
+	var result T
+	err := utils.QueryChecker(query, ac.queryChecks()...)
+	if err != nil {
+		return result, err
+	}
+
+	rows, err := ac.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return result, err
+	}
+
+	return scanOne[T](ac, rows)
+}
+
+// GetExactlyOne follows the same rules as Get but checks the query returns exactly one record: it returns ErrNotFound when there
+// is none & ErrMultipleRows when there are more, reading no further than the second row, rather than ignoring the extra rows
+// as Get & SingleRowScanner do. Use it where a query is meant to match a single record, e.g. by a unique key, to catch
+// duplicates hiding a data integrity bug
+/*
+
+Example:
+
+	account, err := sqlAssister.GetExactlyOne[Account](ctx, Assister, `SELECT "id", "email" FROM "accounts" WHERE "email" = $1`, email)
+	if errors.Is(err, sqlAssister.ErrMultipleRows) {
+		return fmt.Errorf("duplicate accounts for %s: %w", email, err)
+	}
+	if err != nil {
+		return err
+	}
+*/
+func GetExactlyOne[T any](ctx context.Context, ac *Assister, query string, args ...any) (T, error) {
+	var result T
+	err := utils.QueryChecker(query, ac.queryChecks()...)
+	if err != nil {
+		return result, err
+	}
+
+	rows, err := ac.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	plan, err := newRowsScanPlan[T](ac, rows)
+	if err != nil {
+		return result, err
+	}
+
+	result, err = scanFirst(plan, rows)
+	if err != nil {
+		return result, err
+	}
+	if rows.Next() {
+		var zero T
+		return zero, ErrMultipleRows
+	}
+
+	return result, rows.Err()
+}
+
+// SelectGrouped Executes Read operation on multiple records & scans them into T following the same rules as Select,
+// bucketing them by the key keyFn derives from each record. Records keep the order they were returned in within their group.
+// With ContinueOnError the records that scanned are grouped & returned along with the *ScanErrors
+/*
+
+Example:
+
+	ordersByCustomer, err := sqlAssister.SelectGrouped(ctx, Assister, func(o Order) string { return o.CustomerID },
+		`SELECT "id", "customer_id", "total" FROM "orders" WHERE "placed_at" > $1`, since)
+	if err != nil {
+		return nil, err
+	}
+*/
+func SelectGrouped[K comparable, T any](ctx context.Context, ac *Assister, keyFn func(T) K, query string, args ...any) (map[K][]T, error) {
+	results, err := Select[T](ctx, ac, query, args...)
+	if results == nil && err != nil {
+		return nil, err
+	}
+
+	groups := map[K][]T{}
+	for _, result := range results {
+		key := keyFn(result)
+		groups[key] = append(groups[key], result)
+	}
+
+	return groups, err
+}
+
+// MapRows Executes Read operation on multiple records & turns each row into a T with fn, for rows that don't map onto a struct's
+// fields, e.g. combining several columns into one value. fn scans the current row itself & must not call rows.Next or rows.Close,
+// MapRows iterates, closes the rows & checks rows.Err. An error from fn fails the call as a RowError numbering the row, except
+// with ContinueOnError where the rows fn failed on are skipped & listed in a *ScanErrors returned with the rest, & PartialResults
+// applies as it does to Select
+/*
+
+Example:
+
+	ranges, err := sqlAssister.MapRows(ctx, Assister, func(rows *sql.Rows) (Range, error) {
+		var from, to int64
+		err := rows.Scan(&from, &to)
+		return Range{From: from, To: to, Len: to - from + 1}, err
+	}, `SELECT "first_id", "last_id" FROM "id_blocks" WHERE "owner" = $1`, owner)
+	if err != nil {
+		return nil, err
+	}
+*/
+func MapRows[T any](ctx context.Context, ac *Assister, fn func(rows *sql.Rows) (T, error), query string, args ...any) ([]T, error) {
+	err := utils.QueryChecker(query, ac.queryChecks()...)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ac.conn().QueryContext(ctx, ac.limitQuery(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []T
+	var scanErrs *ScanErrors
+	for row := 1; rows.Next(); row++ {
+		result, err := fn(rows)
+		if err != nil {
+			if !ac.continueOnError {
+				return nil, RowError{Row: row, Err: err}
+			}
+			if scanErrs == nil {
+				scanErrs = &ScanErrors{}
+			}
+			scanErrs.Rows = append(scanErrs.Rows, RowError{Row: row, Err: err})
+			continue
+		}
+		results = append(results, result)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		if ac.partialResults && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+			return results, &PartialResultsError{Err: err}
+		}
+		return nil, err
+	}
+	if scanErrs != nil {
+		return results, scanErrs
+	}
+
+	return results, nil
+}
+
+// scanAll scans every remaining row into a T & closes rows, skipping the rows that fail to scan when ac continues on errors
+func scanAll[T any](ac *Assister, rows *sql.Rows) ([]T, error) {
+	defer rows.Close()
+
+	return scanResultSet[T](ac, rows, ac.continueOnError, ac.partialResults)
+}
+
+// scanResultSet scans every remaining row of the current result set into a T, leaving rows open.
+// With continueOnError rows that fail to scan are skipped & their errors returned together as a *ScanErrors,
+// with partialResults the rows scanned before the context was done are returned along with a *PartialResultsError
+func scanResultSet[T any](ac *Assister, rows *sql.Rows, continueOnError bool, partialResults bool) ([]T, error) {
+	plan, err := newRowsScanPlan[T](ac, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanRows(plan, rows, continueOnError, partialResults)
+}
+
+// scanRows scans every remaining row of the current result set into a T with plan, see scanResultSet
+func scanRows[T any](plan *scanPlan[T], rows *sql.Rows, continueOnError bool, partialResults bool) ([]T, error) {
+	return appendRows(plan, rows, nil, continueOnError, partialResults)
+}
+
+// appendRows is scanRows appending the rows to results, returning nil when it fails
+func appendRows[T any](plan *scanPlan[T], rows *sql.Rows, results []T, continueOnError bool, partialResults bool) ([]T, error) {
+	var scanErrs *ScanErrors
+	for row := 1; rows.Next(); row++ {
+		result, err := plan.scan(rows)
+		if err != nil {
+			if !continueOnError {
+				return nil, err
+			}
+			if scanErrs == nil {
+				scanErrs = &ScanErrors{}
+			}
+			scanErrs.Rows = append(scanErrs.Rows, RowError{Row: row, Err: err})
+			continue
+		}
+		results = append(results, result)
+	}
+
+	err := rows.Err()
+	if err != nil {
+		if partialResults && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+			return results, &PartialResultsError{Err: err}
+		}
+		return nil, err
+	}
+	if scanErrs != nil {
+		return results, scanErrs
+	}
+
+	return results, nil
+}
+
+// ContinueOnError returns a copy of the Assister whose Select & Fetch skip the rows that fail to scan, e.g. a NULL in a non nullable field,
+// instead of failing on the first. The rows that scanned are returned along with a *ScanErrors listing every row that didn't.
+// Errors executing the query or reading the rows still fail the call
+/*
+
+Example:
+
+	books, err := sqlAssister.Select[Book](ctx, Assister.ContinueOnError(), `SELECT "id", "name" FROM "imported_books"`)
+	var scanErrs *sqlAssister.ScanErrors
+	if errors.As(err, &scanErrs) {
+		for _, rowErr := range scanErrs.Rows {
+			log.Printf("skipped row %d: %s", rowErr.Row, rowErr.Err)
+		}
+	} else if err != nil {
+		return nil, err
+	}
+*/
+func (ac Assister) ContinueOnError() *Assister {
+	ac.continueOnError = true
+	return &ac
+}
+
+// PartialResults returns a copy of the Assister whose Select, Fetch, SelectFields & QueryMany return the records read so far
+// when the context is cancelled or its deadline passes while the rows are being read, along with a *PartialResultsError
+// wrapping the context's error, instead of discarding them. A context done before the query returned its rows still fails
+// the call without records. Iter streams records as they are read & needs no such option
+/*
+
+Example:
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	events, err := sqlAssister.Select[Event](ctx, Assister.PartialResults(), `SELECT "id", "kind", "payload" FROM "events"`)
+	var partial *sqlAssister.PartialResultsError
+	if errors.As(err, &partial) {
+		log.Printf("processing the %d events read before: %s", len(events), partial.Err)
+	} else if err != nil {
+		return err
+	}
+*/
+func (ac Assister) PartialResults() *Assister {
+	ac.partialResults = true
+	return &ac
+}
+
+// scanOne scans the first row into a T & closes rows. Returns ErrNotFound when there are no rows
+func scanOne[T any](ac *Assister, rows *sql.Rows) (T, error) {
+	defer rows.Close()
+
+	plan, err := newRowsScanPlan[T](ac, rows)
+	if err != nil {
+		var result T
+		return result, err
+	}
+
+	return scanFirst(plan, rows)
+}
+
+// scanFirst scans the first row into a T with plan, see scanOne
+func scanFirst[T any](plan *scanPlan[T], rows *sql.Rows) (T, error) {
+	var result T
+	if !rows.Next() {
+		err := rows.Err()
+		if err != nil {
+			return result, err
+		}
+		return result, ErrNotFound
+	}
+
+	result, err := plan.scan(rows)
+	if err != nil {
+		return result, err
+	}
+
+	return result, rows.Err()
+}
+
+// scanPlan holds the field each result column is scanned into for a given T
+type scanPlan[T any] struct {
+	columns []string
+	fields  []*fieldInfo
+	isPtr   bool
+	isValue bool
+	// decoding decodes the values rows.Scan can't, see decodeDest
+	decoding decoding
+	// emptyNull reads NULL into every string field as "", not only those tagged emptynull, see WithEmptyAsNull
+	emptyNull bool
+	// recovery recovers the panics of planning & scanning, see WithRecoverPanics
+	recovery panicRecovery
+	// groups are the columns read through nested struct pointers, grouped[i] is set for the columns in one
+	groups  []scanGroup
+	grouped []bool
+}
+
+// scanGroup is the columns read through a nested struct pointer. They are read raw first so the pointer can be left nil
+// when they are all NULL, fields that can't hold NULL would otherwise fail to scan a LEFT JOIN's missing match
+type scanGroup struct {
+	columns []int
+}
+
+func newRowsScanPlan[T any](ac *Assister, rows *sql.Rows) (*scanPlan[T], error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	return newScanPlan[T](ac, columns)
+}
+
+func newScanPlan[T any](ac *Assister, columns []string) (_ *scanPlan[T], err error) {
+	recovery := ac.panicRecovery()
+	defer recovery.recover(&err)
+
+	plan := &scanPlan[T]{columns: columns, decoding: ac.decoding(), emptyNull: ac.emptyNull, recovery: recovery}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	structType := t
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+
+	// Anything that is not a plain struct is scanned whole, a pointer T scanning NULL as nil
+	if structType.Kind() != reflect.Struct || isValueType(t) || isValueType(structType) {
+		if len(columns) != 1 {
+			return nil, fmt.Errorf("cannot scan %d columns into %s: expected a single column", len(columns), t)
+		}
+		plan.isValue = true
+		return plan, nil
+	}
+	plan.isPtr = t.Kind() == reflect.Pointer
+
+	info := getStructInfo(structType)
+	plan.fields = make([]*fieldInfo, len(columns))
+	plan.grouped = make([]bool, len(columns))
+	groupOf := map[string]int{}
+	for i, column := range columns {
+		fi, err := info.lookup(column)
+		if err != nil {
+			return nil, err
+		}
+		plan.fields[i] = fi
+
+		if fi.nullGroup == nil {
+			continue
+		}
+		key := fmt.Sprint(fi.nullGroup)
+		group, ok := groupOf[key]
+		if !ok {
+			group = len(plan.groups)
+			groupOf[key] = group
+			plan.groups = append(plan.groups, scanGroup{})
+		}
+		plan.groups[group].columns = append(plan.groups[group].columns, i)
+		plan.grouped[i] = true
+	}
+
+	return plan, nil
+}
+
+// scan scans the current row into a T, any extra destinations receive the columns following the planned ones
+func (plan *scanPlan[T]) scan(rows *sql.Rows, extra ...any) (result T, err error) {
+	defer plan.recovery.recover(&err)
+
+	planned := plan.dests(&result)
+	dest := append(planned[:len(planned):len(planned)], extra...)
+
+	err = rows.Scan(dest...)
+	if err != nil {
+		return result, err
+	}
+
+	return result, plan.fillGroups(&result, planned)
+}
+
+// dests returns the destinations the planned columns are scanned into for result, allocating result when T is a struct pointer
+func (plan *scanPlan[T]) dests(result *T) []any {
+	target := reflect.ValueOf(result).Elem()
+	if plan.isPtr {
+		target.Set(reflect.New(target.Type().Elem()))
+		target = target.Elem()
+	}
+
+	if plan.isValue {
+		return []any{scanDest(target, plan.decoding)}
+	}
+
+	dest := make([]any, len(plan.fields))
+	for i, fi := range plan.fields {
+		if plan.grouped[i] {
+			dest[i] = new(any)
+			continue
+		}
+		dest[i] = scanDest(fieldByIndex(target, fi.index), plan.fieldDecoding(fi))
+	}
+
+	return dest
+}
+
+// fillGroups assigns the raw values read for the nested struct pointer groups of result, leaving a group's pointer nil
+// when all of its columns are NULL. A group nested in another allocates the outer pointer when it isn't all NULL
+func (plan *scanPlan[T]) fillGroups(result *T, dest []any) error {
+	if len(plan.groups) == 0 {
+		return nil
+	}
+
+	target := reflect.ValueOf(result).Elem()
+	if plan.isPtr {
+		target = target.Elem()
+	}
+
+	for _, group := range plan.groups {
+		allNull := true
+		for _, i := range group.columns {
+			if *dest[i].(*any) != nil {
+				allNull = false
+				break
+			}
+		}
+		if allNull {
+			continue
+		}
+
+		for _, i := range group.columns {
+			err := assignDest(scanDest(fieldByIndex(target, plan.fields[i].index), plan.fieldDecoding(plan.fields[i])), *dest[i].(*any))
+			if err != nil {
+				return fmt.Errorf("column %q: %w", plan.columns[i], err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// assign assigns values already read from the driver into a T
+func (plan *scanPlan[T]) assign(values []any) (result T, err error) {
+	defer plan.recovery.recover(&err)
+
+	dest := plan.dests(&result)
+	for i := range dest {
+		err := assignDest(dest[i], values[i])
+		if err != nil {
+			return result, fmt.Errorf("column %q: %w", plan.columns[i], err)
+		}
+	}
+
+	return result, plan.fillGroups(&result, dest)
+}
+
+// fieldDecoding returns how the column read into fi is decoded, reading NULL as "" when fi is tagged emptynull
+// or the Assister writes every empty string as NULL, & decrypting it when fi is tagged encrypted
+func (plan *scanPlan[T]) fieldDecoding(fi *fieldInfo) decoding {
+	d := plan.decoding
+	d.emptyNull = plan.emptyNull || fi.options["emptynull"]
+	d.encrypted = fi.options["encrypted"]
+	return d
+}
+
+// scanDest returns the destination a column is scanned into for target, a decryptDest for an encrypted column,
+// a converterDest when a converter is registered for target's type (see utils.RegisterScanConverter)
+// & a decodeDest decoding with d the types rows.Scan can't read from text, or the strings it reads NULL into.
+// An enumDest checks the value scanned into a registered enum type, see utils.RegisterEnum
+func scanDest(target reflect.Value, d decoding) any {
+	dest := valueDest(target, d)
+	if utils.IsEnum(target.Type()) {
+		return &enumDest{target: target, dest: dest, decoding: d}
+	}
+
+	return dest
+}
+
+// valueDest returns the destination a column is scanned into for target, see scanDest
+func valueDest(target reflect.Value, d decoding) any {
+	if d.encrypted {
+		return &decryptDest{target: target, decoding: d}
+	}
+	converter, ok := utils.LookupScanConverter(target.Type())
+	if ok {
+		return &converterDest{target: target, converter: converter, recovery: d.recovery}
+	}
+	if decodesText(target.Type()) || d.emptyNull && target.Kind() == reflect.String {
+		return &decodeDest{target: target, decoding: d}
+	}
+
+	return target.Addr().Interface()
+}
+
+// decodesText reports whether t is a time or bool type, which rows.Scan can't read from the []byte drivers using a text protocol
+// (MySQL's, sometimes SQLite's) return for them, "2006-01-02 15:04:05" & BIT(1)'s raw \x01, nor from booleans stored as 'Y' & 'N'
+func decodesText(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t == timeType || t == nullTimeType || t == nullBoolType || t.Kind() == reflect.Bool
+}
+
+// decodeDest scans a column with assignValue, which parses text with strconv & utils.ParseTime before giving up.
+// The Assister's time layouts & boolean strings are applied, see WithTimeLayouts & WithBoolStrings
+type decodeDest struct {
+	target   reflect.Value
+	decoding decoding
+}
+
+func (d *decodeDest) Scan(src any) (err error) {
+	defer d.decoding.recovery.recover(&err)
+
+	return assignValue(d.target, src, d.decoding)
+}
+
+// converterDest scans a column through a registered ScanConverter
+type converterDest struct {
+	target    reflect.Value
+	converter utils.ScanConverter
+	recovery  panicRecovery
+}
+
+func (d *converterDest) Scan(src any) (err error) {
+	defer d.recovery.recover(&err)
+
+	converted, err := d.converter(src)
+	if err != nil {
+		return err
+	}
+	if converted == nil {
+		d.target.Set(reflect.Zero(d.target.Type()))
+		return nil
+	}
+
+	value := reflect.ValueOf(converted)
+	if !value.Type().AssignableTo(d.target.Type()) {
+		return fmt.Errorf("scan converter for %s returned %T", d.target.Type(), converted)
+	}
+	d.target.Set(value)
+
+	return nil
+}
+
+// enumDest scans a column into a registered enum type through dest, failing for a value that isn't one of the type's
+type enumDest struct {
+	target   reflect.Value
+	dest     any
+	decoding decoding
+}
+
+func (d *enumDest) Scan(src any) (err error) {
+	defer d.decoding.recovery.recover(&err)
+
+	if scanner, ok := d.dest.(sql.Scanner); ok {
+		err = scanner.Scan(src)
+	} else {
+		err = assignValue(d.target, src, d.decoding)
+	}
+	if err != nil {
+		return err
+	}
+
+	return utils.CheckEnum(d.target.Interface())
+}
+
+// valueOf returns the settable value ptr points to
+func valueOf(ptr any) reflect.Value {
+	return reflect.ValueOf(ptr).Elem()
+}
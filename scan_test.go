@@ -0,0 +1,50 @@
+package sqlAssister
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const orderTable = `CREATE TABLE "orders" ("id" INTEGER PRIMARY KEY, "status" TEXT NOT NULL, "amount" REAL NOT NULL)`
+
+func TestSelectGroupByAliases(t *testing.T) {
+	db := openTestDB(t, orderTable,
+		`INSERT INTO "orders" ("status", "amount") VALUES ('paid', 10), ('paid', 30), ('shipped', 5), ('refunded', 7.5), ('refunded', 2.5)`)
+	ac := New(db, WithDialect(SQLite))
+
+	type statusTotal struct {
+		Status    string  `db:"status"`
+		Cnt       int     `db:"cnt"`
+		AvgAmount float64 `db:"avg_amount"`
+	}
+	totals, err := Select[statusTotal](context.Background(), ac,
+		`SELECT "status", COUNT(*) AS cnt, AVG("amount") AS avg_amount FROM "orders" GROUP BY "status" ORDER BY "status"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []statusTotal{{"paid", 2, 20}, {"refunded", 2, 5}, {"shipped", 1, 5}}
+	if len(totals) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, totals)
+	}
+	for i := range expected {
+		if totals[i] != expected[i] {
+			t.Errorf("group %d: expected %+v, got %+v", i, expected[i], totals[i])
+		}
+	}
+}
+
+func TestSelectUnaliasedAggregate(t *testing.T) {
+	db := openTestDB(t, orderTable, `INSERT INTO "orders" ("status", "amount") VALUES ('paid', 10)`)
+	ac := New(db, WithDialect(SQLite))
+
+	type statusCount struct {
+		Status string `db:"status"`
+		Cnt    int    `db:"cnt"`
+	}
+	_, err := Select[statusCount](context.Background(), ac, `SELECT "status", COUNT(*) FROM "orders" GROUP BY "status"`)
+	if err == nil || !strings.Contains(err.Error(), "COUNT(*)") {
+		t.Errorf("expected an error naming the unaliased COUNT(*) column, got %v", err)
+	}
+}
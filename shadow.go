@@ -0,0 +1,268 @@
+package sqlAssister
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxShadowDiffs caps the differences a ShadowReport lists, a shadow query reading the wrong table would otherwise list every record
+const maxShadowDiffs = 10
+
+// ShadowReader runs the shadow queries of ShadowRead & reports where their results differ from the primary queries', so a query
+// being migrated, e.g. to a new table or schema, can be checked against production traffic before it is switched over.
+// It is shared by every Assister it is given to & its kill switch, Disable, stops the shadow queries of all of them at once.
+// A ShadowReader is safe for concurrent use
+type ShadowReader struct {
+	timeout time.Duration
+	report  func(ShadowReport)
+	// ignored holds the Go field names & columns left out of the comparison, e.g. timestamps the migration rewrites
+	ignored  map[string]bool
+	disabled atomic.Bool
+	inFlight sync.WaitGroup
+}
+
+// ShadowReport describes a shadow query that failed or whose results differ from those of its primary query
+type ShadowReport struct {
+	PrimaryQuery string
+	ShadowQuery  string
+	// Err is the error of the shadow query, in which case there is nothing to compare & Diffs is empty
+	Err error
+	// Diffs describes the differences found, e.g. `record 2 "name": "Dune" != "dune"`, up to 10 of them
+	Diffs []string
+}
+
+// NewShadowReader returns an enabled ShadowReader giving each shadow query up to timeout & passing report every shadow query that
+// fails or differs from its primary query. Fields named in ignoreFields, by Go field name or column, aren't compared.
+// A nil report logs the reports to the Assister's logger instead
+/*
+
+Example:
+
+	shadows := sqlAssister.NewShadowReader(200*time.Millisecond, func(report sqlAssister.ShadowReport) {
+		metrics.ShadowMismatches.WithLabelValues(report.ShadowQuery).Inc()
+	}, "UpdatedAt")
+	Assister := sqlAssister.New(db, sqlAssister.WithShadowReader(shadows))
+*/
+func NewShadowReader(timeout time.Duration, report func(ShadowReport), ignoreFields ...string) *ShadowReader {
+	ignored := make(map[string]bool, len(ignoreFields))
+	for _, field := range ignoreFields {
+		ignored[field] = true
+	}
+
+	return &ShadowReader{
+		timeout: timeout,
+		report:  report,
+		ignored: ignored,
+	}
+}
+
+// WithShadowReader makes ShadowRead run its shadow queries with shadows. Without one ShadowRead only runs the primary query
+func WithShadowReader(shadows *ShadowReader) Option {
+	return func(ac *Assister) {
+		ac.shadowReader = shadows
+	}
+}
+
+// Disable stops ShadowRead from running shadow queries on every Assister sharing the ShadowReader, queries already running finish
+func (s *ShadowReader) Disable() {
+	s.disabled.Store(true)
+}
+
+// Enable resumes the shadow queries stopped by Disable
+func (s *ShadowReader) Enable() {
+	s.disabled.Store(false)
+}
+
+// Enabled reports whether shadow queries are run
+func (s *ShadowReader) Enabled() bool {
+	return !s.disabled.Load()
+}
+
+// Wait blocks until the shadow queries running have finished & been reported, e.g. before shutting down
+func (s *ShadowReader) Wait() {
+	s.inFlight.Wait()
+}
+
+// ShadowRead follows the same rules as Select for primaryQuery, whose records & error are returned, while running shadowQuery
+// with the same args in the background & comparing the records both read, in order, field by field, see WithShadowReader.
+// The shadow query never fails nor slows down the call: its errors & differences are only reported, it is given the
+// ShadowReader's timeout rather than ctx's deadline so it isn't cut short when the call returns, & nothing is compared when the
+// primary query fails. Both queries must order their records the same way. Only the primary query runs when the Assister has no
+// ShadowReader, it is disabled or the Assister is bound to a transaction, which can't run two queries at once
+/*
+
+Example:
+
+	books, err := sqlAssister.ShadowRead[Book](ctx, Assister,
+		`SELECT "id", "name" FROM "books" WHERE "author_id" = $1 ORDER BY "id"`,
+		`SELECT "id", "name" FROM "catalog"."books_v2" WHERE "author_id" = $1 ORDER BY "id"`, authorId)
+	if err != nil {
+		return nil, err
+	}
+*/
+func ShadowRead[T any](ctx context.Context, ac *Assister, primaryQuery string, shadowQuery string, args ...any) ([]T, error) {
+	shadows := ac.shadowReader
+	if shadows == nil || !shadows.Enabled() || ac.tx != nil {
+		return Select[T](ctx, ac, primaryQuery, args...)
+	}
+
+	primaryResults := make(chan []T, 1)
+	shadows.inFlight.Add(1)
+	go func() {
+		defer shadows.inFlight.Done()
+
+		shadowCtx, cancel := context.WithTimeout(detachedContext{ctx}, shadows.timeout)
+		defer cancel()
+		shadowed, err := Select[T](shadowCtx, ac, shadowQuery, args...)
+
+		primary, ok := <-primaryResults
+		if !ok {
+			return
+		}
+		report := ShadowReport{PrimaryQuery: primaryQuery, ShadowQuery: shadowQuery, Err: err}
+		if err == nil {
+			report.Diffs = shadows.diff(reflect.ValueOf(primary), reflect.ValueOf(shadowed), structInfoOf[T]())
+		}
+		if report.Err != nil || len(report.Diffs) > 0 {
+			shadows.send(ac, report)
+		}
+	}()
+
+	results, err := Select[T](ctx, ac, primaryQuery, args...)
+	if err != nil {
+		close(primaryResults)
+		return nil, err
+	}
+	primaryResults <- results
+
+	return results, nil
+}
+
+// send hands report to the ShadowReader's report func, logging it when there is none or the func panics
+func (s *ShadowReader) send(ac *Assister, report ShadowReport) {
+	logger := ac.getLogger()
+	if s.report == nil {
+		if report.Err != nil {
+			logger.Printf("shadow query %q failed: %v", report.ShadowQuery, report.Err)
+			return
+		}
+		logger.Printf("shadow query %q differs from %q: %v", report.ShadowQuery, report.PrimaryQuery, report.Diffs)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Printf("shadow report of %q panicked: %v", report.ShadowQuery, r)
+		}
+	}()
+	s.report(report)
+}
+
+// diff describes the differences between the records of primary & shadow, two slices of the same type whose records are compared
+// field by field with info when they are structs or pointers to structs
+func (s *ShadowReader) diff(primary reflect.Value, shadow reflect.Value, info *structInfo) []string {
+	var diffs []string
+	truncated := 0
+	add := func(format string, args ...any) {
+		if len(diffs) >= maxShadowDiffs {
+			truncated++
+			return
+		}
+		diffs = append(diffs, fmt.Sprintf(format, args...))
+	}
+	if primary.Len() != shadow.Len() {
+		add("%d records != %d records", primary.Len(), shadow.Len())
+	}
+
+	for i := 0; i < primary.Len() && i < shadow.Len(); i++ {
+		p, sh := primary.Index(i), shadow.Index(i)
+		if info != nil && p.Kind() == reflect.Pointer {
+			if p.IsNil() || sh.IsNil() {
+				if p.IsNil() != sh.IsNil() {
+					add("record %d: %s != %s", i, shadowFormat(p, nil), shadowFormat(sh, nil))
+				}
+				continue
+			}
+			p, sh = p.Elem(), sh.Elem()
+		}
+		if info == nil {
+			if !shadowEqual(p, sh) {
+				add("record %d: %s != %s", i, shadowFormat(p, nil), shadowFormat(sh, nil))
+			}
+			continue
+		}
+
+		for _, fi := range info.fields {
+			if s.ignored[fi.name] || s.ignored[fi.column] {
+				continue
+			}
+			pf, pErr := p.FieldByIndexErr(fi.index)
+			sf, sErr := sh.FieldByIndexErr(fi.index)
+			// A field read through a nil nested struct pointer only equals another such field
+			if pErr != nil || sErr != nil {
+				if (pErr == nil) == (sErr == nil) {
+					continue
+				}
+			} else if shadowEqual(pf, sf) {
+				continue
+			}
+			add("record %d %q: %s != %s", i, fi.column, shadowFormat(pf, pErr), shadowFormat(sf, sErr))
+		}
+	}
+	if truncated > 0 {
+		diffs = append(diffs, fmt.Sprintf("& %d more", truncated))
+	}
+
+	return diffs
+}
+
+// shadowEqual compares two decoded values, times being compared as instants as their locations depend on the driver & connection
+func shadowEqual(a reflect.Value, b reflect.Value) bool {
+	if a.Type() == timeType {
+		return a.Interface().(time.Time).Equal(b.Interface().(time.Time))
+	}
+	if a.Kind() == reflect.Pointer && a.Type().Elem() == timeType {
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return a.Elem().Interface().(time.Time).Equal(b.Elem().Interface().(time.Time))
+	}
+
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// shadowFormat writes a field of a ShadowReport difference, nil when it is read through a nil nested struct pointer
+func shadowFormat(v reflect.Value, err error) string {
+	if err != nil {
+		return "nil"
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "nil"
+		}
+		v = v.Elem()
+	}
+
+	return fmt.Sprintf("%#v", v.Interface())
+}
+
+// detachedContext keeps the values of a context, e.g. its tracing span, without its cancellation & deadline
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
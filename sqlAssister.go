@@ -33,20 +33,16 @@ Example:
 		const statement = `
 			SELECT
 				"ID",
-				"cpu_temp",
-				"fan_speed",
-				"hdd_space",
-				"last_logged_in",
-				"sys_time"
-			FROM "Network"."vw_device"
+				"name"
+			FROM "Library"."books"
 			WHERE "ID" = $1;`
 
-		row, err := statementAssister.SingleRowScanner(statement, bookId)
+		row, err := statementAssister.SingleRowScannerWithArgs(statement, bookId)
 		if err != nil {
 			return nil, err
 		}
 
-		err = row.Scan(book)
+		err = row.Scan(&book.ID, &book.Name)
 		if err != nil {
 			return nil, err
 		}
@@ -62,27 +58,258 @@ Example:
 		log.Fatal(book)
 	}
 
+An Assister is safe for concurrent use by multiple goroutines, share one per database rather than creating one per request.
+
+The Example functions of the package run against an in memory SQLite DB & are verified by go test. A runnable bookstore
+covering transactions, struct scanning & pagination end to end lives in the examples module.
+
 See https://pkg.go.dev/database/sql for documentation on the standard sql library
 */
 package sqlAssister
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
 	"github.com/zobstory/sqlAssister/utils"
 )
 
+// Assister executes statements on a *sql.DB with query & error logging. Every method is safe for concurrent use by multiple goroutines:
+// the Assister isn't modified once New returns, methods such as Label return a modified copy, & the caches & registries it shares
+// with other Assisters are synchronized. DB must not be reassigned once the Assister is in use
 type Assister struct {
 	DB *sql.DB
+
+	// name tells the Assister's logs apart from those of Assisters of other DBs, see WithName
+	name           string
+	dialect        Dialect
+	quoting        QuotingMode
+	logger         Logger
+	logQueries     bool
+	logOnErrorOnly bool
+	connReset      []string
+	connResetSet   bool
+	strictRows     bool
+	// slowQueryThreshold logs the statements taking at least as long when positive, see WithSlowQueryThreshold
+	slowQueryThreshold time.Duration
+	// balanceCheck makes the queries the Assister is given checked for unbalanced quotes & parentheses, see WithBalanceCheck
+	balanceCheck bool
+	// autoLimit is appended as a LIMIT to multi record reads when positive, see WithAutoLimit
+	autoLimit int
+	// timeLayouts parse the times read as text & timeBindLayout formats the times of fields tagged timestr, see WithTimeLayouts
+	timeLayouts    []string
+	timeBindLayout string
+	// truthy & falsy are the strings booleans are read from & written as, see WithBoolStrings
+	truthy []string
+	falsy  []string
+	// emptyNull writes the empty strings of every string field as NULL & reads NULL back as "", see WithEmptyAsNull
+	emptyNull bool
+	// blobChunkSize, maxBlobSize & blobProgress shape the streaming of blobs, see StreamBlobTo
+	blobChunkSize int
+	maxBlobSize   int64
+	blobProgress  func(transferred int64)
+	// notificationWaiter waits for the notifications of Listen, see WithNotificationWaiter
+	notificationWaiter NotificationWaiter
+	// queryCache holds the results of CachedSelect, see WithQueryCache
+	queryCache *QueryCache
+	// checkpoints make batch helpers commit chunk by chunk, see WithCheckpoints
+	checkpoints *checkpoints
+	// metadata caches the TableMeta of the Assister & its copies for metadataTTL, see WithMetadataTTL
+	metadata       *metadataCache
+	metadataTTL    time.Duration
+	metadataTTLSet bool
+	// probeQuery is the query Probe runs, see WithProbeQuery
+	probeQuery string
+	// idCounterTable holds the counters of AllocateIDBlock, creating missing ones with autoCreateCounters, see WithIDCounters
+	idCounterTable     string
+	autoCreateCounters bool
+	// shadowReader runs the shadow queries of ShadowRead, see WithShadowReader
+	shadowReader *ShadowReader
+	// queryRegistry holds the queries Warmup prepares, see WithQueryRegistry
+	queryRegistry *QueryRegistry
+	// runOnceTable records the operations RunOnce completed, see WithRunOnceTable
+	runOnceTable string
+	// readOnly refuses the statements that may write, see ReadOnly
+	readOnly bool
+	// transactionPooler keeps the Assister off the session state a transaction pooler doesn't preserve, see WithTransactionPooler
+	transactionPooler bool
+	// fieldCipher encrypts the fields tagged encrypted, as base64 text when encryptedText is set, see WithFieldCipher
+	fieldCipher   Cipher
+	encryptedText bool
+	// clock is the time the Assister reads & waits on, the real clock when nil, see WithClock
+	clock Clock
+	// recoverPanics turns the package's panics into an *InternalError passed to panicHook, see WithRecoverPanics
+	recoverPanics bool
+	panicHook     func(err *InternalError)
+	// appendReturning appends a RETURNING clause listing T's columns to the statements of UpdateReturning without one,
+	// see WithAppendReturning
+	appendReturning bool
+	// retryBudget caps the retries shared by every Assister it is given to, see WithRetryBudget
+	retryBudget *RetryBudget
+	// connRetries is the number of times reads failing with a connection error are retried, see WithConnectionRetries
+	connRetries    int
+	connRetriesSet bool
+	// queryTimeout bounds every statement when positive, see WithDefaultQueryTimeout
+	queryTimeout time.Duration
+	// continueOnError makes Select skip rows that fail to scan, see ContinueOnError
+	continueOnError bool
+	// partialResults makes Select return the rows read before its context was done, see PartialResults
+	partialResults bool
+	// statementComments prepends a comment identifying who issued them to statements, with the fields contextFields extracts,
+	// see WithStatementComments. sqlCommenter appends it in the sqlcommenter format instead, see WithSQLCommenter
+	statementComments bool
+	sqlCommenter      bool
+	contextFields     ContextFields
+	// label names the operation in the errors & logs of failing statements, see Label
+	label string
+	// writeLock makes writes take turns when writes are serialized, see WithSerializedWrites
+	writeLock          *sync.Mutex
+	serializeWrites    bool
+	serializeWritesSet bool
+	// connSetup runs on every connection before it is used, see WithConnectionSetup
+	connSetup       []func(ctx context.Context, conn *sql.Conn) error
+	applicationName string
+	// setupOnConnect is set when the pool runs connSetup on every connection it opens, see NewFromDSN
+	setupOnConnect bool
+	// ownsDB is set when the Assister opened its DB & so closes it, see Close
+	ownsDB bool
+	// q is what statements are executed on, the DB unless the Assister is bound to a transaction or connection
+	q  querier
+	tx *TxAssister
 }
 
 // New returns a new instance of Assister to access the QueryAssister interface
-func New(db *sql.DB) *Assister {
+func New(db *sql.DB, opts ...Option) *Assister {
 	config := &Assister{
 		DB: db,
 	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	if config.writesSerialized() {
+		config.writeLock = &sync.Mutex{}
+	}
+	if config.retryBudget != nil && config.clock != nil {
+		config.retryBudget.useClock(config.clock)
+	}
+	config.metadata = config.newMetadataCache()
 	return config
 }
 
+// Close closes the Assister's DB when the Assister opened it, with NewFromDSN or NewFromConfig. The DB given to New belongs to
+// the caller, who closes it, & Close leaves it open, as do the copies of an Assister bound to a transaction or connection
+func (ac Assister) Close() error {
+	if !ac.ownsDB || ac.q != nil {
+		return nil
+	}
+
+	return ac.DB.Close()
+}
+
+// querier is satisfied by *sql.DB, *sql.Tx & *sql.Conn
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// conn returns what the Assister executes statements on
+func (ac Assister) conn() querier {
+	var q querier = ac.DB
+	if ac.q != nil {
+		q = ac.q
+	}
+	if ac.readOnly {
+		q = readOnlyQuerier{q: q}
+	}
+	if ac.transactionPooler {
+		q = poolerQuerier{q: q, inTx: ac.tx != nil}
+	}
+	if ac.statementComments {
+		q = commentQuerier{q: q, ac: &ac}
+	}
+	q = markedQuerier{q: q}
+	if ac.writeLock != nil {
+		q = serializedQuerier{q: q, writeLock: ac.writeLock, inTx: ac.tx != nil, budget: ac.retryBudget, clock: ac.getClock()}
+	}
+	if ac.q == nil && ac.connectionRetries() > 0 {
+		q = connRetryQuerier{q: q, retries: ac.connectionRetries(), budget: ac.retryBudget, clock: ac.getClock()}
+	}
+
+	if ac.slowQueryThreshold > 0 {
+		q = slowQuerier{q: q, logger: ac.getLogger(), threshold: ac.slowQueryThreshold, label: ac.label, clock: ac.getClock()}
+	}
+	if ac.logQueries || ac.logOnErrorOnly {
+		q = loggingQuerier{q: q, logger: ac.getLogger(), logOnErrorOnly: ac.logOnErrorOnly, label: ac.label}
+	}
+	if ac.label != "" {
+		q = labelQuerier{q: q, label: ac.label}
+	}
+	if ac.queryTimeout > 0 {
+		q = timeoutQuerier{q: q, timeout: ac.queryTimeout, clock: ac.getClock()}
+	}
+
+	return argQuerier{q: q, dialect: ac.dialect}
+}
+
+// limitQuery applies the Assister's auto limit to a multi record read, see WithAutoLimit
+func (ac Assister) limitQuery(query string) string {
+	if ac.autoLimit <= 0 {
+		return query
+	}
+
+	return utils.EnsureLimit(query, ac.autoLimit)
+}
+
+// checkRowsAffected compares the rows affected by a statement to the expected number, see utils.CheckRowsAffected.
+// A check the driver can't support is skipped & noted with the Assister's logger unless WithStrictRowsAffected fails it
+func (ac Assister) checkRowsAffected(results sql.Result, targetNumRowsAffected int64) error {
+	err := utils.CheckRowsAffected(results, targetNumRowsAffected, true)
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, utils.ErrRowsAffectedUnavailable) && !ac.strictRows {
+		ac.noteRowsAffectedSkipped(err)
+		return nil
+	}
+	if ac.logQueries || ac.logOnErrorOnly {
+		ac.getLogger().Printf("ERROR: %s", err)
+	}
+
+	return err
+}
+
+// rowsAffected returns the rows affected by a statement, or expected when the driver can't report them, noting the count skipped,
+// unless WithStrictRowsAffected fails it, see checkRowsAffected
+func (ac Assister) rowsAffected(results sql.Result, expected int64) (int64, error) {
+	rowsAffected, err := utils.RowsAffected(results)
+	if err == nil {
+		return rowsAffected, nil
+	}
+	if ac.strictRows {
+		return 0, err
+	}
+	ac.noteRowsAffectedSkipped(err)
+
+	return expected, nil
+}
+
+// noteRowsAffectedSkipped notes a rows affected check skipped as the driver couldn't report them, whatever the query logging,
+// so a check that never runs leaves a trace. WithStrictRowsAffected fails the check instead
+func (ac Assister) noteRowsAffectedSkipped(err error) {
+	ac.getLogger().Printf("NOTE: rows affected check skipped, the driver did not report rows affected: %s", err)
+}
+
+// Dialect returns the SQL dialect the Assister generates SQL for
+func (ac Assister) Dialect() Dialect {
+	return ac.dialect
+}
+
 // UpdateSingleRow executes any CRUD operation EXCEPT Read for a single record
 /*
 
@@ -94,25 +321,108 @@ Example:
 	}
 */
 func (ac Assister) UpdateSingleRow(query string, args ...any) error {
-	stmt, err := ac.DB.Prepare(query)
+	results, err := ac.conn().ExecContext(context.Background(), query, args...)
+	if err != nil {
+		return err
+	}
+
+	err = ac.checkRowsAffected(results, 1)
 	if err != nil {
 		return err
 	}
-	results, err := stmt.Exec(args...)
+
+	return nil
+}
+
+// DeleteSingleRow executes a DELETE expected to remove a single record.
+// Returns ErrNotFound when no record was deleted & an error when more than one was
+/*
+
+Example:
+
+	err := Assister.DeleteSingleRow(statement, args)
+	if err != nil {
+		return nil, err
+	}
+*/
+func (ac Assister) DeleteSingleRow(query string, args ...any) error {
+	return ac.deleteSingleRow(false, query, args)
+}
+
+// DeleteSingleRowAllowZero executes a DELETE expected to remove at most a single record.
+// Unlike DeleteSingleRow no record being deleted is a success, so deleting a record that is already gone is idempotent
+/*
+
+Example:
+
+	err := Assister.DeleteSingleRowAllowZero(statement, args)
+	if err != nil {
+		return nil, err
+	}
+*/
+func (ac Assister) DeleteSingleRowAllowZero(query string, args ...any) error {
+	return ac.deleteSingleRow(true, query, args)
+}
+
+// UpdateIfChanged executes an UPDATE reporting whether it changed any record.
+// No record being changed is NOT an error, e.g. an "UPDATE ... WHERE version = $1" whose version check failed
+/*
+
+Example:
+
+	changed, err := Assister.UpdateIfChanged(statement, args)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, errors.New("book was modified by someone else")
+	}
+*/
+func (ac Assister) UpdateIfChanged(query string, args ...any) (changed bool, err error) {
+	err = utils.QueryChecker(query, ac.queryChecks()...)
+	if err != nil {
+		return false, err
+	}
+
+	results, err := ac.conn().ExecContext(context.Background(), query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := results.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (ac Assister) deleteSingleRow(allowZero bool, query string, args []any) error {
+	err := utils.QueryChecker(query, ac.queryChecks()...)
 	if err != nil {
 		return err
 	}
 
-	err = utils.GetRowsAffected(results, 1)
+	results, err := ac.conn().ExecContext(context.Background(), query, args...)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	rowsAffected, err := results.RowsAffected()
+	if err == nil && rowsAffected == 0 {
+		if allowZero {
+			return nil
+		}
+		return ErrNotFound
+	}
+
+	return ac.checkRowsAffected(results, 1)
 }
 
 // SingleRowScanner Executes Read operation on a single record & scans a single record into a struct.
 // Expects ONLY a single record to be returned
+// NOTE: the *sql.Row holds a connection of the pool until its Scan is called, a row that is never scanned leaks the connection
+// & enough of them exhaust the pool. Prefer GetInto, which always releases it
 /*
 
 Example:
@@ -129,17 +439,19 @@ Example:
 	}
 */
 func (ac Assister) SingleRowScanner(query string) (*sql.Row, error) {
-	err := utils.QueryChecker(query)
+	err := utils.QueryChecker(query, ac.queryChecks()...)
 	if err != nil {
 		return nil, err
 	}
 
-	row := ac.DB.QueryRow(query)
+	row := ac.conn().QueryRowContext(context.Background(), query)
 	return row, nil
 }
 
 // SingleRowScannerWithArgs Executes Read operation on a single record & scans a single record into a struct.
 // Expects ONLY a single record to be returned
+// NOTE: the *sql.Row holds a connection of the pool until its Scan is called, a row that is never scanned leaks the connection
+// & enough of them exhaust the pool. Prefer GetInto, which always releases it
 /*
 
 Example:
@@ -160,11 +472,55 @@ func (ac Assister) SingleRowScannerWithArgs(query string, args ...any) (*sql.Row
 	if err != nil {
 		return nil, err
 	}
+	err = utils.QueryChecker(query, ac.queryChecks()...)
+	if err != nil {
+		return nil, err
+	}
 
-	row := ac.DB.QueryRow(query, args...)
+	row := ac.conn().QueryRowContext(context.Background(), query, args...)
 	return row, nil
 }
 
+// GetInto Executes Read operation on a single record & scans its columns into dest as row.Scan does, always releasing the
+// connection before returning whether the record was scanned or not. Returns ErrNotFound when no record is found, further
+// records are discarded. Use it rather than SingleRowScanner, whose *sql.Row leaks its connection when it isn't scanned
+/*
+
+Example:
+
+	book := &Book{}
+	err := Assister.GetInto(ctx, `SELECT "id", "name" FROM "books" WHERE "id" = $1`, []any{bookId}, &book.ID, &book.Name)
+	if err != nil {
+		return nil, err
+	}
+*/
+func (ac Assister) GetInto(ctx context.Context, query string, args []any, dest ...any) error {
+	err := utils.QueryChecker(query, ac.queryChecks()...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := ac.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		err = rows.Err()
+		if err != nil {
+			return err
+		}
+		return ErrNotFound
+	}
+	err = rows.Scan(dest...)
+	if err != nil {
+		return err
+	}
+
+	return rows.Close()
+}
+
 // MultipleRowScanner Executes Read operation on multiple records & scans them into a slice of a struct
 // NOTE: MultipleRowScanner can work with a single record BUT please use SingleRowScanner if you are only expecting a single record to be found
 /*
@@ -187,12 +543,12 @@ Example:
 	}
 */
 func (ac Assister) MultipleRowScanner(query string) (*sql.Rows, error) {
-	err := utils.QueryChecker(query)
+	err := utils.QueryChecker(query, ac.queryChecks()...)
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := ac.DB.Query(query)
+	rows, err := ac.conn().QueryContext(context.Background(), ac.limitQuery(query))
 	if err != nil {
 		return nil, err
 	}
@@ -226,8 +582,12 @@ func (ac Assister) MultipleRowScannerWithArgs(query string, args ...any) (*sql.R
 	if err != nil {
 		return nil, err
 	}
+	err = utils.QueryChecker(query, ac.queryChecks()...)
+	if err != nil {
+		return nil, err
+	}
 
-	rows, err := ac.DB.Query(query, args...)
+	rows, err := ac.conn().QueryContext(context.Background(), ac.limitQuery(query), args...)
 	if err != nil {
 		return nil, err
 	}
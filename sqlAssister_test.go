@@ -0,0 +1,83 @@
+package sqlAssister
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// fakeResult is a sql.Result reporting rows affected as a driver would
+type fakeResult struct {
+	rowsAffected int64
+	err          error
+}
+
+func (r fakeResult) LastInsertId() (int64, error) { return 0, errors.New("not supported") }
+
+func (r fakeResult) RowsAffected() (int64, error) { return r.rowsAffected, r.err }
+
+func TestCheckRowsAffectedLogging(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []Option
+		result fakeResult
+		err    error
+		logged []string
+	}{
+		{"skip noted", nil, fakeResult{rowsAffected: -1}, nil, []string{"NOTE: rows affected check skipped"}},
+		{"skip noted logging errors only", []Option{WithLogOnErrorOnly()}, fakeResult{err: errors.New("not supported")}, nil,
+			[]string{"NOTE: rows affected check skipped"}},
+		{"skip noted with query logging", []Option{WithQueryLogging(), WithName("primary")}, fakeResult{rowsAffected: -1}, nil,
+			[]string{"[primary] NOTE: rows affected check skipped"}},
+		{"strict skip", []Option{WithStrictRowsAffected(), WithLogOnErrorOnly()}, fakeResult{rowsAffected: -1},
+			utils.ErrRowsAffectedUnavailable, []string{"ERROR: rows affected unavailable"}},
+		{"mismatch logged on error", []Option{WithLogOnErrorOnly(), WithName("replica")}, fakeResult{rowsAffected: 2},
+			&RowsAffectedError{Affected: 2, Expected: 1}, []string{"[replica] ERROR: number of rows affected does not match"}},
+		{"mismatch without logging", nil, fakeResult{rowsAffected: 2}, &RowsAffectedError{Affected: 2, Expected: 1}, nil},
+		{"match", []Option{WithQueryLogging()}, fakeResult{rowsAffected: 1}, nil, nil},
+	}
+
+	// Nothing may reach the standard library's default logger, which WithLogger replaces
+	var standard bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&standard)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logger := &bufferLogger{}
+			ac := New(nil, append(test.opts, WithLogger(logger))...)
+
+			err := ac.checkRowsAffected(test.result, 1)
+			var mismatch *RowsAffectedError
+			switch {
+			case test.err == nil && err != nil:
+				t.Errorf("expected no error, got %v", err)
+			case errors.As(test.err, &mismatch):
+				var got *RowsAffectedError
+				if !errors.As(err, &got) || *got != *mismatch {
+					t.Errorf("expected %v, got %v", test.err, err)
+				}
+			case test.err != nil && !errors.Is(err, test.err):
+				t.Errorf("expected %v, got %v", test.err, err)
+			}
+
+			lines := logger.Lines()
+			if len(lines) != len(test.logged) {
+				t.Fatalf("expected %d lines logged, got %q", len(test.logged), lines)
+			}
+			for i, prefix := range test.logged {
+				if !strings.HasPrefix(lines[i], prefix) {
+					t.Errorf("expected a line starting with %q, got %q", prefix, lines[i])
+				}
+			}
+		})
+	}
+
+	if standard.Len() > 0 {
+		t.Errorf("expected nothing logged with the standard logger, got %q", standard.String())
+	}
+}
@@ -0,0 +1,142 @@
+// Package sqlAssistertest provides helpers for testing code built on sqlAssister
+package sqlAssistertest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zobstory/sqlAssister"
+)
+
+// FakeClock is a sqlAssister.Clock whose time only moves when Advance is called, for testing retry backoffs, slow query
+// thresholds & TTLs deterministically. Sleeps & timers wait until the clock is advanced past them. A FakeClock is safe for
+// concurrent use: the code under test typically waits on it in one goroutine while the test advances it from another, calling
+// BlockUntil first so it doesn't advance before the wait has started
+/*
+
+Example:
+
+	clock := sqlAssistertest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	statementAssister := sqlAssister.New(db, sqlAssister.WithClock(clock), sqlAssister.WithConnectionRetries(1))
+
+	done := make(chan error)
+	go func() {
+		_, err := sqlAssister.Select[Book](ctx, statementAssister, `SELECT "id", "name" FROM "books"`)
+		done <- err
+	}()
+
+	clock.BlockUntil(1) // the read failed & backs off before retrying
+	clock.Advance(time.Second)
+	err := <-done
+*/
+type FakeClock struct {
+	mu      sync.Mutex
+	waiters *sync.Cond
+	now     time.Time
+	timers  []*fakeTimer
+}
+
+// NewFakeClock returns a FakeClock reading now until it is advanced
+func NewFakeClock(now time.Time) *FakeClock {
+	clock := &FakeClock{now: now}
+	clock.waiters = sync.NewCond(&clock.mu)
+	return clock
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Sleep blocks until the clock is advanced by d
+func (c *FakeClock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// NewTimer returns a Timer firing once the clock is advanced by d, at once when d isn't positive
+func (c *FakeClock) NewTimer(d time.Duration) sqlAssister.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	timer := &fakeTimer{clock: c, at: c.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		timer.c <- c.now
+		return timer
+	}
+	c.timers = append(c.timers, timer)
+	c.waiters.Broadcast()
+
+	return timer
+}
+
+// Advance moves the clock forward by d, firing the timers due by then in the order they are due
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].at.Before(c.timers[j].at)
+	})
+	pending := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.at.After(c.now) {
+			pending = append(pending, timer)
+			continue
+		}
+		timer.c <- c.now
+	}
+	c.timers = pending
+	c.waiters.Broadcast()
+}
+
+// BlockUntil blocks until at least n timers, sleeps included, are waiting for the clock to be advanced
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.timers) < n {
+		c.waiters.Wait()
+	}
+}
+
+// Waiters returns the number of timers, sleeps included, waiting for the clock to be advanced
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.timers)
+}
+
+// fakeTimer is a Timer of a FakeClock
+type fakeTimer struct {
+	clock *FakeClock
+	at    time.Time
+	c     chan time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	for i, timer := range t.clock.timers {
+		if timer == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			t.clock.waiters.Broadcast()
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,239 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// SQLite allows a single writer at a time, concurrent writes fail with "database is locked" (SQLITE_BUSY).
+// With serialized writes, which are on by default for the SQLite dialect, the Assister makes its writes take turns:
+// every statement that isn't a read holds a lock shared by the Assister & its copies while it executes, a transaction that may write
+// holds it from begin to commit or rollback, & a statement still failing with SQLITE_BUSY (e.g. locked by another process) is retried with backoff.
+// SQLite executes a write returning records, such as UPDATE ... RETURNING, as its records are read: they are read whole while it
+// holds the lock & replayed to the caller, see replayRows. Reads don't take the lock & stay concurrent.
+
+const (
+	// busyRetryLimit bounds how long a busy statement is retried when its context has no deadline
+	busyRetryLimit = 5 * time.Second
+	busyBackoffMin = 5 * time.Millisecond
+	busyBackoffMax = 200 * time.Millisecond
+)
+
+// DefaultSQLitePragmas are the pragmas SQLiteConnector applies when none are given:
+// waiting up to 5 seconds for a lock instead of failing at once & write-ahead logging so readers don't block the writer
+var DefaultSQLitePragmas = []string{
+	"PRAGMA busy_timeout = 5000",
+	"PRAGMA journal_mode = WAL",
+}
+
+// WithSerializedWrites turns the serialization of writes on or off. Defaults to on for the SQLite dialect & off otherwise.
+// Only statements executed through Assisters sharing the lock, i.e. created by the same New call, take turns.
+// As a transaction holds the lock, writing through the Assister rather than the TxAssister inside WithTransaction waits forever
+func WithSerializedWrites(enabled bool) Option {
+	return func(ac *Assister) {
+		ac.serializeWrites = enabled
+		ac.serializeWritesSet = true
+	}
+}
+
+// writesSerialized reports whether the Assister's writes take turns once its options are applied
+func (ac Assister) writesSerialized() bool {
+	if ac.serializeWritesSet {
+		return ac.serializeWrites
+	}
+
+	return ac.dialect == SQLite
+}
+
+// lockWrites takes the write lock for a transaction, returning the func releasing it.
+// A read only Assister's transaction can't write & so doesn't take it, staying concurrent like the reads
+func (ac Assister) lockWrites() func() {
+	if ac.writeLock == nil || ac.readOnly {
+		return func() {}
+	}
+
+	ac.writeLock.Lock()
+	var once sync.Once
+	return func() {
+		once.Do(ac.writeLock.Unlock)
+	}
+}
+
+// serializedQuerier makes the writes executed on q take turns & retries statements failing with SQLITE_BUSY.
+// Statements in a transaction neither lock, the transaction already holds the lock, nor retry
+type serializedQuerier struct {
+	q         querier
+	writeLock *sync.Mutex
+	inTx      bool
+	budget    *RetryBudget
+	clock     Clock
+}
+
+func (q serializedQuerier) lock(query string) func() {
+	if !q.takesLock(query) {
+		return func() {}
+	}
+
+	q.writeLock.Lock()
+	return q.writeLock.Unlock
+}
+
+func (q serializedQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	unlock := q.lock(query)
+	defer unlock()
+
+	return retryBusy(ctx, !q.inTx, q.budget, q.clock, func() (sql.Result, error) {
+		return q.q.ExecContext(ctx, query, args...)
+	})
+}
+
+func (q serializedQuerier) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return retryBusy(ctx, !q.inTx, q.budget, q.clock, func() (*sql.Stmt, error) {
+		return q.q.PrepareContext(ctx, query)
+	})
+}
+
+func (q serializedQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if q.takesLock(query) {
+		r, err := q.queryLocked(ctx, query, args)
+		if err != nil {
+			return nil, err
+		}
+		return replayRows(ctx, query, r)
+	}
+
+	return retryBusy(ctx, !q.inTx, q.budget, q.clock, func() (*sql.Rows, error) {
+		return q.q.QueryContext(ctx, query, args...)
+	})
+}
+
+func (q serializedQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	if q.takesLock(query) {
+		r, err := q.queryLocked(ctx, query, args)
+		if err != nil {
+			r = &replay{err: err}
+		}
+		return replayRow(ctx, query, r)
+	}
+
+	row, _ := retryBusy(ctx, !q.inTx, q.budget, q.clock, func() (*sql.Row, error) {
+		row := q.q.QueryRowContext(ctx, query, args...)
+		return row, row.Err()
+	})
+	return row
+}
+
+// takesLock reports whether query is a write executed outside of a transaction, which takes the write lock
+func (q serializedQuerier) takesLock(query string) bool {
+	return !q.inTx && utils.DetectStatementKind(query) != utils.StatementSelect
+}
+
+// queryLocked executes a write returning records & reads them whole while holding the write lock, as SQLite only finishes
+// the write once they are read
+func (q serializedQuerier) queryLocked(ctx context.Context, query string, args []any) (*replay, error) {
+	q.writeLock.Lock()
+	defer q.writeLock.Unlock()
+
+	return retryBusy(ctx, true, q.budget, q.clock, func() (*replay, error) {
+		rows, err := q.q.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		return readReplay(rows, -1)
+	})
+}
+
+// retryBusy runs fn until it doesn't fail with SQLITE_BUSY, backing off on clock between attempts,
+// for as long as ctx allows or busyRetryLimit when ctx has no deadline & budget has retries left.
+// The time left before a deadline of ctx is measured on clock
+func retryBusy[R any](ctx context.Context, retry bool, budget *RetryBudget, clock Clock, fn func() (R, error)) (R, error) {
+	result, err := fn()
+	if !retry || !utils.IsBusyError(err) {
+		return result, err
+	}
+
+	deadline, hasDeadline := ctx.Deadline()
+	started := clock.Now()
+	canWait := func(backoff time.Duration) bool {
+		if hasDeadline {
+			return deadline.Sub(clock.Now()) > backoff
+		}
+		return clock.Now().Sub(started)+backoff < busyRetryLimit
+	}
+
+	backoff := busyBackoffMin
+	for utils.IsBusyError(err) && canWait(backoff) && budget.take(clock.Now()) {
+		if !sleepContext(ctx, clock, backoff) {
+			return result, err
+		}
+
+		result, err = fn()
+		backoff *= 2
+		if backoff > busyBackoffMax {
+			backoff = busyBackoffMax
+		}
+	}
+
+	return result, err
+}
+
+// SQLiteConnector returns a connector opening connections to dsn with an SQLite driver, running pragmas on every connection
+// before it is used. DefaultSQLitePragmas are applied when no pragmas are given. Open the database with sql.OpenDB
+/*
+
+Example:
+
+	connector, err := sqlAssister.SQLiteConnector(&sqlite3.SQLiteDriver{}, "app.db")
+	if err != nil {
+		return err
+	}
+
+	db := sql.OpenDB(connector)
+	Assister := sqlAssister.New(db, sqlAssister.WithDialect(sqlAssister.SQLite))
+*/
+func SQLiteConnector(d driver.Driver, dsn string, pragmas ...string) (driver.Connector, error) {
+	if len(pragmas) == 0 {
+		pragmas = DefaultSQLitePragmas
+	}
+
+	connector, err := openConnector(d, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return pragmaConnector{Connector: connector, pragmas: pragmas}, nil
+}
+
+// pragmaConnector runs pragmas on every connection opened by the Connector
+type pragmaConnector struct {
+	driver.Connector
+	pragmas []string
+}
+
+func (c pragmaConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		_ = conn.Close()
+		return nil, errors.New("SQLite driver connection doesn't implement driver.ExecerContext")
+	}
+	for _, pragma := range c.pragmas {
+		_, err = execer.ExecContext(ctx, pragma, nil)
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
@@ -0,0 +1,202 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSerializedWriteReturningReadUnderLock(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openTestDB(t, bookTable, `INSERT INTO "books" ("name") VALUES ('Dune'), ('Emma')`), WithDialect(SQLite))
+
+	rows, err := ac.conn().QueryContext(ctx, `UPDATE "books" SET "stock" = "stock" + 1 RETURNING "id", "name", "stock"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	// The records are read whole under the lock, leaving neither the lock nor SQLite's write transaction to the open rows
+	if !ac.writeLock.TryLock() {
+		t.Fatal("expected the write lock released once the query returned")
+	}
+	ac.writeLock.Unlock()
+
+	writeCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, err = ac.conn().ExecContext(writeCtx, `UPDATE "books" SET "stock" = 10 WHERE "id" = ?`, 1)
+	if err != nil {
+		t.Fatalf("expected a write while the returned rows are open to succeed, got %v", err)
+	}
+
+	var books []testBook
+	for rows.Next() {
+		var book testBook
+		err = rows.Scan(&book.ID, &book.Name, &book.Stock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		books = append(books, book)
+	}
+	if rows.Err() != nil {
+		t.Fatal(rows.Err())
+	}
+	if len(books) != 2 || books[0].Name != "Dune" || books[0].Stock != 1 || books[1].Name != "Emma" || books[1].Stock != 1 {
+		t.Errorf("expected both books returned with a stock of 1, got %+v", books)
+	}
+}
+
+func TestSerializedWriteReturningError(t *testing.T) {
+	ac := New(openTestDB(t, bookTable), WithDialect(SQLite))
+
+	_, err := UpdateReturning[testBook](context.Background(), ac, `UPDATE "books" SET "name" = NULL WHERE "id" = 1 RETURNING *`)
+	if err != ErrNotFound {
+		t.Errorf("expected ErrNotFound updating no record, got %v", err)
+	}
+
+	_, err = ac.conn().QueryContext(context.Background(), `UPDATE "missing" SET "name" = 'x' RETURNING *`)
+	if err == nil {
+		t.Error("expected updating a missing table to fail")
+	}
+	var name string
+	err = ac.conn().QueryRowContext(context.Background(), `UPDATE "missing" SET "name" = 'x' RETURNING "name"`).Scan(&name)
+	if err == nil {
+		t.Error("expected scanning the row of an update of a missing table to fail")
+	}
+}
+
+func TestSerializedWritesConcurrent(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openTestDB(t, bookTable, `INSERT INTO "books" ("name") VALUES ('Dune')`), WithDialect(SQLite))
+
+	const workers, rounds = 8, 25
+	var wg sync.WaitGroup
+	stocks := make(chan int64, workers*rounds)
+	errs := make(chan error, workers*rounds*3)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				book, err := UpdateReturning[testBook](ctx, ac,
+					`UPDATE "books" SET "stock" = "stock" + 1 WHERE "id" = ? RETURNING *`, 1)
+				if err != nil {
+					errs <- err
+					continue
+				}
+				stocks <- book.Stock
+
+				err = ac.UpdateSingleRow(insertBook, "copy")
+				if err != nil {
+					errs <- err
+				}
+
+				_, err = Select[testBook](ctx, ac, `SELECT * FROM "books" WHERE "id" = ?`, 1)
+				if err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(stocks)
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// Every update saw the stock left by the one before it
+	seen := map[int64]bool{}
+	for stock := range stocks {
+		if seen[stock] {
+			t.Errorf("stock %d returned twice", stock)
+		}
+		seen[stock] = true
+	}
+	book, err := GetByID[testBook](ctx, ac, "books", Key{"id", 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if book.Stock != workers*rounds || len(seen) != workers*rounds {
+		t.Errorf("expected a stock of %d, got %d with %d distinct values returned", workers*rounds, book.Stock, len(seen))
+	}
+	if count := countBooks(t, ac); count != 1+workers*rounds {
+		t.Errorf("expected %d books, got %d", 1+workers*rounds, count)
+	}
+}
+
+func TestSerializedWritesConcurrentReadTransactions(t *testing.T) {
+	ctx := context.Background()
+	// SQLite's deferred transactions let readers overlap, only the Assister's write lock could serialize them
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "reads.db")+"?_busy_timeout=5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	_, err = db.Exec(bookTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ac := New(db, WithDialect(SQLite))
+	_, err = ac.DB.Exec(insertBook, "Dune")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	begins := map[string]func(fn func(tx *TxAssister) error) error{
+		"read only Assister": func(fn func(tx *TxAssister) error) error {
+			return ac.ReadOnly().WithTransaction(ctx, fn)
+		},
+		"read only options": func(fn func(tx *TxAssister) error) error {
+			return ac.withTransaction(ctx, &sql.TxOptions{ReadOnly: true}, fn)
+		},
+	}
+	for name, begin := range begins {
+		t.Run(name, func(t *testing.T) {
+			// Each transaction reads, then waits for the other to have read before it ends: taking the write lock would deadlock them
+			var wg sync.WaitGroup
+			read := [2]chan struct{}{make(chan struct{}), make(chan struct{})}
+			errs := make(chan error, 2)
+			for i := 0; i < 2; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					errs <- begin(func(tx *TxAssister) error {
+						count, err := Get[int](ctx, tx.Assister, `SELECT COUNT(*) FROM "books"`)
+						if err != nil || count != 1 {
+							return fmt.Errorf("expected 1 book, got %d & %v", count, err)
+						}
+						close(read[i])
+						select {
+						case <-read[1-i]:
+							return nil
+						case <-time.After(5 * time.Second):
+							return errors.New("the other read transaction never ran alongside")
+						}
+					})
+				}(i)
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				if err != nil {
+					t.Error(err)
+				}
+			}
+		})
+	}
+
+	// Not holding the lock, the read only options refuse writes
+	err = ac.withTransaction(ctx, &sql.TxOptions{ReadOnly: true}, func(tx *TxAssister) error {
+		return tx.UpdateSingleRow(insertBook, "Emma")
+	})
+	if !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected a write in a read only transaction refused, got %v", err)
+	}
+}
@@ -0,0 +1,365 @@
+package sqlAssister
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// structValue returns the struct v holds or points to along with its field mapping
+func structValue(v any) (reflect.Value, *structInfo, error) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return reflect.Value{}, nil, errors.New("cannot read fields from a nil pointer")
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}, nil, fmt.Errorf("expected a struct but got %T", v)
+	}
+
+	return value, getStructInfo(value.Type()), nil
+}
+
+// fieldValue returns the value of a mapped field, nil when an embedded struct pointer on the way to it is nil
+func fieldValue(value reflect.Value, fi *fieldInfo) reflect.Value {
+	for i, x := range fi.index {
+		if i > 0 && value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				return reflect.Zero(fi.typ)
+			}
+			value = value.Elem()
+		}
+		value = value.Field(x)
+	}
+
+	return value
+}
+
+// field finds a mapped field by its Go field name or its column
+func (info *structInfo) field(name string) (*fieldInfo, error) {
+	for _, fi := range info.fields {
+		if fi.name == name {
+			return fi, nil
+		}
+	}
+	if fi, ok := info.byColumn[name]; ok {
+		return fi, nil
+	}
+
+	return nil, fmt.Errorf("%s has no field or column %q", info.typ, name)
+}
+
+// Args returns the values of every mapped field of the struct s holds or points to, in the order the fields are declared
+// (embedded structs' fields in place), to bind them as positional args to a query written with its placeholders in that order.
+// Values are converted as the struct helpers of an Assister left to its defaults bind them, timestr, boolchar & emptynull
+// included, see Assister.Args to bind them as configured.
+// Panics when s is not a struct or a pointer to one, as Args is meant to be called inline, when a field of a registered enum
+// type holds none of its values & when a field is tagged encrypted: without a cipher Args would write it as plaintext,
+// bind it with Assister.Args
+/*
+
+Example:
+
+	type NewBook struct {
+		ID       string `db:"id"`
+		Name     string `db:"name"`
+		AuthorID string `db:"author_id"`
+	}
+
+	err := Assister.UpdateSingleRow(`INSERT INTO "books" ("id", "name", "author_id") VALUES ($1, $2, $3)`, sqlAssister.Args(book)...)
+	if err != nil {
+		return err
+	}
+*/
+func Args(s any) []any {
+	value, info, err := structValue(s)
+	if err != nil {
+		panic("sqlAssister.Args: " + err.Error())
+	}
+
+	var defaults Assister
+	args := make([]any, len(info.fields))
+	for i, fi := range info.fields {
+		if fi.options["encrypted"] {
+			panic("sqlAssister.Args: field " + fi.name + " is tagged encrypted, bind it with Assister.Args to encrypt it with the Assister's cipher")
+		}
+		args[i], err = defaults.fieldArg(fi, fieldValue(value, fi))
+		if err != nil {
+			panic("sqlAssister.Args: " + err.Error())
+		}
+	}
+
+	return args
+}
+
+// Args returns the values of the named fields of the struct v holds or points to, by Go field name or column & in the order given,
+// to bind them as positional args to a hand written query. Values are converted as the struct helpers bind them: fields tagged
+// timestr & boolchar are formatted for the Assister & nil pointers become NULL, registered arg converters then apply as for any arg.
+// An unknown field fails the call, as does a field of a registered enum type holding none of its values. Unlike the package level
+// Args it binds only the fields named
+/*
+
+Example:
+
+	args, err := Assister.Args(book, "Name", "Price", "ID")
+	if err != nil {
+		return err
+	}
+
+	err = Assister.UpdateSingleRow(`UPDATE "books" SET "name" = $1, "price" = $2 WHERE "id" = $3`, args...)
+	if err != nil {
+		return err
+	}
+*/
+func (ac Assister) Args(v any, fields ...string) (_ []any, err error) {
+	defer ac.panicRecovery().recover(&err)
+
+	value, info, err := structValue(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("no fields present to bind")
+	}
+
+	args := make([]any, len(fields))
+	for i, name := range fields {
+		fi, err := info.field(name)
+		if err != nil {
+			return nil, err
+		}
+		args[i], err = ac.fieldArg(fi, fieldValue(value, fi))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return args, nil
+}
+
+// UpdateStruct updates the single record in table identified by the values of the fields mapped to keyColumns.
+// When no keyColumns are given the record is identified by the fields tagged `db:"column,pk"`, several forming a composite key.
+// Every other field is set from v EXCEPT fields holding their zero value (false, 0, "", nil, the zero time), which are left untouched
+// so a partially filled struct doesn't overwrite columns. Tag a field `db:"column,always"` to set it even when zero,
+// or use UpdateStructFields to choose the fields explicitly
+/*
+
+Example:
+
+	type Book struct {
+		ID     string `db:"id,pk"`
+		Name   string `db:"name"`
+		Active bool   `db:"active,always"`
+	}
+
+	err := Assister.UpdateStruct(ctx, "books", book)
+	if err != nil {
+		return err
+	}
+*/
+func (ac Assister) UpdateStruct(ctx context.Context, table string, v any, keyColumns ...string) error {
+	return ac.updateStruct(ctx, table, v, nil, keyColumns)
+}
+
+// UpdateStructFields updates the single record in table identified by the values of the fields mapped to keyColumns (or tagged pk),
+// setting ONLY the named fields whether or not they hold their zero value. Fields are named by Go field name or column
+/*
+
+Example:
+
+	book.Active = false
+	err := Assister.UpdateStructFields(ctx, "books", book, []string{"Active"}, "id")
+	if err != nil {
+		return err
+	}
+*/
+func (ac Assister) UpdateStructFields(ctx context.Context, table string, v any, fields []string, keyColumns ...string) error {
+	if len(fields) == 0 {
+		return errors.New("no fields present to update")
+	}
+
+	return ac.updateStruct(ctx, table, v, fields, keyColumns)
+}
+
+// structKeys returns the key identifying the record v holds, from keyColumns or the fields tagged pk
+func structKeys(value reflect.Value, info *structInfo, keyColumns []string) ([]Key, map[*fieldInfo]bool, error) {
+	keyFields := info.pkFields()
+	if len(keyColumns) > 0 {
+		keyFields = make([]*fieldInfo, len(keyColumns))
+		for i, column := range keyColumns {
+			fi, ok := info.byColumn[column]
+			if !ok {
+				return nil, nil, fmt.Errorf("%s has no field mapped to key column %q", info.typ, column)
+			}
+			keyFields[i] = fi
+		}
+	}
+	if len(keyFields) == 0 {
+		return nil, nil, fmt.Errorf("no key columns given & %s has no fields tagged as primary key `db:\"column,pk\"`", info.typ)
+	}
+
+	keys := make([]Key, len(keyFields))
+	isKey := map[*fieldInfo]bool{}
+	for i, fi := range keyFields {
+		keyValue := fieldValue(value, fi).Interface()
+		if len(keyColumns) == 0 && isNilKey(keyValue) {
+			return nil, nil, fmt.Errorf("primary key of %s is incomplete: %q is not set", info.typ, fi.column)
+		}
+		keys[i] = Key{Column: fi.column, Value: keyValue}
+		isKey[fi] = true
+	}
+
+	return keys, isKey, checkKeys(nil, keys)
+}
+
+// BuildUpdateStruct returns the statement & args UpdateStruct executes without executing it, to inspect, log or wrap it
+/*
+
+Example:
+
+	query, args, err := Assister.BuildUpdateStruct("books", book)
+	if err != nil {
+		return err
+	}
+*/
+func (ac Assister) BuildUpdateStruct(table string, v any, keyColumns ...string) (query string, args []any, err error) {
+	update, err := ac.updateStructQuery(table, v, nil, keyColumns)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return update.SQL()
+}
+
+// BuildUpdateStructFields returns the statement & args UpdateStructFields executes without executing it, to inspect, log or wrap it
+/*
+
+Example:
+
+	query, args, err := Assister.BuildUpdateStructFields("books", book, []string{"Active"}, "id")
+	if err != nil {
+		return err
+	}
+*/
+func (ac Assister) BuildUpdateStructFields(table string, v any, fields []string, keyColumns ...string) (query string, args []any, err error) {
+	if len(fields) == 0 {
+		return "", nil, errors.New("no fields present to update")
+	}
+
+	update, err := ac.updateStructQuery(table, v, fields, keyColumns)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return update.SQL()
+}
+
+func (ac Assister) updateStruct(ctx context.Context, table string, v any, fields []string, keyColumns []string) error {
+	update, err := ac.updateStructQuery(table, v, fields, keyColumns)
+	if err != nil {
+		return err
+	}
+
+	results, err := update.Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return ac.checkRowsAffected(results, 1)
+}
+
+func (ac Assister) updateStructQuery(table string, v any, fields []string, keyColumns []string) (_ *UpdateQuery, err error) {
+	defer ac.panicRecovery().recover(&err)
+
+	err = utils.ValidateIdentifier(table)
+	if err != nil {
+		return nil, err
+	}
+
+	value, info, err := structValue(v)
+	if err != nil {
+		return nil, err
+	}
+	keys, isKey, err := structKeys(value, info, keyColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	update, err := ac.structUpdate(table, value, info, fields, isKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return update.Where(keyConds(keys)...), nil
+}
+
+// fieldArg returns the value a struct field is bound as, formatting the times of fields tagged timestr & the booleans of fields tagged boolchar,
+// binding the empty strings of fields tagged emptynull as NULL & encrypting the fields tagged encrypted. Nil pointers are bound as NULL,
+// unless they implement driver.Valuer & so decide for themselves. A field of a registered enum type holding none of its values fails,
+// see utils.RegisterEnum
+func (ac Assister) fieldArg(fi *fieldInfo, fv reflect.Value) (any, error) {
+	err := utils.CheckEnum(fv.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("field %s: %w", fi.name, err)
+	}
+
+	switch {
+	case fi.options["timestr"]:
+		return ac.timeArg(fv.Interface()), nil
+	case fi.options["boolchar"]:
+		return ac.boolArg(fv.Interface()), nil
+	case fv.Kind() == reflect.Pointer && fv.IsNil() && !fv.Type().Implements(valuerType):
+		return nil, nil
+	case (ac.emptyNull || fi.options["emptynull"]) && isEmptyString(fv):
+		return nil, nil
+	case fi.options["encrypted"]:
+		return ac.encryptField(fi, fv)
+	}
+
+	return fv.Interface(), nil
+}
+
+// structUpdate builds the SET list of an UPDATE from value's fields, either the named fields or every non zero field not in skip
+func (ac Assister) structUpdate(table string, value reflect.Value, info *structInfo, fields []string, skip map[*fieldInfo]bool) (*UpdateQuery, error) {
+	update := ac.Table(table).Update()
+	if fields != nil {
+		for _, name := range fields {
+			fi, err := info.field(name)
+			if err != nil {
+				return nil, err
+			}
+			arg, err := ac.fieldArg(fi, fieldValue(value, fi))
+			if err != nil {
+				return nil, err
+			}
+			update.Set(fi.column, arg)
+		}
+	} else {
+		for _, fi := range info.fields {
+			if skip[fi] {
+				continue
+			}
+			fv := fieldValue(value, fi)
+			if fv.IsZero() && !fi.options["always"] {
+				continue
+			}
+			arg, err := ac.fieldArg(fi, fv)
+			if err != nil {
+				return nil, err
+			}
+			update.Set(fi.column, arg)
+		}
+	}
+
+	if len(update.columns) == 0 {
+		return nil, fmt.Errorf("update of %q has nothing to set: UpdateStruct leaves fields holding their zero value (false, 0, \"\", nil, the zero time) untouched, "+
+			"tag fields `db:\"column,always\"` or use UpdateStructFields to set zero values", table)
+	}
+
+	return update, nil
+}
@@ -0,0 +1,245 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const accountTable = `CREATE TABLE "accounts" ("id" INTEGER PRIMARY KEY, "name" TEXT NOT NULL, "active" BOOLEAN NOT NULL,
+	"nickname" TEXT NOT NULL, "last_seen" DATETIME NOT NULL, "manager_id" INTEGER)`
+
+type testAccount struct {
+	ID        int64     `db:"id,pk"`
+	Name      string    `db:"name"`
+	Active    bool      `db:"active"`
+	Nickname  string    `db:"nickname"`
+	LastSeen  time.Time `db:"last_seen"`
+	ManagerID *int64    `db:"manager_id"`
+}
+
+// zeroableAccount sets its fields even when they hold their zero value
+type zeroableAccount struct {
+	ID        int64     `db:"id,pk"`
+	Name      string    `db:"name"`
+	Active    bool      `db:"active,always"`
+	Nickname  string    `db:"nickname,always"`
+	LastSeen  time.Time `db:"last_seen,always"`
+	ManagerID *int64    `db:"manager_id,always"`
+}
+
+func TestUpdateStructZeroValues(t *testing.T) {
+	lastSeen := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	manager := int64(5)
+	set := testAccount{ID: 1, Name: "renamed", Active: true, Nickname: "ace", LastSeen: lastSeen, ManagerID: &manager}
+	zeroed := testAccount{ID: 1, Name: "renamed"}
+
+	tests := []struct {
+		name     string
+		update   func(ctx context.Context, ac *Assister) error
+		expected testAccount
+	}{
+		{"omit zero by default", func(ctx context.Context, ac *Assister) error {
+			return ac.UpdateStruct(ctx, "accounts", testAccount{ID: 1, Name: "renamed"})
+		}, set},
+		{"always tag", func(ctx context.Context, ac *Assister) error {
+			return ac.UpdateStruct(ctx, "accounts", zeroableAccount{ID: 1, Name: "renamed"})
+		}, zeroed},
+		{"explicit fields", func(ctx context.Context, ac *Assister) error {
+			return ac.UpdateStructFields(ctx, "accounts", &testAccount{ID: 1, Name: "renamed"},
+				[]string{"Name", "Active", "nickname", "LastSeen", "manager_id"})
+		}, zeroed},
+		{"explicit fields leave the others", func(ctx context.Context, ac *Assister) error {
+			return ac.UpdateStructFields(ctx, "accounts", testAccount{ID: 1, Name: "ignored"}, []string{"Active", "ManagerID"})
+		}, testAccount{ID: 1, Name: "original", Nickname: "ace", LastSeen: lastSeen}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := openTestDB(t, accountTable)
+			ac := New(db, WithDialect(SQLite))
+			_, err := db.Exec(`INSERT INTO "accounts" VALUES (1, 'original', true, 'ace', ?, 5)`, lastSeen)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = test.update(ctx, ac)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			account, err := Get[testAccount](ctx, ac, `SELECT * FROM "accounts" WHERE "id" = 1`)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := test.expected
+			if account.Name != expected.Name || account.Active != expected.Active || account.Nickname != expected.Nickname ||
+				!account.LastSeen.Equal(expected.LastSeen) || (account.ManagerID == nil) != (expected.ManagerID == nil) ||
+				account.ManagerID != nil && *account.ManagerID != *expected.ManagerID {
+				t.Errorf("expected %+v, got %+v", expected, account)
+			}
+		})
+	}
+}
+
+func TestUpdateStructNothingToSet(t *testing.T) {
+	ac := New(nil, WithDialect(SQLite))
+	_, _, err := ac.BuildUpdateStruct("accounts", testAccount{ID: 1})
+	if err == nil || !strings.Contains(err.Error(), "zero value") || !strings.Contains(err.Error(), "UpdateStructFields") {
+		t.Errorf("expected an error explaining the zero value rule, got %v", err)
+	}
+
+	query, args, err := ac.BuildUpdateStruct("accounts", zeroableAccount{ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Name isn't tagged always, so it is left untouched
+	expected := `UPDATE "accounts" SET "active" = ?, "nickname" = ?, "last_seen" = ?, "manager_id" = ? WHERE "id" = ?`
+	if query != expected {
+		t.Errorf("expected %s, got %s", expected, query)
+	}
+	if len(args) != 5 || args[0] != false || args[1] != "" || !args[2].(time.Time).IsZero() || args[3] != nil {
+		t.Errorf("expected the zero values bound & the nil pointer bound as NULL, got %#v", args)
+	}
+}
+
+const releaseTable = `CREATE TABLE "releases" ("id" INTEGER PRIMARY KEY, "title" TEXT, "released_at" TEXT, "draft" TEXT, "editor_id" INTEGER,
+	"note" TEXT, "status" INTEGER)`
+
+// release has a field of every kind the struct helpers convert before binding
+type release struct {
+	ID         int64       `db:"id,pk"`
+	Title      string      `db:"title"`
+	ReleasedAt time.Time   `db:"released_at,timestr"`
+	Draft      bool        `db:"draft,boolchar"`
+	EditorID   *int64      `db:"editor_id"`
+	Note       string      `db:"note,emptynull"`
+	Status     errorStatus `db:"status"`
+}
+
+// TestArgsSharesInsertPath checks Assister.Args binds each field as InsertAll does, both going through fieldArg
+func TestArgsSharesInsertPath(t *testing.T) {
+	editor := int64(3)
+	releasedAt := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	ac := New(nil, WithDialect(SQLite), WithTimeBindLayout(time.DateTime), WithBoolStrings([]string{"J"}, []string{"N"}))
+	columns := []string{"id", "title", "released_at", "draft", "editor_id", "note", "status"}
+
+	records := []release{
+		{ID: 1, Title: "Dune", ReleasedAt: releasedAt, Draft: true, EditorID: &editor, Note: "first", Status: 1},
+		{ID: 2, Title: "Emma", Status: 2},
+	}
+	expected := [][]any{
+		{int64(1), "Dune", "2024-03-01 12:30:00", "J", &editor, "first", errorStatus(1)},
+		{int64(2), "Emma", "0001-01-01 00:00:00", "N", nil, nil, errorStatus(2)},
+	}
+	for i, record := range records {
+		args, err := ac.Args(&record, columns...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inserted, err := ac.insertRows(mustStructInfo(t, record), []reflect.Value{reflect.ValueOf(record)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(args, expected[i]) || !reflect.DeepEqual(inserted[0], expected[i]) {
+			t.Errorf("record %d: expected %#v bound, Args bound %#v & InsertAll %#v", record.ID, expected[i], args, inserted)
+		}
+	}
+
+	// Both refuse a value that isn't one of the enum's
+	invalid := release{ID: 3, Status: 7}
+	_, err := ac.Args(invalid, "ID", "Status")
+	if !errors.Is(err, ErrInvalidEnum) || !strings.Contains(err.Error(), "Status") {
+		t.Errorf("expected Args to refuse the invalid status, got %v", err)
+	}
+	_, err = ac.insertRows(mustStructInfo(t, invalid), []reflect.Value{reflect.ValueOf(invalid)})
+	if !errors.Is(err, ErrInvalidEnum) {
+		t.Errorf("expected InsertAll to refuse the invalid status, got %v", err)
+	}
+}
+
+// mustStructInfo returns the mapping of v's struct type
+func mustStructInfo(t *testing.T, v any) *structInfo {
+	t.Helper()
+	_, info, err := structValue(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return info
+}
+
+func TestArgs(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openTestDB(t, releaseTable), WithDialect(SQLite))
+	editor := int64(3)
+	record := release{ID: 1, Title: "Dune", ReleasedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Draft: true, EditorID: &editor, Status: 1}
+
+	// Fields are named by Go name or column, in the order of the hand written query
+	args, err := ac.Args(record, "Title", "released_at", "Draft", "EditorID", "note", "Status", "ID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ac.UpdateSingleRow(`INSERT INTO "releases" ("title", "released_at", "draft", "editor_id", "note", "status", "id") VALUES (?, ?, ?, ?, ?, ?, ?)`, args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = InsertAll(ctx, ac, "releases", []release{{ID: 2, Title: record.Title, ReleasedAt: record.ReleasedAt, Draft: true, EditorID: &editor, Status: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := MapRows(ctx, ac, func(rows *sql.Rows) ([]any, error) {
+		values := make([]any, 6)
+		dest := make([]any, len(values))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		return values, rows.Scan(dest...)
+	}, `SELECT "title", "released_at", "draft", "editor_id", "note", "status" FROM "releases" ORDER BY "id"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 2 || !reflect.DeepEqual(stored[0], stored[1]) || stored[0][4] != nil {
+		t.Errorf("expected Args & InsertAll to store the same values, the empty note as NULL, got %v", stored)
+	}
+
+	read, err := GetByID[release](ctx, ac, "releases", Key{"id", 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Title != "Dune" || !read.ReleasedAt.Equal(record.ReleasedAt) || !read.Draft || read.EditorID == nil || *read.EditorID != 3 || read.Status != 1 {
+		t.Errorf("expected %+v read back, got %+v", record, read)
+	}
+
+	// The package level Args converts the fields the same way, for an Assister left to its defaults
+	all, err := New(nil).Args(record, "ID", "Title", "ReleasedAt", "Draft", "EditorID", "Note", "Status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packageArgs := Args(record); !reflect.DeepEqual(packageArgs, all) {
+		t.Errorf("expected Args to bind %v, got %v", all, packageArgs)
+	}
+
+	failures := []struct {
+		name   string
+		v      any
+		fields []string
+		want   string
+	}{
+		{"unknown field", record, []string{"Title", "Publisher"}, `no field or column "Publisher"`},
+		{"no fields", record, nil, "no fields"},
+		{"not a struct", 42, []string{"Title"}, "struct"},
+		{"nil pointer", (*release)(nil), []string{"Title"}, "nil"},
+	}
+	for _, failure := range failures {
+		_, err := ac.Args(failure.v, failure.fields...)
+		if err == nil || !strings.Contains(err.Error(), failure.want) {
+			t.Errorf("%s: expected an error mentioning %q, got %v", failure.name, failure.want, err)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package sqlAssister
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// RowHash returns a stable hash of the fields of v mapped to columns, or of every mapped field when no columns are given,
+// for detecting records that changed between a source & a destination. Columns are named by column or Go field name.
+// Values are serialized with utils.ArgsKey so values that bind the same hash the same, & columns are hashed in column order
+// so reordering struct fields doesn't change the hash
+/*
+
+Example:
+
+	hash, err := sqlAssister.RowHash(book, "name", "price")
+	if err != nil {
+		return err
+	}
+*/
+func RowHash(v any, columns ...string) (string, error) {
+	value, info, err := structValue(v)
+	if err != nil {
+		return "", err
+	}
+
+	fields := info.fields
+	if len(columns) > 0 {
+		fields = make([]*fieldInfo, len(columns))
+		for i, column := range columns {
+			fields[i], err = info.field(column)
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+	sorted := make([]*fieldInfo, len(fields))
+	copy(sorted, fields)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].column < sorted[j].column })
+
+	hash := sha256.New()
+	for _, fi := range sorted {
+		key, err := utils.ArgsKey(fi.column, fieldValue(value, fi).Interface())
+		if err != nil {
+			return "", fmt.Errorf("field %s: %w", fi.name, err)
+		}
+		hash.Write([]byte(key))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// HashedKey is the key of a source record & its RowHash
+type HashedKey struct {
+	Key  any
+	Hash string
+}
+
+// SelectChanged compares candidates to the hashes stored in hashColumn of table & returns the candidates that are missing from table
+// or whose stored hash differs, in the order given, so only those need to be upserted.
+// The stored hashes are read with IN lists of keyColumn split into chunks so any number of candidates can be compared
+/*
+
+Example:
+
+	candidates := make([]sqlAssister.HashedKey, len(books))
+	for i, book := range books {
+		hash, err := sqlAssister.RowHash(book)
+		if err != nil {
+			return err
+		}
+		candidates[i] = sqlAssister.HashedKey{Key: book.ID, Hash: hash}
+	}
+
+	changed, err := Assister.SelectChanged(ctx, "books", "id", "row_hash", candidates)
+	if err != nil {
+		return err
+	}
+*/
+func (ac Assister) SelectChanged(ctx context.Context, table string, keyColumn string, hashColumn string, candidates []HashedKey) ([]HashedKey, error) {
+	if len(candidates) == 0 {
+		return nil, errors.New("no candidates present")
+	}
+
+	byKey := make(map[string]int, len(candidates))
+	keys := make([]any, len(candidates))
+	for i, candidate := range candidates {
+		argsKey, err := utils.ArgsKey(candidate.Key)
+		if err != nil {
+			return nil, fmt.Errorf("candidate %d: %w", i+1, err)
+		}
+		byKey[argsKey] = i
+		keys[i] = candidate.Key
+	}
+
+	unchanged := make([]bool, len(candidates))
+	for _, chunk := range chunkValues(keys, inChunkSize) {
+		rows, err := ac.Table(table).Select(keyColumn, hashColumn).Where(In(keyColumn, chunk...)).Query(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		err = markUnchanged(rows, candidates, byKey, unchanged)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var changed []HashedKey
+	for i, candidate := range candidates {
+		if !unchanged[i] {
+			changed = append(changed, candidate)
+		}
+	}
+
+	return changed, nil
+}
+
+// markUnchanged marks the candidates whose stored hash read from rows matches & closes rows
+func markUnchanged(rows *sql.Rows, candidates []HashedKey, byKey map[string]int, unchanged []bool) error {
+	defer rows.Close()
+
+	for rows.Next() {
+		var key any
+		var hash sql.NullString
+		err := rows.Scan(&key, &hash)
+		if err != nil {
+			return err
+		}
+
+		i, ok, err := lookupKey(byKey, key)
+		if err != nil {
+			return err
+		}
+		if ok && hash.Valid && hash.String == candidates[i].Hash {
+			unchanged[i] = true
+		}
+	}
+
+	return rows.Err()
+}
+
+// lookupKey finds the candidate for a key read from the database, which drivers may return as bytes for a text key
+func lookupKey(byKey map[string]int, key any) (int, bool, error) {
+	argsKey, err := utils.ArgsKey(key)
+	if err != nil {
+		return 0, false, err
+	}
+	if i, ok := byKey[argsKey]; ok {
+		return i, true, nil
+	}
+
+	if b, isBytes := key.([]byte); isBytes {
+		argsKey, err = utils.ArgsKey(string(b))
+		if err != nil {
+			return 0, false, err
+		}
+		i, ok := byKey[argsKey]
+		return i, ok, nil
+	}
+
+	return 0, false, nil
+}
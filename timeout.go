@@ -0,0 +1,182 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"time"
+)
+
+// WithDefaultQueryTimeout bounds every statement the Assister executes to timeout, so a slow query fails instead of holding
+// its connection indefinitely. The rules deciding a statement's deadline, with timeout being the default or a WithTimeout override:
+//   - the caller's context deadline is kept when it is earlier than now + timeout, the earlier deadline always wins
+//   - a caller's context deadline later than now + timeout does NOT extend the timeout, only WithTimeout or WithNoTimeout do
+//   - WithNoTimeout leaves the caller's context as is, its own deadline if any still applies
+//
+// The timeout applies per statement, not to a whole transaction. A query's rows are read whole before it returns, within the timeout,
+// & handed back from memory, so reading them afterwards takes as long as the caller likes but a large result set is held at once.
+// The deadline is reckoned & enforced on the Assister's clock, see WithClock
+func WithDefaultQueryTimeout(timeout time.Duration) Option {
+	return func(ac *Assister) {
+		ac.queryTimeout = timeout
+	}
+}
+
+// WithTimeout returns a copy of the Assister whose statements are bounded by timeout instead of the default query timeout,
+// which it may shorten or extend. The caller's context deadline still wins when it is earlier, see WithDefaultQueryTimeout
+/*
+
+Example:
+
+	books, err := sqlAssister.Select[Book](ctx, Assister.WithTimeout(30*time.Second), yearlyReportQuery, year)
+	if err != nil {
+		return nil, err
+	}
+*/
+func (ac Assister) WithTimeout(timeout time.Duration) *Assister {
+	ac.queryTimeout = timeout
+	return &ac
+}
+
+// WithNoTimeout returns a copy of the Assister whose statements aren't bounded by the default query timeout,
+// for migrations & report jobs that legitimately run long. Only the caller's context deadline, if any, applies
+/*
+
+Example:
+
+	err := Assister.WithNoTimeout().EnsureTable(ctx, createEventsTableStatement)
+	if err != nil {
+		return err
+	}
+*/
+func (ac Assister) WithNoTimeout() *Assister {
+	ac.queryTimeout = 0
+	return &ac
+}
+
+// queryDeadline returns the deadline a statement started at now runs under given the caller's ctx & the timeout,
+// reporting false when the statement has no deadline of its own to add to ctx
+func queryDeadline(ctx context.Context, timeout time.Duration, now time.Time) (time.Time, bool) {
+	if timeout <= 0 {
+		return time.Time{}, false
+	}
+
+	deadline := now.Add(timeout)
+	if callerDeadline, ok := ctx.Deadline(); ok && !callerDeadline.After(deadline) {
+		return time.Time{}, false
+	}
+
+	return deadline, true
+}
+
+// timeoutQuerier bounds every statement executed on q by a timeout, see WithDefaultQueryTimeout
+type timeoutQuerier struct {
+	q       querier
+	timeout time.Duration
+	clock   Clock
+}
+
+// withDeadline returns ctx bounded by the statement's deadline, reporting false when it has none of its own
+func (q timeoutQuerier) withDeadline(ctx context.Context) (context.Context, context.CancelFunc, bool) {
+	deadline, ok := queryDeadline(ctx, q.timeout, q.clock.Now())
+	if !ok {
+		return ctx, func() {}, false
+	}
+
+	// The runtime enforces context deadlines on the real clock, another clock's are enforced by its timers
+	if _, ok := q.clock.(realClock); ok {
+		ctx, cancel := context.WithDeadline(ctx, deadline)
+		return ctx, cancel, true
+	}
+	ctx, cancel := withClockDeadline(ctx, q.clock, deadline)
+	return ctx, cancel, true
+}
+
+// clockDeadlineContext is a context whose deadline is enforced on a Clock: it is done once the clock reaches its deadline,
+// reporting context.DeadlineExceeded, or once its parent is done
+type clockDeadlineContext struct {
+	context.Context
+	deadline time.Time
+	exceeded *atomic.Bool
+}
+
+func withClockDeadline(ctx context.Context, clock Clock, deadline time.Time) (context.Context, context.CancelFunc) {
+	inner, cancel := context.WithCancel(ctx)
+	c := clockDeadlineContext{Context: inner, deadline: deadline, exceeded: &atomic.Bool{}}
+
+	timer := clock.NewTimer(deadline.Sub(clock.Now()))
+	go func() {
+		select {
+		case <-timer.C():
+			c.exceeded.Store(true)
+			cancel()
+		case <-inner.Done():
+			timer.Stop()
+		}
+	}()
+
+	return c, cancel
+}
+
+func (c clockDeadlineContext) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func (c clockDeadlineContext) Err() error {
+	if c.exceeded.Load() {
+		return context.DeadlineExceeded
+	}
+
+	return c.Context.Err()
+}
+
+func (q timeoutQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, cancel, _ := q.withDeadline(ctx)
+	defer cancel()
+
+	return q.q.ExecContext(ctx, query, args...)
+}
+
+func (q timeoutQuerier) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	ctx, cancel, _ := q.withDeadline(ctx)
+	defer cancel()
+
+	return q.q.PrepareContext(ctx, query)
+}
+
+// QueryContext reads the rows whole under the deadline & replays them, see replayRows, so the deadline's timer is released as
+// the statement returns rather than once it fires. Rows the deadline interrupted replay the records read before it, then its error
+func (q timeoutQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	deadlineCtx, cancel, ok := q.withDeadline(ctx)
+	if !ok {
+		return q.q.QueryContext(ctx, query, args...)
+	}
+	defer cancel()
+
+	rows, err := q.q.QueryContext(deadlineCtx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	r, err := readReplay(rows, -1)
+	r.readErr = err
+
+	return replayRows(ctx, query, r)
+}
+
+// QueryRowContext reads the first record under the deadline & replays it as QueryContext does
+func (q timeoutQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	deadlineCtx, cancel, ok := q.withDeadline(ctx)
+	if !ok {
+		return q.q.QueryRowContext(ctx, query, args...)
+	}
+	defer cancel()
+
+	rows, err := q.q.QueryContext(deadlineCtx, query, args...)
+	if err != nil {
+		return replayRow(ctx, query, &replay{err: err})
+	}
+	r, err := readReplay(rows, 1)
+	r.readErr = err
+
+	return replayRow(ctx, query, r)
+}
@@ -0,0 +1,225 @@
+package sqlAssister_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/zobstory/sqlAssister"
+	"github.com/zobstory/sqlAssister/sqlAssistertest"
+)
+
+// blockingConn blocks every statement until its context is done, handing the context to started first
+type blockingConn struct {
+	started chan context.Context
+}
+
+func (c blockingConn) Connect(context.Context) (driver.Conn, error) { return c, nil }
+
+func (c blockingConn) Driver() driver.Driver { return nil }
+
+func (c blockingConn) ExecContext(ctx context.Context, _ string, _ []driver.NamedValue) (driver.Result, error) {
+	c.started <- ctx
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (c blockingConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+
+func (c blockingConn) Close() error { return nil }
+
+func (c blockingConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+// startBlocked executes a statement on ac in the background, returning the context it reached the driver with & the channel
+// receiving its error
+func startBlocked(t *testing.T, ctx context.Context, ac *sqlAssister.Assister, conn blockingConn) (context.Context, <-chan error) {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() {
+		_, err := ac.ExecExpecting(ctx, `UPDATE "books" SET "stock" = 0`, 1)
+		done <- err
+	}()
+
+	select {
+	case driverCtx := <-conn.started:
+		return driverCtx, done
+	case err := <-done:
+		t.Fatalf("expected the statement to reach the driver, got %v", err)
+		return nil, nil
+	}
+}
+
+// expectBlocked checks the statement is still executing
+func expectBlocked(t *testing.T, done <-chan error) {
+	t.Helper()
+	select {
+	case err := <-done:
+		t.Fatalf("expected the statement to still be executing, it returned %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestDefaultQueryTimeoutOnClock(t *testing.T) {
+	start := time.Now()
+	tests := []struct {
+		name string
+		// callerTimeout is the caller's own timeout on the real clock, none when 0
+		callerTimeout time.Duration
+		assister      func(ac *sqlAssister.Assister) *sqlAssister.Assister
+		// deadline is the timeout the statement runs under on the fake clock, none when 0
+		deadline time.Duration
+	}{
+		{"default", 0, nil, time.Minute},
+		{"earlier caller deadline wins", 30 * time.Second, nil, 0},
+		{"later caller deadline doesn't extend", time.Hour, nil, time.Minute},
+		{"WithTimeout extends", 0, func(ac *sqlAssister.Assister) *sqlAssister.Assister { return ac.WithTimeout(10 * time.Minute) }, 10 * time.Minute},
+		{"WithTimeout shortens", time.Hour, func(ac *sqlAssister.Assister) *sqlAssister.Assister { return ac.WithTimeout(time.Second) }, time.Second},
+		{"WithNoTimeout", 0, func(ac *sqlAssister.Assister) *sqlAssister.Assister { return ac.WithNoTimeout() }, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clock := sqlAssistertest.NewFakeClock(start)
+			conn := blockingConn{started: make(chan context.Context, 1)}
+			db := sql.OpenDB(conn)
+			defer db.Close()
+			ac := sqlAssister.New(db, sqlAssister.WithClock(clock), sqlAssister.WithDefaultQueryTimeout(time.Minute))
+			if test.assister != nil {
+				ac = test.assister(ac)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if test.callerTimeout > 0 {
+				ctx, cancel = context.WithDeadline(context.Background(), start.Add(test.callerTimeout))
+			}
+			defer cancel()
+
+			driverCtx, done := startBlocked(t, ctx, ac, conn)
+			deadline, ok := driverCtx.Deadline()
+			if test.deadline == 0 {
+				callerDeadline, callerOk := ctx.Deadline()
+				if ok != callerOk || !deadline.Equal(callerDeadline) {
+					t.Errorf("expected the caller's deadline %v, got %v", callerDeadline, deadline)
+				}
+				if clock.Waiters() != 0 {
+					t.Errorf("expected no deadline on the clock, %d timers waiting", clock.Waiters())
+				}
+
+				cancel()
+				err := <-done
+				if !errors.Is(err, context.Canceled) {
+					t.Errorf("expected the caller's cancellation, got %v", err)
+				}
+				return
+			}
+
+			if !ok || !deadline.Equal(start.Add(test.deadline)) {
+				t.Fatalf("expected the deadline %v on the clock, got %v", start.Add(test.deadline), deadline)
+			}
+			clock.Advance(test.deadline - time.Millisecond)
+			expectBlocked(t, done)
+
+			clock.Advance(time.Millisecond)
+			err := <-done
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected the statement to time out on the clock, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDefaultQueryTimeoutCallerCancel(t *testing.T) {
+	clock := sqlAssistertest.NewFakeClock(time.Now())
+	conn := blockingConn{started: make(chan context.Context, 1)}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+	ac := sqlAssister.New(db, sqlAssister.WithClock(clock), sqlAssister.WithDefaultQueryTimeout(time.Minute))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, done := startBlocked(t, ctx, ac, conn)
+	cancel()
+	err := <-done
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the caller's cancellation, got %v", err)
+	}
+
+	// The deadline's timer is stopped once the statement returns
+	deadline := time.Now().Add(time.Second)
+	for clock.Waiters() != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if clock.Waiters() != 0 {
+		t.Errorf("expected the deadline's timer stopped, %d waiting", clock.Waiters())
+	}
+}
+
+// waitReleased waits for the timers waiting on clock & the goroutines beyond want to be released, as the goroutines enforcing
+// a cancelled deadline exit asynchronously, failing once they aren't in time
+func waitReleased(t *testing.T, clock *sqlAssistertest.FakeClock, want int) {
+	t.Helper()
+	giveUp := time.Now().Add(5 * time.Second)
+	for clock.Waiters() > 0 || runtime.NumGoroutine() > want {
+		if time.Now().After(giveUp) {
+			t.Fatalf("expected the deadlines released, %d timers & %d goroutines are left for %d before", clock.Waiters(), runtime.NumGoroutine(), want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestQueryTimeoutReleased(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "timeout.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE "records" ("id" INTEGER PRIMARY KEY); INSERT INTO "records" ("id") VALUES (1), (2), (3)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := sqlAssistertest.NewFakeClock(time.Now())
+	ac := sqlAssister.New(db, sqlAssister.WithDialect(sqlAssister.SQLite), sqlAssister.WithClock(clock), sqlAssister.WithDefaultQueryTimeout(time.Minute))
+
+	// A first query opens the connection & starts database/sql's own goroutines
+	_, err = sqlAssister.Get[int64](ctx, ac, `SELECT COUNT(*) FROM "records"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitReleased(t, clock, runtime.NumGoroutine())
+	goroutines := runtime.NumGoroutine()
+
+	// Successful reads release their deadline as they return, leaving no timer waiting for the clock to reach it
+	for i := 0; i < 200; i++ {
+		ids, err := sqlAssister.Select[int64](ctx, ac, `SELECT "id" FROM "records" ORDER BY "id"`)
+		if err != nil || len(ids) != 3 {
+			t.Fatalf("expected the 3 ids, got %v & %v", ids, err)
+		}
+		count, err := sqlAssister.Get[int64](ctx, ac, `SELECT COUNT(*) FROM "records"`)
+		if err != nil || count != 3 {
+			t.Fatalf("expected a count of 3, got %d & %v", count, err)
+		}
+		_, err = sqlAssister.Get[int64](ctx, ac, `SELECT "id" FROM "records" WHERE "id" = 4`)
+		if !errors.Is(err, sqlAssister.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	}
+	waitReleased(t, clock, goroutines)
+
+	// The rows were read within the deadline, the clock passing it before they are scanned doesn't fail them
+	results, err := ac.QueryMultiple(ctx, `SELECT "id" FROM "records" ORDER BY "id"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer results.Close()
+	waitReleased(t, clock, goroutines+1)
+	clock.Advance(time.Hour)
+	ids, err := sqlAssister.ScanResultSet[int64](results)
+	if err != nil || len(ids) != 3 {
+		t.Errorf("expected the 3 ids scanned past the deadline, got %v & %v", ids, err)
+	}
+}
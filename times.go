@@ -0,0 +1,58 @@
+package sqlAssister
+
+import (
+	"database/sql"
+	"time"
+)
+
+// WithTimeLayouts sets the layouts, tried in order, a time read as text is parsed with when scanned into a time.Time field,
+// e.g. from a legacy varchar column or a driver returning times as []byte. Defaults to utils.DefaultTimeLayouts along with any
+// layout registered with utils.RegisterTimeLayout. A time matching none fails to scan naming the column, the value & the layouts tried
+/*
+
+Example:
+
+	statementAssister = sqlAssister.New(db, sqlAssister.WithTimeLayouts(
+		"2006-01-02 15:04:05",
+		"02/01/2006 15:04",
+		"20060102",
+	))
+*/
+func WithTimeLayouts(layouts ...string) Option {
+	return func(ac *Assister) {
+		ac.timeLayouts = layouts
+	}
+}
+
+// WithTimeBindLayout sets the layout the time.Time fields tagged `db:"column,timestr"` are formatted with when a struct is written,
+// for timestamps stored in string columns. Defaults to time.RFC3339Nano. Reading them back is covered by WithTimeLayouts
+func WithTimeBindLayout(layout string) Option {
+	return func(ac *Assister) {
+		ac.timeBindLayout = layout
+	}
+}
+
+// timeArg formats the time a field tagged timestr holds with the bind layout, see WithTimeBindLayout
+func (ac Assister) timeArg(v any) any {
+	layout := ac.timeBindLayout
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+
+	switch v := v.(type) {
+	case time.Time:
+		return v.Format(layout)
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return v.Format(layout)
+	case sql.NullTime:
+		if !v.Valid {
+			return nil
+		}
+		return v.Time.Format(layout)
+	}
+
+	return v
+}
@@ -0,0 +1,494 @@
+package sqlAssister
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// TxAssister exposes the Assister methods bound to a single transaction.
+// Every statement executed through it runs inside the transaction. It is safe for concurrent use by goroutines started by
+// the WithTransaction function, their statements take turns on the transaction's connection, but they must finish before it returns
+type TxAssister struct {
+	*Assister
+	Tx *sql.Tx
+
+	ctx    context.Context
+	tally  *txTally
+	hooks  *txHooks
+	unlock func()
+	// savepoints numbers the savepoints of the transaction so nested ones have distinct names
+	savepoints *atomic.Int64
+}
+
+// WithTransaction runs fn inside a transaction, committing it when fn returns nil & rolling it back otherwise.
+// The transaction is also rolled back if fn panics, the panic is then re-raised.
+// If ctx is cancelled while fn runs, statements executed afterwards fail with ErrTxContextCanceled & the transaction is rolled back.
+// Called on an Assister already bound to a transaction, such as tx.Assister, fn runs in a savepoint of it instead: only fn's
+// statements are rolled back when it fails, & the callbacks fn queues with AfterCommit wait for the outermost transaction to commit.
+// Savepoints of the same transaction must not be run by several goroutines at once
+/*
+
+Example:
+
+	err := Assister.WithTransaction(ctx, func(tx *sqlAssister.TxAssister) error {
+		err := tx.UpdateSingleRow(debitStatement, amount, fromId)
+		if err != nil {
+			return err
+		}
+
+		return tx.UpdateSingleRow(creditStatement, amount, toId)
+	})
+	if err != nil {
+		return err
+	}
+
+	// In a savepoint: a failed loyalty credit doesn't roll back the order
+	err = tx.WithTransaction(ctx, func(tx *sqlAssister.TxAssister) error {
+		tx.AfterCommit(notifyLoyaltyService)
+		return tx.UpdateSingleRow(creditPointsStatement, points, customerId)
+	})
+*/
+func (ac Assister) WithTransaction(ctx context.Context, fn func(tx *TxAssister) error) error {
+	return ac.withTransaction(ctx, nil, fn)
+}
+
+// WithReadCommitted runs fn inside a READ COMMITTED transaction as WithTransaction does: every statement sees the data committed
+// before it started, so two reads of the same records may differ. The default isolation level of Postgres
+/*
+
+Example:
+
+	err := Assister.WithReadCommitted(ctx, func(tx *sqlAssister.TxAssister) error {
+		return tx.UpdateSingleRow(markShippedStatement, orderId)
+	})
+*/
+func (ac Assister) WithReadCommitted(ctx context.Context, fn func(tx *TxAssister) error) error {
+	return ac.withTransaction(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted}, fn)
+}
+
+// WithRepeatableRead runs fn inside a REPEATABLE READ transaction as WithTransaction does: on Postgres every statement sees the
+// snapshot taken by the transaction's first statement, for reports & exports reading several tables consistently.
+// The default isolation level of MySQL's InnoDB. Postgres fails a write to a record changed concurrently with a serialization failure
+/*
+
+Example:
+
+	err := Assister.WithRepeatableRead(ctx, func(tx *sqlAssister.TxAssister) error {
+		orders, err := sqlAssister.Select[Order](ctx, tx.Assister, ordersQuery, day)
+		if err != nil {
+			return err
+		}
+		lines, err := sqlAssister.Select[OrderLine](ctx, tx.Assister, orderLinesQuery, day)
+		if err != nil {
+			return err
+		}
+		return export(orders, lines)
+	})
+*/
+func (ac Assister) WithRepeatableRead(ctx context.Context, fn func(tx *TxAssister) error) error {
+	return ac.withTransaction(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead}, fn)
+}
+
+// WithSerializable runs fn inside a SERIALIZABLE transaction as WithTransaction does: the outcome is that of the transactions
+// running one after the other, for read-then-write logic such as checking a balance before debiting it. The database fails
+// a transaction it can't serialize, such failures are meant to be retried, see utils.IsSerializationFailure
+/*
+
+Example:
+
+	err := Assister.WithSerializable(ctx, func(tx *sqlAssister.TxAssister) error {
+		balance, err := sqlAssister.Get[int64](ctx, tx.Assister, balanceQuery, accountId)
+		if err != nil {
+			return err
+		}
+		if balance < amount {
+			return ErrInsufficientFunds
+		}
+		return tx.UpdateSingleRow(debitStatement, amount, accountId)
+	})
+*/
+func (ac Assister) WithSerializable(ctx context.Context, fn func(tx *TxAssister) error) error {
+	return ac.withTransaction(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable}, fn)
+}
+
+// withTransaction runs fn inside a transaction begun with opts, nil for the driver's defaults, see WithTransaction
+func (ac Assister) withTransaction(ctx context.Context, opts *sql.TxOptions, fn func(tx *TxAssister) error) (err error) {
+	if ac.tx != nil {
+		if opts != nil {
+			return errors.New("the isolation level of a transaction can't change in a savepoint, call WithTransaction instead")
+		}
+		return ac.withSavepoint(ctx, fn)
+	}
+
+	// A read only transaction refuses writes like a read only Assister, whatever the driver makes of opts, so it can leave the write lock alone
+	if opts != nil && opts.ReadOnly {
+		ac.readOnly = true
+	}
+	// With serialized writes the transaction holds the write lock throughout, so it can't be made to wait on another's lock part way through
+	unlock := ac.lockWrites()
+	tx, err := ac.DB.BeginTx(ctx, opts)
+	if err != nil {
+		unlock()
+		return err
+	}
+
+	txAssister := newTxAssister(ctx, ac, tx, unlock)
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			txAssister.rolledBack(fmt.Errorf("transaction rolled back after panic: %v", p))
+			panic(p)
+		}
+	}()
+
+	err = fn(txAssister)
+	if err != nil {
+		// fn's error is what the caller needs to see, a rollback failing because the context already
+		// rolled the transaction back (sql.ErrTxDone) must not mask it
+		_ = tx.Rollback()
+		txAssister.rolledBack(err)
+		return err
+	}
+
+	// Don't commit work fn may not have finished because its context was cancelled part way through
+	if ctx.Err() != nil {
+		_ = tx.Rollback()
+		err = txContextError{ctx.Err()}
+		txAssister.rolledBack(err)
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		txAssister.rolledBack(err)
+		return err
+	}
+	txAssister.committed()
+
+	return nil
+}
+
+// withSavepoint runs fn in a savepoint of the Assister's transaction, rolling back to it when fn fails, see WithTransaction.
+// The callbacks fn queues are handed to the enclosing scope when the savepoint is released, & so run once the outermost
+// transaction's outcome is known. When the savepoint is rolled back its work is undone for good, its AfterRollback callbacks run
+// right away & its AfterCommit callbacks never do
+func (ac Assister) withSavepoint(ctx context.Context, fn func(tx *TxAssister) error) (err error) {
+	outer := ac.tx
+	name := "sql_assister_savepoint_" + strconv.FormatInt(outer.savepoints.Add(1), 10)
+	_, err = outer.Tx.ExecContext(ctx, "SAVEPOINT "+name)
+	if err != nil {
+		return err
+	}
+
+	nested := &TxAssister{
+		Tx:         outer.Tx,
+		ctx:        outer.ctx,
+		tally:      outer.tally,
+		hooks:      &txHooks{},
+		unlock:     func() {},
+		savepoints: outer.savepoints,
+	}
+	bound := ac
+	bound.tx = nested
+	nested.Assister = &bound
+
+	rollback := func(cause error) {
+		// Rolls back even once ctx is done, the enclosing scope may still commit its own work
+		_, _ = outer.Tx.ExecContext(markContext(context.Background()), "ROLLBACK TO SAVEPOINT "+name)
+		nested.runRollbackHooks(cause)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			rollback(fmt.Errorf("savepoint rolled back after panic: %v", p))
+			panic(p)
+		}
+	}()
+
+	err = fn(nested)
+	if err == nil && ctx.Err() != nil {
+		err = txContextError{ctx.Err()}
+	}
+	if err == nil {
+		_, err = outer.Tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	}
+	if err != nil {
+		rollback(err)
+		return err
+	}
+
+	nested.hooks.mu.Lock()
+	afterCommit, afterRollback := nested.hooks.afterCommit, nested.hooks.afterRollback
+	nested.hooks.mu.Unlock()
+	for _, fn := range afterCommit {
+		outer.AfterCommit(fn)
+	}
+	for _, fn := range afterRollback {
+		outer.AfterRollback(fn)
+	}
+
+	return nil
+}
+
+// FromTx binds the Assister to a transaction begun elsewhere, e.g. by a framework owning the transaction's lifecycle,
+// so its methods run their statements inside it. The caller remains in charge of committing or rolling back tx:
+// AfterCommit & AfterRollback callbacks never run & methods running a transaction of their own, such as BulkUpdate, run in tx.
+// Statements after ctx is done fail with ErrTxContextCanceled, as in WithTransaction. tx doesn't hold the write lock of
+// WithSerializedWrites, so its writes aren't serialized with those of the Assister's other transactions
+/*
+
+Example:
+
+	func (h *Handler) PlaceOrder(ctx context.Context, tx *sql.Tx, order Order) error {
+		txAssister := h.Assister.FromTx(ctx, tx)
+		return txAssister.UpdateSingleRow(insertOrderStatement, order.ID, order.CustomerID)
+	}
+*/
+func (ac Assister) FromTx(ctx context.Context, tx *sql.Tx) *TxAssister {
+	return newTxAssister(ctx, ac, tx, func() {})
+}
+
+// InTransaction reports whether the Assister's statements run inside a transaction, as those of a TxAssister do, so code given
+// either can join the caller's transaction rather than start its own
+/*
+
+Example:
+
+	func (s *OrderService) PlaceOrder(ctx context.Context, ac *sqlAssister.Assister, order Order) error {
+		if ac.InTransaction() {
+			return s.placeOrder(ctx, ac, order)
+		}
+
+		return ac.WithTransaction(ctx, func(tx *sqlAssister.TxAssister) error {
+			return s.placeOrder(ctx, tx.Assister, order)
+		})
+	}
+*/
+func (ac Assister) InTransaction() bool {
+	return ac.tx != nil
+}
+
+// AfterCommit queues fn to run once the transaction has committed, e.g. to publish an event or invalidate a cache
+// only for changes that were actually made. Callbacks run in the order they were queued after WithTransaction's commit,
+// a panicking callback is recovered & logged so it can't change the transaction's result. tx.Summary is final by the time they run.
+// Callbacks queued in a savepoint wait for the outermost transaction to commit, see WithTransaction
+/*
+
+Example:
+
+	err := Assister.WithTransaction(ctx, func(tx *sqlAssister.TxAssister) error {
+		err := tx.UpdateStruct(ctx, "books", book)
+		if err != nil {
+			return err
+		}
+
+		tx.AfterCommit(func() {
+			cache.Delete("book:" + book.ID)
+		})
+		return nil
+	})
+*/
+func (tx *TxAssister) AfterCommit(fn func()) {
+	tx.hooks.mu.Lock()
+	defer tx.hooks.mu.Unlock()
+
+	tx.hooks.afterCommit = append(tx.hooks.afterCommit, fn)
+}
+
+// AfterRollback queues fn to run once the transaction has been rolled back, or failed to commit, with the error that caused it.
+// Callbacks run in the order they were queued, a panicking callback is recovered & logged
+/*
+
+Example:
+
+	tx.AfterRollback(func(err error) {
+		log.Printf("order %s was not placed: %s", orderId, err)
+	})
+*/
+func (tx *TxAssister) AfterRollback(fn func(err error)) {
+	tx.hooks.mu.Lock()
+	defer tx.hooks.mu.Unlock()
+
+	tx.hooks.afterRollback = append(tx.hooks.afterRollback, fn)
+}
+
+// OnCommit queues fn to run once the transaction has committed, see AfterCommit. fn doesn't run when the commit fails
+func (tx *TxAssister) OnCommit(fn func()) {
+	tx.AfterCommit(fn)
+}
+
+// OnRollback queues fn to run once the transaction has been rolled back or failed to commit, see AfterRollback for a callback receiving the cause
+func (tx *TxAssister) OnRollback(fn func()) {
+	tx.AfterRollback(func(error) {
+		fn()
+	})
+}
+
+// txHooks holds the callbacks queued to run once a transaction's outcome is known
+type txHooks struct {
+	mu            sync.Mutex
+	afterCommit   []func()
+	afterRollback []func(err error)
+}
+
+func (tx *TxAssister) committed() {
+	tx.unlock()
+	tx.tally.end()
+
+	tx.hooks.mu.Lock()
+	callbacks := tx.hooks.afterCommit
+	tx.hooks.mu.Unlock()
+
+	for _, fn := range callbacks {
+		tx.runHook(fn)
+	}
+}
+
+func (tx *TxAssister) rolledBack(err error) {
+	tx.unlock()
+	tx.tally.end()
+	tx.runRollbackHooks(err)
+}
+
+func (tx *TxAssister) runRollbackHooks(err error) {
+	tx.hooks.mu.Lock()
+	callbacks := tx.hooks.afterRollback
+	tx.hooks.mu.Unlock()
+
+	for _, fn := range callbacks {
+		tx.runHook(func() { fn(err) })
+	}
+}
+
+// runHook runs a callback recovering & logging a panic
+func (tx *TxAssister) runHook(fn func()) {
+	defer func() {
+		if p := recover(); p != nil {
+			tx.getLogger().Printf("ERROR: transaction callback panicked: %v", p)
+		}
+	}()
+
+	fn()
+}
+
+// SetConstraintsDeferred defers the constraint checks of the rest of the transaction to commit, so records with
+// circular foreign keys can be inserted one by one. Only constraints declared DEFERRABLE are deferred on Postgres,
+// every other constraint is still checked per statement. SQLite defers its foreign key checks instead, MySQL can't defer checks
+/*
+
+Example:
+
+	err := Assister.WithTransaction(ctx, func(tx *sqlAssister.TxAssister) error {
+		err := tx.SetConstraintsDeferred(ctx)
+		if err != nil {
+			return err
+		}
+
+		// insert the employee referencing a department not yet inserted, then the department referencing the employee
+	})
+*/
+func (tx *TxAssister) SetConstraintsDeferred(ctx context.Context) error {
+	var statement string
+	switch tx.dialect {
+	case Postgres:
+		statement = "SET CONSTRAINTS ALL DEFERRED"
+	case SQLite:
+		statement = "PRAGMA defer_foreign_keys = ON"
+	default:
+		return unsupportedDialectf("deferring constraint checks is not supported on %s", tx.dialect)
+	}
+
+	_, err := tx.conn().ExecContext(ctx, statement)
+	return err
+}
+
+func newTxAssister(ctx context.Context, ac Assister, tx *sql.Tx, unlock func()) *TxAssister {
+	txAssister := &TxAssister{
+		Tx:         tx,
+		ctx:        ctx,
+		tally:      newTxTally(ac.getClock()),
+		hooks:      &txHooks{},
+		unlock:     unlock,
+		savepoints: &atomic.Int64{},
+	}
+
+	bound := ac
+	bound.q = txQuerier{tx: tx, ctx: ctx, tally: txAssister.tally}
+	bound.tx = txAssister
+	txAssister.Assister = &bound
+
+	return txAssister
+}
+
+// txQuerier executes statements on a transaction, refusing to once the transaction's context is done, & tallies them
+type txQuerier struct {
+	tx    *sql.Tx
+	ctx   context.Context
+	tally *txTally
+}
+
+func (q txQuerier) checkContext() error {
+	if q.ctx.Err() != nil {
+		return txContextError{q.ctx.Err()}
+	}
+
+	return nil
+}
+
+func (q txQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	err := q.checkContext()
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := q.tx.ExecContext(ctx, query, args...)
+	if err == nil {
+		q.tally.record(query, results)
+	}
+
+	return results, err
+}
+
+func (q txQuerier) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	err := q.checkContext()
+	if err != nil {
+		return nil, err
+	}
+
+	return q.tx.PrepareContext(ctx, query)
+}
+
+func (q txQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	err := q.checkContext()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.tx.QueryContext(ctx, query, args...)
+	if err == nil {
+		q.tally.record(query, nil)
+	}
+
+	return rows, err
+}
+
+// QueryRowContext can't return an error, a done context is reported by the returned row's Scan instead. The row is replayed,
+// see replayRow, as database/sql fails a statement on a transaction it has rolled back with sql.ErrTxDone, & the rollback
+// following a done context happens in the background
+func (q txQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	err := q.checkContext()
+	if err != nil {
+		return replayRow(context.Background(), query, &replay{err: err})
+	}
+
+	row := q.tx.QueryRowContext(ctx, query, args...)
+	if row.Err() == nil {
+		q.tally.record(query, nil)
+	}
+
+	return row
+}
@@ -0,0 +1,112 @@
+package sqlAssister
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// RowTally counts the statements of one kind executed in a transaction & the records they affected
+type RowTally struct {
+	// Statements is the number of statements of this kind executed
+	Statements int
+	// RowsAffected is the total reported by the statements whose driver could report it
+	RowsAffected int64
+	// Unknown is the number of statements whose affected records couldn't be counted,
+	// because the driver can't report them or the statement was run as a query, e.g. INSERT ... RETURNING
+	Unknown int
+}
+
+// TxSummary tallies the writes executed in a transaction, for audit summaries such as "inserted 3 records, updated 1".
+// Reads aren't tallied
+type TxSummary struct {
+	Inserts RowTally
+	Updates RowTally
+	Deletes RowTally
+	// Other tallies every other statement that isn't a read, such as DDL
+	Other RowTally
+	// Statements is the number of statements executed in the transaction, reads included
+	Statements int
+	// Duration is how long the transaction has been open, or was open once it is committed or rolled back
+	Duration time.Duration
+}
+
+// Summary returns the tally of the writes executed in the transaction so far.
+// Only statements executed through the TxAssister are tallied, not those executed on Tx directly
+func (tx *TxAssister) Summary() TxSummary {
+	return tx.tally.summary()
+}
+
+// txTally accumulates a TxSummary as statements are executed, it is shared by every copy of the transaction's querier
+type txTally struct {
+	mu      sync.Mutex
+	tallies TxSummary
+	started time.Time
+	ended   time.Time
+	clock   Clock
+}
+
+func newTxTally(clock Clock) *txTally {
+	return &txTally{started: clock.Now(), clock: clock}
+}
+
+// record tallies a statement, results is nil when the statement was run as a query
+func (t *txTally) record(query string, results sql.Result) {
+	kind := utils.DetectStatementKind(query)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.tallies.Statements++
+	var tally *RowTally
+	switch kind {
+	case utils.StatementSelect:
+		return
+	case utils.StatementInsert:
+		tally = &t.tallies.Inserts
+	case utils.StatementUpdate:
+		tally = &t.tallies.Updates
+	case utils.StatementDelete:
+		tally = &t.tallies.Deletes
+	default:
+		tally = &t.tallies.Other
+	}
+
+	tally.Statements++
+	if results == nil {
+		tally.Unknown++
+		return
+	}
+	rowsAffected, err := results.RowsAffected()
+	if err != nil || rowsAffected < 0 {
+		tally.Unknown++
+		return
+	}
+	tally.RowsAffected += rowsAffected
+}
+
+// end stops the transaction's clock once it is committed or rolled back
+func (t *txTally) end() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.ended.IsZero() {
+		t.ended = t.clock.Now()
+	}
+}
+
+func (t *txTally) summary() TxSummary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	summary := t.tallies
+	ended := t.ended
+	if ended.IsZero() {
+		ended = t.clock.Now()
+	}
+	summary.Duration = ended.Sub(t.started)
+
+	return summary
+}
@@ -0,0 +1,249 @@
+package sqlAssister
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTransactionCancelledBetweenStatements(t *testing.T) {
+	db := openTestDB(t, bookTable)
+	ac := New(db, WithDialect(SQLite))
+
+	// database/sql rolls the transaction back in the background once its context is done, repeat to hit both orders
+	for i := 0; i < 50; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		err := ac.WithTransaction(ctx, func(tx *TxAssister) error {
+			err := tx.UpdateSingleRow(`INSERT INTO "books" ("name") VALUES (?)`, "Dune")
+			if err != nil {
+				return err
+			}
+			cancel()
+
+			_, err = tx.conn().ExecContext(context.Background(), `UPDATE "books" SET "stock" = 1`)
+			if !errors.Is(err, ErrTxContextCanceled) || !errors.Is(err, context.Canceled) {
+				t.Errorf("exec after cancel: expected ErrTxContextCanceled wrapping context.Canceled, got %v", err)
+			}
+			_, err = tx.conn().QueryContext(context.Background(), `SELECT "name" FROM "books"`)
+			if !errors.Is(err, ErrTxContextCanceled) {
+				t.Errorf("query after cancel: expected ErrTxContextCanceled, got %v", err)
+			}
+
+			row, err := tx.SingleRowScannerWithArgs(`SELECT "name" FROM "books" WHERE "id" = ?`, 1)
+			if err != nil {
+				return err
+			}
+			var name string
+			err = row.Scan(&name)
+			if !errors.Is(err, ErrTxContextCanceled) || !errors.Is(err, context.Canceled) {
+				t.Errorf("query row after cancel: expected ErrTxContextCanceled wrapping context.Canceled, got %v", err)
+			}
+
+			return nil
+		})
+		if !errors.Is(err, ErrTxContextCanceled) {
+			t.Fatalf("expected the transaction to fail with ErrTxContextCanceled, got %v", err)
+		}
+	}
+
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM "books"`).Scan(&count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("expected every insert to be rolled back, found %d records", count)
+	}
+	if inUse := db.Stats().InUse; inUse != 0 {
+		t.Errorf("expected no connection in use, %d leaked", inUse)
+	}
+}
+
+// countBooks returns the number of records in the books table
+func countBooks(t *testing.T, ac *Assister) int {
+	t.Helper()
+	count, err := Get[int](context.Background(), ac, `SELECT COUNT(*) FROM "books"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return count
+}
+
+const insertBook = `INSERT INTO "books" ("name") VALUES (?)`
+
+func TestSavepointCommit(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openTestDB(t, bookTable), WithDialect(SQLite))
+
+	var calls []string
+	err := ac.WithTransaction(ctx, func(tx *TxAssister) error {
+		tx.AfterCommit(func() { calls = append(calls, "outer before") })
+		err := tx.WithTransaction(ctx, func(nested *TxAssister) error {
+			nested.AfterCommit(func() { calls = append(calls, "nested") })
+			nested.AfterRollback(func(error) { calls = append(calls, "nested rolled back") })
+			return nested.UpdateSingleRow(insertBook, "nested")
+		})
+		if err != nil {
+			return err
+		}
+		if len(calls) != 0 {
+			t.Errorf("expected the callbacks to wait for the outermost commit, %q ran on release", calls)
+		}
+		tx.AfterCommit(func() { calls = append(calls, "outer after") })
+
+		return tx.UpdateSingleRow(insertBook, "outer")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"outer before", "nested", "outer after"}
+	if strings.Join(calls, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %q, got %q", expected, calls)
+	}
+	if count := countBooks(t, ac); count != 2 {
+		t.Errorf("expected both records committed, got %d", count)
+	}
+}
+
+func TestSavepointErrorRollback(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openTestDB(t, bookTable), WithDialect(SQLite))
+	failure := errors.New("out of stock")
+
+	var calls []string
+	err := ac.WithTransaction(ctx, func(tx *TxAssister) error {
+		err := tx.UpdateSingleRow(insertBook, "outer")
+		if err != nil {
+			return err
+		}
+
+		err = tx.WithTransaction(ctx, func(nested *TxAssister) error {
+			nested.AfterCommit(func() { calls = append(calls, "nested committed") })
+			nested.AfterRollback(func(err error) { calls = append(calls, "nested rolled back: "+err.Error()) })
+			err := nested.UpdateSingleRow(insertBook, "nested")
+			if err != nil {
+				return err
+			}
+			return failure
+		})
+		if !errors.Is(err, failure) {
+			t.Errorf("expected the savepoint's error, got %v", err)
+		}
+		if len(calls) != 1 {
+			t.Errorf("expected the savepoint's rollback callback to run once it rolled back, got %q", calls)
+		}
+
+		// The transaction carries on without the savepoint's work
+		tx.AfterCommit(func() { calls = append(calls, "outer committed") })
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"nested rolled back: out of stock", "outer committed"}
+	if strings.Join(calls, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %q, got %q", expected, calls)
+	}
+	books, err := Select[testBook](ctx, ac, `SELECT * FROM "books"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(books) != 1 || books[0].Name != "outer" {
+		t.Errorf("expected only the outer record committed, got %+v", books)
+	}
+}
+
+func TestSavepointPanicRollback(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openTestDB(t, bookTable), WithDialect(SQLite))
+
+	var calls []string
+	func() {
+		defer func() {
+			if p := recover(); p != "boom" {
+				t.Errorf("expected the panic to be re-raised, got %v", p)
+			}
+		}()
+		_ = ac.WithTransaction(ctx, func(tx *TxAssister) error {
+			tx.AfterRollback(func(err error) { calls = append(calls, "outer rolled back") })
+			err := tx.UpdateSingleRow(insertBook, "outer")
+			if err != nil {
+				return err
+			}
+
+			return tx.WithTransaction(ctx, func(nested *TxAssister) error {
+				nested.AfterCommit(func() { calls = append(calls, "nested committed") })
+				nested.AfterRollback(func(err error) { calls = append(calls, "nested rolled back") })
+				err := nested.UpdateSingleRow(insertBook, "nested")
+				if err != nil {
+					return err
+				}
+				panic("boom")
+			})
+		})
+	}()
+
+	expected := []string{"nested rolled back", "outer rolled back"}
+	if strings.Join(calls, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %q, got %q", expected, calls)
+	}
+	if count := countBooks(t, ac); count != 0 {
+		t.Errorf("expected the whole transaction rolled back, got %d records", count)
+	}
+}
+
+func TestSavepointReleasedThenOuterRollback(t *testing.T) {
+	ctx := context.Background()
+	ac := New(openTestDB(t, bookTable), WithDialect(SQLite))
+	failure := errors.New("payment declined")
+
+	var calls []string
+	err := ac.WithTransaction(ctx, func(tx *TxAssister) error {
+		err := tx.WithTransaction(ctx, func(nested *TxAssister) error {
+			nested.AfterCommit(func() { calls = append(calls, "nested committed") })
+			nested.AfterRollback(func(err error) { calls = append(calls, "nested rolled back: "+err.Error()) })
+			return nested.UpdateSingleRow(insertBook, "nested")
+		})
+		if err != nil {
+			return err
+		}
+
+		// Nested twice, the released savepoint's callbacks are handed up scope by scope
+		err = tx.WithTransaction(ctx, func(nested *TxAssister) error {
+			return nested.WithTransaction(ctx, func(deeper *TxAssister) error {
+				deeper.AfterRollback(func(err error) { calls = append(calls, "deeper rolled back") })
+				return nil
+			})
+		})
+		if err != nil {
+			return err
+		}
+
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected the outer error, got %v", err)
+	}
+
+	expected := []string{"nested rolled back: payment declined", "deeper rolled back"}
+	if strings.Join(calls, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %q, got %q", expected, calls)
+	}
+	if count := countBooks(t, ac); count != 0 {
+		t.Errorf("expected the released savepoint's work rolled back with the transaction, got %d records", count)
+	}
+}
+
+func TestSavepointIsolationLevel(t *testing.T) {
+	ac := New(openTestDB(t, bookTable), WithDialect(SQLite))
+	err := ac.WithTransaction(context.Background(), func(tx *TxAssister) error {
+		return tx.WithSerializable(context.Background(), func(*TxAssister) error { return nil })
+	})
+	if err == nil || !strings.Contains(err.Error(), "savepoint") {
+		t.Errorf("expected an isolation level change in a savepoint to be refused, got %v", err)
+	}
+}
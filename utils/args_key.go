@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ArgsKey returns a stable key for a list of query arguments, for use in cache & deduplication keys.
+// Each argument is encoded with a prefix for its kind so values that print the same never collide (int 1 & string "1").
+// Registered converters & driver.Valuer are applied first, so arguments that bind the same produce the same key.
+// Integers of every size share an encoding as do floats, times are normalized to UTC seconds & nanoseconds dropping
+// the monotonic clock & location, byte slices are hashed & slices are encoded element by element.
+// Returns an error for arguments of any other kind, such as maps & structs that aren't Valuers
+func ArgsKey(args ...any) (string, error) {
+	var b strings.Builder
+	for i, arg := range args {
+		err := writeArgKey(&b, arg, 0)
+		if err != nil {
+			return "", fmt.Errorf("arg %d: %w", i+1, err)
+		}
+	}
+
+	return b.String(), nil
+}
+
+const maxArgKeyDepth = 32
+
+var (
+	valuerType    = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	timeValueType = reflect.TypeOf(time.Time{})
+)
+
+func writeArgKey(b *strings.Builder, arg any, depth int) error {
+	if depth > maxArgKeyDepth {
+		return fmt.Errorf("argument nested too deeply")
+	}
+
+	arg, err := ConvertArg(arg)
+	if err != nil {
+		return err
+	}
+	if arg == nil {
+		b.WriteString("n;")
+		return nil
+	}
+
+	value := reflect.ValueOf(arg)
+	if value.Type().Implements(valuerType) {
+		if value.Kind() == reflect.Pointer && value.IsNil() {
+			b.WriteString("n;")
+			return nil
+		}
+		converted, err := arg.(driver.Valuer).Value()
+		if err != nil {
+			return err
+		}
+		if _, isValuer := converted.(driver.Valuer); isValuer {
+			return fmt.Errorf("%T.Value() returned another driver.Valuer", arg)
+		}
+		return writeArgKey(b, converted, depth+1)
+	}
+
+	if value.Type() == timeValueType {
+		t := arg.(time.Time).UTC()
+		b.WriteString("t:" + strconv.FormatInt(t.Unix(), 10) + "." + strconv.Itoa(t.Nanosecond()) + ";")
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer:
+		if value.IsNil() {
+			b.WriteString("n;")
+			return nil
+		}
+		return writeArgKey(b, value.Elem().Interface(), depth+1)
+	case reflect.Bool:
+		b.WriteString("b:" + strconv.FormatBool(value.Bool()) + ";")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b.WriteString("i:" + strconv.FormatInt(value.Int(), 10) + ";")
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		b.WriteString("i:" + strconv.FormatUint(value.Uint(), 10) + ";")
+	case reflect.Float32, reflect.Float64:
+		b.WriteString("f:" + strconv.FormatFloat(value.Float(), 'g', -1, 64) + ";")
+	case reflect.String:
+		s := value.String()
+		b.WriteString("s" + strconv.Itoa(len(s)) + ":" + s + ";")
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			if value.Kind() == reflect.Slice && value.IsNil() {
+				b.WriteString("n;")
+				return nil
+			}
+			bytes := make([]byte, value.Len())
+			reflect.Copy(reflect.ValueOf(bytes), value)
+			sum := sha256.Sum256(bytes)
+			b.WriteString("x:" + hex.EncodeToString(sum[:]) + ";")
+			return nil
+		}
+		b.WriteString("a" + strconv.Itoa(value.Len()) + "[")
+		for i := 0; i < value.Len(); i++ {
+			err := writeArgKey(b, value.Index(i).Interface(), depth+1)
+			if err != nil {
+				return err
+			}
+		}
+		b.WriteString("];")
+	default:
+		return fmt.Errorf("unsupported argument type %T", arg)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestArgsKeyCollisions(t *testing.T) {
+	instant := time.Date(2024, 3, 1, 12, 0, 0, 500, time.UTC)
+	tests := [][]any{
+		{1},
+		{"1"},
+		{1.0},
+		{1.5},
+		{true},
+		{"true"},
+		{nil},
+		{""},
+		{0},
+		{[]byte("1")},
+		{[]byte("2")},
+		{"a", "b"},
+		{"a;", "b"},
+		{"a", ";b"},
+		{"ab"},
+		{[]int{1, 2}},
+		{[]int{1}, 2},
+		{[]string{"1", "2"}},
+		{[]any{nil}},
+		{instant},
+		{instant.Add(time.Nanosecond)},
+		{instant.Unix()},
+		{sql.NullString{String: "", Valid: false}, "x"},
+		{sql.NullString{String: "x", Valid: true}, nil},
+		{"s1:a;"},
+		{"a", "s1:a;"},
+	}
+
+	keys := map[string]int{}
+	for i, args := range tests {
+		key, err := ArgsKey(args...)
+		if err != nil {
+			t.Fatalf("%#v: %v", args, err)
+		}
+		if other, ok := keys[key]; ok {
+			t.Errorf("%#v & %#v collide on %q", tests[other], args, key)
+		}
+		keys[key] = i
+	}
+}
+
+func TestArgsKeyEquivalence(t *testing.T) {
+	one := 1
+	var nilInt *int
+	instant := time.Date(2024, 3, 1, 12, 0, 0, 500, time.UTC)
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		paris = time.FixedZone("CET", 3600)
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		a, b []any
+	}{
+		{"integer sizes", []any{int8(1), int32(1)}, []any{uint64(1), 1}},
+		{"float sizes", []any{float32(0.5)}, []any{0.5}},
+		{"pointers", []any{&one}, []any{1}},
+		{"nil pointer", []any{nilInt}, []any{nil}},
+		{"valuer", []any{sql.NullInt64{Int64: 1, Valid: true}}, []any{1}},
+		{"null valuer", []any{sql.NullInt64{}}, []any{nil}},
+		{"locations", []any{instant}, []any{instant.In(paris)}},
+		{"monotonic clock", []any{now}, []any{now.Round(0)}},
+		{"byte slices", []any{[]byte("blob")}, []any{[]byte{'b', 'l', 'o', 'b'}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a, err := ArgsKey(test.a...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := ArgsKey(test.b...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if a != b {
+				t.Errorf("expected the same key, got %q & %q", a, b)
+			}
+		})
+	}
+}
+
+func TestArgsKeyUnsupported(t *testing.T) {
+	for _, arg := range []any{map[string]int{"a": 1}, struct{ A int }{1}, make(chan int)} {
+		_, err := ArgsKey("ok", arg)
+		if err == nil {
+			t.Errorf("expected %T to be refused", arg)
+		}
+	}
+}
@@ -8,17 +8,113 @@ import (
 )
 
 // GetRowsAffected helper function that takes the actual number rows affected & compares it to expected number rows affected.
-// Returns an error if the expected rows affected don't match the actual rows affected
+// Returns an error if the expected rows affected don't match the actual rows affected, a *RowsAffectedError logged with the
+// standard logger, & the driver's error as it is when the driver can't report rows affected.
+// See CheckRowsAffected to skip the check for such a driver & leave the logging to the caller
 func GetRowsAffected(results sql.Result, targetNumRowsAffected int64) error {
 	rowsAffected, err := results.RowsAffected()
 	if err != nil {
 		return err
 	}
 	if rowsAffected != targetNumRowsAffected {
-		sqlErr := errors.New(fmt.Sprintf("number of rows affected does not match the expected number of rows affected: %v / %v", rowsAffected, targetNumRowsAffected))
-		log.Printf("ERROR: %s", sqlErr)
-		return sqlErr
+		err := &RowsAffectedError{Affected: rowsAffected, Expected: targetNumRowsAffected}
+		log.Printf("ERROR: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// CheckRowsAffected compares the number of rows affected to the expected number of rows affected, returning a *RowsAffectedError
+// when they differ. Nothing is logged, the caller decides what to do with the error.
+//
+// Not every driver can report rows affected for every statement: some return an error from RowsAffected() & some return -1.
+// Unless strict is set these are treated as "check skipped" & nil is returned rather than a false failure.
+// With strict set they are returned as an error wrapping ErrRowsAffectedUnavailable.
+//
+// MySQL reports the rows CHANGED by an UPDATE by default, so an UPDATE setting a record to the values it already holds affects 0 rows.
+// Connect with the CLIENT_FOUND_ROWS flag (clientFoundRows=true for github.com/go-sql-driver/mysql) to have the rows MATCHED reported instead
+func CheckRowsAffected(results sql.Result, targetNumRowsAffected int64, strict bool) error {
+	rowsAffected, err := RowsAffected(results)
+	if err != nil {
+		if strict {
+			return err
+		}
+		return nil
+	}
+
+	if rowsAffected != targetNumRowsAffected {
+		return &RowsAffectedError{Affected: rowsAffected, Expected: targetNumRowsAffected}
 	}
 
 	return nil
 }
+
+// ErrRowsAffectedUnavailable is wrapped by the errors of RowsAffected, & of CheckRowsAffected when strict, for a driver that can't
+// report the rows a statement affected
+var ErrRowsAffectedUnavailable error = &sentinelError{message: "rows affected unavailable", op: "rows affected check"}
+
+// sentinelError is the type of the package's sentinels, whose Op & Label make them a sqlAssister.Error like the package's
+// error types. Label is "" as a sentinel is shared
+type sentinelError struct {
+	message string
+	op      string
+}
+
+func (e *sentinelError) Error() string { return e.message }
+
+func (e *sentinelError) Op() string { return e.op }
+
+func (e *sentinelError) Label() string { return "" }
+
+// RowsAffected returns the rows affected by a statement, failing with an error wrapping ErrRowsAffectedUnavailable, & the driver's
+// error if any, when the driver returned an error or a negative number
+func RowsAffected(results sql.Result) (int64, error) {
+	rowsAffected, err := results.RowsAffected()
+	if err == nil && rowsAffected < 0 {
+		err = errors.New("driver reported a negative number of rows affected")
+	}
+	if err != nil {
+		return 0, rowsAffectedUnavailableError{err}
+	}
+
+	return rowsAffected, nil
+}
+
+// rowsAffectedUnavailableError matches both ErrRowsAffectedUnavailable & the driver's error
+type rowsAffectedUnavailableError struct {
+	cause error
+}
+
+func (e rowsAffectedUnavailableError) Error() string {
+	return ErrRowsAffectedUnavailable.Error() + ": " + e.cause.Error()
+}
+
+func (e rowsAffectedUnavailableError) Is(target error) bool {
+	return target == ErrRowsAffectedUnavailable
+}
+
+func (e rowsAffectedUnavailableError) Unwrap() error {
+	return e.cause
+}
+
+// Op & Label make the error a sqlAssister.Error, it is never labelled as the statement itself succeeded
+func (e rowsAffectedUnavailableError) Op() string { return "rows affected check" }
+
+func (e rowsAffectedUnavailableError) Label() string { return "" }
+
+// RowsAffectedError is returned by CheckRowsAffected when a statement affected another number of rows than expected,
+// e.g. an UPDATE of a single record matching several
+type RowsAffectedError struct {
+	Affected int64
+	Expected int64
+}
+
+func (e *RowsAffectedError) Error() string {
+	return fmt.Sprintf("number of rows affected does not match the expected number of rows affected: %v / %v", e.Affected, e.Expected)
+}
+
+// Op & Label make the error a sqlAssister.Error, it is never labelled as the statement itself succeeded
+func (e *RowsAffectedError) Op() string { return "rows affected check" }
+
+func (e *RowsAffectedError) Label() string { return "" }
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+)
+
+// fakeResult is a sql.Result reporting rows affected as a driver would
+type fakeResult struct {
+	rowsAffected int64
+	err          error
+}
+
+func (r fakeResult) LastInsertId() (int64, error) { return 0, errors.New("not supported") }
+
+func (r fakeResult) RowsAffected() (int64, error) { return r.rowsAffected, r.err }
+
+func TestCheckRowsAffected(t *testing.T) {
+	driverErr := errors.New("RowsAffected not supported")
+	tests := []struct {
+		name        string
+		result      fakeResult
+		strict      bool
+		mismatch    bool
+		unavailable bool
+	}{
+		{"match", fakeResult{rowsAffected: 1}, false, false, false},
+		{"match strict", fakeResult{rowsAffected: 1}, true, false, false},
+		{"none", fakeResult{rowsAffected: 0}, false, true, false},
+		{"several strict", fakeResult{rowsAffected: 3}, true, true, false},
+		{"negative skipped", fakeResult{rowsAffected: -1}, false, false, false},
+		{"negative strict", fakeResult{rowsAffected: -1}, true, false, true},
+		{"driver error skipped", fakeResult{err: driverErr}, false, false, false},
+		{"driver error strict", fakeResult{err: driverErr}, true, false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := CheckRowsAffected(test.result, 1, test.strict)
+
+			var mismatch *RowsAffectedError
+			if errors.As(err, &mismatch) != test.mismatch {
+				t.Fatalf("expected a *RowsAffectedError %t, got %v", test.mismatch, err)
+			}
+			if test.mismatch && (mismatch.Affected != test.result.rowsAffected || mismatch.Expected != 1) {
+				t.Errorf("expected %d / 1 rows affected, got %d / %d", test.result.rowsAffected, mismatch.Affected, mismatch.Expected)
+			}
+			if errors.Is(err, ErrRowsAffectedUnavailable) != test.unavailable {
+				t.Errorf("expected ErrRowsAffectedUnavailable %t, got %v", test.unavailable, err)
+			}
+			if test.unavailable && test.result.err != nil && !errors.Is(err, driverErr) {
+				t.Errorf("expected the driver's error to be wrapped, got %v", err)
+			}
+			if !test.mismatch && !test.unavailable && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+// TestGetRowsAffected checks GetRowsAffected keeps its original contract: the driver's error returned as it is & a mismatch,
+// a -1 included, logged with the standard logger
+func TestGetRowsAffected(t *testing.T) {
+	var logged bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&logged)
+
+	err := GetRowsAffected(fakeResult{rowsAffected: 1}, 1)
+	if err != nil || logged.Len() > 0 {
+		t.Errorf("expected a match to pass silently, got %v & %q", err, logged.String())
+	}
+
+	driverErr := errors.New("RowsAffected not supported")
+	err = GetRowsAffected(fakeResult{err: driverErr}, 1)
+	if err != driverErr || logged.Len() > 0 {
+		t.Errorf("expected the driver's error returned as it is, got %v & %q", err, logged.String())
+	}
+
+	for _, affected := range []int64{0, 2, -1} {
+		logged.Reset()
+		err = GetRowsAffected(fakeResult{rowsAffected: affected}, 1)
+		var mismatch *RowsAffectedError
+		if !errors.As(err, &mismatch) || mismatch.Affected != affected {
+			t.Errorf("expected a *RowsAffectedError for %d rows, got %v", affected, err)
+		}
+		if !strings.Contains(logged.String(), "ERROR: number of rows affected does not match") {
+			t.Errorf("expected the mismatch of %d rows logged, got %q", affected, logged.String())
+		}
+	}
+}
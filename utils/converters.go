@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"sync"
+)
+
+// ArgConverter converts an argument of a custom type into a value the database driver understands
+type ArgConverter func(v any) (driver.Value, error)
+
+var argConverters sync.Map
+
+// RegisterArgConverter registers the converter applied to arguments of the same type as sample,
+// for types that can't implement driver.Valuer themselves, e.g. types from another package
+func RegisterArgConverter(sample any, converter ArgConverter) {
+	argConverters.Store(reflect.TypeOf(sample), converter)
+}
+
+// ConvertArg applies the converter registered for arg's type, returning arg unchanged when there is none
+func ConvertArg(arg any) (any, error) {
+	if arg == nil {
+		return nil, nil
+	}
+
+	converter, ok := argConverters.Load(reflect.TypeOf(arg))
+	if !ok {
+		return arg, nil
+	}
+
+	return converter.(ArgConverter)(arg)
+}
+
+// ScanConverter converts a value read from the database driver into a custom type.
+// src is nil for NULL, the returned value must be assignable to the registered type
+type ScanConverter func(src any) (any, error)
+
+var scanConverters sync.Map
+
+// RegisterScanConverter registers the converter used when scanning a column into a value of the same type as sample,
+// for types that can't implement sql.Scanner themselves, e.g. types from another package
+func RegisterScanConverter(sample any, converter ScanConverter) {
+	scanConverters.Store(reflect.TypeOf(sample), converter)
+}
+
+// LookupScanConverter returns the converter registered for scanning into t
+func LookupScanConverter(t reflect.Type) (ScanConverter, bool) {
+	converter, ok := scanConverters.Load(t)
+	if !ok {
+		return nil, false
+	}
+
+	return converter.(ScanConverter), true
+}
@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect identifies the flavour of SQL a database speaks.
+// It decides how placeholders are written & how identifiers are quoted in generated SQL
+type Dialect int
+
+const (
+	// Postgres uses $1, $2, ... placeholders & "double quoted" identifiers
+	Postgres Dialect = iota
+	// MySQL uses ? placeholders & `backtick quoted` identifiers
+	MySQL
+	// SQLite uses ? placeholders & "double quoted" identifiers
+	SQLite
+)
+
+// String returns the name of the dialect
+func (d Dialect) String() string {
+	switch d {
+	case Postgres:
+		return "postgres"
+	case MySQL:
+		return "mysql"
+	case SQLite:
+		return "sqlite"
+	default:
+		return "dialect(" + strconv.Itoa(int(d)) + ")"
+	}
+}
+
+// ParseDialect returns the dialect named name, case insensitively: postgres, mysql or sqlite, or one of the names their common
+// drivers register, postgresql, pgx & sqlite3
+func ParseDialect(name string) (Dialect, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "postgres", "postgresql", "pgx":
+		return Postgres, nil
+	case "mysql":
+		return MySQL, nil
+	case "sqlite", "sqlite3":
+		return SQLite, nil
+	}
+
+	return Postgres, fmt.Errorf("unknown dialect %q, expected postgres, mysql or sqlite", name)
+}
+
+// Placeholder returns the bind parameter marker for the n-th (1 based) argument
+func (d Dialect) Placeholder(n int) string {
+	if d == Postgres {
+		return "$" + strconv.Itoa(n)
+	}
+
+	return "?"
+}
+
+// QuoteIdentifier quotes a table or column name for the dialect, escaping any embedded quote characters.
+// Dotted names such as schema.table are quoted part by part
+func QuoteIdentifier(d Dialect, name string) string {
+	quote := `"`
+	if d == MySQL {
+		quote = "`"
+	}
+
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = quote + strings.ReplaceAll(part, quote, quote+quote) + quote
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// QuotingMode decides how generated SQL writes table & column names
+type QuotingMode int
+
+const (
+	// AlwaysQuote quotes identifiers exactly as given, preserving their case, so "CamelCase" names work as written
+	AlwaysQuote QuotingMode = iota
+	// FoldToLower lowercases identifiers before quoting them, matching how Postgres folds unquoted names,
+	// so Table("Books") targets a table created as CREATE TABLE Books
+	FoldToLower
+	// NoQuoting writes plain identifiers unquoted, leaving the database to fold their case.
+	// Names that aren't plain identifiers, such as names with spaces, are still quoted as are reserved words, see IsReservedWord,
+	// which are lowercased on Postgres as it would have folded them
+	NoQuoting
+)
+
+// Quote renders a table or column name for the dialect according to the quoting mode
+func (m QuotingMode) Quote(d Dialect, name string) string {
+	switch m {
+	case FoldToLower:
+		return QuoteIdentifier(d, strings.ToLower(name))
+	case NoQuoting:
+		if ValidateIdentifier(name) == nil {
+			return quoteReservedWords(d, name)
+		}
+	}
+
+	return QuoteIdentifier(d, name)
+}
+
+// quoteReservedWords quotes the parts of a plain identifier that are reserved words, leaving the others as they are
+func quoteReservedWords(d Dialect, name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		if !IsReservedWord(part) {
+			continue
+		}
+		if d == Postgres {
+			part = strings.ToLower(part)
+		}
+		parts[i] = QuoteIdentifier(d, part)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// reservedWords are the keywords Postgres, MySQL or SQLite reject as unquoted table or column names
+var reservedWords = map[string]bool{}
+
+func init() {
+	for _, word := range strings.Fields(`
+		add all alter analyse analyze and any array as asc asymmetric between both by call case cast change check collate column
+		constraint create cross current_catalog current_date current_role current_time current_timestamp current_user database
+		databases default deferrable delete desc describe distinct div do drop dual else end escape except exists explain false
+		fetch for force foreign from full grant group having if ignore in index initially inner insert intersect interval into
+		is join key keys kill lateral leading left like limit localtime localtimestamp lock match mod natural not notnull null
+		offset on only option or order outer placing primary procedure range rank read references regexp rename replace
+		require returning revoke right row rows select session_user set show some symmetric table then to trailing
+		transaction trigger true union unique update usage use user using values variadic view when where window with write
+	`) {
+		reservedWords[word] = true
+	}
+}
+
+// IsReservedWord reports whether name is a keyword Postgres, MySQL or SQLite reject as an unquoted table or column name, e.g. order
+func IsReservedWord(name string) bool {
+	return reservedWords[strings.ToLower(name)]
+}
+
+// ValidateIdentifier checks a table or column name supplied at runtime is a plain identifier:
+// letters, digits & underscores, not starting with a digit, optionally dotted for schema.table names
+func ValidateIdentifier(name string) error {
+	if name == "" {
+		return errors.New("identifier is empty")
+	}
+
+	for _, part := range strings.Split(name, ".") {
+		if part == "" {
+			return fmt.Errorf("invalid identifier %q", name)
+		}
+		for i, r := range part {
+			isLetter := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+			isDigit := r >= '0' && r <= '9'
+			if !isLetter && !(isDigit && i > 0) {
+				return fmt.Errorf("invalid identifier %q", name)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+)
+
+// sqlStateError is implemented by driver errors exposing their SQLSTATE code, e.g. github.com/lib/pq & pgx
+type sqlStateError interface {
+	SQLState() string
+}
+
+// alreadyExistsStates are the SQLSTATE codes for creating an object that already exists
+var alreadyExistsStates = map[string]bool{
+	"42P07": true, // Postgres duplicate_table, also raised for indexes & sequences
+	"42P06": true, // Postgres duplicate_schema
+	"42710": true, // Postgres duplicate_object
+	"42723": true, // Postgres duplicate_function
+	"42S01": true, // MySQL table already exists (error 1050)
+	"42S11": true, // MySQL index already exists
+}
+
+// IsAlreadyExistsError reports whether err is a driver error for DDL creating an object that already exists.
+// Postgres & MySQL errors are recognised by SQLSTATE when the driver exposes it, otherwise every dialect is recognised by its message:
+// Postgres `relation "x" already exists`, MySQL `Error 1050: Table 'x' already exists` & SQLite `table x already exists`
+func IsAlreadyExistsError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) && alreadyExistsStates[stateErr.SQLState()] {
+		return true
+	}
+
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "already exists") ||
+		strings.Contains(message, "error 1050") ||
+		strings.Contains(message, "duplicate key name")
+}
+
+// IsBusyError reports whether err is SQLite reporting the database is locked by another connection (SQLITE_BUSY or SQLITE_LOCKED).
+// SQLite drivers don't share an error type so the error is recognised by its message, `database is locked` or `database table is locked`
+func IsBusyError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "database is locked") ||
+		strings.Contains(message, "database table is locked") ||
+		strings.Contains(message, "sqlite_busy")
+}
+
+// IsUniqueViolation reports whether err is the database rejecting a record whose key, or the values of a unique index, another
+// record already holds. Postgres errors are recognised by SQLSTATE 23505 when the driver exposes it, otherwise every dialect is
+// recognised by its message: Postgres `duplicate key value violates unique constraint`, MySQL `Error 1062: Duplicate entry` &
+// SQLite `UNIQUE constraint failed`
+func IsUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) && stateErr.SQLState() == "23505" {
+		return true
+	}
+
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "duplicate key value") ||
+		strings.Contains(message, "error 1062") ||
+		strings.Contains(message, "unique constraint failed")
+}
+
+// serializationFailureStates are the SQLSTATE codes for a transaction aborted so it can be retried
+var serializationFailureStates = map[string]bool{
+	"40001": true, // serialization_failure, also MySQL's deadlock (error 1213)
+	"40P01": true, // Postgres deadlock_detected
+}
+
+// IsSerializationFailure reports whether err is the database aborting a transaction it couldn't serialize with concurrent ones,
+// or a deadlock, which succeeds when the whole transaction is retried. Errors are recognised by SQLSTATE when the driver exposes it,
+// otherwise by their message: Postgres `could not serialize access` & MySQL `Error 1213: Deadlock found`
+func IsSerializationFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) && serializationFailureStates[stateErr.SQLState()] {
+		return true
+	}
+
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "could not serialize access") ||
+		strings.Contains(message, "deadlock detected") ||
+		strings.Contains(message, "error 1213")
+}
+
+// connectionStates are the SQLSTATE codes, besides those of the 08 connection exception class, for a server going away
+var connectionStates = map[string]bool{
+	"57P01": true, // Postgres admin_shutdown, sent to the sessions of a primary being failed over
+	"57P02": true, // Postgres crash_shutdown
+	"57P03": true, // Postgres cannot_connect_now, the server is starting up or in recovery
+}
+
+// connectionMessages are the messages of connection errors from drivers that expose no SQLSTATE, lowercased
+var connectionMessages = []string{
+	"unexpected eof",             // lib/pq & pgx reading from a closed connection
+	"connection reset",           // the server or a proxy reset the connection
+	"broken pipe",                // writing to a connection closed by the server
+	"connection refused",         // the server isn't accepting connections yet
+	"bad connection",             // database/sql's driver.ErrBadConn
+	"invalid connection",         // go-sql-driver/mysql's ErrInvalidConn
+	"server has gone away",       // MySQL error 2006
+	"lost connection to mysql",   // MySQL error 2013
+	"terminating connection due", // Postgres sessions terminated by a shutdown or an administrator
+	"the database system is",     // Postgres shutting down, starting up or in recovery mode
+}
+
+// IsConnectionError reports whether err is the connection to the database failing rather than the statement, as happens to the
+// statements in flight when a database fails over, so a read that hit it succeeds when retried on a new connection.
+// Postgres errors are recognised by SQLSTATE (class 08 & the 57P0x shutdowns) when the driver exposes it, otherwise errors of
+// every dialect are recognised by the messages lib/pq, pgx & go-sql-driver/mysql return, e.g. `unexpected EOF`,
+// `read: connection reset by peer`, `driver: bad connection` & `invalid connection`. SQLite has no connection to lose
+func IsConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) {
+		state := stateErr.SQLState()
+		if strings.HasPrefix(state, "08") || connectionStates[state] {
+			return true
+		}
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, connectionMessage := range connectionMessages {
+		if strings.Contains(message, connectionMessage) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ErrInvalidEnum is wrapped by the errors of CheckEnum, for a value that isn't one of its enum type's registered values
+var ErrInvalidEnum error = &sentinelError{message: "invalid enum value", op: "enum check"}
+
+// Integer is the constraint of the integer types an enum can be based on
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// enumValues holds the valid values of a registered enum type, in the order they were registered for the errors listing them
+type enumValues struct {
+	valid  map[any]bool
+	values []any
+}
+
+var enums sync.Map
+
+// RegisterEnum registers the valid values of an integer based enum type, typically from an init func next to its constants.
+// Scanning a column into the type, or a pointer to it, then fails with an error wrapping ErrInvalidEnum for any other value
+// rather than letting bad data through as an invalid enum, as does binding a struct field of the type holding another value.
+// Registering a type again replaces its values
+/*
+
+Example:
+
+	type Status int
+
+	const (
+		StatusPending Status = iota + 1
+		StatusShipped
+		StatusDelivered
+	)
+
+	func init() {
+		utils.RegisterEnum(StatusPending, StatusShipped, StatusDelivered)
+	}
+*/
+func RegisterEnum[E Integer](values ...E) {
+	enum := enumValues{valid: make(map[any]bool, len(values)), values: make([]any, len(values))}
+	for i, value := range values {
+		enum.valid[value] = true
+		enum.values[i] = value
+	}
+
+	enums.Store(reflect.TypeOf((*E)(nil)).Elem(), enum)
+}
+
+// IsEnum reports whether t, or the type t points to, is a registered enum type
+func IsEnum(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	_, ok := enums.Load(t)
+
+	return ok
+}
+
+// CheckEnum returns an error wrapping ErrInvalidEnum when v, or the value v points to, is of a registered enum type but isn't
+// one of its values. A nil pointer & a value of any other type are valid
+func CheckEnum(v any) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if !value.IsValid() {
+		return nil
+	}
+
+	registered, ok := enums.Load(value.Type())
+	if !ok {
+		return nil
+	}
+	enum := registered.(enumValues)
+	if enum.valid[value.Interface()] {
+		return nil
+	}
+
+	return fmt.Errorf("%w %d for %s, expected one of %d", ErrInvalidEnum, value.Interface(), value.Type(), enum.values)
+}
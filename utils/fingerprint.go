@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Fingerprint returns a stable hash of a query's shape, for use as a metrics label or to group queries in logs.
+// Queries that differ only in literal values, bind parameters, IN list lengths, comments, case or whitespace
+// share a fingerprint, see NormalizeQuery
+/*
+
+Example:
+
+	// both are "select * from "books" where "id" in (...)"
+	utils.Fingerprint(`SELECT * FROM "books" WHERE "id" IN (1, 2, 3)`) == utils.Fingerprint(`select * from "books" where "id" in ($1, $2)`)
+*/
+func Fingerprint(query string) string {
+	sum := sha256.Sum256([]byte(NormalizeQuery(query)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// NormalizeQuery returns the normalized form of a query Fingerprint hashes: comments are dropped, whitespace is collapsed,
+// keywords & unquoted identifiers are lowercased, string & number literals & bind parameters are replaced with ?,
+// IN lists of parameters are collapsed to (...) & the rows of a multi row VALUES list are collapsed into the first
+func NormalizeQuery(query string) string {
+	tokens := collapseLists(tokenizeSQL(query))
+
+	var b strings.Builder
+	for i, token := range tokens {
+		if i > 0 && !noSpaceBetween(tokens[i-1], token) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(token.text)
+	}
+
+	return b.String()
+}
+
+func collapseLists(tokens []sqlToken) []sqlToken {
+	collapsed := make([]sqlToken, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		collapsed = append(collapsed, token)
+		if token.kind != tokenWord || i+1 >= len(tokens) || tokens[i+1].text != "(" {
+			continue
+		}
+
+		switch token.text {
+		case "in":
+			end, ok := paramGroupEnd(tokens, i+1)
+			if ok {
+				collapsed = append(collapsed, sqlToken{kind: tokenPunct, text: "("}, sqlToken{kind: tokenPunct, text: "..."}, sqlToken{kind: tokenPunct, text: ")"})
+				i = end
+			}
+		case "values":
+			end, ok := paramGroupEnd(tokens, i+1)
+			if !ok {
+				continue
+			}
+			collapsed = append(collapsed, tokens[i+1:end+1]...)
+			i = end
+			for i+2 < len(tokens) && tokens[i+1].text == "," && tokens[i+2].text == "(" {
+				next, ok := paramGroupEnd(tokens, i+2)
+				if !ok {
+					break
+				}
+				i = next
+			}
+		}
+	}
+
+	return collapsed
+}
+
+// paramGroupEnd returns the index of the ) closing the parenthesised list of parameters opened at start
+func paramGroupEnd(tokens []sqlToken, start int) (int, bool) {
+	for i := start + 1; i < len(tokens); i++ {
+		switch {
+		case tokens[i].text == ")":
+			return i, i > start+1
+		case tokens[i].kind == tokenParam || tokens[i].text == ",":
+		default:
+			return 0, false
+		}
+	}
+
+	return 0, false
+}
+
+// spacedKeywords are the keywords followed by a space rather than written like a function call when a ( follows
+var spacedKeywords = map[string]bool{
+	"and": true, "as": true, "exists": true, "from": true, "in": true, "join": true, "not": true,
+	"on": true, "or": true, "select": true, "using": true, "values": true, "where": true, "with": true,
+}
+
+func noSpaceBetween(prev, next sqlToken) bool {
+	if prev.kind == tokenPunct && (prev.text == "(" || prev.text == "." || prev.text == "::") || next.text == "::" {
+		return true
+	}
+	if next.text == "(" {
+		return prev.kind == tokenWord && !spacedKeywords[prev.text]
+	}
+
+	return next.kind == tokenPunct && (next.text == ")" || next.text == "," || next.text == "." || next.text == ";")
+}
@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// addStatements seeds f with the statements of the corpus & a few more hitting the corners of the tokenizer.
+// The inputs fuzzing found failing are kept under testdata/fuzz
+func addStatements(f *testing.F) {
+	for _, test := range statementCorpus {
+		f.Add(test.query)
+	}
+	for _, query := range []string{
+		`SELECT * FROM "books" WHERE "id" IN ($1, $2, $3) AND "name" = 'it''s' -- trailing`,
+		"SELECT * FROM `books` WHERE `name` = 'it\\'s' LIMIT 10",
+		`SELECT E'\'', $fn$ body $fn$, $1$ FROM t /* outer /* inner */ */`,
+		`INSERT INTO books (name) VALUES (?), (?), (?) ON CONFLICT DO NOTHING`,
+		`SELECT * FROM books FOR UPDATE SKIP LOCKED`,
+		`SELECT a <-- comment` + "\n" + `b FROM t`,
+		`SELECT * FROM (SELECT * FROM books LIMIT 1) b FETCH FIRST 5 ROWS ONLY`,
+	} {
+		f.Add(query)
+	}
+}
+
+var dialects = []Dialect{Postgres, MySQL, SQLite}
+
+func FuzzQueryChecker(f *testing.F) {
+	addStatements(f)
+	f.Fuzz(func(t *testing.T, query string) {
+		err := QueryChecker(query)
+		if (err != nil) != (query == "") {
+			t.Errorf("QueryChecker(%q) = %v, expected an error for an empty query only", query, err)
+		}
+
+		for _, d := range dialects {
+			err = QueryChecker(query, func(query string) error { return CheckBalanced(d, query) })
+			if err != nil && query != "" && !errors.Is(err, ErrUnbalanced) {
+				t.Errorf("CheckBalanced(%s, %q) = %v, expected it to wrap ErrUnbalanced", d, query, err)
+			}
+		}
+	})
+}
+
+func FuzzCheckBalanced(f *testing.F) {
+	addStatements(f)
+	f.Fuzz(func(t *testing.T, query string) {
+		for _, d := range dialects {
+			err := CheckBalanced(d, query)
+			if err != nil {
+				if !errors.Is(err, ErrUnbalanced) {
+					t.Errorf("CheckBalanced(%s, %q) = %v, expected it to wrap ErrUnbalanced", d, query, err)
+				}
+				continue
+			}
+
+			// A balanced query stays balanced in parentheses & after a line comment
+			wrapped := "SELECT * FROM (" + query + "\n) q"
+			if err := CheckBalanced(d, wrapped); err != nil {
+				t.Errorf("CheckBalanced(%s, %q) = nil but the query in parentheses is %v", d, query, err)
+			}
+			if err := CheckBalanced(d, query+"\n-- )'"); err != nil {
+				t.Errorf("CheckBalanced(%s, %q) = nil but %v with a trailing comment", d, query, err)
+			}
+		}
+	})
+}
+
+func FuzzNormalizeQuery(f *testing.F) {
+	addStatements(f)
+	f.Fuzz(func(t *testing.T, query string) {
+		normalized := NormalizeQuery(query)
+		if again := NormalizeQuery(normalized); again != normalized {
+			t.Errorf("NormalizeQuery isn't idempotent on %q:\nonce:  %s\ntwice: %s", query, normalized, again)
+		}
+
+		fingerprint := Fingerprint(query)
+		if len(fingerprint) != 16 {
+			t.Errorf("Fingerprint(%q) = %q, expected 16 hex digits", query, fingerprint)
+		}
+		// Leading whitespace & comments don't change the shape
+		for _, prefix := range []string{" \n\t", "-- leading\n", "/* leading */ "} {
+			if other := Fingerprint(prefix + query); other != fingerprint {
+				t.Errorf("Fingerprint(%q) = %s but %s with the prefix %q", query, fingerprint, other, prefix)
+			}
+		}
+	})
+}
+
+func FuzzDetectStatementKind(f *testing.F) {
+	addStatements(f)
+	f.Fuzz(func(t *testing.T, query string) {
+		kind := DetectStatementKind(query)
+		readOnly := IsReadOnlyStatement(query)
+		if kind == StatementSelect && !readOnly {
+			t.Errorf("%q is a SELECT but isn't read only", query)
+		}
+		if readOnly && (kind == StatementInsert || kind == StatementUpdate || kind == StatementDelete) {
+			t.Errorf("%q is an %s but is read only", query, kind)
+		}
+
+		for _, prefix := range []string{" \n\t", "-- leading\n", "/* leading */ "} {
+			if other := DetectStatementKind(prefix + query); other != kind {
+				t.Errorf("DetectStatementKind(%q) = %s but %s with the prefix %q", query, kind, other, prefix)
+			}
+		}
+	})
+}
+
+func FuzzEnsureLimit(f *testing.F) {
+	addStatements(f)
+	f.Fuzz(func(t *testing.T, query string) {
+		limited := EnsureLimit(query, 100)
+		if again := EnsureLimit(limited, 100); again != limited {
+			t.Errorf("EnsureLimit isn't idempotent on %q:\nonce:  %q\ntwice: %q", query, limited, again)
+		}
+		if limited == query {
+			return
+		}
+
+		if !strings.HasPrefix(limited, strings.TrimRight(query, " \t\r\n;")) {
+			t.Errorf("EnsureLimit(%q) = %q, expected the query kept as its prefix", query, limited)
+		}
+		if !HasLimit(limited) || DetectStatementKind(limited) != StatementSelect {
+			t.Errorf("EnsureLimit(%q) = %q, expected a limited SELECT", query, limited)
+		}
+		if normalized := NormalizeQuery(limited); !strings.HasSuffix(normalized, "limit ?") {
+			t.Errorf("EnsureLimit(%q) = %q, expected the LIMIT to end the query, normalized as %q", query, limited, normalized)
+		}
+	})
+}
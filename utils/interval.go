@@ -0,0 +1,302 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// errIntervalOverflow is returned for intervals beyond the ±292 years a time.Duration holds
+var errIntervalOverflow = errors.New("interval overflows a time.Duration")
+
+// intervalUnits are the lengths of the units Postgres writes intervals with, in its postgres & postgres_verbose IntervalStyles.
+// Days are taken as 24 hours as Postgres does outside of timestamp arithmetic, months & years have no fixed length
+var intervalUnits = map[string]time.Duration{
+	"week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+	"day": 24 * time.Hour, "days": 24 * time.Hour,
+	"hour": time.Hour, "hours": time.Hour, "hr": time.Hour, "hrs": time.Hour,
+	"minute": time.Minute, "minutes": time.Minute, "min": time.Minute, "mins": time.Minute,
+	"second": time.Second, "seconds": time.Second, "sec": time.Second, "secs": time.Second,
+	"millisecond": time.Millisecond, "milliseconds": time.Millisecond, "msec": time.Millisecond, "msecs": time.Millisecond,
+	"microsecond": time.Microsecond, "microseconds": time.Microsecond, "usec": time.Microsecond, "usecs": time.Microsecond,
+}
+
+// monthUnits are the units of a month or longer, which can't be converted to a duration
+var monthUnits = map[string]bool{
+	"year": true, "years": true, "yr": true, "yrs": true, "mon": true, "mons": true, "month": true, "months": true,
+	"decade": true, "decades": true, "century": true, "centuries": true, "millennium": true, "millennia": true,
+}
+
+// ParseInterval parses a duration returned by the driver as text: a Postgres interval in its postgres, postgres_verbose,
+// iso_8601 or sql_standard IntervalStyle (e.g. "1 day 02:03:04.5", "@ 1 hour 30 mins ago", "P1DT2H", "-1 2:03:04"),
+// a MySQL TIME such as "-838:59:59" or a number of seconds such as "90.5". Intervals counting months or years fail to parse,
+// they have no fixed length, convert them in SQL with EXTRACT(EPOCH FROM ...) instead. As do intervals beyond the ±292 years
+// a time.Duration holds. Fractions of seconds beyond nanoseconds are truncated
+func ParseInterval(text string) (time.Duration, error) {
+	d, err := parseInterval(strings.TrimSpace(text))
+	if err != nil {
+		return 0, fmt.Errorf("parse interval %q: %w", quoteTruncated(text), err)
+	}
+
+	return d, nil
+}
+
+func parseInterval(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, errors.New("empty interval")
+	}
+	if d, err := parseScaled(s, time.Second); err == nil || errors.Is(err, errIntervalOverflow) {
+		return d, err
+	}
+	if strings.HasPrefix(strings.TrimLeft(s, "+-"), "P") {
+		return parseISOInterval(s)
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(s, "@"))
+	negate := false
+	if len(fields) > 0 && fields[len(fields)-1] == "ago" {
+		negate = true
+		fields = fields[:len(fields)-1]
+	}
+	if len(fields) == 0 {
+		return 0, errors.New("empty interval")
+	}
+
+	var total time.Duration
+	for i := 0; i < len(fields); i++ {
+		var d time.Duration
+		var err error
+		field := fields[i]
+		switch {
+		case strings.Contains(field, ":"):
+			d, err = parseClock(field)
+		case i+1 < len(fields) && strings.Contains(fields[i+1], ":"):
+			// sql_standard writes the days as a bare number before the time, a sign on the days alone applying to both
+			d, err = parseScaled(field, 24*time.Hour)
+			if err == nil && strings.HasPrefix(field, "-") && !strings.ContainsAny(fields[i+1][:1], "+-") {
+				fields[i+1] = "-" + fields[i+1]
+			}
+		case i+1 < len(fields):
+			i++
+			unit := strings.ToLower(fields[i])
+			if monthUnits[unit] {
+				d, err = parseScaled(field, time.Second)
+				if err == nil && d != 0 {
+					err = fmt.Errorf("%s %s have no fixed duration", field, unit)
+				}
+				break
+			}
+			scale, ok := intervalUnits[unit]
+			if !ok {
+				return 0, fmt.Errorf("unknown unit %q", fields[i])
+			}
+			d, err = parseScaled(field, scale)
+		default:
+			return 0, fmt.Errorf("%q has no unit", field)
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		total, err = addDurations(total, d)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if negate {
+		if total == math.MinInt64 {
+			return 0, errIntervalOverflow
+		}
+		total = -total
+	}
+
+	return total, nil
+}
+
+// parseISOInterval parses an ISO 8601 duration such as P1DT2H3M4.5S, whose components may each carry a sign as Postgres writes them
+func parseISOInterval(s string) (time.Duration, error) {
+	negate := false
+	switch s[0] {
+	case '-':
+		negate = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") || len(s) == 1 {
+		return 0, errors.New("malformed ISO 8601 interval")
+	}
+	s = s[1:]
+
+	var total time.Duration
+	inTime := false
+	for s != "" {
+		if s[0] == 'T' {
+			inTime = true
+			s = s[1:]
+			continue
+		}
+
+		end := 0
+		for end < len(s) && (s[end] == '-' || s[end] == '+' || s[end] == '.' || ('0' <= s[end] && s[end] <= '9')) {
+			end++
+		}
+		if end == 0 || end == len(s) {
+			return 0, fmt.Errorf("malformed ISO 8601 interval component %q", s)
+		}
+		number, designator := s[:end], s[end]
+		s = s[end+1:]
+
+		var scale time.Duration
+		switch {
+		case designator == 'Y' && !inTime, designator == 'M' && !inTime:
+			d, err := parseScaled(number, time.Second)
+			if err != nil {
+				return 0, err
+			}
+			if d != 0 {
+				return 0, fmt.Errorf("%s%c has no fixed duration", number, designator)
+			}
+			continue
+		case designator == 'W' && !inTime:
+			scale = 7 * 24 * time.Hour
+		case designator == 'D' && !inTime:
+			scale = 24 * time.Hour
+		case designator == 'H' && inTime:
+			scale = time.Hour
+		case designator == 'M' && inTime:
+			scale = time.Minute
+		case designator == 'S' && inTime:
+			scale = time.Second
+		default:
+			return 0, fmt.Errorf("unexpected ISO 8601 designator %q", designator)
+		}
+
+		d, err := parseScaled(number, scale)
+		if err != nil {
+			return 0, err
+		}
+		total, err = addDurations(total, d)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if negate {
+		if total == math.MinInt64 {
+			return 0, errIntervalOverflow
+		}
+		total = -total
+	}
+
+	return total, nil
+}
+
+// parseClock parses a signed [H]H:MM[:SS[.fraction]] time, the hours being unbounded as in "838:59:59"
+func parseClock(s string) (time.Duration, error) {
+	negate := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		negate = true
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 || strings.ContainsAny(s, "+-") {
+		return 0, fmt.Errorf("malformed time %q", s)
+	}
+	scales := []time.Duration{time.Hour, time.Minute, time.Second}
+	var total time.Duration
+	for i, part := range parts {
+		if part == "" || (i < len(parts)-1 && strings.Contains(part, ".")) {
+			return 0, fmt.Errorf("malformed time %q", s)
+		}
+		d, err := parseScaled(part, scales[i])
+		if err != nil {
+			return 0, err
+		}
+		total, err = addDurations(total, d)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if negate {
+		total = -total
+	}
+
+	return total, nil
+}
+
+// parseScaled parses a signed decimal number such as -1.5 & multiplies it by scale exactly, without going through a float.
+// Digits beyond the scale's resolution are truncated
+func parseScaled(s string, scale time.Duration) (time.Duration, error) {
+	negative := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		negative = true
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	whole, fraction, _ := strings.Cut(s, ".")
+	if whole == "" && fraction == "" {
+		return 0, fmt.Errorf("malformed number %q", s)
+	}
+
+	// Accumulated as a negative number, which reaches one further than a positive one & so holds math.MinInt64
+	var total int64
+	for _, c := range whole {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("malformed number %q", s)
+		}
+		if total < (math.MinInt64+int64(c-'0'))/10 {
+			return 0, errIntervalOverflow
+		}
+		total = total*10 - int64(c-'0')
+	}
+	if total < math.MinInt64/int64(scale) {
+		return 0, errIntervalOverflow
+	}
+	total *= int64(scale)
+
+	divisor := int64(1)
+	for _, c := range fraction {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("malformed number %q", s)
+		}
+		if divisor >= int64(scale) {
+			continue
+		}
+		divisor *= 10
+		step := int64(c-'0') * int64(scale) / divisor
+		if total < math.MinInt64+step {
+			return 0, errIntervalOverflow
+		}
+		total -= step
+	}
+
+	if !negative {
+		if total == math.MinInt64 {
+			return 0, errIntervalOverflow
+		}
+		total = -total
+	}
+
+	return time.Duration(total), nil
+}
+
+// addDurations adds a & b, failing rather than wrapping around when the sum overflows
+func addDurations(a time.Duration, b time.Duration) (time.Duration, error) {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		return 0, errIntervalOverflow
+	}
+
+	return sum, nil
+}
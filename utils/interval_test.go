@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestParseInterval(t *testing.T) {
+	const day = 24 * time.Hour
+	tests := []struct {
+		text string
+		want time.Duration
+	}{
+		{"0", 0},
+		{"90.5", 90*time.Second + 500*time.Millisecond},
+		{"-90.5", -90*time.Second - 500*time.Millisecond},
+		{"1 day 02:03:04.5", day + 2*time.Hour + 3*time.Minute + 4500*time.Millisecond},
+		{"-1 days -02:03:04", -day - 2*time.Hour - 3*time.Minute - 4*time.Second},
+		{"1 day -02:00:00", 22 * time.Hour},
+		{"@ 1 hour 30 mins ago", -90 * time.Minute},
+		{"@ 1 day -1 hour ago", -23 * time.Hour},
+		{"P1DT2H", day + 2*time.Hour},
+		{"-P1DT2H", -day - 2*time.Hour},
+		{"P-1DT2H", -day + 2*time.Hour},
+		{"PT0.000000001S", time.Nanosecond},
+		{"-1 2:03:04", -day - 2*time.Hour - 3*time.Minute - 4*time.Second},
+		{"-838:59:59", -838*time.Hour - 59*time.Minute - 59*time.Second},
+		{"0 years 0 mons 3 days", 3 * day},
+		{"1.0000000001", time.Second},
+		// Beyond the ±68 years an int32 number of seconds holds
+		{"2147483648", math.MaxInt32*time.Second + time.Second},
+		{"-2147483649", math.MinInt32*time.Second - time.Second},
+		{"36525 days", 36525 * day},
+		{"-36525 days 00:00:01", -36525*day + time.Second},
+		{"876600:00:00", 876600 * time.Hour},
+		// The bounds of a time.Duration
+		{"9223372036.854775807", math.MaxInt64},
+		{"-9223372036.854775808", math.MinInt64},
+		{"-106751 days -23:47:16.854775808", math.MinInt64},
+	}
+
+	for _, test := range tests {
+		got, err := ParseInterval(test.text)
+		if err != nil || got != test.want {
+			t.Errorf("ParseInterval(%q) = %v, %v, expected %v", test.text, got, err, test.want)
+		}
+	}
+}
+
+func TestParseIntervalRefusals(t *testing.T) {
+	overflows := []string{
+		"9223372036.854775808",
+		"-9223372036.854775809",
+		"106752 days",
+		"-106752 days",
+		"106751 days 23:47:16.854775808",
+		"2562048:00:00",
+		"P106752D",
+		"@ 106751 days 23:47:16.854775808 ago",
+		"99999999999999999999",
+	}
+	for _, text := range overflows {
+		_, err := ParseInterval(text)
+		if !errors.Is(err, errIntervalOverflow) {
+			t.Errorf("ParseInterval(%q) = %v, expected an overflow", text, err)
+		}
+	}
+
+	for _, text := range []string{"", "ago", "1 month", "P1Y", "-2 years", "1 fortnight", "1:2:3:4", "1.5:00", "P", "PT1D", "1 -"} {
+		_, err := ParseInterval(text)
+		if err == nil || errors.Is(err, errIntervalOverflow) {
+			t.Errorf("ParseInterval(%q) = %v, expected it refused", text, err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// HasLimit reports whether a query limits the records it returns at its top level, with LIMIT or FETCH FIRST/NEXT.
+// A LIMIT inside a subquery doesn't count as it doesn't limit the query's own results
+func HasLimit(query string) bool {
+	tokens := tokenizeSQL(query)
+
+	depth := 0
+	for i, token := range tokens {
+		switch {
+		case token.text == "(":
+			depth++
+		case token.text == ")":
+			depth--
+		case depth > 0 || token.kind != tokenWord:
+		case token.text == "limit":
+			return true
+		case token.text == "fetch" && i+1 < len(tokens) && (tokens[i+1].text == "first" || tokens[i+1].text == "next"):
+			return true
+		}
+	}
+
+	return false
+}
+
+// EnsureLimit appends LIMIT limit to a SELECT that doesn't limit its results already, see HasLimit.
+// Any other statement is returned as is, as is a SELECT with a locking clause such as FOR UPDATE which LIMIT would have to precede,
+// & one ending inside a string, quoted identifier, block comment or parenthesis that would swallow the LIMIT. A MySQL string
+// escaping a quote with a backslash reads as such & isn't limited either
+func EnsureLimit(query string, limit int) string {
+	if DetectStatementKind(query) != StatementSelect || HasLimit(query) || hasLockingClause(query) || !endsAtTopLevel(query) {
+		return query
+	}
+
+	// On its own line so a trailing -- comment doesn't swallow it
+	return strings.TrimRight(query, " \t\r\n;") + "\nLIMIT " + strconv.Itoa(limit)
+}
+
+// hasLockingClause reports whether a query ends in FOR UPDATE, FOR SHARE & the like at its top level
+func hasLockingClause(query string) bool {
+	tokens := tokenizeSQL(query)
+
+	depth := 0
+	for i, token := range tokens {
+		switch {
+		case token.text == "(":
+			depth++
+		case token.text == ")":
+			depth--
+		case depth == 0 && token.text == "for" && i+1 < len(tokens):
+			switch tokens[i+1].text {
+			case "update", "share", "no", "key":
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// endsAtTopLevel reports whether a query ends outside of any string, quoted identifier, block comment & parenthesis,
+// where text appended to it is read as the query's own
+func endsAtTopLevel(query string) bool {
+	tokens, closed := tokenize(query)
+	if !closed {
+		return false
+	}
+
+	depth := 0
+	for _, token := range tokens {
+		switch token.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth < 0 {
+				return false
+			}
+		}
+	}
+
+	return depth == 0
+}
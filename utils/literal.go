@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QuoteLiteral renders value as an SQL literal for the dialect, for the few places that can't take a bind parameter,
+// e.g. COPY options, EXPLAIN settings or DDL defaults.
+//
+// ALWAYS PREFER BIND PARAMETERS. A bound value is never parsed as SQL, a literal is: only reach for QuoteLiteral where the
+// database refuses a parameter, & never to build a WHERE clause or the values of an INSERT.
+//
+// Supported values are deliberately few, anything else fails:
+//   - nil & nil pointers: NULL
+//   - strings: single quoted with embedded quotes doubled. On Postgres a string holding a backslash is written as an E'...' string
+//     with the backslashes doubled, read the same whatever standard_conforming_strings is set to. On MySQL it is written as a
+//     _utf8mb4 X'...' hex literal, read the same whatever NO_BACKSLASH_ESCAPES is set to. Strings holding a NUL byte fail,
+//     no dialect stores them in text
+//   - integers & finite floats: as numbers, negative numbers in parentheses, NaN & infinities fail
+//   - booleans: TRUE & FALSE, 1 & 0 on SQLite
+//   - time.Time: a timestamp with its offset, cast to timestamptz on Postgres, in UTC without offset on MySQL
+//   - pointers to those & driver.Valuer values, such as sql.NullString, through the value they hold
+func QuoteLiteral(d Dialect, value any) (string, error) {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v := reflect.ValueOf(value)
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return "NULL", nil
+		}
+		converted, err := valuer.Value()
+		if err != nil {
+			return "", err
+		}
+		if _, ok := converted.(driver.Valuer); ok {
+			return "", fmt.Errorf("cannot quote %T as a literal, its Value returns a driver.Valuer", value)
+		}
+		return QuoteLiteral(d, converted)
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "NULL", nil
+	case string:
+		return quoteString(d, v)
+	case bool:
+		switch {
+		case d == SQLite && v:
+			return "1", nil
+		case d == SQLite:
+			return "0", nil
+		case v:
+			return "TRUE", nil
+		default:
+			return "FALSE", nil
+		}
+	case time.Time:
+		switch d {
+		case Postgres:
+			return "'" + v.Format("2006-01-02 15:04:05.999999999Z07:00") + "'::timestamptz", nil
+		case MySQL:
+			return "'" + v.UTC().Format("2006-01-02 15:04:05.999999") + "'", nil
+		default:
+			return "'" + v.Format("2006-01-02 15:04:05.999999999-07:00") + "'", nil
+		}
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return "NULL", nil
+		}
+		return QuoteLiteral(d, v.Elem().Interface())
+	case reflect.String:
+		return quoteString(d, v.String())
+	case reflect.Bool:
+		return QuoteLiteral(d, v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return negativeNumber(strconv.FormatInt(v.Int(), 10)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", fmt.Errorf("cannot quote %v as a literal, it isn't a finite number", f)
+		}
+		return negativeNumber(strconv.FormatFloat(f, 'g', -1, v.Type().Bits())), nil
+	}
+
+	return "", fmt.Errorf("cannot quote %T as a literal, only strings, numbers, booleans, times & NULL are supported", value)
+}
+
+// negativeNumber parenthesizes a negative number, which written after a minus would otherwise start a -- comment
+func negativeNumber(number string) string {
+	if strings.HasPrefix(number, "-") {
+		return "(" + number + ")"
+	}
+
+	return number
+}
+
+// quoteString renders s as a string literal for the dialect, see QuoteLiteral
+func quoteString(d Dialect, s string) (string, error) {
+	if strings.IndexByte(s, 0) >= 0 {
+		return "", errors.New("cannot quote a string holding a NUL byte as a literal")
+	}
+
+	quoted := "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	if !strings.Contains(s, `\`) {
+		return quoted, nil
+	}
+
+	switch d {
+	case Postgres:
+		// E'' strings read backslashes as escapes whatever standard_conforming_strings is set to, so doubling them is exact
+		return "E" + strings.ReplaceAll(quoted, `\`, `\\`), nil
+	case MySQL:
+		// A hex literal has no escapes to be read differently with & without NO_BACKSLASH_ESCAPES
+		return "_utf8mb4 X'" + hex.EncodeToString([]byte(s)) + "'", nil
+	default:
+		// SQLite reads backslashes as they are
+		return quoted, nil
+	}
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// failingValuer is a driver.Valuer whose Value fails
+type failingValuer struct{}
+
+func (failingValuer) Value() (driver.Value, error) {
+	return nil, errors.New("no value")
+}
+
+func TestQuoteLiteral(t *testing.T) {
+	name := "O'Brien"
+	var nilName *string
+	stamp := time.Date(2024, 3, 1, 9, 30, 0, 500000000, time.FixedZone("", 2*60*60))
+	type status string
+
+	tests := []struct {
+		value    any
+		postgres string
+		mysql    string
+		sqlite   string
+	}{
+		{nil, "NULL", "NULL", "NULL"},
+		{nilName, "NULL", "NULL", "NULL"},
+		{"", "''", "''", "''"},
+		{"it's", "'it''s'", "'it''s'", "'it''s'"},
+		{&name, "'O''Brien'", "'O''Brien'", "'O''Brien'"},
+		{status("draft"), "'draft'", "'draft'", "'draft'"},
+		{"héllo ☃", "'héllo ☃'", "'héllo ☃'", "'héllo ☃'"},
+		// Backslashes read the same whatever standard_conforming_strings or NO_BACKSLASH_ESCAPES are set to
+		{`C:\path`, `E'C:\\path'`, "_utf8mb4 X'433a5c70617468'", `'C:\path'`},
+		{`\'; DROP TABLE "books"; --`, `E'\\''; DROP TABLE "books"; --'`,
+			"_utf8mb4 X'5c273b2044524f50205441424c452022626f6f6b73223b202d2d'", `'\''; DROP TABLE "books"; --'`},
+		{42, "42", "42", "42"},
+		{int8(-1), "(-1)", "(-1)", "(-1)"},
+		{int64(math.MinInt64), "(-9223372036854775808)", "(-9223372036854775808)", "(-9223372036854775808)"},
+		{uint64(math.MaxUint64), "18446744073709551615", "18446744073709551615", "18446744073709551615"},
+		{-2.5, "(-2.5)", "(-2.5)", "(-2.5)"},
+		{float32(0.1), "0.1", "0.1", "0.1"},
+		{1e21, "1e+21", "1e+21", "1e+21"},
+		{true, "TRUE", "TRUE", "1"},
+		{false, "FALSE", "FALSE", "0"},
+		{stamp, "'2024-03-01 09:30:00.5+02:00'::timestamptz", "'2024-03-01 07:30:00.5'", "'2024-03-01 09:30:00.5+02:00'"},
+		{stamp.UTC().Truncate(time.Second), "'2024-03-01 07:30:00Z'::timestamptz", "'2024-03-01 07:30:00'", "'2024-03-01 07:30:00+00:00'"},
+		{sql.NullString{String: "x", Valid: true}, "'x'", "'x'", "'x'"},
+		{sql.NullString{}, "NULL", "NULL", "NULL"},
+		{sql.NullInt64{Int64: -3, Valid: true}, "(-3)", "(-3)", "(-3)"},
+		{&sql.NullBool{Bool: true, Valid: true}, "TRUE", "TRUE", "1"},
+		{(*sql.NullString)(nil), "NULL", "NULL", "NULL"},
+	}
+
+	for _, test := range tests {
+		for d, want := range map[Dialect]string{Postgres: test.postgres, MySQL: test.mysql, SQLite: test.sqlite} {
+			got, err := QuoteLiteral(d, test.value)
+			if err != nil || got != want {
+				t.Errorf("QuoteLiteral(%s, %#v) = %s & %v, expected %s", d, test.value, got, err, want)
+			}
+		}
+	}
+}
+
+func TestQuoteLiteralRefusals(t *testing.T) {
+	tests := []struct {
+		value any
+		want  string
+	}{
+		{"a\x00b", "NUL byte"},
+		{math.NaN(), "isn't a finite number"},
+		{math.Inf(1), "isn't a finite number"},
+		{float32(math.Inf(-1)), "isn't a finite number"},
+		{[]byte("raw"), "only strings, numbers, booleans, times & NULL are supported"},
+		{[]string{"a"}, "are supported"},
+		{map[string]int{}, "are supported"},
+		{struct{ Name string }{"x"}, "are supported"},
+		{failingValuer{}, "no value"},
+	}
+
+	for _, test := range tests {
+		for _, d := range []Dialect{Postgres, MySQL, SQLite} {
+			got, err := QuoteLiteral(d, test.value)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("QuoteLiteral(%s, %#v) = %s & %v, expected an error mentioning %q", d, test.value, got, err, test.want)
+			}
+		}
+	}
+}
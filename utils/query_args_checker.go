@@ -1,6 +1,13 @@
 package utils
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// QueryCheck is an additional check of a query's text QueryChecker can run before the query is sent, e.g. one calling CheckBalanced
+type QueryCheck func(query string) error
 
 func QueryCheckerWithArgs(query string, args ...any) error {
 	switch {
@@ -19,10 +26,86 @@ func QueryCheckerWithArgs(query string, args ...any) error {
 	}
 }
 
-func QueryChecker(query string) error {
+// QueryChecker checks a query is present, then runs the optional checks on it returning the first error
+func QueryChecker(query string, checks ...QueryCheck) error {
 	if len(query) == 0 {
 		return errors.New("no query present")
 	}
 
+	for _, check := range checks {
+		err := check(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ErrUnbalanced is wrapped by the errors of CheckBalanced
+var ErrUnbalanced error = &sentinelError{message: "malformed query", op: "balance check"}
+
+// CheckBalanced flags a query whose quotes or parentheses are unbalanced, the telltale of a query assembled by string concatenation
+// gone wrong, before the database answers with a confusing syntax error. It is a heuristic sharing the tokenizer's view of the query,
+// not a parser: escaped quotes, quoted identifiers, dollar quoted strings & comments are respected & anything else is left to the database.
+// A backslash escapes quotes in MySQL strings only, as the other dialects follow the SQL standard.
+// Errors give the offset of the offending character & wrap ErrUnbalanced
+func CheckBalanced(d Dialect, query string) error {
+	var open []int
+	for i := 0; i < len(query); {
+		c := query[i]
+		start := i
+		closed := true
+		var what string
+		switch {
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				i = len(query)
+				break
+			}
+			i += end + 1
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			i, closed = skipBlockComment(query, i)
+			what = "block comment"
+		case d == MySQL && (c == '\'' || c == '"'):
+			i, closed = skipBackslashQuoted(query, i, c)
+			what = "string literal"
+		case c == '\'':
+			i, closed = skipQuoted(query, i, '\'')
+			what = "string literal"
+		case isEscapeString(query, i):
+			i, closed = skipBackslashQuoted(query, i+1, '\'')
+			what = "escape string"
+		case c == '"' || c == '`':
+			i, closed = skipQuoted(query, i, c)
+			what = "quoted identifier"
+		case c == '$' && dollarTag(query, i) != "":
+			i, closed = skipDollarQuoted(query, i, dollarTag(query, i))
+			what = "dollar quoted string"
+		case isWordStart(c):
+			for i < len(query) && isWordPart(query[i]) {
+				i++
+			}
+		case c == '(':
+			open = append(open, i)
+			i++
+		case c == ')':
+			if len(open) == 0 {
+				return fmt.Errorf("%w: unbalanced ')' at offset %d", ErrUnbalanced, i)
+			}
+			open = open[:len(open)-1]
+			i++
+		default:
+			i++
+		}
+		if !closed {
+			return fmt.Errorf("%w: unterminated %s starting at offset %d", ErrUnbalanced, what, start)
+		}
+	}
+	if len(open) > 0 {
+		return fmt.Errorf("%w: unclosed '(' at offset %d", ErrUnbalanced, open[len(open)-1])
+	}
+
 	return nil
 }
@@ -0,0 +1,19 @@
+package utils
+
+// HasReturning reports whether a statement returns the records it writes with a RETURNING clause at its top level.
+// A RETURNING inside a common table expression or a subquery doesn't count as the statement's own records aren't returned
+func HasReturning(query string) bool {
+	depth := 0
+	for _, token := range tokenizeSQL(query) {
+		switch {
+		case token.text == "(":
+			depth++
+		case token.text == ")":
+			depth--
+		case depth == 0 && token.kind == tokenWord && token.text == "returning":
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,266 @@
+package utils
+
+// StatementKind classifies a statement by what it does to the data
+type StatementKind int
+
+const (
+	// StatementOther is any statement that isn't one of the kinds below, such as DDL, SET or PRAGMA
+	StatementOther StatementKind = iota
+	// StatementSelect reads records, SELECT, VALUES & TABLE statements
+	StatementSelect
+	// StatementInsert inserts records, INSERT & MySQL's REPLACE statements
+	StatementInsert
+	// StatementUpdate updates records
+	StatementUpdate
+	// StatementDelete deletes records
+	StatementDelete
+)
+
+// String returns the name of the statement kind
+func (k StatementKind) String() string {
+	switch k {
+	case StatementSelect:
+		return "select"
+	case StatementInsert:
+		return "insert"
+	case StatementUpdate:
+		return "update"
+	case StatementDelete:
+		return "delete"
+	default:
+		return "other"
+	}
+}
+
+// DetectStatementKind returns the kind of a statement from its leading keyword, ignoring comments, the parentheses of
+// (SELECT ...) UNION (SELECT ...) & the common table expressions of a WITH clause so `WITH moved AS (...) DELETE FROM ...` is a delete.
+// A data modifying common table expression makes the statement a write, `WITH a AS (INSERT ... RETURNING *) SELECT * FROM a` is an insert,
+// as does SELECT ... INTO creating a table which is other. A write with a RETURNING clause is still a write
+func DetectStatementKind(query string) StatementKind {
+	return statementKind(tokenizeSQL(query))
+}
+
+// statementKind classifies a statement, or the body of a common table expression, from its tokens
+func statementKind(tokens []sqlToken) StatementKind {
+	for len(tokens) > 0 && tokens[0].text == "(" {
+		tokens = tokens[1:]
+	}
+	if len(tokens) == 0 || tokens[0].kind != tokenWord {
+		return StatementOther
+	}
+
+	if tokens[0].text != "with" {
+		return leadingKind(tokens)
+	}
+
+	// The kind of the strongest common table expression, a write in any of them makes the whole statement a write
+	cteKind := StatementOther
+	rest := tokens[1:]
+	if len(rest) > 0 && rest[0].text == "recursive" {
+		rest = rest[1:]
+	}
+	for {
+		// name [(columns)] AS [[NOT] MATERIALIZED] (body)
+		if len(rest) < 2 {
+			return StatementOther
+		}
+		rest = rest[1:]
+		if rest[0].text == "(" {
+			rest = rest[matchParen(rest):]
+		}
+		if len(rest) == 0 || rest[0].text != "as" {
+			return StatementOther
+		}
+		rest = rest[1:]
+		for len(rest) > 0 && (rest[0].text == "not" || rest[0].text == "materialized") {
+			rest = rest[1:]
+		}
+		if len(rest) == 0 || rest[0].text != "(" {
+			return StatementOther
+		}
+
+		end := matchParen(rest)
+		body := rest[1:end]
+		if len(body) > 0 && body[len(body)-1].text == ")" {
+			body = body[:len(body)-1]
+		}
+		if kind := statementKind(body); kind != StatementSelect && kind != StatementOther {
+			cteKind = kind
+		}
+		rest = rest[end:]
+
+		if len(rest) == 0 || rest[0].text != "," {
+			break
+		}
+		rest = rest[1:]
+	}
+
+	kind := statementKind(rest)
+	if kind == StatementSelect && cteKind != StatementOther {
+		return cteKind
+	}
+
+	return kind
+}
+
+// leadingKind classifies a statement that doesn't start with a WITH clause by its leading keyword
+func leadingKind(tokens []sqlToken) StatementKind {
+	switch tokens[0].text {
+	case "select":
+		if selectsInto(tokens) {
+			return StatementOther
+		}
+		return StatementSelect
+	case "values", "table":
+		return StatementSelect
+	case "insert", "replace":
+		return StatementInsert
+	case "update":
+		return StatementUpdate
+	case "delete":
+		return StatementDelete
+	default:
+		return StatementOther
+	}
+}
+
+// selectsInto reports whether a SELECT has a top level INTO, creating a table on Postgres & SQL Server
+func selectsInto(tokens []sqlToken) bool {
+	depth := 0
+	for _, token := range tokens {
+		switch {
+		case token.text == "(":
+			depth++
+		case token.text == ")":
+			depth--
+		case depth == 0 && token.kind == tokenWord && token.text == "into":
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchParen returns the index following the parenthesis closing the one tokens start with, or len(tokens) if it is never closed
+func matchParen(tokens []sqlToken) int {
+	depth := 0
+	for i, token := range tokens {
+		switch token.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+
+	return len(tokens)
+}
+
+// IsSessionStatement reports whether a statement changes the state of the database session beyond the current transaction,
+// state a transaction pooler such as pgbouncer hands to whichever client gets the server connection next:
+// SET, except SET LOCAL, SET TRANSACTION & SET CONSTRAINTS which end with the transaction, RESET, DISCARD, LISTEN & PREPARE.
+// Session state changed by a function call, e.g. SELECT set_config('search_path', ..., false), isn't recognised
+func IsSessionStatement(query string) bool {
+	tokens := tokenizeSQL(query)
+	if len(tokens) == 0 || tokens[0].kind != tokenWord {
+		return false
+	}
+
+	switch tokens[0].text {
+	case "set":
+		if len(tokens) < 2 {
+			return true
+		}
+		switch tokens[1].text {
+		case "local", "transaction", "constraints":
+			return false
+		}
+		return true
+	case "reset", "discard", "listen", "prepare":
+		return true
+	default:
+		return false
+	}
+}
+
+// IsReadOnlyStatement reports whether a statement only reads: a read (see DetectStatementKind), SHOW, DESCRIBE, SET LOCAL &
+// SET TRANSACTION, which last until the transaction ends, & EXPLAIN, unless it is EXPLAIN ANALYZE of a write which runs the write.
+// SET TRANSACTION ... READ WRITE & SET LOCAL of the role, session authorization or read only settings lift the guarantee & may
+// write, as do a session wide SET & RESET, which outlive the statement on a pooled connection.
+// Everything else, writes, DDL, PRAGMA, CALL, COPY, ..., may write. A SELECT calling a function that writes isn't recognised
+func IsReadOnlyStatement(query string) bool {
+	tokens := tokenizeSQL(query)
+	if statementKind(tokens) == StatementSelect {
+		return true
+	}
+	if len(tokens) == 0 || tokens[0].kind != tokenWord {
+		return false
+	}
+
+	switch tokens[0].text {
+	case "show", "describe", "desc":
+		return true
+	case "set":
+		return isReadOnlySet(tokens)
+	case "explain":
+		rest, analyze := tokens[1:], false
+		for len(rest) > 0 {
+			if rest[0].text == "(" {
+				end := matchParen(rest)
+				for _, option := range rest[:end] {
+					analyze = analyze || option.text == "analyze" || option.text == "analyse"
+				}
+				rest = rest[end:]
+				continue
+			}
+			if rest[0].text == "analyze" || rest[0].text == "analyse" {
+				analyze = true
+			} else if rest[0].text != "verbose" && rest[0].text != "query" && rest[0].text != "plan" {
+				break
+			}
+			rest = rest[1:]
+		}
+		return !analyze || statementKind(rest) == StatementSelect
+	default:
+		return false
+	}
+}
+
+// readOnlySetRefused are the settings a SET LOCAL of which may let the transaction write
+var readOnlySetRefused = map[string]bool{
+	"role":                          true,
+	"session":                       true,
+	"session_authorization":         true,
+	"transaction_read_only":         true,
+	"default_transaction_read_only": true,
+}
+
+// isReadOnlySet reports whether the SET statement tokens only reads, see IsReadOnlyStatement
+func isReadOnlySet(tokens []sqlToken) bool {
+	for i, token := range tokens {
+		// Another statement after this one is left to the database, refused
+		if token.text == ";" && i < len(tokens)-1 {
+			return false
+		}
+	}
+	if len(tokens) < 3 {
+		return false
+	}
+
+	switch tokens[1].text {
+	case "transaction":
+		for _, token := range tokens[2:] {
+			if token.kind == tokenWord && token.text == "write" {
+				return false
+			}
+		}
+		return true
+	case "local":
+		return tokens[2].kind == tokenWord && !readOnlySetRefused[tokens[2].text]
+	default:
+		return false
+	}
+}
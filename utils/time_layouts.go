@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultTimeLayouts are the layouts ParseTime tries, in order, covering the text MySQL's text protocol returns for
+// DATETIME, TIMESTAMP & DATE columns & the text SQLite stores times as
+var DefaultTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+	time.RFC3339Nano,
+}
+
+var timeLayouts = struct {
+	sync.RWMutex
+	layouts []string
+}{layouts: DefaultTimeLayouts}
+
+// RegisterTimeLayout adds a layout ParseTime tries after those already registered, for drivers returning times in another format
+func RegisterTimeLayout(layout string) {
+	timeLayouts.Lock()
+	defer timeLayouts.Unlock()
+
+	layouts := make([]string, len(timeLayouts.layouts), len(timeLayouts.layouts)+1)
+	copy(layouts, timeLayouts.layouts)
+	timeLayouts.layouts = append(layouts, layout)
+}
+
+// maxQuotedTimeText is how much of a time that failed to parse is quoted in the error, the column may hold anything
+const maxQuotedTimeText = 64
+
+// ParseTime parses a time returned by the driver as text with the first of layouts that fits it,
+// or the first registered layout that fits it when no layouts are given, see DefaultTimeLayouts. Times without a zone are read as UTC
+func ParseTime(text string, layouts ...string) (time.Time, error) {
+	if len(layouts) == 0 {
+		timeLayouts.RLock()
+		layouts = timeLayouts.layouts
+		timeLayouts.RUnlock()
+	}
+
+	for _, layout := range layouts {
+		parsed, err := time.Parse(layout, text)
+		if err == nil {
+			return parsed, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("time %q matches none of the layouts tried: %q", quoteTruncated(text), layouts)
+}
+
+// quoteTruncated truncates text that failed to parse to the part quoted in the error, the column may hold anything
+func quoteTruncated(text string) string {
+	if len(text) > maxQuotedTimeText {
+		return text[:maxQuotedTimeText] + "..."
+	}
+
+	return text
+}
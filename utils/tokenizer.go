@@ -0,0 +1,257 @@
+package utils
+
+import "strings"
+
+// The tokenizer is the shared lexical layer of the helpers reading SQL text: statement kind detection, LIMIT detection & fingerprinting.
+// It is not a parser, it only needs to tell keywords apart from what merely looks like them in literals, quoted identifiers & comments.
+// It understands '' & E'\'' escaped strings, "double quoted" & `backtick quoted` identifiers, Postgres $tag$ dollar quoted strings,
+// -- line & nested /* block */ comments, & $1, ?, :name & @name bind parameters
+
+type sqlTokenKind int
+
+const (
+	tokenWord sqlTokenKind = iota
+	tokenQuotedIdentifier
+	tokenParam
+	tokenPunct
+)
+
+type sqlToken struct {
+	kind sqlTokenKind
+	text string
+}
+
+// tokenizeSQL splits a query into words, quoted identifiers, punctuation & parameters, which stand for every literal & bind parameter.
+// Comments & whitespace are dropped
+func tokenizeSQL(query string) []sqlToken {
+	tokens, _ := tokenize(query)
+	return tokens
+}
+
+// tokenize is tokenizeSQL also reporting whether the query's last string, quoted identifier or block comment was closed
+func tokenize(query string) (tokens []sqlToken, closed bool) {
+	param := sqlToken{kind: tokenParam, text: "?"}
+
+	closed = true
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case isSpace(c):
+			i++
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return tokens, true
+			}
+			i += end + 1
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			i, closed = skipBlockComment(query, i)
+		case c == '\'':
+			i, closed = skipQuoted(query, i, '\'')
+			tokens = append(tokens, param)
+		case isEscapeString(query, i):
+			i, closed = skipBackslashQuoted(query, i+1, '\'')
+			tokens = append(tokens, param)
+		case c == '"' || c == '`':
+			var end int
+			end, closed = skipQuoted(query, i, c)
+			tokens = append(tokens, sqlToken{kind: tokenQuotedIdentifier, text: query[i:end]})
+			i = end
+		case c == '$' && i+1 < len(query) && isDigit(query[i+1]):
+			i++
+			for i < len(query) && isDigit(query[i]) {
+				i++
+			}
+			tokens = append(tokens, param)
+		case c == '$':
+			tag := dollarTag(query, i)
+			if tag == "" {
+				tokens = append(tokens, sqlToken{kind: tokenPunct, text: "$"})
+				i++
+				break
+			}
+			i, closed = skipDollarQuoted(query, i, tag)
+			tokens = append(tokens, param)
+		case c == '?':
+			i++
+			tokens = append(tokens, param)
+		case (c == ':' || c == '@') && i+1 < len(query) && isWordStart(query[i+1]) && !(i > 0 && query[i-1] == ':'):
+			i++
+			for i < len(query) && isWordPart(query[i]) {
+				i++
+			}
+			tokens = append(tokens, param)
+		case isDigit(c) || (c == '.' && i+1 < len(query) && isDigit(query[i+1])):
+			i = skipNumber(query, i)
+			tokens = append(tokens, param)
+		case isWordStart(c):
+			start := i
+			for i < len(query) && isWordPart(query[i]) {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenWord, text: strings.ToLower(query[start:i])})
+		case strings.IndexByte("(),;.", c) >= 0:
+			i++
+			tokens = append(tokens, sqlToken{kind: tokenPunct, text: string(c)})
+		default:
+			// Operators such as <=, <>, || & :: are kept together, a -- or /* within one starts a comment as it does in Postgres
+			start := i
+			for i < len(query) && isOperator(query[i]) && (i == start || !startsComment(query[i:])) {
+				i++
+			}
+			if i == start {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenPunct, text: query[start:i]})
+		}
+	}
+
+	return tokens, closed
+}
+
+// The skip functions return the index following the construct starting at i, reporting false when the query ends before it is closed
+
+// skipQuoted skips quoted text, a doubled quote character escapes it
+func skipQuoted(query string, i int, quote byte) (int, bool) {
+	for i++; i < len(query); i++ {
+		if query[i] != quote {
+			continue
+		}
+		if i+1 < len(query) && query[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1, true
+	}
+
+	return len(query), false
+}
+
+// isEscapeString reports whether a Postgres E'...' string starts at i
+func isEscapeString(query string, i int) bool {
+	return (query[i] == 'e' || query[i] == 'E') && i+1 < len(query) && query[i+1] == '\'' && (i == 0 || !isWordPart(query[i-1]))
+}
+
+// skipBackslashQuoted skips quoted text in which a backslash escapes the next character as well as a doubled quote character does,
+// as in Postgres E'...' strings & MySQL strings
+func skipBackslashQuoted(query string, i int, quote byte) (int, bool) {
+	for i++; i < len(query); i++ {
+		switch {
+		case query[i] == '\\':
+			i++
+		case query[i] != quote:
+		case i+1 < len(query) && query[i+1] == quote:
+			i++
+		default:
+			return i + 1, true
+		}
+	}
+
+	return len(query), false
+}
+
+// dollarTag returns the opening $tag$ of the Postgres dollar quoted string starting at i, $$...$$ or $tag$...$tag$, or "" when there is none.
+// Tags follow the rules of identifiers so $1$ is a bind parameter followed by a $, not a tag
+func dollarTag(query string, i int) string {
+	tagEnd := strings.IndexByte(query[i+1:], '$')
+	if tagEnd < 0 || !isWord(query[i+1:i+1+tagEnd]) || (tagEnd > 0 && isDigit(query[i+1])) {
+		return ""
+	}
+
+	return query[i : i+tagEnd+2]
+}
+
+// skipDollarQuoted skips the dollar quoted string opened by tag
+func skipDollarQuoted(query string, i int, tag string) (int, bool) {
+	end := strings.Index(query[i+len(tag):], tag)
+	if end < 0 {
+		return len(query), false
+	}
+
+	return i + len(tag) + end + len(tag), true
+}
+
+// skipBlockComment skips a block comment, block comments nest as they do in Postgres
+func skipBlockComment(query string, i int) (int, bool) {
+	depth := 0
+	for i < len(query) {
+		switch {
+		case strings.HasPrefix(query[i:], "/*"):
+			depth++
+			i += 2
+		case strings.HasPrefix(query[i:], "*/"):
+			depth--
+			i += 2
+			if depth == 0 {
+				return i, true
+			}
+		default:
+			i++
+		}
+	}
+
+	return len(query), false
+}
+
+// skipNumber returns the index following the number starting at i, including decimals, exponents & hex
+func skipNumber(query string, i int) int {
+	if strings.HasPrefix(query[i:], "0x") || strings.HasPrefix(query[i:], "0X") {
+		i += 2
+		for i < len(query) && strings.IndexByte("0123456789abcdefABCDEF", query[i]) >= 0 {
+			i++
+		}
+		return i
+	}
+
+	for i < len(query) && (isDigit(query[i]) || query[i] == '.') {
+		i++
+	}
+	if i < len(query) && (query[i] == 'e' || query[i] == 'E') {
+		j := i + 1
+		if j < len(query) && (query[j] == '+' || query[j] == '-') {
+			j++
+		}
+		if j < len(query) && isDigit(query[j]) {
+			i = j
+			for i < len(query) && isDigit(query[i]) {
+				i++
+			}
+		}
+	}
+
+	return i
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isWordStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isWordPart(c byte) bool {
+	return isWordStart(c) || isDigit(c) || c == '$'
+}
+
+func isWord(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isWordPart(s[i]) || s[i] == '$' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func startsComment(s string) bool {
+	return strings.HasPrefix(s, "--") || strings.HasPrefix(s, "/*")
+}
+
+func isOperator(c byte) bool {
+	return strings.IndexByte("+-*/<>=!|&%^~:#", c) >= 0
+}
@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+// renderTokens joins the text of tokens with spaces, quoted identifiers keep their quotes & literals & parameters are ?
+func renderTokens(tokens []sqlToken) string {
+	texts := make([]string, len(tokens))
+	for i, token := range tokens {
+		texts[i] = token.text
+	}
+
+	return strings.Join(texts, " ")
+}
+
+func TestTokenizeSQL(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"words lowered", `SELECT Name FROM Books`, `select name from books`},
+		{"quoted identifiers kept", `SELECT "Name", ` + "`order`" + ` FROM "Books"`, `select "Name" , ` + "`order`" + ` from "Books"`},
+		{"doubled quotes", `SELECT 'it''s', "a""b" FROM t`, `select ? , "a""b" from t`},
+		{"keyword in a string", `SELECT 'DELETE FROM books; --' FROM t`, `select ? from t`},
+		{"escape string", `SELECT E'it\'s -- not a comment', e'\\' FROM t`, `select ? , ? from t`},
+		{"word ending in e", `SELECT name'x' FROM t`, `select name ? from t`},
+		{"line comment", "SELECT 1 -- DELETE FROM books\nFROM t", `select ? from t`},
+		{"trailing line comment", `SELECT 1 -- the end`, `select ?`},
+		{"block comment", `SELECT /* DELETE */ 1`, `select ?`},
+		{"nested block comment", `SELECT /* outer /* inner */ DELETE */ 1`, `select ?`},
+		{"unterminated block comment", `SELECT 1 /* DELETE`, `select ?`},
+		{"dollar quoted", `SELECT $$it's DELETE$$, 1`, `select ? , ?`},
+		{"tagged dollar quoted", `SELECT $fn$ body $$ still $fn$ FROM t`, `select ? from t`},
+		{"function body", `CREATE FUNCTION f() RETURNS int AS $body$ BEGIN DELETE FROM t; END $body$ LANGUAGE plpgsql`,
+			`create function f ( ) returns int as ? language plpgsql`},
+		{"postgres parameters", `SELECT * FROM t WHERE a = $1 AND b = $12`, `select * from t where a = ? and b = ?`},
+		{"parameter then dollar", `SELECT $1$`, `select ? $`},
+		{"named parameters", `SELECT * FROM t WHERE a = :name AND b = @other`, `select * from t where a = ? and b = ?`},
+		{"cast isn't a parameter", `SELECT a::text FROM t`, `select a :: text from t`},
+		{"numbers", `SELECT 1, 2.5, .5, 1e10, 3E-2, 0xFF`, `select ? , ? , ? , ? , ? , ?`},
+		{"operators", `SELECT a <= b, a <> b, a || b FROM t`, `select a <= b , a <> b , a || b from t`},
+		{"comment inside an operator", `SELECT a <-- comment` + "\n" + `b FROM t`, `select a < b from t`},
+		{"qualified names", `SELECT "b"."name" FROM s.books b`, `select "b" . "name" from s . books b`},
+		{"unterminated string", `SELECT 'oops`, `select ?`},
+		{"unterminated identifier", `SELECT "oops`, `select "oops`},
+		{"utf8 words", `SELECT prénom FROM t`, `select prénom from t`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := renderTokens(tokenizeSQL(test.query))
+			if got != test.want {
+				t.Errorf("tokenizeSQL(%q)\ngot:  %s\nwant: %s", test.query, got, test.want)
+			}
+		})
+	}
+}
+
+// statementCorpus holds real world statements with the kind DetectStatementKind returns & whether IsReadOnlyStatement holds
+var statementCorpus = []struct {
+	name     string
+	query    string
+	kind     StatementKind
+	readOnly bool
+}{
+	{"select", `SELECT * FROM books`, StatementSelect, true},
+	{"leading comment", "-- list the books\n/* all of them */ SELECT * FROM books", StatementSelect, true},
+	{"values", `VALUES (1, 'a'), (2, 'b')`, StatementSelect, true},
+	{"table", `TABLE books`, StatementSelect, true},
+	{"parenthesized union", `(SELECT id FROM a) UNION ALL (SELECT id FROM b) ORDER BY 1`, StatementSelect, true},
+	{"union chain", `SELECT id FROM a UNION SELECT id FROM b EXCEPT SELECT id FROM c INTERSECT SELECT id FROM d`, StatementSelect, true},
+	{"select into", `SELECT * INTO archive FROM books`, StatementOther, false},
+	{"into in a subquery", `SELECT (SELECT 1 INTO x) FROM t`, StatementSelect, true},
+	{"multi cte select", `WITH a AS (SELECT 1 AS x), b (y) AS (SELECT x FROM a WHERE x IN (SELECT 1)), c AS MATERIALIZED (SELECT * FROM b)
+		SELECT * FROM a JOIN b ON a.x = b.y JOIN c ON TRUE`, StatementSelect, true},
+	{"recursive cte", `WITH RECURSIVE tree(id, parent) AS (
+			SELECT id, parent FROM nodes WHERE id = $1
+			UNION ALL
+			SELECT n.id, n.parent FROM nodes n JOIN tree t ON n.parent = t.id
+		) SELECT * FROM tree`, StatementSelect, true},
+	{"not materialized cte", `WITH a AS NOT MATERIALIZED (SELECT 1) SELECT * FROM a`, StatementSelect, true},
+	{"cte deleting", `WITH moved AS (DELETE FROM queue WHERE id = $1 RETURNING *) INSERT INTO done SELECT * FROM moved`, StatementInsert, false},
+	{"data modifying cte", `WITH a AS (INSERT INTO t (x) VALUES (1) RETURNING *) SELECT * FROM a`, StatementInsert, false},
+	{"data modifying second cte", `WITH a AS (SELECT 1), b AS (UPDATE t SET x = 1 RETURNING *) SELECT * FROM a, b`, StatementUpdate, false},
+	{"cte then update", `WITH stale AS (SELECT id FROM sessions WHERE seen < now() - interval '1 day')
+		UPDATE sessions SET active = false WHERE id IN (SELECT id FROM stale)`, StatementUpdate, false},
+	{"cte then delete", `WITH "old" AS (SELECT "id" FROM "logs" LIMIT 100) DELETE FROM "logs" WHERE "id" IN (SELECT "id" FROM "old")`, StatementDelete, false},
+	{"keywords in a cte string", `WITH a AS (SELECT 'DELETE FROM t' AS q) SELECT * FROM a`, StatementSelect, true},
+	{"keywords in a cte comment", `WITH a AS (/* UPDATE t */ SELECT 1) SELECT * FROM a`, StatementSelect, true},
+	{"parenthesis in a cte string", `WITH a AS (SELECT ')' AS p) SELECT * FROM a`, StatementSelect, true},
+	{"dollar quoted cte", `WITH a AS (SELECT $q$ ) INSERT $q$ AS p) SELECT * FROM a`, StatementSelect, true},
+	{"insert", `INSERT INTO books (name) VALUES ($1)`, StatementInsert, false},
+	{"insert returning", `INSERT INTO books (name) VALUES ($1) RETURNING id`, StatementInsert, false},
+	{"replace", `REPLACE INTO books (id, name) VALUES (?, ?)`, StatementInsert, false},
+	{"update", `update books set name = ? where id = ?`, StatementUpdate, false},
+	{"delete", `DELETE FROM books WHERE id = :id`, StatementDelete, false},
+	{"ddl", `CREATE TABLE t (id int)`, StatementOther, false},
+	{"pragma", `PRAGMA journal_mode = WAL`, StatementOther, false},
+	{"show", `SHOW search_path`, StatementOther, true},
+	{"set", `SET search_path TO library`, StatementOther, false},
+	{"set local", `SET LOCAL statement_timeout = '5s'`, StatementOther, true},
+	{"set transaction read only", `SET TRANSACTION READ ONLY`, StatementOther, true},
+	{"set transaction isolation", `SET TRANSACTION ISOLATION LEVEL SERIALIZABLE`, StatementOther, true},
+	{"set transaction read write", `SET TRANSACTION ISOLATION LEVEL SERIALIZABLE, READ WRITE`, StatementOther, false},
+	{"set read only off", `SET default_transaction_read_only = off`, StatementOther, false},
+	{"set local read only off", `SET LOCAL transaction_read_only = off`, StatementOther, false},
+	{"set role", `SET ROLE admin`, StatementOther, false},
+	{"set local role", `SET LOCAL ROLE admin`, StatementOther, false},
+	{"set local session authorization", `SET LOCAL SESSION AUTHORIZATION admin`, StatementOther, false},
+	{"set local quoted setting", `SET LOCAL "role" = 'admin'`, StatementOther, false},
+	{"set local then write", `SET LOCAL statement_timeout = '5s'; DELETE FROM books`, StatementOther, false},
+	{"set session", `SET SESSION statement_timeout = '5s'`, StatementOther, false},
+	{"reset", `RESET ALL`, StatementOther, false},
+	{"explain", `EXPLAIN SELECT * FROM books`, StatementOther, true},
+	{"explain a write", `EXPLAIN DELETE FROM books`, StatementOther, true},
+	{"explain analyze a read", `EXPLAIN ANALYZE SELECT * FROM books`, StatementOther, true},
+	{"explain analyze a write", `EXPLAIN ANALYZE DELETE FROM books`, StatementOther, false},
+	{"explain options analyze a write", `EXPLAIN (ANALYZE, BUFFERS) UPDATE books SET stock = 0`, StatementOther, false},
+	{"explain analyze a data modifying cte", `EXPLAIN ANALYZE WITH a AS (DELETE FROM t RETURNING *) SELECT * FROM a`, StatementOther, false},
+	{"malformed cte", `WITH a SELECT 1`, StatementOther, false},
+	{"unclosed cte", `WITH a AS (SELECT 1`, StatementOther, false},
+	{"empty", ``, StatementOther, false},
+	{"comment only", `-- nothing`, StatementOther, false},
+}
+
+func TestStatementCorpus(t *testing.T) {
+	for _, test := range statementCorpus {
+		t.Run(test.name, func(t *testing.T) {
+			kind := DetectStatementKind(test.query)
+			if kind != test.kind {
+				t.Errorf("DetectStatementKind: expected %s, got %s", test.kind, kind)
+			}
+			readOnly := IsReadOnlyStatement(test.query)
+			if readOnly != test.readOnly {
+				t.Errorf("IsReadOnlyStatement: expected %t, got %t", test.readOnly, readOnly)
+			}
+		})
+	}
+}
+
+func TestIsSessionStatement(t *testing.T) {
+	tests := []struct {
+		query   string
+		session bool
+	}{
+		{`SET search_path TO library`, true},
+		{`/* pooled */ set statement_timeout = '5s'`, true},
+		{`SET LOCAL search_path TO library`, false},
+		{`SET TRANSACTION ISOLATION LEVEL SERIALIZABLE`, false},
+		{`SET CONSTRAINTS ALL DEFERRED`, false},
+		{`RESET ALL`, true},
+		{`DISCARD ALL`, true},
+		{`LISTEN events`, true},
+		{`PREPARE q AS SELECT 1`, true},
+		{`SELECT 'SET search_path'`, false},
+		{`SELECT set_config('search_path', 'x', false)`, false},
+	}
+
+	for _, test := range tests {
+		if got := IsSessionStatement(test.query); got != test.session {
+			t.Errorf("IsSessionStatement(%q): expected %t, got %t", test.query, test.session, got)
+		}
+	}
+}
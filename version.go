@@ -0,0 +1,125 @@
+package sqlAssister
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+// UpdateWithVersion updates the single record in table identified by keyColumns (or the fields tagged pk when none are given)
+// using optimistic locking: the UPDATE only applies while versionColumn still holds the version read into record & increments it.
+// Fields are set following the same rules as UpdateStruct.
+// Returns ErrOptimisticLock when no record matched because the version changed (or the record is gone).
+// When record is a pointer its version field is incremented on success so it can be updated again.
+// A driver that can't report rows affected can't report a conflict either: the check is skipped as UpdateSingleRow skips it,
+// the update counting as a success, or fails WithStrictRowsAffected
+/*
+
+Example:
+
+	type Account struct {
+		ID      string `db:"id,pk"`
+		Balance int64  `db:"balance,always"`
+		Version int64  `db:"version"`
+	}
+
+	account.Balance -= amount
+	err := Assister.UpdateWithVersion(ctx, "accounts", &account, nil, "version")
+	if errors.Is(err, sqlAssister.ErrOptimisticLock) {
+		// reload the account & retry
+	}
+*/
+func (ac Assister) UpdateWithVersion(ctx context.Context, table string, record any, keyColumns []string, versionColumn string) error {
+	update, version, err := ac.versionedUpdate(table, record, keyColumns, versionColumn)
+	if err != nil {
+		return err
+	}
+
+	results, err := update.Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	// A driver that can't report rows affected can't report a conflict either, checkRowsAffected skips or fails the check
+	rowsAffected, err := utils.RowsAffected(results)
+	if err == nil && rowsAffected == 0 {
+		return ErrOptimisticLock
+	}
+	err = ac.checkRowsAffected(results, 1)
+	if err != nil {
+		return err
+	}
+
+	if version.CanSet() {
+		if version.Kind() >= reflect.Uint && version.Kind() <= reflect.Uintptr {
+			version.SetUint(version.Uint() + 1)
+		} else {
+			version.SetInt(version.Int() + 1)
+		}
+	}
+
+	return nil
+}
+
+// BuildUpdateWithVersion returns the statement & args UpdateWithVersion executes without executing it, to inspect, log or wrap it
+/*
+
+Example:
+
+	query, args, err := Assister.BuildUpdateWithVersion("accounts", account, nil, "version")
+	if err != nil {
+		return err
+	}
+*/
+func (ac Assister) BuildUpdateWithVersion(table string, record any, keyColumns []string, versionColumn string) (query string, args []any, err error) {
+	update, _, err := ac.versionedUpdate(table, record, keyColumns, versionColumn)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return update.SQL()
+}
+
+// versionedUpdate builds the UPDATE of UpdateWithVersion, returning it along with record's version field
+func (ac Assister) versionedUpdate(table string, record any, keyColumns []string, versionColumn string) (_ *UpdateQuery, _ reflect.Value, err error) {
+	defer ac.panicRecovery().recover(&err)
+
+	err = utils.ValidateIdentifier(table)
+	if err != nil {
+		return nil, reflect.Value{}, err
+	}
+
+	value, info, err := structValue(record)
+	if err != nil {
+		return nil, reflect.Value{}, err
+	}
+	versionField, ok := info.byColumn[versionColumn]
+	if !ok {
+		return nil, reflect.Value{}, fmt.Errorf("%s has no field mapped to version column %q", info.typ, versionColumn)
+	}
+	version := fieldValue(value, versionField)
+	if !isIntKind(version.Kind()) {
+		return nil, reflect.Value{}, fmt.Errorf("version column %q must be mapped to an integer field, not %s", versionColumn, version.Type())
+	}
+
+	keys, skip, err := structKeys(value, info, keyColumns)
+	if err != nil {
+		return nil, reflect.Value{}, err
+	}
+	skip[versionField] = true
+
+	update, err := ac.structUpdate(table, value, info, nil, skip)
+	if err != nil {
+		return nil, reflect.Value{}, err
+	}
+	quoted := ac.QuoteIdentifier(versionColumn)
+	update.Set(versionColumn, sqlExpr(quoted+" + 1"))
+
+	return update.Where(keyConds(keys)...).Where(Eq(versionColumn, version.Interface())), version, nil
+}
+
+func isIntKind(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Uintptr
+}
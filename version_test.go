@@ -0,0 +1,108 @@
+package sqlAssister
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/zobstory/sqlAssister/utils"
+)
+
+type versionedAccount struct {
+	ID      int64 `db:"id,pk"`
+	Balance int64 `db:"balance,always"`
+	Version int64 `db:"version"`
+}
+
+func TestUpdateWithVersion(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t, `CREATE TABLE "accounts" ("id" INTEGER PRIMARY KEY, "balance" INTEGER NOT NULL, "version" INTEGER NOT NULL)`,
+		`INSERT INTO "accounts" VALUES (1, 100, 1)`)
+	ac := New(db, WithDialect(SQLite))
+
+	account := versionedAccount{ID: 1, Balance: 80, Version: 1}
+	stale := account
+	err := ac.UpdateWithVersion(ctx, "accounts", &account, nil, "version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Version != 2 {
+		t.Errorf("expected the version incremented to 2, got %d", account.Version)
+	}
+
+	stale.Balance = 0
+	err = ac.UpdateWithVersion(ctx, "accounts", &stale, nil, "version")
+	if !errors.Is(err, ErrOptimisticLock) {
+		t.Fatalf("expected ErrOptimisticLock updating a stale version, got %v", err)
+	}
+	if stale.Version != 1 {
+		t.Errorf("expected the stale version left as is, got %d", stale.Version)
+	}
+
+	stored, err := GetByID[versionedAccount](ctx, ac, "accounts", Key{"id", 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored != account {
+		t.Errorf("expected %+v stored, got %+v", account, stored)
+	}
+}
+
+func TestUpdateWithVersionRowsAffectedUnavailable(t *testing.T) {
+	driverErr := errors.New("RowsAffected not supported")
+	tests := []struct {
+		name      string
+		result    fakeResult
+		strict    bool
+		err       error
+		increment bool
+	}{
+		{"negative skipped", fakeResult{rowsAffected: -1}, false, nil, true},
+		{"negative strict", fakeResult{rowsAffected: -1}, true, utils.ErrRowsAffectedUnavailable, false},
+		{"driver error skipped", fakeResult{err: driverErr}, false, nil, true},
+		{"driver error strict", fakeResult{err: driverErr}, true, driverErr, false},
+		{"conflict", fakeResult{rowsAffected: 0}, true, ErrOptimisticLock, false},
+		{"several", fakeResult{rowsAffected: 2}, false, &RowsAffectedError{Affected: 2, Expected: 1}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			mock.ExpectExec(`UPDATE "accounts" SET "balance" = \$1, "version" = "version" \+ 1 WHERE "id" = \$2 AND "version" = \$3`).
+				WithArgs(80, 1, 4).
+				WillReturnResult(test.result)
+
+			opts := []Option{}
+			if test.strict {
+				opts = append(opts, WithStrictRowsAffected())
+			}
+			ac := New(db, opts...)
+
+			account := versionedAccount{ID: 1, Balance: 80, Version: 4}
+			err = ac.UpdateWithVersion(context.Background(), "accounts", &account, nil, "version")
+			var mismatch *RowsAffectedError
+			switch {
+			case test.err == nil && err != nil:
+				t.Errorf("expected the check to be skipped, got %v", err)
+			case errors.As(test.err, &mismatch):
+				if !errors.As(err, &mismatch) {
+					t.Errorf("expected a *RowsAffectedError, got %v", err)
+				}
+			case test.err != nil && !errors.Is(err, test.err):
+				t.Errorf("expected %v, got %v", test.err, err)
+			}
+			if (account.Version == 5) != test.increment {
+				t.Errorf("expected the version incremented %t, got %d", test.increment, account.Version)
+			}
+			err = mock.ExpectationsWereMet()
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}